@@ -0,0 +1,91 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+// Assign sets the given local variable.
+//
+// The value is expanded before it is set. The variable's name is not.
+//
+// Unlike Export(), it does not mark the variable to be passed into the
+// environment of any commands that Exec() runs. If the variable has
+// already been exported, it stays exported.
+//
+// It ignores the contents of the pipeline.
+//
+// It is an emulation of UNIX shell scripting's `name=value`
+func Assign(name string, value string, opts ...*StepOption) *SequenceStep {
+	// build our Scriptish command
+	return NewSequenceStep(
+		func(p *Pipe) (int, error) {
+			// expand our input
+			expValue, err := expandString(p, value)
+
+			// debugging support
+			Tracef("Assign(%#v, %#v)", name, value)
+			Tracef("=> Assign(%#v, %#v)", name, expValue)
+
+			if err != nil {
+				return StatusNotOkay, err
+			}
+
+			// if we are not in a sequence, the best that we can do
+			// is set the variable
+			env, ok := getSequenceEnv(p)
+			if !ok {
+				err := p.Env.Setenv(name, expValue)
+				if err != nil {
+					return StatusNotOkay, err
+				}
+				return StatusOkay, nil
+			}
+
+			// we always set the local variable, even if the program's
+			// environment has a variable of the same name
+			err = env.localVars.Setenv(name, expValue)
+			if err != nil {
+				return StatusNotOkay, err
+			}
+
+			// all done
+			return StatusOkay, nil
+		},
+		opts...,
+	)
+}
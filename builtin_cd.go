@@ -0,0 +1,90 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+// Cd changes the sequence's working directory.
+//
+// Any relative paths used by the rest of the sequence are resolved
+// against the new working directory. This includes the working directory
+// of any command that Exec() runs. The program's own working directory
+// is not changed.
+//
+// If dir is empty, Cd changes to $HOME. If dir is `-`, Cd changes to
+// $OLDPWD.
+//
+// It ignores the contents of the pipeline.
+//
+// On success, it returns the status code `StatusOkay`. On failure,
+// it returns the status code `StatusNotOkay`.
+//
+// It is an emulation of UNIX shell scripting's `cd <dir>`
+func Cd(dir string, opts ...*StepOption) *SequenceStep {
+	// build our Scriptish command
+	return NewSequenceStep(
+		func(p *Pipe) (int, error) {
+			// expand our input
+			expDir, err := expandPathArg(p, dir)
+			if err != nil {
+				return StatusNotOkay, err
+			}
+
+			// special cases
+			switch expDir {
+			case "":
+				expDir = p.Env.Getenv("HOME")
+			case "-":
+				expDir = p.Env.Getenv("OLDPWD")
+			}
+
+			// debugging support
+			Tracef("Cd(%#v)", dir)
+			Tracef("=> Cd(%#v)", expDir)
+
+			err = cdPipeDir(p, expDir)
+			if err != nil {
+				return StatusNotOkay, err
+			}
+
+			// all done
+			return StatusOkay, nil
+		},
+		opts...,
+	)
+}
@@ -0,0 +1,277 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCdChangesTheWorkingDirectoryOfTheSequence(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	cwd, _ := os.Getwd()
+	expectedResult := filepath.Join(cwd, "testdata") + "\n"
+
+	list := NewList(
+		Cd("testdata"),
+		Pwd(),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec().String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestCdDoesNotChangeTheProgramWorkingDirectory(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult, _ := os.Getwd()
+
+	list := NewList(
+		Cd("testdata"),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	err := list.Exec().Error()
+	actualResult, _ := os.Getwd()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestCdResolvesRelativePathsFromTheCurrentWorkingDirectory(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	cwd, _ := os.Getwd()
+	expectedResult := filepath.Join(cwd, "testdata", "listfiles") + "\n"
+
+	list := NewList(
+		Cd("testdata"),
+		Cd("listfiles"),
+		Pwd(),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec().String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestCdSetsPwdAndOldPwd(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	cwd, _ := os.Getwd()
+	expectedResult := filepath.Join(cwd, "testdata") + "\n" + cwd + "\n"
+
+	list := NewList(
+		Cd("testdata"),
+		Echo("$PWD"),
+		Echo("$OLDPWD"),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec().String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestCdReturnsAnErrorIfTheDirectoryDoesNotExist(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	pipeline := NewPipeline(
+		Cd("testdata/does-not-exist"),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	err := pipeline.Exec().Error()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.NotNil(t, err)
+	assert.True(t, os.IsNotExist(err))
+	assert.Equal(t, StatusNotOkay, pipeline.StatusCode())
+}
+
+func TestCdReturnsAnErrorIfTheTargetIsNotADirectory(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	pipeline := NewPipeline(
+		Cd("testdata/listfiles/one.txt"),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	err := pipeline.Exec().Error()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.NotNil(t, err)
+	assert.Equal(t, StatusNotOkay, pipeline.StatusCode())
+}
+
+func TestCdAffectsPathsUsedByLaterSteps(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := "one.txt\ntwo.txt\n"
+	list := NewList(
+		Cd("testdata/listfiles"),
+		ListFiles("*.txt"),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec().String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestCdIsUsedAsTheWorkingDirectoryForExec(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	cwd, _ := os.Getwd()
+	expectedResult := filepath.Join(cwd, "testdata") + "\n"
+
+	list := NewList(
+		Cd("testdata"),
+		Exec([]string{"/bin/pwd"}),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec().String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestCdIsInheritedByLogicCalls(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	cwd, _ := os.Getwd()
+	expectedResult := filepath.Join(cwd, "testdata") + "\n"
+
+	list := NewList(
+		Cd("testdata"),
+		If(
+			NewList(TestFilepathExists("listfiles")),
+			NewList(Pwd()),
+		),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec().String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
@@ -45,6 +45,9 @@ import (
 
 // Chmod attempts to change the permissions on the given file.
 //
+// If filepath is a glob pattern or a brace expression, Chmod changes
+// the permissions on every path that it expands to.
+//
 // It ignores the contents of the pipeline.
 //
 // On success, it returns the status code `StatusOkay`. On failure,
@@ -54,17 +57,23 @@ func Chmod(filepath string, mode os.FileMode, opts ...*StepOption) *SequenceStep
 	return NewSequenceStep(
 		func(p *Pipe) (int, error) {
 			// expand our input
-			expFilepath := p.Env.Expand(filepath)
+			expFilepaths, err := expandPathArgs(p, filepath)
 
 			// debugging support
 			Tracef("Chmod(%#v, 0%o)", filepath, mode)
-			Tracef("=> Chmod(%#v, 0%o)", expFilepath, mode)
+			Tracef("=> Chmod(%s, 0%o)", tracePathArgs(expFilepaths), mode)
 
-			err := os.Chmod(expFilepath, mode)
 			if err != nil {
 				return StatusNotOkay, err
 			}
 
+			for _, expFilepath := range expFilepaths {
+				err = os.Chmod(resolvePipePath(p, expFilepath), mode)
+				if err != nil {
+					return StatusNotOkay, err
+				}
+			}
+
 			// all done
 			return StatusOkay, nil
 		},
@@ -0,0 +1,184 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportSetsTheLocalVariable(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := "hello world\n"
+	list := NewList(
+		Export("SCRIPTISH_TEST_EXPORT", "hello $1"),
+		Echo("$SCRIPTISH_TEST_EXPORT"),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec("world").String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+	assert.Equal(t, "hello world", list.LocalVars.Getenv("SCRIPTISH_TEST_EXPORT"))
+}
+
+func TestExportedVariablesArePassedToExecCommands(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := "hello world\n"
+	list := NewList(
+		Export("SCRIPTISH_TEST_EXPORT", "hello world"),
+		Exec([]string{"/bin/sh", "-c", "printenv SCRIPTISH_TEST_EXPORT || true"}),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec().String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestExportedVariablesArePassedToExecCommandsInSubSequences(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := "hello world\nhello world\n"
+	list := NewList(
+		Export("SCRIPTISH_TEST_EXPORT", "hello world"),
+		If(
+			NewList(Exec([]string{"/usr/bin/env", "true"})),
+			NewList(
+				Exec([]string{"/bin/sh", "-c", "printenv SCRIPTISH_TEST_EXPORT || true"}),
+			),
+		),
+		ForWords(
+			"w",
+			[]string{"one"},
+			NewList(
+				Exec([]string{"/bin/sh", "-c", "printenv SCRIPTISH_TEST_EXPORT || true"}),
+			),
+		),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec().String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestVariablesExportedInSubSequencesAreNotPassedBackToTheCaller(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := ""
+	list := NewList(
+		If(
+			NewList(Exec([]string{"/usr/bin/env", "true"})),
+			NewList(
+				Export("SCRIPTISH_TEST_EXPORT", "hello world"),
+			),
+		),
+		Exec([]string{"/bin/sh", "-c", "printenv SCRIPTISH_TEST_EXPORT || true"}),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec().String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestExportDoesNotChangeTheProgramEnvironment(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	list := NewList(
+		Export("SCRIPTISH_TEST_EXPORT_ONLY_LOCAL", "hello world"),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	err := list.Exec().Error()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	_, ok := os.LookupEnv("SCRIPTISH_TEST_EXPORT_ONLY_LOCAL")
+	assert.False(t, ok)
+}
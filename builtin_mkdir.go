@@ -46,6 +46,9 @@ import (
 // Mkdir creates the named directory, along with any parent folders
 // that are needed.
 //
+// If filepath is a brace expression (eg `logs/{app,db}`), Mkdir creates
+// every directory that it expands to.
+//
 // It ignores the contents of the pipeline.
 //
 // On success, it returns the status code `StatusOkay`. On failure,
@@ -55,17 +58,23 @@ func Mkdir(filepath string, mode os.FileMode, opts ...*StepOption) *SequenceStep
 	return NewSequenceStep(
 		func(p *Pipe) (int, error) {
 			// expand our input
-			expFilepath := p.Env.Expand(filepath)
+			expFilepaths, err := expandPathArgs(p, filepath)
 
 			// debugging support
 			Tracef("Mkdir(%#v, 0%o)", filepath, mode)
-			Tracef("=> Mkdir(%#v, 0%o)", expFilepath, mode)
+			Tracef("=> Mkdir(%s, 0%o)", tracePathArgs(expFilepaths), mode)
 
-			err := os.MkdirAll(expFilepath, mode)
 			if err != nil {
 				return StatusNotOkay, err
 			}
 
+			for _, expFilepath := range expFilepaths {
+				err = os.MkdirAll(resolvePipePath(p, expFilepath), mode)
+				if err != nil {
+					return StatusNotOkay, err
+				}
+			}
+
 			// all done
 			return StatusOkay, nil
 		},
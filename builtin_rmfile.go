@@ -45,6 +45,10 @@ import (
 
 // RmFile deletes the given file.
 //
+// If filepath is a glob pattern or a brace expression, RmFile deletes
+// every file that it expands to, stopping at the first one that it
+// cannot delete.
+//
 // It ignores the contents of the pipeline.
 //
 // It ignores the file's file permissions, because the underlying
@@ -54,17 +58,23 @@ func RmFile(filepath string, opts ...*StepOption) *SequenceStep {
 	return NewSequenceStep(
 		func(p *Pipe) (int, error) {
 			// expand our input
-			expFilepath := p.Env.Expand(filepath)
+			expFilepaths, err := expandPathArgs(p, filepath)
 
 			// debugging support
 			Tracef("RmFile(%#v)", filepath)
-			Tracef("=> RmFile(%#v)", expFilepath)
+			Tracef("=> RmFile(%s)", tracePathArgs(expFilepaths))
 
-			err := os.Remove(expFilepath)
 			if err != nil {
 				return StatusNotOkay, err
 			}
 
+			for _, expFilepath := range expFilepaths {
+				err = os.Remove(resolvePipePath(p, expFilepath))
+				if err != nil {
+					return StatusNotOkay, err
+				}
+			}
+
 			// all done
 			return StatusOkay, nil
 		},
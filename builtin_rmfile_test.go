@@ -85,6 +85,35 @@ func TestRmFileRemovesAGivenFile(t *testing.T) {
 	assert.False(t, fileExists)
 }
 
+func TestRmFileRemovesEveryFileThatAGlobMatches(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	dir := makeGlobTestTree(t)
+	defer os.RemoveAll(dir)
+
+	expectedResult := []string{".hidden.txt", "c.log", "sub"}
+	pipeline := NewPipeline(
+		RmFile("*.txt"),
+	)
+	pipeline.Dir = dir
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	err := pipeline.Exec().Error()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	actualResult, err := NewPipeline(ListFiles(dir), XargsBasename()).Exec().Strings()
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
 func TestRmFileSetsErrorIfFileDoesNotExist(t *testing.T) {
 	// ----------------------------------------------------------------
 	// setup your test
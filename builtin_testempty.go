@@ -47,12 +47,16 @@ func TestEmpty(input string, opts ...*StepOption) *SequenceStep {
 	return NewSequenceStep(
 		func(p *Pipe) (int, error) {
 			// expand our input
-			expInput := p.Env.Expand(input)
+			expInput, err := expandString(p, input)
 
 			// debugging support
 			Tracef("TestEmpty(%#v)", input)
 			Tracef("=> TestEmpty(%#v)", expInput)
 
+			if err != nil {
+				return StatusNotOkay, err
+			}
+
 			// is it empty?
 			if len(strings.TrimSpace(expInput)) > 0 {
 				return StatusNotOkay, nil
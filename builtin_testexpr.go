@@ -0,0 +1,75 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+// Test evaluates the given conditional expression. It returns
+// StatusOkay if the expression is true, StatusNotOkay if it is false,
+// and StatusSyntaxError if the expression does not make sense.
+//
+// It supports all of UNIX shells' file, string and integer operators,
+// `!`, `&&` / `-a`, `||` / `-o`, and `( ... )`. Pass each operator
+// and operand as a separate argument, just like you would in a shell
+// script.
+//
+// Each operand is expanded (without globbing) just before it is used.
+// The right-hand side of `=`, `==` and `!=` is a glob pattern. After a
+// `=~` match, the whole match is in the local variable BASH_REMATCH,
+// and the subexpressions are in BASH_REMATCH_1, BASH_REMATCH_2, and
+// so on.
+//
+// It is an emulation of UNIX shell scripting's `[[ ... ]]`.
+func Test(args []string, opts ...*StepOption) *SequenceStep {
+	// build our Scriptish command
+	return NewSequenceStep(
+		func(p *Pipe) (int, error) {
+			// evaluate our expression
+			result, expArgs, err := evalTest(p, args)
+
+			// debugging support
+			Tracef("Test(%#v)", args)
+			Tracef("=> Test(%#v)", expArgs)
+			Tracef("=> %t", result)
+
+			// all done
+			return testStatus(result, err), err
+		},
+		opts...,
+	)
+}
@@ -49,6 +49,9 @@ import (
 // It does not care what the filepath points at (file, folder, named pipe,
 // and so on).
 //
+// If filepath is a glob pattern or a brace expression, every path that
+// it expands to must exist. A glob pattern that matches nothing fails.
+//
 // It ignores the contents of the pipeline.
 // It follows symbolic links.
 func TestFilepathExists(filepath string, opts ...*StepOption) *SequenceStep {
@@ -56,18 +59,29 @@ func TestFilepathExists(filepath string, opts ...*StepOption) *SequenceStep {
 	return NewSequenceStep(
 		func(p *Pipe) (int, error) {
 			// expand our input
-			expFilepath := p.Env.Expand(filepath)
+			expFilepaths, err := expandPathArgs(p, filepath)
 
 			// debugging support
 			Tracef("TestFilepathExists(%#v)", filepath)
-			Tracef("=> TestFilepathExists(%#v)", expFilepath)
+			Tracef("=> TestFilepathExists(%s)", tracePathArgs(expFilepaths))
 
-			// does the file exist?
-			_, err := os.Stat(expFilepath)
 			if err != nil {
 				return StatusNotOkay, err
 			}
 
+			// like UNIX shells, a glob that matches nothing is not a filepath
+			if len(expFilepaths) == 0 {
+				return StatusNotOkay, nil
+			}
+
+			// do the files exist?
+			for _, expFilepath := range expFilepaths {
+				_, err = os.Stat(resolvePipePath(p, expFilepath))
+				if err != nil {
+					return StatusNotOkay, err
+				}
+			}
+
 			// all done
 			return StatusOkay, nil
 		},
@@ -47,6 +47,9 @@ import (
 // Touch creates the named file (if it doesn't exist), or updates its
 // atime and mtime (if it does exist)
 //
+// If filepath is a glob pattern or a brace expression, Touch does this
+// for every path that it expands to.
+//
 // It ignores the contents of the pipeline.
 //
 // On success, it returns the status code `StatusOkay`. On failure,
@@ -56,34 +59,22 @@ func Touch(filepath string, opts ...*StepOption) *SequenceStep {
 	return NewSequenceStep(
 		func(p *Pipe) (int, error) {
 			// expand our input
-			expFilepath := p.Env.Expand(filepath)
+			expFilepaths, err := expandPathArgs(p, filepath)
 
 			// debugging support
 			Tracef("Touch(%#v)", filepath)
-			Tracef("=> Touch(%#v)", expFilepath)
-
-			var fh *os.File
+			Tracef("=> Touch(%s)", tracePathArgs(expFilepaths))
 
-			// does the file exist?
-			_, err := os.Stat(expFilepath)
 			if err != nil {
-				if os.IsNotExist(err) {
-					fh, err = os.OpenFile(expFilepath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-					if err != nil {
-						return StatusNotOkay, err
-					}
-					defer fh.Close()
-				}
-			} else {
-				// if we get here, then the file does exist
-				//
-				// we need to modify its inode data
-				now := time.Now()
-				err = os.Chtimes(expFilepath, now, now)
+				return StatusNotOkay, err
 			}
 
-			if err != nil {
-				return StatusNotOkay, err
+			for _, expFilepath := range expFilepaths {
+				// relative paths start from the sequence's working directory
+				err = touchFile(resolvePipePath(p, expFilepath))
+				if err != nil {
+					return StatusNotOkay, err
+				}
 			}
 
 			// all done
@@ -92,3 +83,26 @@ func Touch(filepath string, opts ...*StepOption) *SequenceStep {
 		opts...,
 	)
 }
+
+// touchFile does the work for Touch(), one file at a time
+func touchFile(fullPath string) error {
+	// does the file exist?
+	_, err := os.Stat(fullPath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return err
+		}
+
+		fh, err := os.OpenFile(fullPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return err
+		}
+		return fh.Close()
+	}
+
+	// if we get here, then the file does exist
+	//
+	// we need to modify its inode data
+	now := time.Now()
+	return os.Chtimes(fullPath, now, now)
+}
@@ -131,3 +131,32 @@ func TestTouchUpdatesTheModifiedTimeOfAFile(t *testing.T) {
 	modTime := fi.ModTime().Unix()
 	assert.True(t, now.Unix()-modTime < 2)
 }
+
+func TestTouchCreatesEveryFileThatABraceExpressionExpandsTo(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	dir := makeGlobTestTree(t)
+	defer os.RemoveAll(dir)
+
+	expectedResult := []string{"new1.md", "new2.md", "new3.md"}
+	pipeline := NewPipeline(
+		Touch("new{1..3}.md"),
+	)
+	pipeline.Dir = dir
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	err := pipeline.Exec().Error()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	actualResult, err := NewPipeline(ListFiles(dir+"/*.md"), XargsBasename()).Exec().Strings()
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
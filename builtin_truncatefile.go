@@ -46,25 +46,34 @@ import (
 // TruncateFile removes the contents of the given file.
 //
 // If the file does not exist, it is created.
+//
+// If filename is a glob pattern or a brace expression, TruncateFile
+// truncates every file that it expands to.
 func TruncateFile(filename string, opts ...*StepOption) *SequenceStep {
 	// build our Scriptish command
 	return NewSequenceStep(
 		func(p *Pipe) (int, error) {
 			// expand our input
-			expFilename := p.Env.Expand(filename)
+			expFilenames, err := expandPathArgs(p, filename)
 
 			// debugging support
 			Tracef("TruncateFile(%#v)", filename)
-			Tracef("=> TruncateFile(%#v)", expFilename)
+			Tracef("=> TruncateFile(%s)", tracePathArgs(expFilenames))
 
-			// open / create the file
-			fh, err := os.OpenFile(expFilename, os.O_TRUNC|os.O_CREATE|os.O_WRONLY, 0644)
 			if err != nil {
 				return StatusNotOkay, err
 			}
 
-			// we're done here
-			fh.Close()
+			for _, expFilename := range expFilenames {
+				// open / create the file
+				fh, err := os.OpenFile(resolvePipePath(p, expFilename), os.O_TRUNC|os.O_CREATE|os.O_WRONLY, 0644)
+				if err != nil {
+					return StatusNotOkay, err
+				}
+
+				// we're done here
+				fh.Close()
+			}
 
 			// all done
 			return StatusOkay, nil
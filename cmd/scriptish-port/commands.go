@@ -0,0 +1,1603 @@
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// mapping describes the Scriptish step that we use in place of a
+// bash command
+type mapping struct {
+	// step is the name of the Scriptish function to call
+	step string
+
+	// args are the Go expressions to pass into the Scriptish function
+	args []string
+
+	// input is a file that the step needs to read from, if any
+	input *word
+
+	// todos are things that someone needs to double-check
+	todos []string
+
+	// globs is true if the step expands glob patterns and brace
+	// expressions in its arguments
+	globs bool
+}
+
+// commandMapper turns a bash command into a Scriptish step
+//
+// It returns nil if it does not understand the command's arguments.
+// args does not include the name of the command itself.
+type commandMapper func(args []*word) *mapping
+
+// commandMappers are all of the bash commands that have a Scriptish
+// equivalent
+var commandMappers = map[string]commandMapper{
+	"[":        mapTest,
+	"[[":       mapTest,
+	"awk":      mapAwk,
+	"basename": mapBasename,
+	"cat":      mapCat,
+	"cd":       mapCd,
+	"chmod":    mapChmod,
+	"cut":      mapCut,
+	"dirname":  mapDirname,
+	"echo":     mapEcho,
+	"exit":     mapExit,
+	"export":   mapExport,
+	"find":     mapFind,
+	"grep":     mapGrep,
+	"head":     mapHead,
+	"let":      mapLet,
+	"ls":       mapLs,
+	"mkdir":    mapMkdir,
+	"mktemp":   mapMktemp,
+	"popd":     mapPopd,
+	"pushd":    mapPushd,
+	"pwd":      mapPwd,
+	"return":   mapReturn,
+	"rm":       mapRm,
+	"rmdir":    mapRmdir,
+	"sed":      mapSed,
+	"sort":     mapSort,
+	"tail":     mapTail,
+	"test":     mapTest,
+	"touch":    mapTouch,
+	"tr":       mapTr,
+	"uniq":     mapUniq,
+	"wc":       mapWc,
+	"which":    mapWhich,
+	"xargs":    mapXargs,
+}
+
+// shellBuiltins are bash commands that only make sense inside the
+// shell that is running the script
+//
+// We cannot Exec() these as external commands, because they would
+// not have the effect that the script expects.
+var shellBuiltins = map[string]bool{
+	".":        true,
+	"[[":       true,
+	"alias":    true,
+	"cd":       true,
+	"declare":  true,
+	"eval":     true,
+	"exec":     true,
+	"exit":     true,
+	"export":   true,
+	"getopts":  true,
+	"let":      true,
+	"local":    true,
+	"popd":     true,
+	"pushd":    true,
+	"read":     true,
+	"readonly": true,
+	"return":   true,
+	"set":      true,
+	"shift":    true,
+	"shopt":    true,
+	"source":   true,
+	"trap":     true,
+	"typeset":  true,
+	"ulimit":   true,
+	"umask":    true,
+	"unset":    true,
+	"wait":     true,
+}
+
+// goString returns the Go expression for the given word
+//
+// Scriptish does not support tilde expansion, so we rewrite any
+// unquoted `~` at the start of the word to use $HOME instead.
+func goString(w *word) string {
+	value := w.value
+	if strings.HasPrefix(w.raw, "~") && (value == "~" || strings.HasPrefix(value, "~/")) {
+		value = "$HOME" + value[1:]
+	}
+	return strconv.Quote(value)
+}
+
+// splitAssignment returns the name and the Go expression for the value
+// of a `name=value` word
+//
+// bash performs tilde expansion on the value, so we do too.
+func splitAssignment(w *word) (string, string) {
+	parts := strings.SplitN(w.value, "=", 2)
+	rawParts := strings.SplitN(w.raw, "=", 2)
+	return parts[0], goString(&word{raw: rawParts[1], value: parts[1]})
+}
+
+// goStrings returns the Go expression for a []string holding the
+// given words
+func goStrings(words []*word) string {
+	values := make([]string, len(words))
+	for i, w := range words {
+		values[i] = goString(w)
+	}
+	return "[]string{" + strings.Join(values, ", ") + "}"
+}
+
+// isFlag returns true if the word looks like a command-line flag
+func isFlag(w *word) bool {
+	return len(w.value) > 1 && w.value[0] == '-'
+}
+
+// globChars are the characters that make Scriptish expand a filepath
+// as a glob pattern or a brace expression
+const globChars = "*?[{"
+
+// hasGlob returns true if the word contains an unquoted glob pattern
+// or brace expression
+//
+// Only some Scriptish steps expand these, so the others need to
+// leave the command to bash.
+func hasGlob(w *word) bool {
+	unquoted, _ := globQuoting(w)
+	return unquoted
+}
+
+// globQuoting tells you if the word has any glob characters outside
+// of quotes, and if it has any inside of quotes
+//
+// Bash only expands the glob characters that are outside of quotes.
+// Scriptish cannot tell the difference.
+func globQuoting(w *word) (unquoted bool, quoted bool) {
+	var inSingle, inDouble bool
+	raw := w.raw
+
+	for i := 0; i < len(raw); i++ {
+		c := raw[i]
+		switch {
+		case c == '\\' && !inSingle:
+			if i+1 < len(raw) && strings.IndexByte(globChars, raw[i+1]) >= 0 {
+				quoted = true
+			}
+			i++
+		case c == '\'' && !inDouble:
+			inSingle = !inSingle
+		case c == '"' && !inSingle:
+			inDouble = !inDouble
+		case c == '$' && !inSingle && i+1 < len(raw) && raw[i+1] == '{':
+			// `${...}` is a variable, not a brace expression
+			end := strings.IndexByte(raw[i:], '}')
+			if end < 0 {
+				return unquoted, quoted
+			}
+			i += end
+		case c == '$' && !inSingle && strings.HasPrefix(raw[i+1:], "(("):
+			// `$((...))` is arithmetic, not a glob pattern
+			end := strings.Index(raw[i:], "))")
+			if end < 0 {
+				return unquoted, quoted
+			}
+			i += end + 1
+		case strings.IndexByte(globChars, c) >= 0:
+			if inSingle || inDouble {
+				quoted = true
+			} else {
+				unquoted = true
+			}
+		}
+	}
+
+	return unquoted, quoted
+}
+
+// globOptions returns any step options that a step which expands glob
+// patterns needs, so that it treats the given arguments like bash would
+func globOptions(args []*word) ([]string, []string) {
+	var unquoted, quoted bool
+	for _, arg := range args {
+		u, q := globQuoting(arg)
+		unquoted = unquoted || u
+		quoted = quoted || q
+	}
+
+	switch {
+	case !quoted:
+		return nil, nil
+	case !unquoted:
+		return []string{stepCall("NoGlob")}, nil
+	default:
+		return nil, []string{"Scriptish will also expand the glob characters that are quoted here"}
+	}
+}
+
+// optionalInput deals with commands that take an optional filename
+// to read from
+func optionalInput(m *mapping, args []*word) *mapping {
+	switch len(args) {
+	case 0:
+		return m
+	case 1:
+		if isFlag(args[0]) || hasGlob(args[0]) {
+			return nil
+		}
+		m.input = args[0]
+		return m
+	default:
+		return nil
+	}
+}
+
+// singleArg deals with commands that take exactly one argument
+func singleArg(step string, args []*word) *mapping {
+	if len(args) != 1 || isFlag(args[0]) || hasGlob(args[0]) {
+		return nil
+	}
+	return &mapping{step: step, args: []string{goString(args[0])}}
+}
+
+// pathArg deals with commands that take exactly one filepath, which
+// the Scriptish step expands as a glob pattern
+func pathArg(step string, args []*word) *mapping {
+	if len(args) != 1 || isFlag(args[0]) {
+		return nil
+	}
+	return &mapping{step: step, args: []string{goString(args[0])}, globs: true}
+}
+
+// noArgs deals with commands that do not take any arguments
+func noArgs(step string, args []*word) *mapping {
+	if len(args) != 0 {
+		return nil
+	}
+	return &mapping{step: step}
+}
+
+// lineCount parses the `-n N` / `-nN` / `-N` flag used by head and tail
+func lineCount(args []*word) (int, []*word, bool) {
+	if len(args) == 0 || !isFlag(args[0]) {
+		return 10, args, true
+	}
+
+	flag := args[0].value
+	rest := args[1:]
+	switch {
+	case flag == "-n":
+		if len(rest) == 0 {
+			return 0, nil, false
+		}
+		flag = rest[0].value
+		rest = rest[1:]
+	case strings.HasPrefix(flag, "-n"):
+		flag = flag[2:]
+	default:
+		flag = flag[1:]
+	}
+
+	n, err := strconv.Atoi(flag)
+	if err != nil || n < 0 {
+		return 0, nil, false
+	}
+	return n, rest, true
+}
+
+// awkPrintRegex matches the awk programs that only print some of the
+// fields, such as `{ print $1, $NF }`
+var awkPrintRegex = regexp.MustCompile(`^\s*\{\s*print\s+(\$([1-9][0-9]*|NF|\(NF-[1-9][0-9]*\))(\s*,\s*\$([1-9][0-9]*|NF|\(NF-[1-9][0-9]*\)))*)\s*;?\s*\}\s*$`)
+
+// awkFieldRegex matches a single field in an awk print statement
+var awkFieldRegex = regexp.MustCompile(`\$([1-9][0-9]*|NF|\(NF-[1-9][0-9]*\))`)
+
+func mapAwk(args []*word) *mapping {
+	fopts := []string{}
+
+	// what separates the fields?
+	if len(args) > 0 && strings.HasPrefix(args[0].value, "-F") {
+		separator := args[0].value[2:]
+		args = args[1:]
+		if separator == "" {
+			if len(args) == 0 {
+				return nil
+			}
+			separator = args[0].value
+			args = args[1:]
+		}
+
+		switch {
+		case separator == " ":
+			// awk's default
+		case separator == `\t`:
+			fopts = append(fopts, `Delimiter: "\t"`)
+		case len(separator) == 1:
+			fopts = append(fopts, "Delimiter: "+strconv.Quote(separator))
+		default:
+			fopts = append(fopts, "DelimiterRegex: "+strconv.Quote(separator))
+		}
+		if len(fopts) > 0 {
+			fopts = append(fopts, `OutputDelimiter: " "`)
+		}
+	}
+
+	// we only understand programs that print some of the fields
+	if len(args) == 0 || isFlag(args[0]) {
+		return nil
+	}
+	matches := awkPrintRegex.FindStringSubmatch(args[0].value)
+	if matches == nil {
+		return nil
+	}
+	spec := []string{}
+	for _, field := range awkFieldRegex.FindAllStringSubmatch(matches[1], -1) {
+		spec = append(spec, strings.Trim(field[1], "()"))
+	}
+
+	retval := mapping{
+		step: "SelectFields",
+		args: []string{
+			strconv.Quote(strings.Join(spec, ",")),
+			"scriptish.FieldOptions{" + strings.Join(fopts, ", ") + "}",
+		},
+	}
+	return optionalInput(&retval, args[1:])
+}
+
+func mapBasename(args []*word) *mapping {
+	return singleArg("Basename", args)
+}
+
+func mapCat(args []*word) *mapping {
+	switch {
+	case len(args) == 0:
+		return &mapping{step: "Cat"}
+	case len(args) == 1 && args[0].value == "-":
+		return &mapping{step: "Cat"}
+	}
+	return pathArg("CatFile", args)
+}
+
+func mapCd(args []*word) *mapping {
+	if len(args) == 0 {
+		return &mapping{step: "Cd", args: []string{`""`}}
+	}
+	if len(args) == 1 && args[0].value == "-" {
+		return &mapping{step: "Cd", args: []string{`"-"`}}
+	}
+	return singleArg("Cd", args)
+}
+
+// octalMode matches the file modes that we can pass into Chmod()
+var octalMode = regexp.MustCompile(`^0?[0-7]{3}$`)
+
+func mapChmod(args []*word) *mapping {
+	if len(args) != 2 || !octalMode.MatchString(args[0].value) || isFlag(args[1]) {
+		return nil
+	}
+
+	mode := args[0].value
+	if len(mode) == 3 {
+		mode = "0" + mode
+	}
+	return &mapping{step: "Chmod", args: []string{goString(args[1]), mode}, globs: true}
+}
+
+func mapCut(args []*word) *mapping {
+	var step, spec string
+	var delimiter *word
+
+	for len(args) > 0 && isFlag(args[0]) {
+		flag := args[0].value[:2]
+		value := args[0].value[2:]
+		args = args[1:]
+
+		// the flag's value can be the next argument
+		if value == "" {
+			if len(args) == 0 {
+				return nil
+			}
+			value = args[0].value
+			args = args[1:]
+		}
+
+		switch flag {
+		case "-f":
+			step = "CutFields"
+		case "-c":
+			step = "CutChars"
+		case "-b":
+			step = "CutBytes"
+		case "-d":
+			delimiter = &word{raw: value, value: value}
+			continue
+		default:
+			return nil
+		}
+		if spec != "" {
+			return nil
+		}
+		spec = value
+	}
+
+	// `-d` only makes sense with `-f`
+	switch {
+	case step == "":
+		return nil
+	case delimiter != nil && step != "CutFields":
+		return nil
+	}
+
+	retval := mapping{step: step, args: []string{strconv.Quote(spec)}}
+	switch {
+	case delimiter != nil:
+		retval.step = "SelectFields"
+		retval.args = append(retval.args, "scriptish.FieldOptions{Delimiter: "+goString(delimiter)+"}")
+		retval.todos = append(retval.todos, "cut writes out lines that have no delimiter unchanged; SelectFields() treats them as a single field")
+	case step == "CutFields":
+		retval.todos = append(retval.todos, "CutFields() splits on whitespace, not tabs")
+	}
+	if retval.step != "CutFields" && !isAscendingRangeSpec(spec) {
+		retval.todos = append(retval.todos, "cut writes out "+strings.ToLower(step[3:])+" in the order that they appear in the line; "+retval.step+"() writes them out in the order of the range spec")
+	}
+
+	return optionalInput(&retval, args)
+}
+
+// isAscendingRangeSpec returns true if each range in the `cut`-style
+// spec starts after the range before it
+func isAscendingRangeSpec(spec string) bool {
+	last := 0
+	for _, item := range strings.Split(spec, ",") {
+		start := strings.SplitN(item, "-", 2)[0]
+		if start == "" {
+			start = "1"
+		}
+		n, err := strconv.Atoi(start)
+		if err != nil || n <= last {
+			return false
+		}
+		last = n
+	}
+
+	return true
+}
+
+func mapDirname(args []*word) *mapping {
+	return singleArg("Dirname", args)
+}
+
+func mapEcho(args []*word) *mapping {
+	// we leave `echo -n` and friends to the real echo command
+	if len(args) > 0 && isFlag(args[0]) {
+		return nil
+	}
+
+	// special case
+	if len(args) == 1 && (args[0].value == "$@" || args[0].value == "$*") {
+		return &mapping{step: "EchoArgs"}
+	}
+
+	values := make([]string, len(args))
+	for i, arg := range args {
+		if hasGlob(arg) {
+			return nil
+		}
+		values[i] = arg.value
+	}
+	return &mapping{step: "Echo", args: []string{strconv.Quote(strings.Join(values, " "))}}
+}
+
+func mapExit(args []*word) *mapping {
+	if len(args) != 1 {
+		return nil
+	}
+	statusCode, err := strconv.Atoi(args[0].value)
+	if err != nil {
+		return nil
+	}
+
+	return &mapping{
+		step: "Exit",
+		args: []string{strconv.Itoa(statusCode)},
+	}
+}
+
+func mapLet(args []*word) *mapping {
+	if len(args) == 0 {
+		return nil
+	}
+
+	// `let a b` is the same as `let "a, b"`
+	values := make([]string, len(args))
+	for i, arg := range args {
+		values[i] = arg.value
+	}
+	return &mapping{step: "Let", args: []string{strconv.Quote(strings.Join(values, ", "))}}
+}
+
+// findPredicates are the `find` tests that take one argument, and the
+// FindPredicate that we translate them into
+var findPredicates = map[string]string{
+	"-iname":      "FindIName",
+	"-ipath":      "FindIPath",
+	"-iregex":     "FindIRegex",
+	"-iwholename": "FindIPath",
+	"-mtime":      "FindMtime",
+	"-name":       "FindName",
+	"-newer":      "FindNewer",
+	"-path":       "FindPath",
+	"-perm":       "FindPerm",
+	"-regex":      "FindRegex",
+	"-size":       "FindSize",
+	"-type":       "FindType",
+	"-wholename":  "FindPath",
+}
+
+// findSettings are the `find` options that take a number, and the
+// FindPredicate that we translate them into
+var findSettings = map[string]string{
+	"-maxdepth": "FindMaxDepth",
+	"-mindepth": "FindMinDepth",
+}
+
+func mapFind(args []*word) *mapping {
+	var preds []string
+	if len(args) > 0 && args[0].value == "-L" {
+		preds = append(preds, stepCall("FindFollowSymlinks"))
+		args = args[1:]
+	}
+
+	// Find() only takes one starting point
+	if len(args) == 0 || isFlag(args[0]) || args[0].value == "!" || args[0].value == "(" {
+		return nil
+	}
+	root := args[0]
+	if _, quoted := globQuoting(root); quoted {
+		return nil
+	}
+	args = args[1:]
+
+	// `-print` is what Find() does anyway, unless it only applies to
+	// one side of an `-o`
+	if len(args) > 0 && args[len(args)-1].value == "-print" {
+		depth := 0
+		for _, arg := range args {
+			switch arg.value {
+			case "(":
+				depth++
+			case ")":
+				depth--
+			case "-o", "-or":
+				if depth == 0 {
+					return nil
+				}
+			}
+		}
+		args = args[:len(args)-1]
+	}
+
+	var todos []string
+	if len(args) > 0 {
+		parser := findParser{args: args}
+		pred, ok := parser.parseOr()
+		if !ok || parser.pos < len(args) {
+			return nil
+		}
+		preds = append(preds, pred)
+		todos = parser.todos
+	}
+
+	return &mapping{
+		step:  "Find",
+		args:  []string{goString(root), "[]scriptish.FindPredicate{" + strings.Join(preds, ", ") + "}"},
+		todos: todos,
+	}
+}
+
+// findParser translates a `find` expression into a FindPredicate
+type findParser struct {
+	args  []*word
+	pos   int
+	todos []string
+}
+
+func (f *findParser) peek() string {
+	if f.pos >= len(f.args) {
+		return ""
+	}
+	return f.args[f.pos].value
+}
+
+func (f *findParser) parseOr() (string, bool) {
+	preds := []string{}
+	for {
+		pred, ok := f.parseAnd()
+		if !ok {
+			return "", false
+		}
+		preds = append(preds, pred)
+
+		if f.peek() != "-o" && f.peek() != "-or" {
+			break
+		}
+		f.pos++
+	}
+
+	if len(preds) == 1 {
+		return preds[0], true
+	}
+	return stepCall("FindOr", preds...), true
+}
+
+func (f *findParser) parseAnd() (string, bool) {
+	preds := []string{}
+	for {
+		pred, ok := f.parseNot()
+		if !ok {
+			return "", false
+		}
+		preds = append(preds, pred)
+
+		// `-a` is optional
+		switch f.peek() {
+		case "-a", "-and":
+			f.pos++
+			continue
+		case "", "-o", "-or", ")":
+			if len(preds) == 1 {
+				return preds[0], true
+			}
+			return stepCall("FindAnd", preds...), true
+		}
+	}
+}
+
+func (f *findParser) parseNot() (string, bool) {
+	switch f.peek() {
+	case "!", "-not":
+		f.pos++
+		pred, ok := f.parseNot()
+		if !ok {
+			return "", false
+		}
+		return stepCall("FindNot", pred), true
+	case "(":
+		f.pos++
+		pred, ok := f.parseOr()
+		if !ok || f.peek() != ")" {
+			return "", false
+		}
+		f.pos++
+		return pred, true
+	}
+
+	return f.parsePrimary()
+}
+
+func (f *findParser) parsePrimary() (string, bool) {
+	op := f.peek()
+	f.pos++
+
+	switch op {
+	case "-empty":
+		return stepCall("FindEmpty"), true
+	case "-prune":
+		f.todos = append(f.todos, "Find() writes out the paths that FindPrune() matches, unless they are inside a FindNot()")
+		return stepCall("FindPrune"), true
+	}
+
+	// everything else takes an argument
+	if f.pos >= len(f.args) {
+		return "", false
+	}
+	arg := f.args[f.pos]
+	f.pos++
+
+	// bash would expand these glob patterns before `find` sees them
+	if hasGlob(arg) {
+		return "", false
+	}
+
+	if step, ok := findPredicates[op]; ok {
+		if op == "-regex" || op == "-iregex" {
+			f.todos = append(f.todos, "Find() uses Go's regexp syntax, not emacs regular expressions")
+		}
+		return stepCall(step, goString(arg)), true
+	}
+	if step, ok := findSettings[op]; ok {
+		n, err := strconv.Atoi(arg.value)
+		if err != nil {
+			return "", false
+		}
+		return stepCall(step, strconv.Itoa(n)), true
+	}
+
+	return "", false
+}
+
+func mapExport(args []*word) *mapping {
+	if len(args) != 1 || !isAssignment(args[0]) {
+		return nil
+	}
+
+	name, value := splitAssignment(args[0])
+	return &mapping{step: "Export", args: []string{strconv.Quote(name), value}}
+}
+
+// breRegex matches the parts of a basic regular expression that
+// mean something different in a Go regexp
+var breRegex = regexp.MustCompile(`\\[(){}|+?]`)
+
+func mapGrep(args []*word) *mapping {
+	// special cases - the steps that came before GrepWith()
+	step, rest := "Grep", args
+	if len(rest) > 0 && rest[0].value == "-v" {
+		step, rest = "GrepV", rest[1:]
+	}
+	if len(rest) > 0 && rest[0].value == "-e" {
+		rest = rest[1:]
+	}
+	if len(rest) > 0 && !isFlag(rest[0]) && (len(rest) == 1 || (len(rest) == 2 && !isFlag(rest[1]))) {
+		retval := mapping{step: step, args: []string{strconv.Quote(rest[0].value)}}
+		if breRegex.MatchString(rest[0].value) {
+			retval.todos = append(retval.todos, "this regex uses basic regular expression syntax, which Go's regexp package does not support")
+		}
+		return optionalInput(&retval, rest[1:])
+	}
+
+	flags := map[byte]bool{}
+	values := map[byte]int{}
+	patterns := []*word{}
+	globs := map[string][]string{}
+
+	for len(args) > 0 && isFlag(args[0]) {
+		arg := args[0].value
+		args = args[1:]
+
+		// `--` ends the flags
+		if arg == "--" {
+			break
+		}
+
+		// long options are the same as their short options
+		if strings.HasPrefix(arg, "--") {
+			name, value := arg, ""
+			if i := strings.IndexByte(arg, '='); i >= 0 {
+				name, value = arg[:i], arg[i+1:]
+			}
+
+			// these do not have short options
+			if field, ok := grepGlobFlags[name]; ok {
+				if !strings.Contains(arg, "=") {
+					if len(args) == 0 {
+						return nil
+					}
+					value = args[0].value
+					args = args[1:]
+				}
+				globs[field] = append(globs[field], strconv.Quote(value))
+				continue
+			}
+
+			short, ok := grepLongFlags[name]
+			if !ok {
+				return nil
+			}
+			arg = "-" + short + value
+		}
+
+		for i := 1; i < len(arg); i++ {
+			switch arg[i] {
+			case 'i', 'F', 'w', 'x', 'v', 'c', 'o', 'n', 'E', 'r', 'l', 'L', 'H', 'h', 'I', 'a':
+				flags[arg[i]] = true
+				continue
+			case 'e', 'm', 'A', 'B', 'C':
+				// these take a value
+			default:
+				return nil
+			}
+
+			// the flag's value can be the next argument
+			var value *word
+			if i+1 < len(arg) {
+				value = &word{raw: arg[i+1:], value: arg[i+1:]}
+			} else {
+				if len(args) == 0 {
+					return nil
+				}
+				value = args[0]
+				args = args[1:]
+			}
+
+			if arg[i] == 'e' {
+				patterns = append(patterns, value)
+				break
+			}
+
+			n, err := strconv.Atoi(value.value)
+			if err != nil || n < 0 || (arg[i] == 'm' && n == 0) {
+				return nil
+			}
+			values[arg[i]] = n
+			break
+		}
+	}
+
+	// without -e, the pattern is the first argument
+	if len(patterns) == 0 {
+		if len(args) == 0 {
+			return nil
+		}
+		patterns = append(patterns, args[0])
+		args = args[1:]
+	}
+
+	// -A and -B take priority over -C
+	for _, flag := range []byte{'A', 'B'} {
+		if _, ok := values[flag]; !ok {
+			values[flag] = values['C']
+		}
+	}
+
+	// build up the options that we need
+	gopts := []string{}
+	for _, flag := range []struct {
+		flag byte
+		name string
+	}{
+		{'i', "IgnoreCase"},
+		{'F', "FixedStrings"},
+		{'w', "MatchWords"},
+		{'x', "MatchLines"},
+		{'v', "Invert"},
+		{'c', "Count"},
+		{'o', "OnlyMatching"},
+		{'m', "MaxCount"},
+		{'n', "LineNumbers"},
+		{'B', "BeforeContext"},
+		{'A', "AfterContext"},
+	} {
+		switch {
+		case values[flag.flag] > 0:
+			gopts = append(gopts, flag.name+": "+strconv.Itoa(values[flag.flag]))
+		case flags[flag.flag]:
+			gopts = append(gopts, flag.name+": true")
+		}
+	}
+
+	quoted := make([]string, len(patterns))
+	var todos []string
+	for i, pattern := range patterns {
+		quoted[i] = strconv.Quote(pattern.value)
+		if !flags['E'] && !flags['F'] && breRegex.MatchString(pattern.value) && todos == nil {
+			todos = append(todos, "this regex uses basic regular expression syntax, which Go's regexp package does not support")
+		}
+	}
+
+	// are we searching files?
+	searchFiles := len(args) > 1 || len(globs) > 0
+	for _, flag := range []byte("rlLHhIa") {
+		searchFiles = searchFiles || flags[flag]
+	}
+	if !searchFiles {
+		retval := mapping{
+			step: "GrepWith",
+			args: []string{
+				"[]string{" + strings.Join(quoted, ", ") + "}",
+				"scriptish.GrepOptions{" + strings.Join(gopts, ", ") + "}",
+			},
+			todos: todos,
+		}
+		return optionalInput(&retval, args)
+	}
+
+	// GrepFiles() expands glob patterns in the paths, but cannot tell
+	// which ones were quoted
+	for _, arg := range args {
+		if _, quoted := globQuoting(arg); quoted || isFlag(arg) {
+			return nil
+		}
+	}
+	if len(args) == 0 && !flags['r'] {
+		return nil
+	}
+
+	gfopts := []string{}
+	if len(gopts) > 0 {
+		gfopts = append(gfopts, "GrepOptions: scriptish.GrepOptions{"+strings.Join(gopts, ", ")+"}")
+	}
+	for _, flag := range []struct {
+		flag byte
+		name string
+	}{
+		{'r', "Recursive"},
+		{0, "Include"},
+		{0, "Exclude"},
+		{0, "ExcludeDir"},
+		{'l', "FilesWithMatches"},
+		{'L', "FilesWithoutMatch"},
+		{'H', "WithFilename"},
+		{'h', "NoFilename"},
+		{'I', "SkipBinaryFiles"},
+		{'a', "BinaryAsText"},
+	} {
+		switch {
+		case len(globs[flag.name]) > 0:
+			gfopts = append(gfopts, flag.name+": []string{"+strings.Join(globs[flag.name], ", ")+"}")
+		case flag.flag != 0 && flags[flag.flag]:
+			gfopts = append(gfopts, flag.name+": true")
+		}
+	}
+
+	return &mapping{
+		step: "GrepFiles",
+		args: []string{
+			"[]string{" + strings.Join(quoted, ", ") + "}",
+			goStrings(args),
+			"scriptish.GrepFilesOptions{" + strings.Join(gfopts, ", ") + "}",
+		},
+		todos: todos,
+	}
+}
+
+// grepGlobFlags maps the `grep` long options that take glob patterns
+// onto the GrepFilesOptions fields that hold them
+var grepGlobFlags = map[string]string{
+	"--include":     "Include",
+	"--exclude":     "Exclude",
+	"--exclude-dir": "ExcludeDir",
+}
+
+// grepLongFlags maps the `grep` long options that GrepWith() and
+// GrepFiles() support onto their short options
+var grepLongFlags = map[string]string{
+	"--ignore-case":         "i",
+	"--fixed-strings":       "F",
+	"--word-regexp":         "w",
+	"--line-regexp":         "x",
+	"--invert-match":        "v",
+	"--count":               "c",
+	"--only-matching":       "o",
+	"--line-number":         "n",
+	"--extended-regexp":     "E",
+	"--regexp":              "e",
+	"--max-count":           "m",
+	"--after-context":       "A",
+	"--before-context":      "B",
+	"--context":             "C",
+	"--recursive":           "r",
+	"--files-with-matches":  "l",
+	"--files-without-match": "L",
+	"--with-filename":       "H",
+	"--no-filename":         "h",
+	"--text":                "a",
+}
+
+func mapHead(args []*word) *mapping {
+	n, rest, ok := lineCount(args)
+	if !ok {
+		return nil
+	}
+	return optionalInput(&mapping{step: "Head", args: []string{strconv.Itoa(n)}}, rest)
+}
+
+func mapLs(args []*word) *mapping {
+	if len(args) > 0 && args[0].value == "-1" {
+		args = args[1:]
+	}
+	if len(args) != 1 || isFlag(args[0]) {
+		return nil
+	}
+
+	// ListFiles() understands glob patterns
+	return &mapping{step: "ListFiles", args: []string{goString(args[0])}, globs: true}
+}
+
+func mapMkdir(args []*word) *mapping {
+	// Mkdir() always creates any parent folders
+	if len(args) > 0 && args[0].value == "-p" {
+		args = args[1:]
+	}
+	retval := pathArg("Mkdir", args)
+	if retval != nil {
+		retval.args = append(retval.args, "0755")
+	}
+	return retval
+}
+
+func mapMktemp(args []*word) *mapping {
+	switch {
+	case len(args) == 0:
+		return &mapping{step: "MkTempFile", args: []string{`""`, `"tmp.*"`}}
+	case len(args) == 1 && args[0].value == "-d":
+		return &mapping{step: "MkTempDir", args: []string{`""`, `"tmp."`}}
+	case len(args) == 1 && args[0].value == "-u":
+		return &mapping{step: "MkTempFilename", args: []string{`""`, `"tmp.*"`}}
+	}
+	return nil
+}
+
+func mapPopd(args []*word) *mapping {
+	return noArgs("Popd", args)
+}
+
+func mapPushd(args []*word) *mapping {
+	return singleArg("Pushd", args)
+}
+
+func mapPwd(args []*word) *mapping {
+	return noArgs("Pwd", args)
+}
+
+func mapReturn(args []*word) *mapping {
+	if len(args) != 1 {
+		return nil
+	}
+	statusCode, err := strconv.Atoi(args[0].value)
+	if err != nil {
+		return nil
+	}
+	return &mapping{step: "Return", args: []string{strconv.Itoa(statusCode)}}
+}
+
+func mapRm(args []*word) *mapping {
+	var todos []string
+	if len(args) > 0 && args[0].value == "-f" {
+		todos = append(todos, "RmFile() returns an error if the file does not exist")
+		args = args[1:]
+	}
+
+	retval := pathArg("RmFile", args)
+	if retval != nil {
+		retval.todos = todos
+	}
+	return retval
+}
+
+func mapRmdir(args []*word) *mapping {
+	return pathArg("RmDir", args)
+}
+
+func mapSed(args []*word) *mapping {
+	step := "Sed"
+	extended := false
+	exprs := []*word{}
+
+	for len(args) > 0 && isFlag(args[0]) {
+		switch args[0].value {
+		case "-n":
+			step = "SedN"
+		case "-E", "-r":
+			extended = true
+		case "-e":
+			if len(args) < 2 {
+				return nil
+			}
+			exprs = append(exprs, args[1])
+			args = args[1:]
+		default:
+			// this includes `-i`, which we cannot do
+			return nil
+		}
+		args = args[1:]
+	}
+
+	// without `-e`, the first argument is the script
+	if len(exprs) == 0 {
+		if len(args) == 0 {
+			return nil
+		}
+		exprs = append(exprs, args[0])
+		args = args[1:]
+	}
+
+	retval := mapping{step: step, args: []string{goStrings(exprs)}}
+	for _, expr := range exprs {
+		if !extended && breRegex.MatchString(expr.value) {
+			retval.todos = append(retval.todos, "this script uses basic regular expression syntax, which Go's regexp package does not support")
+			break
+		}
+	}
+	return optionalInput(&retval, args)
+}
+
+func mapSort(args []*word) *mapping {
+	// special cases - the steps that came before SortBy()
+	switch {
+	case len(args) == 0 || !isFlag(args[0]):
+		return optionalInput(&mapping{step: "Sort"}, args)
+	case args[0].value == "-r" && (len(args) == 1 || !isFlag(args[1])):
+		return optionalInput(&mapping{step: "Rsort"}, args[1:])
+	}
+
+	flags := map[byte]bool{}
+	keys := []string{}
+	var separator *word
+	keyModifiers := ""
+	todos := []string{}
+
+	for len(args) > 0 && isFlag(args[0]) {
+		arg := args[0].value
+		args = args[1:]
+
+		for i := 1; i < len(arg); i++ {
+			switch arg[i] {
+			case 'n', 'h', 'V', 'M', 'f', 'r', 'u', 's':
+				flags[arg[i]] = true
+				continue
+			case 't', 'k':
+				// these take a value
+			default:
+				return nil
+			}
+
+			// the flag's value can be the next argument
+			value := arg[i+1:]
+			if value == "" {
+				if len(args) == 0 {
+					return nil
+				}
+				value = args[0].value
+				args = args[1:]
+			}
+
+			if arg[i] == 't' {
+				separator = &word{raw: value, value: value}
+				break
+			}
+
+			key, modifiers, ok := sortKeySpec(value)
+			if !ok {
+				return nil
+			}
+			keys = append(keys, key)
+			keyModifiers += modifiers
+			break
+		}
+	}
+
+	// key modifiers apply to just that key, and replace the global
+	// flags for that key, so we can only use them when there is one
+	// key and no global flags
+	if keyModifiers != "" {
+		if len(keys) > 1 || flags['n'] || flags['h'] || flags['V'] || flags['M'] || flags['f'] || flags['r'] {
+			return nil
+		}
+		for i := 0; i < len(keyModifiers); i++ {
+			flags[keyModifiers[i]] = true
+		}
+		if strings.Contains(keyModifiers, "r") {
+			todos = append(todos, "sort -k...r does not reverse the order of lines whose keys are the same; SortBy() does")
+		}
+	}
+
+	// we can only compare one way
+	compare := ""
+	for _, flag := range []struct {
+		flag byte
+		name string
+	}{
+		{'n', "SortNumeric"},
+		{'h', "SortHumanNumeric"},
+		{'V', "SortVersion"},
+		{'M', "SortMonth"},
+	} {
+		if !flags[flag.flag] {
+			continue
+		}
+		if compare != "" {
+			return nil
+		}
+		compare = "scriptish." + flag.name
+	}
+
+	// build up the options that we need
+	sopts := []string{}
+	if compare != "" {
+		sopts = append(sopts, "Compare: "+compare)
+	}
+	if len(keys) > 0 {
+		sopts = append(sopts, "Keys: "+strconv.Quote(strings.Join(keys, ",")))
+	}
+	if separator != nil {
+		sopts = append(sopts, "Separator: "+goString(separator))
+	}
+	for _, flag := range []struct {
+		flag byte
+		name string
+	}{
+		{'f', "FoldCase"},
+		{'r', "Reverse"},
+		{'u', "Unique"},
+		{'s', "Stable"},
+	} {
+		if flags[flag.flag] {
+			sopts = append(sopts, flag.name+": true")
+		}
+	}
+
+	retval := mapping{
+		step:  "SortBy",
+		args:  []string{"scriptish.SortOptions{" + strings.Join(sopts, ", ") + "}"},
+		todos: todos,
+	}
+	return optionalInput(&retval, args)
+}
+
+// sortKeyRegex matches a `sort -k` key that uses whole fields, such as
+// `2`, `2,2` or `3,4n`
+var sortKeyRegex = regexp.MustCompile(`^([1-9][0-9]*)([a-zA-Z]*)(?:,([1-9][0-9]*)([a-zA-Z]*))?$`)
+
+// sortKeySpec turns a `sort -k` key into a SortBy() range, along with
+// any modifiers on the end of the key
+func sortKeySpec(key string) (string, string, bool) {
+	matches := sortKeyRegex.FindStringSubmatch(key)
+	if matches == nil {
+		return "", "", false
+	}
+
+	modifiers := matches[2] + matches[4]
+	if strings.Trim(modifiers, "nhVMfr") != "" {
+		return "", "", false
+	}
+
+	switch {
+	case matches[3] == "":
+		return matches[1] + "-", modifiers, true
+	case matches[1] == matches[3]:
+		return matches[1], modifiers, true
+	default:
+		return matches[1] + "-" + matches[3], modifiers, true
+	}
+}
+
+func mapTail(args []*word) *mapping {
+	n, rest, ok := lineCount(args)
+	if !ok {
+		return nil
+	}
+	return optionalInput(&mapping{step: "Tail", args: []string{strconv.Itoa(n)}}, rest)
+}
+
+func mapTest(args []*word) *mapping {
+	// `[` and `[[` need their closing brackets removing
+	doubleBrackets := false
+	if len(args) > 0 && (args[len(args)-1].value == "]" || args[len(args)-1].value == "]]") {
+		doubleBrackets = args[len(args)-1].value == "]]"
+		args = args[:len(args)-1]
+	}
+	if len(args) == 0 {
+		return nil
+	}
+
+	// some tests have their own steps
+	if len(args) == 2 {
+		switch args[0].value {
+		case "-e":
+			return &mapping{step: "TestFilepathExists", args: []string{goString(args[1])}, globs: true}
+		case "-n":
+			return &mapping{step: "TestNotEmpty", args: []string{goString(args[1])}}
+		case "-z":
+			return &mapping{step: "TestEmpty", args: []string{goString(args[1])}}
+		}
+	}
+
+	var todos []string
+	for i, arg := range args {
+		unquoted, quoted := globQuoting(arg)
+
+		// `[` and `test` expand glob patterns, but Test() does not
+		if unquoted && !doubleBrackets {
+			return nil
+		}
+
+		// Test() does not have arrays
+		if arg.value == "=~" {
+			todos = append(todos, "Test() puts the subexpressions that =~ matches into $BASH_REMATCH_1, $BASH_REMATCH_2 and so on, instead of ${BASH_REMATCH[1]}")
+		}
+
+		// Test() cannot tell which parts of a pattern were quoted
+		if quoted && i > 0 && isTestPatternOperator(args[i-1].value) {
+			todos = append(todos, "Test() treats the right-hand side of "+args[i-1].value+" as a glob pattern, even if it is quoted")
+		}
+	}
+
+	return &mapping{step: "Test", args: []string{goStrings(args)}, todos: todos}
+}
+
+// isTestPatternOperator returns true if Test() treats the right-hand
+// side of the given operator as a glob pattern
+func isTestPatternOperator(op string) bool {
+	return op == "=" || op == "==" || op == "!="
+}
+
+func mapTouch(args []*word) *mapping {
+	return pathArg("Touch", args)
+}
+
+// trSet matches the `tr` character sets that we can translate
+var trSet = regexp.MustCompile(`^[^-\\\[\]]+$`)
+
+func mapTr(args []*word) *mapping {
+	if len(args) != 2 || isFlag(args[0]) {
+		return nil
+	}
+
+	old := []rune(args[0].value)
+	new := []rune(args[1].value)
+	if !trSet.MatchString(args[0].value) || !trSet.MatchString(args[1].value) || len(old) != len(new) {
+		return nil
+	}
+
+	oldChars := make([]string, len(old))
+	newChars := make([]string, len(new))
+	for i := range old {
+		oldChars[i] = strconv.Quote(string(old[i]))
+		newChars[i] = strconv.Quote(string(new[i]))
+	}
+
+	return &mapping{
+		step: "Tr",
+		args: []string{
+			"[]string{" + strings.Join(oldChars, ", ") + "}",
+			"[]string{" + strings.Join(newChars, ", ") + "}",
+		},
+	}
+}
+
+func mapUniq(args []*word) *mapping {
+	// special case - the step that came before UniqBy()
+	if len(args) == 0 || !isFlag(args[0]) {
+		return optionalInput(&mapping{step: "Uniq"}, args)
+	}
+
+	flags := map[byte]bool{}
+	skips := map[byte]int{}
+
+	for len(args) > 0 && isFlag(args[0]) {
+		arg := args[0].value
+		args = args[1:]
+
+		// long options are the same as their short options
+		if strings.HasPrefix(arg, "--") {
+			name, value := arg, ""
+			if i := strings.IndexByte(arg, '='); i >= 0 {
+				name, value = arg[:i], arg[i:]
+			}
+			short, ok := uniqLongFlags[name]
+			if !ok {
+				return nil
+			}
+			arg = "-" + short + strings.TrimPrefix(value, "=")
+		}
+
+		for i := 1; i < len(arg); i++ {
+			switch arg[i] {
+			case 'c', 'd', 'u', 'i':
+				flags[arg[i]] = true
+				continue
+			case 'f', 's':
+				// these take a value
+			default:
+				return nil
+			}
+
+			// the flag's value can be the next argument
+			value := arg[i+1:]
+			if value == "" {
+				if len(args) == 0 {
+					return nil
+				}
+				value = args[0].value
+				args = args[1:]
+			}
+
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return nil
+			}
+			skips[arg[i]] = n
+			break
+		}
+	}
+
+	// build up the options that we need
+	uopts := []string{}
+	for _, flag := range []struct {
+		flag byte
+		name string
+	}{
+		{'c', "Count"},
+		{'d', "Repeated"},
+		{'u', "Unique"},
+		{'i', "IgnoreCase"},
+	} {
+		if flags[flag.flag] {
+			uopts = append(uopts, flag.name+": true")
+		}
+	}
+	if skips['f'] > 0 {
+		uopts = append(uopts, "SkipFields: "+strconv.Itoa(skips['f']))
+	}
+	if skips['s'] > 0 {
+		uopts = append(uopts, "SkipChars: "+strconv.Itoa(skips['s']))
+	}
+
+	retval := mapping{
+		step: "UniqBy",
+		args: []string{"scriptish.UniqOptions{" + strings.Join(uopts, ", ") + "}"},
+	}
+	return optionalInput(&retval, args)
+}
+
+// uniqLongFlags maps the `uniq` long options that UniqBy() supports
+// onto their short options
+var uniqLongFlags = map[string]string{
+	"--count":       "c",
+	"--repeated":    "d",
+	"--unique":      "u",
+	"--ignore-case": "i",
+	"--skip-fields": "f",
+	"--skip-chars":  "s",
+}
+
+func mapWc(args []*word) *mapping {
+	if len(args) != 1 {
+		return nil
+	}
+
+	switch args[0].value {
+	case "-l":
+		return &mapping{step: "CountLines"}
+	case "-w":
+		return &mapping{step: "CountWords"}
+	}
+	return nil
+}
+
+func mapWhich(args []*word) *mapping {
+	return singleArg("Which", args)
+}
+
+func mapXargs(args []*word) *mapping {
+	values := make([]string, len(args))
+	for i, arg := range args {
+		values[i] = arg.value
+	}
+
+	switch strings.Join(values, " ") {
+	case "basename":
+		return &mapping{step: "XargsBasename"}
+	case "cat":
+		return &mapping{step: "XargsCat"}
+	case "dirname":
+		return &mapping{step: "XargsDirname"}
+	case "rm", "rm -f":
+		return &mapping{step: "XargsRmFile"}
+	case "test -e":
+		return &mapping{step: "XargsTestFilepathExists"}
+	}
+
+	return mapXargsCommand(args)
+}
+
+// mapXargsCommand translates `xargs [-n N] [-P N] [-I STR] command ...`
+// into an Xargs() that Exec()s the command
+func mapXargsCommand(args []*word) *mapping {
+	maxArgs, maxProcs := 0, 1
+	replace := ""
+
+	for len(args) > 1 && isFlag(args[0]) {
+		flag := args[0].value
+		value := args[1].value
+		args = args[2:]
+
+		// `-n1` is the same as `-n 1`
+		if len(flag) > 2 {
+			args = append([]*word{{raw: value, value: value}}, args...)
+			value = flag[2:]
+			flag = flag[:2]
+		}
+
+		var err error
+		switch flag {
+		case "-n":
+			maxArgs, err = strconv.Atoi(value)
+		case "-P":
+			maxProcs, err = strconv.Atoi(value)
+		case "-I":
+			replace = value
+		default:
+			return nil
+		}
+		if err != nil || maxArgs < 0 || maxProcs < 1 {
+			return nil
+		}
+	}
+	if len(args) == 0 || isFlag(args[0]) {
+		return nil
+	}
+
+	// build the command that the body runs
+	var todos []string
+	cmd := make([]string, 0, len(args)+1)
+	for _, arg := range args {
+		value := arg.value
+		if replace != "" {
+			value = strings.Replace(value, replace, "$1", -1)
+		}
+		cmd = append(cmd, strconv.Quote(value))
+	}
+	if replace == "" {
+		if maxArgs > 1 {
+			return nil
+		}
+		cmd = append(cmd, strconv.Quote("$1"))
+		if maxArgs == 0 {
+			todos = append(todos, "Xargs() runs the command once for each line of input, not once for as many lines as will fit")
+		}
+	}
+	// like UNIX xargs, we pass each line on exactly as it is
+	body := stepCall("NewList", stepCall("Exec", "[]string{"+strings.Join(cmd, ", ")+"}", stepCall("NoGlob")))
+
+	// `-P` needs a new body for each batch that runs at the same time
+	if maxProcs > 1 {
+		return &mapping{
+			step:  "XargsParallel",
+			args:  []string{strconv.Itoa(maxProcs), "func() *scriptish.Sequence { return " + body + " }", "scriptish.XargsOptions{}"},
+			todos: todos,
+		}
+	}
+
+	return &mapping{step: "Xargs", args: []string{body, "scriptish.XargsOptions{}"}, todos: todos}
+}
+
+// stepCall returns the Go code that calls the given Scriptish step
+func stepCall(step string, args ...string) string {
+	return fmt.Sprintf("scriptish.%s(%s)", step, strings.Join(args, ", "))
+}
@@ -0,0 +1,304 @@
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mapCommand runs a single bash command through our mapping table
+func mapCommand(t *testing.T, src string) *mapping {
+	nodes, err := parseScript(src)
+	assert.Nil(t, err, src)
+
+	cmd := nodes[0].(*simpleCommand)
+	mapper, ok := commandMappers[cmd.args[0].value]
+	assert.True(t, ok, src)
+
+	return mapper(cmd.args[1:])
+}
+
+func TestCommandMappersTranslateCommandsIntoScriptishSteps(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	testData := map[string]string{
+		`basename "$file"`:  `scriptish.Basename("$file")`,
+		"cat":               "scriptish.Cat()",
+		"cat config.yaml":   `scriptish.CatFile("config.yaml")`,
+		"cd":                `scriptish.Cd("")`,
+		"cd ~/src":          `scriptish.Cd("$HOME/src")`,
+		"chmod 755 run.sh":  `scriptish.Chmod("run.sh", 0755)`,
+		"chmod 0600 key":    `scriptish.Chmod("key", 0600)`,
+		"chmod 644 *.txt":   `scriptish.Chmod("*.txt", 0644)`,
+		"cut -f 2-3":        `scriptish.CutFields("2-3")`,
+		"dirname /a/b":      `scriptish.Dirname("/a/b")`,
+		"find .":            `scriptish.Find(".", []scriptish.FindPredicate{})`,
+		"find . -perm -u+x": `scriptish.Find(".", []scriptish.FindPredicate{scriptish.FindPerm("-u+x")})`,
+		"find -L src -maxdepth 2 -type f -name '*.go' ! -path './vendor/*'": `scriptish.Find("src", []scriptish.FindPredicate{scriptish.FindFollowSymlinks(), scriptish.FindAnd(scriptish.FindMaxDepth(2), scriptish.FindType("f"), scriptish.FindName("*.go"), scriptish.FindNot(scriptish.FindPath("./vendor/*")))})`,
+		`find . \( -name "*.go" -o -name "*.txt" \) -mtime -7 -print`:       `scriptish.Find(".", []scriptish.FindPredicate{scriptish.FindAnd(scriptish.FindOr(scriptish.FindName("*.go"), scriptish.FindName("*.txt")), scriptish.FindMtime("-7"))})`,
+		"cut -c 1-10":                   `scriptish.CutChars("1-10")`,
+		"cut -b1,5-":                    `scriptish.CutBytes("1,5-")`,
+		"cut -d, -f 2-4":                `scriptish.SelectFields("2-4", scriptish.FieldOptions{Delimiter: ","})`,
+		"awk -F: '{print $1, $NF}'":     `scriptish.SelectFields("1,NF", scriptish.FieldOptions{Delimiter: ":", OutputDelimiter: " "})`,
+		"awk '{ print $(NF-1) }'":       `scriptish.SelectFields("NF-1", scriptish.FieldOptions{})`,
+		`awk -F '[,;]' '{print $2}'`:    `scriptish.SelectFields("2", scriptish.FieldOptions{DelimiterRegex: "[,;]", OutputDelimiter: " "})`,
+		"echo hello   world":            `scriptish.Echo("hello world")`,
+		`echo "$@"`:                     "scriptish.EchoArgs()",
+		"exit 3":                        "scriptish.Exit(3)",
+		"export PATH=$HOME/bin":         `scriptish.Export("PATH", "$HOME/bin")`,
+		"grep -v '^#'":                  `scriptish.GrepV("^#")`,
+		"grep -e foo":                   `scriptish.Grep("foo")`,
+		"grep -i foo":                   `scriptish.GrepWith([]string{"foo"}, scriptish.GrepOptions{IgnoreCase: true})`,
+		"grep -e a -e b":                `scriptish.GrepWith([]string{"a", "b"}, scriptish.GrepOptions{})`,
+		"grep -Fxv a.b":                 `scriptish.GrepWith([]string{"a.b"}, scriptish.GrepOptions{FixedStrings: true, MatchLines: true, Invert: true})`,
+		"grep -cw -m3 foo":              `scriptish.GrepWith([]string{"foo"}, scriptish.GrepOptions{MatchWords: true, Count: true, MaxCount: 3})`,
+		"grep -on -C2 -A 1 foo":         `scriptish.GrepWith([]string{"foo"}, scriptish.GrepOptions{OnlyMatching: true, LineNumbers: true, BeforeContext: 2, AfterContext: 1})`,
+		"grep --context=1 -E 'a+'":      `scriptish.GrepWith([]string{"a+"}, scriptish.GrepOptions{BeforeContext: 1, AfterContext: 1})`,
+		"grep -rl TODO ./src":           `scriptish.GrepFiles([]string{"TODO"}, []string{"./src"}, scriptish.GrepFilesOptions{Recursive: true, FilesWithMatches: true})`,
+		"grep -H listen *.conf":         `scriptish.GrepFiles([]string{"listen"}, []string{"*.conf"}, scriptish.GrepFilesOptions{WithFilename: true})`,
+		"grep foo a.txt b.txt":          `scriptish.GrepFiles([]string{"foo"}, []string{"a.txt", "b.txt"}, scriptish.GrepFilesOptions{})`,
+		"grep -rc TODO":                 `scriptish.GrepFiles([]string{"TODO"}, []string{}, scriptish.GrepFilesOptions{GrepOptions: scriptish.GrepOptions{Count: true}, Recursive: true})`,
+		"grep -rIn --include=*.c x .":   `scriptish.GrepFiles([]string{"x"}, []string{"."}, scriptish.GrepFilesOptions{GrepOptions: scriptish.GrepOptions{LineNumbers: true}, Recursive: true, Include: []string{"*.c"}, SkipBinaryFiles: true})`,
+		"grep -r --exclude a x":         `scriptish.GrepFiles([]string{"x"}, []string{}, scriptish.GrepFilesOptions{Recursive: true, Exclude: []string{"a"}})`,
+		"grep -rhL --exclude-dir=.g x":  `scriptish.GrepFiles([]string{"x"}, []string{}, scriptish.GrepFilesOptions{Recursive: true, ExcludeDir: []string{".g"}, FilesWithoutMatch: true, NoFilename: true})`,
+		"head -n 5":                     "scriptish.Head(5)",
+		"head -3":                       "scriptish.Head(3)",
+		"let i++":                       `scriptish.Let("i++")`,
+		"let 'x = 2 * y' z=1":           `scriptish.Let("x = 2 * y, z=1")`,
+		"echo $((i * 2))":               `scriptish.Echo("$((i * 2))")`,
+		"ls -1 *.txt":                   `scriptish.ListFiles("*.txt")`,
+		"mkdir -p build":                `scriptish.Mkdir("build", 0755)`,
+		"mktemp":                        `scriptish.MkTempFile("", "tmp.*")`,
+		"mktemp -d":                     `scriptish.MkTempDir("", "tmp.")`,
+		"mktemp -u":                     `scriptish.MkTempFilename("", "tmp.*")`,
+		"popd":                          "scriptish.Popd()",
+		"pushd /tmp":                    `scriptish.Pushd("/tmp")`,
+		"pwd":                           "scriptish.Pwd()",
+		"return 1":                      "scriptish.Return(1)",
+		"rm out.txt":                    `scriptish.RmFile("out.txt")`,
+		"rm *.o":                        `scriptish.RmFile("*.o")`,
+		"rmdir build":                   `scriptish.RmDir("build")`,
+		"sed -E 's/a(b+)/c\\1/g'":       `scriptish.Sed([]string{"s/a(b+)/c\\1/g"})`,
+		"sed -n -e 2p -e '/^#/p'":       `scriptish.SedN([]string{"2p", "/^#/p"})`,
+		"sort":                          "scriptish.Sort()",
+		"sort -r":                       "scriptish.Rsort()",
+		"sort -n":                       `scriptish.SortBy(scriptish.SortOptions{Compare: scriptish.SortNumeric})`,
+		"sort -t: -k3,3n":               `scriptish.SortBy(scriptish.SortOptions{Compare: scriptish.SortNumeric, Keys: "3", Separator: ":"})`,
+		"sort -rhu":                     `scriptish.SortBy(scriptish.SortOptions{Compare: scriptish.SortHumanNumeric, Reverse: true, Unique: true})`,
+		"sort -V -k2":                   `scriptish.SortBy(scriptish.SortOptions{Compare: scriptish.SortVersion, Keys: "2-"})`,
+		"sort -fs -k 1,2 -k4":           `scriptish.SortBy(scriptish.SortOptions{Keys: "1-2,4-", FoldCase: true, Stable: true})`,
+		"tail -n5":                      "scriptish.Tail(5)",
+		`[ -e "$file" ]`:                `scriptish.TestFilepathExists("$file")`,
+		`[[ -n $x ]]`:                   `scriptish.TestNotEmpty("$x")`,
+		`test -z "$x"`:                  `scriptish.TestEmpty("$x")`,
+		"[ -d build ]":                  `scriptish.Test([]string{"-d", "build"})`,
+		`[[ "$x" == v* ]]`:              `scriptish.Test([]string{"$x", "==", "v*"})`,
+		`test "$a" -lt 3`:               `scriptish.Test([]string{"$a", "-lt", "3"})`,
+		"touch stamp":                   `scriptish.Touch("stamp")`,
+		"touch log/{a,b}":               `scriptish.Touch("log/{a,b}")`,
+		"tr ab xy":                      `scriptish.Tr([]string{"a", "b"}, []string{"x", "y"})`,
+		"uniq":                          "scriptish.Uniq()",
+		"uniq -c":                       `scriptish.UniqBy(scriptish.UniqOptions{Count: true})`,
+		"uniq -di":                      `scriptish.UniqBy(scriptish.UniqOptions{Repeated: true, IgnoreCase: true})`,
+		"uniq -u -f 2 -s3":              `scriptish.UniqBy(scriptish.UniqOptions{Unique: true, SkipFields: 2, SkipChars: 3})`,
+		"uniq --count --skip-chars=4":   `scriptish.UniqBy(scriptish.UniqOptions{Count: true, SkipChars: 4})`,
+		"wc -l":                         "scriptish.CountLines()",
+		"wc -w":                         "scriptish.CountWords()",
+		"which git":                     `scriptish.Which("git")`,
+		"xargs cat":                     "scriptish.XargsCat()",
+		"xargs rm -f":                   "scriptish.XargsRmFile()",
+		"xargs test -e":                 "scriptish.XargsTestFilepathExists()",
+		"xargs basename":                "scriptish.XargsBasename()",
+		"xargs dirname":                 "scriptish.XargsDirname()",
+		"xargs -n1 gzip -9":             `scriptish.Xargs(scriptish.NewList(scriptish.Exec([]string{"gzip", "-9", "$1"}, scriptish.NoGlob())), scriptish.XargsOptions{})`,
+		"xargs -P 4 -I {} cp {} {}.bak": `scriptish.XargsParallel(4, func() *scriptish.Sequence { return scriptish.NewList(scriptish.Exec([]string{"cp", "$1", "$1.bak"}, scriptish.NoGlob())) }, scriptish.XargsOptions{})`,
+	}
+
+	for src, expectedResult := range testData {
+		// ----------------------------------------------------------------
+		// perform the change
+
+		m := mapCommand(t, src)
+
+		// ----------------------------------------------------------------
+		// test the results
+
+		if assert.NotNil(t, m, src) {
+			assert.Equal(t, expectedResult, stepCall(m.step, m.args...), src)
+		}
+	}
+}
+
+func TestCommandMappersReturnTheFileToReadFrom(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	testData := map[string]string{
+		"grep foo config.yaml": "config.yaml",
+		"grep -in foo c.yaml":  "c.yaml",
+		"awk '{print $2}' f":   "f",
+		"sed 1d data.csv":      "data.csv",
+		"head -n 2 data.csv":   "data.csv",
+		"sort names.txt":       "names.txt",
+		"sort -n names.txt":    "names.txt",
+		"uniq names.txt":       "names.txt",
+		"uniq -c names.txt":    "names.txt",
+	}
+
+	for src, expectedResult := range testData {
+		// ----------------------------------------------------------------
+		// perform the change
+
+		m := mapCommand(t, src)
+
+		// ----------------------------------------------------------------
+		// test the results
+
+		if assert.NotNil(t, m, src) && assert.NotNil(t, m.input, src) {
+			assert.Equal(t, expectedResult, m.input.value, src)
+		}
+	}
+}
+
+func TestCommandMappersReturnNilForArgumentsTheyDoNotUnderstand(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	testData := []string{
+		"basename a.txt .txt",
+		"cat a b",
+		"chmod u+x run.sh",
+		"cut -c 1-3 -f 2",
+		"cut -d, -c 2",
+		"awk '{ print $0 }'",
+		"awk '{ print $1 $2 }'",
+		"echo -n hello",
+		"echo *.txt",
+		"exit $status",
+		"grep -q foo",
+		"grep -l foo",
+		"grep -m 0 foo",
+		"grep -A x foo",
+		"grep -i",
+		"grep --colour foo",
+		"grep -l foo",
+		"grep -R foo .",
+		"grep -r foo '*.go'",
+		"grep -r foo src -i",
+		"grep --include",
+		"head -c 10",
+		"ls -l",
+		"mktemp /tmp/foo.XXXX",
+		"rm -rf build",
+		"sed -i 's/a/b/' file.txt",
+		"sed",
+		"sort -k2.3",
+		"sort -n -M",
+		"sort -k1n -k2",
+		"sort -r -k2,2n",
+		"sort --reverse",
+		"sort -o out.txt",
+		"uniq -D",
+		"uniq -f",
+		"uniq -f x",
+		"uniq -w 3",
+		"uniq --all-repeated",
+		"uniq a.txt b.txt",
+		"tr a-z A-Z",
+		"tr abc xy",
+		"wc -l file.txt",
+		"xargs -0 rm",
+		"xargs -n 2 echo",
+		"[ -f *.txt ]",
+		"find . -name *.go",
+		"find . -delete",
+		"find a b",
+		"find . -name '*.go' -o -name '*.txt' -print",
+	}
+
+	for _, src := range testData {
+		// ----------------------------------------------------------------
+		// perform the change
+
+		m := mapCommand(t, src)
+
+		// ----------------------------------------------------------------
+		// test the results
+
+		assert.Nil(t, m, src)
+	}
+}
+
+func TestCommandMappersAddTodosForBehaviourThatIsDifferent(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	testData := []string{
+		"rm -f out.txt",
+		`grep 'a\(b\)'`,
+		`grep -i 'a\+'`,
+		`sed 's/\(a\)/\1/'`,
+		`[ "$x" = "v*" ]`,
+		`[[ $x =~ ^v([0-9]+) ]]`,
+		"find . -path ./vendor -prune -o -type f",
+		"xargs gzip",
+		"cut -d: -f1",
+		"cut -c 3,1",
+		"sort -k2,2nr",
+	}
+
+	for _, src := range testData {
+		// ----------------------------------------------------------------
+		// perform the change
+
+		m := mapCommand(t, src)
+
+		// ----------------------------------------------------------------
+		// test the results
+
+		if assert.NotNil(t, m, src) {
+			assert.NotEmpty(t, m.todos, src)
+		}
+	}
+}
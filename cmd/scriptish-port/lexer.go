@@ -0,0 +1,572 @@
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// tokenKind tells us what kind of token we are looking at
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenNewline
+	tokenSemicolon
+	tokenAmpersand
+	tokenPipe
+	tokenAnd
+	tokenOr
+	tokenLeftParen
+	tokenRightParen
+	tokenRedirect
+	tokenDoubleSemicolon
+)
+
+// token is a single token from a bash script
+type token struct {
+	kind tokenKind
+
+	// text is the token, exactly as it appears in the script
+	text string
+
+	// where the token appears in the script
+	start int
+	end   int
+
+	// word is only set for tokenWord
+	word *word
+
+	// heredocEnd is only set for `<<` redirects; it is where the
+	// here document's body finishes in the script
+	heredocEnd int
+}
+
+// word is a single word from a bash script, such as a command name
+// or an argument
+type word struct {
+	// raw is the word, exactly as it appears in the script
+	raw string
+
+	// value is the word with any quoting removed, ready to be
+	// expanded by Scriptish
+	value string
+
+	// quoted is true if any part of the word was quoted
+	quoted bool
+
+	// unsupported explains why we cannot translate this word at all
+	// (eg, it contains a command substitution)
+	unsupported string
+
+	// warnings are problems that we can translate around, but that
+	// someone needs to double-check
+	warnings []string
+}
+
+// lexer turns a bash script into tokens
+type lexer struct {
+	src string
+	pos int
+
+	// here documents that start on the current line
+	pendingHeredocs []*heredoc
+
+	// inDoubleBrackets is true between `[[` and `]]`, where bash
+	// treats `&&`, `||`, `(`, `)`, `<` and `>` as ordinary words
+	inDoubleBrackets bool
+
+	tokens []token
+}
+
+// heredoc is a here document that we need to skip over
+type heredoc struct {
+	delimiter string
+	stripTabs bool
+	redirect  int
+}
+
+// tokenize splits the given bash script up into tokens
+func tokenize(src string) ([]token, error) {
+	l := lexer{src: src}
+
+	for {
+		tok, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+		l.tokens = append(l.tokens, tok)
+		if tok.kind == tokenEOF {
+			return l.tokens, nil
+		}
+	}
+}
+
+// next returns the next token from the script
+func (l *lexer) next() (token, error) {
+	l.skipBlanksAndComments()
+
+	// have we run out of script?
+	if l.pos >= len(l.src) {
+		return token{kind: tokenEOF, start: l.pos, end: l.pos}, nil
+	}
+
+	start := l.pos
+	c := l.src[l.pos]
+
+	// special case - operators inside `[[ ... ]]`
+	if l.inDoubleBrackets {
+		op := l.src[l.pos:]
+		if strings.HasPrefix(op, "&&") || strings.HasPrefix(op, "||") {
+			l.pos += 2
+			return l.newWordToken(start), nil
+		}
+		if strings.IndexByte("()<>|", c) >= 0 {
+			return l.lexWordToken(start)
+		}
+	}
+
+	switch c {
+	case '\n':
+		l.pos++
+		err := l.skipHeredocs()
+		if err != nil {
+			return token{}, err
+		}
+		return l.newToken(tokenNewline, start), nil
+	case ';':
+		if l.peek(1) == ';' {
+			l.pos += 2
+			return l.newToken(tokenDoubleSemicolon, start), nil
+		}
+		l.pos++
+		return l.newToken(tokenSemicolon, start), nil
+	case '|':
+		if l.peek(1) == '|' {
+			l.pos += 2
+			return l.newToken(tokenOr, start), nil
+		}
+		l.pos++
+		return l.newToken(tokenPipe, start), nil
+	case '&':
+		if l.peek(1) == '&' {
+			l.pos += 2
+			return l.newToken(tokenAnd, start), nil
+		}
+		if l.peek(1) == '>' {
+			return l.lexRedirect(start)
+		}
+		l.pos++
+		return l.newToken(tokenAmpersand, start), nil
+	case '(':
+		l.pos++
+		return l.newToken(tokenLeftParen, start), nil
+	case ')':
+		l.pos++
+		return l.newToken(tokenRightParen, start), nil
+	case '<', '>':
+		return l.lexRedirect(start)
+	}
+
+	// redirects can start with a file descriptor number
+	i := l.pos
+	for i < len(l.src) && l.src[i] >= '0' && l.src[i] <= '9' {
+		i++
+	}
+	if i > l.pos && i < len(l.src) && (l.src[i] == '<' || l.src[i] == '>') {
+		l.pos = i
+		return l.lexRedirect(start)
+	}
+
+	// if we get here, we have a word
+	return l.lexWordToken(start)
+}
+
+// lexWordToken returns the word that starts at the current position
+func (l *lexer) lexWordToken(start int) (token, error) {
+	w, err := l.lexWord()
+	if err != nil {
+		return token{}, err
+	}
+	tok := l.newToken(tokenWord, start)
+	tok.word = w
+
+	if !w.quoted && (w.value == "[[" || w.value == "]]") {
+		l.inDoubleBrackets = w.value == "[["
+	}
+
+	return tok, nil
+}
+
+// newWordToken returns a word token for an operator that bash treats
+// as an ordinary word
+func (l *lexer) newWordToken(start int) token {
+	tok := l.newToken(tokenWord, start)
+	tok.word = &word{raw: tok.text, value: tok.text}
+	return tok
+}
+
+func (l *lexer) newToken(kind tokenKind, start int) token {
+	return token{
+		kind:  kind,
+		text:  l.src[start:l.pos],
+		start: start,
+		end:   l.pos,
+	}
+}
+
+// peek returns the character that is offset characters ahead of us,
+// or 0 if we have run out of script
+func (l *lexer) peek(offset int) byte {
+	if l.pos+offset >= len(l.src) {
+		return 0
+	}
+	return l.src[l.pos+offset]
+}
+
+func (l *lexer) skipBlanksAndComments() {
+	for l.pos < len(l.src) {
+		switch l.src[l.pos] {
+		case ' ', '\t', '\r':
+			l.pos++
+		case '\\':
+			// line continuation
+			if l.peek(1) != '\n' {
+				return
+			}
+			l.pos += 2
+		case '#':
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' {
+				l.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// lexRedirect reads a redirect operator, such as `>`, `2>>` or `2>&1`
+//
+// l.pos must point at the `<`, `>` or `&` character
+func (l *lexer) lexRedirect(start int) (token, error) {
+	operators := []string{"&>>", "&>", ">>", ">&", ">|", "<<<", "<<-", "<<", "<&", "<>", "<", ">"}
+	for _, op := range operators {
+		if !strings.HasPrefix(l.src[l.pos:], op) {
+			continue
+		}
+		l.pos += len(op)
+
+		// `>&2` and friends name a file descriptor, not a file
+		if strings.HasSuffix(op, "&") && op != "&>" {
+			for l.pos < len(l.src) && (l.src[l.pos] >= '0' && l.src[l.pos] <= '9' || l.src[l.pos] == '-') {
+				l.pos++
+			}
+		}
+
+		tok := l.newToken(tokenRedirect, start)
+
+		// here documents need their body skipping
+		if op == "<<" || op == "<<-" {
+			l.skipBlanksAndComments()
+			w, err := l.lexWord()
+			if err != nil {
+				return token{}, err
+			}
+			l.pendingHeredocs = append(l.pendingHeredocs, &heredoc{
+				delimiter: w.value,
+				stripTabs: op == "<<-",
+				redirect:  len(l.tokens),
+			})
+			tok.end = l.pos
+			tok.text = l.src[start:l.pos]
+		}
+
+		return tok, nil
+	}
+
+	return token{}, fmt.Errorf("unexpected character %q at offset %d", l.src[l.pos], l.pos)
+}
+
+// skipHeredocs skips over the body of any here documents that started
+// on the line that we have just finished
+func (l *lexer) skipHeredocs() error {
+	for _, doc := range l.pendingHeredocs {
+		for {
+			if l.pos >= len(l.src) {
+				return fmt.Errorf("here document %q is not terminated", doc.delimiter)
+			}
+
+			lineEnd := strings.IndexByte(l.src[l.pos:], '\n')
+			var line string
+			if lineEnd < 0 {
+				line = l.src[l.pos:]
+				l.pos = len(l.src)
+			} else {
+				line = l.src[l.pos : l.pos+lineEnd]
+				l.pos += lineEnd + 1
+			}
+
+			if doc.stripTabs {
+				line = strings.TrimLeft(line, "\t")
+			}
+			if line == doc.delimiter {
+				break
+			}
+		}
+		l.tokens[doc.redirect].heredocEnd = l.pos
+	}
+
+	l.pendingHeredocs = nil
+	return nil
+}
+
+// lexWord reads a single word, dealing with any quoting along the way
+func (l *lexer) lexWord() (*word, error) {
+	start := l.pos
+	retval := word{}
+	var value strings.Builder
+
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+
+		switch c {
+		case '|', '<', '>', '(', ')':
+			// inside `[[ ... ]]`, these are part of the word
+			if l.inDoubleBrackets {
+				value.WriteByte(c)
+				l.pos++
+				continue
+			}
+
+			// end of the word
+			retval.raw = l.src[start:l.pos]
+			retval.value = value.String()
+			return &retval, nil
+
+		case ' ', '\t', '\r', '\n', ';', '&':
+			// end of the word
+			retval.raw = l.src[start:l.pos]
+			retval.value = value.String()
+			return &retval, nil
+
+		case '\\':
+			// the next character is escaped
+			if l.peek(1) == '\n' {
+				l.pos += 2
+				continue
+			}
+			if l.peek(1) == 0 {
+				l.pos++
+				continue
+			}
+			escaped := l.src[l.pos+1]
+			if escaped == '$' {
+				retval.warnings = append(retval.warnings, "contains an escaped '$', which Scriptish will expand")
+			}
+			retval.quoted = true
+			value.WriteByte(escaped)
+			l.pos += 2
+
+		case '\'':
+			end := strings.IndexByte(l.src[l.pos+1:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated single quote at offset %d", l.pos)
+			}
+			content := l.src[l.pos+1 : l.pos+1+end]
+			if strings.Contains(content, "$") {
+				retval.warnings = append(retval.warnings, "contains a single-quoted '$', which Scriptish will expand")
+			}
+			retval.quoted = true
+			value.WriteString(content)
+			l.pos += end + 2
+
+		case '"':
+			err := l.lexDoubleQuoted(&retval, &value)
+			if err != nil {
+				return nil, err
+			}
+
+		case '`':
+			end := strings.IndexByte(l.src[l.pos+1:], '`')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated backtick at offset %d", l.pos)
+			}
+			retval.unsupported = "command substitution"
+			value.WriteString(l.src[l.pos : l.pos+end+2])
+			l.pos += end + 2
+
+		case '$':
+			err := l.lexDollar(&retval, &value)
+			if err != nil {
+				return nil, err
+			}
+
+		default:
+			value.WriteByte(c)
+			l.pos++
+		}
+	}
+
+	retval.raw = l.src[start:l.pos]
+	retval.value = value.String()
+	return &retval, nil
+}
+
+// lexDoubleQuoted reads a double-quoted part of a word
+//
+// l.pos must point at the opening double quote
+func (l *lexer) lexDoubleQuoted(w *word, value *strings.Builder) error {
+	start := l.pos
+	w.quoted = true
+	l.pos++
+
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+
+		switch c {
+		case '"':
+			l.pos++
+			return nil
+
+		case '\\':
+			next := l.peek(1)
+			switch next {
+			case '$':
+				w.warnings = append(w.warnings, "contains an escaped '$', which Scriptish will expand")
+				value.WriteByte(next)
+				l.pos += 2
+			case '"', '\\', '`':
+				value.WriteByte(next)
+				l.pos += 2
+			case '\n':
+				l.pos += 2
+			default:
+				value.WriteByte(c)
+				l.pos++
+			}
+
+		case '`':
+			end := strings.IndexByte(l.src[l.pos+1:], '`')
+			if end < 0 {
+				return fmt.Errorf("unterminated backtick at offset %d", l.pos)
+			}
+			w.unsupported = "command substitution"
+			value.WriteString(l.src[l.pos : l.pos+end+2])
+			l.pos += end + 2
+
+		case '$':
+			err := l.lexDollar(w, value)
+			if err != nil {
+				return err
+			}
+
+		default:
+			value.WriteByte(c)
+			l.pos++
+		}
+	}
+
+	return fmt.Errorf("unterminated double quote at offset %d", start)
+}
+
+// lexDollar reads a parameter expansion, command substitution or
+// arithmetic expansion
+//
+// l.pos must point at the `$`
+func (l *lexer) lexDollar(w *word, value *strings.Builder) error {
+	start := l.pos
+
+	switch l.peek(1) {
+	case '(':
+		// Scriptish supports arithmetic expansion, but it can only
+		// run sub-sequences that the caller has named
+		if l.peek(2) != '(' {
+			w.unsupported = "command substitution"
+		}
+		end, err := l.findClosing(l.pos+1, '(', ')')
+		if err != nil {
+			return err
+		}
+		l.pos = end
+
+	case '{':
+		end, err := l.findClosing(l.pos+1, '{', '}')
+		if err != nil {
+			return err
+		}
+		l.pos = end
+
+	case '\'':
+		w.unsupported = "ANSI-C quoting"
+		end := strings.IndexByte(l.src[l.pos+2:], '\'')
+		if end < 0 {
+			return fmt.Errorf("unterminated single quote at offset %d", l.pos)
+		}
+		l.pos += end + 3
+
+	default:
+		l.pos++
+	}
+
+	value.WriteString(l.src[start:l.pos])
+	return nil
+}
+
+// findClosing returns the offset just after the bracket that closes the
+// bracket at the given offset
+func (l *lexer) findClosing(offset int, open byte, close byte) (int, error) {
+	depth := 0
+	for i := offset; i < len(l.src); i++ {
+		switch l.src[i] {
+		case '\\':
+			i++
+		case '\'':
+			end := strings.IndexByte(l.src[i+1:], '\'')
+			if end < 0 {
+				return 0, fmt.Errorf("unterminated single quote at offset %d", i)
+			}
+			i += end + 1
+		case open:
+			depth++
+		case close:
+			depth--
+			if depth == 0 {
+				return i + 1, nil
+			}
+		}
+	}
+
+	return 0, fmt.Errorf("unterminated %q at offset %d", open, offset)
+}
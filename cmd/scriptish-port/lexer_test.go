@@ -0,0 +1,299 @@
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenizeSplitsCommandsIntoTokens(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	src := "grep foo | sort && echo done || exit 1; ls &\n"
+	expectedKinds := []tokenKind{
+		tokenWord, tokenWord, tokenPipe, tokenWord, tokenAnd, tokenWord,
+		tokenWord, tokenOr, tokenWord, tokenWord, tokenSemicolon,
+		tokenWord, tokenAmpersand, tokenNewline, tokenEOF,
+	}
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	tokens, err := tokenize(src)
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	actualKinds := []tokenKind{}
+	for _, tok := range tokens {
+		actualKinds = append(actualKinds, tok.kind)
+	}
+	assert.Equal(t, expectedKinds, actualKinds)
+}
+
+func TestTokenizeSkipsCommentsAndLineContinuations(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	src := "# a comment\necho one \\\n  two # another comment\n"
+	expectedText := []string{"\n", "echo", "one", "two", "\n", ""}
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	tokens, err := tokenize(src)
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	actualText := []string{}
+	for _, tok := range tokens {
+		actualText = append(actualText, tok.text)
+	}
+	assert.Equal(t, expectedText, actualText)
+}
+
+func TestTokenizeRemovesQuoting(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	src := `echo 'single quoted' "double \"quoted\"" back\ slash "$HOME"/bin`
+	expectedValues := []string{"echo", "single quoted", `double "quoted"`, "back slash", "$HOME/bin"}
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	tokens, err := tokenize(src)
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	actualValues := []string{}
+	for _, tok := range tokens {
+		if tok.kind == tokenWord {
+			actualValues = append(actualValues, tok.word.value)
+			assert.Equal(t, tok.text, tok.word.raw)
+		}
+	}
+	assert.Equal(t, expectedValues, actualValues)
+	assert.False(t, tokens[0].word.quoted)
+	assert.True(t, tokens[1].word.quoted)
+}
+
+func TestTokenizeWarnsAboutDollarSignsThatWouldNotBeExpandedByBash(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	src := `grep 'end$'`
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	tokens, err := tokenize(src)
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Empty(t, tokens[0].word.warnings)
+	assert.NotEmpty(t, tokens[1].word.warnings)
+}
+
+func TestTokenizeMarksCommandSubstitutionAsUnsupported(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	testData := []string{
+		"echo $(date)",
+		"echo `date`",
+		`echo "today is $(date)"`,
+	}
+
+	for _, src := range testData {
+		// ----------------------------------------------------------------
+		// perform the change
+
+		tokens, err := tokenize(src)
+
+		// ----------------------------------------------------------------
+		// test the results
+
+		assert.Nil(t, err, src)
+		assert.NotEqual(t, "", tokens[1].word.unsupported, src)
+	}
+}
+
+func TestTokenizeSupportsArithmeticExpansion(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	testData := []string{
+		"echo $((1 + 2))",
+		`echo "total: $((count * 2))"`,
+	}
+
+	for _, src := range testData {
+		// ----------------------------------------------------------------
+		// perform the change
+
+		tokens, err := tokenize(src)
+
+		// ----------------------------------------------------------------
+		// test the results
+
+		assert.Nil(t, err, src)
+		assert.Equal(t, "", tokens[1].word.unsupported, src)
+	}
+}
+
+func TestTokenizeTreatsOperatorsInsideDoubleBracketsAsWords(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	src := `[[ -f "$1" && ( $2 < 1 || $2 =~ ^v([0-9]+)|x$ ) ]] && echo yes`
+	expectedValues := []string{
+		"[[", "-f", "$1", "&&", "(", "$2", "<", "1", "||", "$2", "=~",
+		"^v([0-9]+)|x$", ")", "]]",
+	}
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	tokens, err := tokenize(src)
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	actualValues := []string{}
+	for _, tok := range tokens[:len(expectedValues)] {
+		if assert.Equal(t, tokenWord, tok.kind, tok.text) {
+			actualValues = append(actualValues, tok.word.value)
+		}
+	}
+	assert.Equal(t, expectedValues, actualValues)
+	assert.Equal(t, tokenAnd, tokens[len(expectedValues)].kind)
+}
+
+func TestTokenizeRecognisesRedirects(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	src := "cmd >out 2>>err 2>&1 >&2 &>both <in"
+	expectedText := []string{"cmd", ">", "out", "2>>", "err", "2>&1", ">&2", "&>", "both", "<", "in", ""}
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	tokens, err := tokenize(src)
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	actualText := []string{}
+	for _, tok := range tokens {
+		actualText = append(actualText, tok.text)
+	}
+	assert.Equal(t, expectedText, actualText)
+	assert.Equal(t, tokenRedirect, tokens[5].kind)
+}
+
+func TestTokenizeSkipsHereDocuments(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	src := "cat <<-EOF\n\thello\n\tEOF\necho done\n"
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	tokens, err := tokenize(src)
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, "<<-EOF", tokens[1].text)
+	assert.Equal(t, len("cat <<-EOF\n\thello\n\tEOF\n"), tokens[1].heredocEnd)
+	assert.Equal(t, "echo", tokens[3].text)
+}
+
+func TestTokenizeReturnsAnErrorForUnterminatedQuotes(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	testData := []string{
+		"echo 'hello",
+		`echo "hello`,
+		"cat <<EOF\nhello\n",
+	}
+
+	for _, src := range testData {
+		// ----------------------------------------------------------------
+		// perform the change
+
+		_, err := tokenize(src)
+
+		// ----------------------------------------------------------------
+		// test the results
+
+		assert.NotNil(t, err, src)
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+// scriptish-port translates a bash script into Go code that uses
+// Scriptish.
+//
+// Usage:
+//
+//	scriptish-port [-o output.go] [script.sh]
+//
+// If no script is given, scriptish-port reads the script from stdin.
+// If no output file is given, the Go code is written to stdout.
+//
+// scriptish-port translates simple commands, pipes, `&&` and `||`,
+// if / elif / else, while / until / for loops, case statements,
+// redirects and functions. Anything that it cannot translate is run
+// in bash via scriptish.Exec(), with a `TODO: scriptish-port` comment
+// that explains why.
+//
+// The generated code is a starting point. Always review it before
+// using it.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+func main() {
+	err := run(os.Args[1:], os.Stdin, os.Stdout)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "scriptish-port: %s\n", err.Error())
+		os.Exit(1)
+	}
+}
+
+// run does all of the work, so that we can test it
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	flags := flag.NewFlagSet("scriptish-port", flag.ContinueOnError)
+	output := flags.String("o", "", "write the Go code to this file instead of stdout")
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	// where is the script?
+	var src []byte
+	name := "stdin"
+	switch flags.NArg() {
+	case 0:
+		src, err = ioutil.ReadAll(stdin)
+	case 1:
+		name = flags.Arg(0)
+		src, err = ioutil.ReadFile(name)
+	default:
+		return fmt.Errorf("expected one script, got %d", flags.NArg())
+	}
+	if err != nil {
+		return err
+	}
+
+	// translate it
+	code, err := translate(name, string(src))
+	if err != nil {
+		return fmt.Errorf("%s: %s", name, err.Error())
+	}
+
+	// all done
+	if *output == "" {
+		_, err = stdout.Write(code)
+		return err
+	}
+	return ioutil.WriteFile(*output, code, 0644)
+}
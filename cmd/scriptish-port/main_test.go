@@ -0,0 +1,144 @@
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunReadsTheScriptFromStdin(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	stdin := strings.NewReader("echo hello\n")
+	var stdout bytes.Buffer
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	err := run([]string{}, stdin, &stdout)
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), "generated by scriptish-port from stdin")
+	assert.Contains(t, stdout.String(), `scriptish.Echo("hello")`)
+}
+
+func TestRunReadsTheScriptFromAFile(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	dir, err := ioutil.TempDir("", "scriptish-port-")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	script := filepath.Join(dir, "hello.sh")
+	err = ioutil.WriteFile(script, []byte("echo hello\n"), 0644)
+	assert.Nil(t, err)
+
+	var stdout bytes.Buffer
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	err = run([]string{script}, strings.NewReader(""), &stdout)
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Contains(t, stdout.String(), "generated by scriptish-port from "+script)
+}
+
+func TestRunWritesTheOutputToAFile(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	dir, err := ioutil.TempDir("", "scriptish-port-")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	output := filepath.Join(dir, "main.go")
+	var stdout bytes.Buffer
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	err = run([]string{"-o", output}, strings.NewReader("pwd\n"), &stdout)
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Empty(t, stdout.String())
+
+	actualResult, err := ioutil.ReadFile(output)
+	assert.Nil(t, err)
+	assert.Contains(t, string(actualResult), "scriptish.Pwd()")
+}
+
+func TestRunReturnsAnErrorForInvalidScripts(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	stdin := strings.NewReader("echo 'unterminated\n")
+	var stdout bytes.Buffer
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	err := run([]string{}, stdin, &stdout)
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.NotNil(t, err)
+	assert.True(t, strings.HasPrefix(err.Error(), "stdin: "))
+}
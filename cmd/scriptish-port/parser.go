@@ -0,0 +1,870 @@
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// node is anything that we can find in a list of commands
+type node interface {
+	// source returns the node, exactly as it appears in the script
+	source() string
+}
+
+// span is the part of the script that a node came from
+type span struct {
+	raw string
+}
+
+func (s span) source() string {
+	return s.raw
+}
+
+// simpleCommand is a command name, followed by its arguments
+type simpleCommand struct {
+	span
+
+	// any `name=value` variables set before the command name
+	assigns []*word
+
+	// the command name, and its arguments
+	args []*word
+
+	redirects []*redirect
+}
+
+// redirect is a single redirect, such as `> file` or `2>&1`
+type redirect struct {
+	op     string
+	target *word
+}
+
+// pipeline is one or more commands joined together by `|`
+type pipeline struct {
+	span
+
+	commands []node
+}
+
+// andOr is one or more pipelines joined together by `&&` or `||`
+type andOr struct {
+	span
+
+	first node
+	rest  []andOrPart
+}
+
+// andOrPart is a pipeline that follows `&&` or `||`
+type andOrPart struct {
+	op  tokenKind
+	cmd node
+}
+
+// negation is a pipeline that starts with `!`
+type negation struct {
+	span
+
+	cmd node
+}
+
+// ifClause is an `if ... then ... fi` statement
+type ifClause struct {
+	span
+
+	cond     []node
+	body     []node
+	elseBody []node
+
+	redirects []*redirect
+}
+
+// loopClause is a `while ... do ... done` or `until ... do ... done`
+// statement
+type loopClause struct {
+	span
+
+	// keyword is either `while` or `until`
+	keyword string
+
+	cond []node
+	body []node
+
+	redirects []*redirect
+}
+
+// forClause is a `for name in words ; do ... done` statement
+type forClause struct {
+	span
+
+	varName string
+	words   []*word
+	body    []node
+
+	redirects []*redirect
+}
+
+// caseClause is a `case word in pattern) ... ;; esac` statement
+type caseClause struct {
+	span
+
+	subject *word
+	arms    []caseArm
+
+	redirects []*redirect
+}
+
+// caseArm is a single `pattern|pattern) ... ;;` in a `case` statement
+type caseArm struct {
+	patterns []*word
+	body     []node
+}
+
+// braceGroup is a `{ ... }` group of commands
+type braceGroup struct {
+	span
+
+	body []node
+
+	redirects []*redirect
+}
+
+// funcDecl is a function definition
+type funcDecl struct {
+	span
+
+	name string
+	body []node
+}
+
+// unsupported is anything that we cannot translate
+type unsupported struct {
+	span
+
+	reason string
+}
+
+// parser turns the tokens from a bash script into a tree of nodes
+type parser struct {
+	src    string
+	tokens []token
+	pos    int
+}
+
+// parseScript parses the given bash script
+func parseScript(src string) ([]node, error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := parser{src: src, tokens: tokens}
+	retval, err := p.parseList()
+	if err != nil {
+		return nil, err
+	}
+
+	// did we understand everything?
+	if p.peek().kind != tokenEOF {
+		return nil, p.unexpected()
+	}
+
+	return retval, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) advance() token {
+	retval := p.tokens[p.pos]
+	if retval.kind != tokenEOF {
+		p.pos++
+	}
+	return retval
+}
+
+// peekKeyword returns true if the next token is the given (unquoted)
+// reserved word
+func (p *parser) peekKeyword(keywords ...string) bool {
+	tok := p.peek()
+	if tok.kind != tokenWord || tok.word.quoted {
+		return false
+	}
+	for _, keyword := range keywords {
+		if tok.text == keyword {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) expectKeyword(keyword string) error {
+	if !p.peekKeyword(keyword) {
+		return fmt.Errorf("expected %q, found %q at offset %d", keyword, p.peek().text, p.peek().start)
+	}
+	p.advance()
+	return nil
+}
+
+func (p *parser) unexpected() error {
+	tok := p.peek()
+	if tok.kind == tokenEOF {
+		return fmt.Errorf("unexpected end of script")
+	}
+	return fmt.Errorf("unexpected %q at offset %d", tok.text, tok.start)
+}
+
+func (p *parser) skipNewlines() {
+	for p.peek().kind == tokenNewline {
+		p.advance()
+	}
+}
+
+// spanFrom returns the part of the script from the token at the given
+// position up to the last token that we have read
+func (p *parser) spanFrom(startPos int) span {
+	start := p.tokens[startPos].start
+	end := start
+	for i := startPos; i < p.pos; i++ {
+		if p.tokens[i].end > end {
+			end = p.tokens[i].end
+		}
+	}
+	return span{raw: p.src[start:end]}
+}
+
+// heredocSpanFrom works like spanFrom, but includes the body of any
+// here documents
+func (p *parser) heredocSpanFrom(startPos int) span {
+	retval := p.spanFrom(startPos)
+	start := p.tokens[startPos].start
+	end := start + len(retval.raw)
+	for i := startPos; i < p.pos; i++ {
+		if p.tokens[i].heredocEnd > end {
+			end = p.tokens[i].heredocEnd
+		}
+	}
+	return span{raw: p.src[start:end]}
+}
+
+// listTerminators are the reserved words that end a list of commands
+var listTerminators = []string{"then", "elif", "else", "fi", "}", "do", "done", "esac"}
+
+// parseList parses a list of commands, up to the end of the script or
+// a reserved word that ends the list
+func (p *parser) parseList() ([]node, error) {
+	retval := []node{}
+
+	for {
+		// skip any empty lines
+		for p.peek().kind == tokenNewline || p.peek().kind == tokenSemicolon {
+			p.advance()
+		}
+
+		// are we done?
+		tok := p.peek()
+		if tok.kind == tokenEOF || tok.kind == tokenRightParen || tok.kind == tokenDoubleSemicolon ||
+			p.peekKeyword(listTerminators...) {
+			return retval, nil
+		}
+
+		startPos := p.pos
+		cmd, err := p.parseAndOr()
+		if err != nil {
+			return nil, err
+		}
+
+		// what separates this command from the next one?
+		switch p.peek().kind {
+		case tokenAmpersand:
+			p.advance()
+			cmd = &unsupported{span: p.heredocSpanFrom(startPos), reason: "background jobs are not supported"}
+		case tokenNewline, tokenSemicolon, tokenEOF, tokenRightParen, tokenDoubleSemicolon:
+			// nothing to do
+		default:
+			if !p.peekKeyword(listTerminators...) {
+				return nil, p.unexpected()
+			}
+		}
+
+		retval = append(retval, cmd)
+	}
+}
+
+// parseAndOr parses pipelines joined together by `&&` or `||`
+func (p *parser) parseAndOr() (node, error) {
+	startPos := p.pos
+
+	first, err := p.parsePipeline()
+	if err != nil {
+		return nil, err
+	}
+
+	retval := andOr{first: first}
+	for p.peek().kind == tokenAnd || p.peek().kind == tokenOr {
+		op := p.advance().kind
+		p.skipNewlines()
+
+		cmd, err := p.parsePipeline()
+		if err != nil {
+			return nil, err
+		}
+		retval.rest = append(retval.rest, andOrPart{op: op, cmd: cmd})
+	}
+
+	// do we have anything to join together?
+	if len(retval.rest) == 0 {
+		return first, nil
+	}
+
+	retval.span = p.heredocSpanFrom(startPos)
+	return &retval, nil
+}
+
+// parsePipeline parses commands joined together by `|`
+func (p *parser) parsePipeline() (node, error) {
+	startPos := p.pos
+
+	if p.peekKeyword("!") {
+		p.advance()
+		cmd, err := p.parsePipeline()
+		if err != nil {
+			return nil, err
+		}
+		return &negation{span: p.heredocSpanFrom(startPos), cmd: cmd}, nil
+	}
+
+	first, err := p.parseCommand()
+	if err != nil {
+		return nil, err
+	}
+
+	retval := pipeline{commands: []node{first}}
+	for p.peek().kind == tokenPipe {
+		p.advance()
+		p.skipNewlines()
+
+		cmd, err := p.parseCommand()
+		if err != nil {
+			return nil, err
+		}
+		retval.commands = append(retval.commands, cmd)
+	}
+
+	// do we have anything to join together?
+	if len(retval.commands) == 1 {
+		return first, nil
+	}
+
+	retval.span = p.heredocSpanFrom(startPos)
+	return &retval, nil
+}
+
+// validName matches the names of bash variables and functions
+var validName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// parseCommand parses a single command
+func (p *parser) parseCommand() (node, error) {
+	startPos := p.pos
+	tok := p.peek()
+
+	switch {
+	case p.peekKeyword("if"):
+		return p.parseIf()
+
+	case p.peekKeyword("function"):
+		return p.parseFunction()
+
+	case p.peekKeyword("{"):
+		return p.parseBraceGroup()
+
+	case p.peekKeyword("while", "until"):
+		return p.parseLoop()
+
+	case p.peekKeyword("for"):
+		retval, err := p.parseFor()
+		if retval != nil || err != nil {
+			return retval, err
+		}
+
+		// if we get here, it is a `for` loop that we do not understand
+		p.pos = startPos
+		err = p.skipCompound()
+		if err != nil {
+			return nil, err
+		}
+		return &unsupported{
+			span:   p.heredocSpanFrom(startPos),
+			reason: "this kind of `for` loop is not supported",
+		}, nil
+
+	case p.peekKeyword("case"):
+		retval, err := p.parseCase()
+		if retval != nil || err != nil {
+			return retval, err
+		}
+
+		// if we get here, it is a `case` statement that we do not
+		// understand
+		p.pos = startPos
+		err = p.skipCompound()
+		if err != nil {
+			return nil, err
+		}
+		return &unsupported{
+			span:   p.heredocSpanFrom(startPos),
+			reason: "this kind of `case` statement is not supported",
+		}, nil
+
+	case p.peekKeyword("select"):
+		err := p.skipCompound()
+		if err != nil {
+			return nil, err
+		}
+		return &unsupported{
+			span:   p.heredocSpanFrom(startPos),
+			reason: fmt.Sprintf("`%s` is not supported", tok.text),
+		}, nil
+
+	case tok.kind == tokenLeftParen:
+		err := p.skipCompound()
+		if err != nil {
+			return nil, err
+		}
+		return &unsupported{span: p.heredocSpanFrom(startPos), reason: "subshells are not supported"}, nil
+
+	case tok.kind == tokenWord && !tok.word.quoted && validName.MatchString(tok.text) &&
+		p.tokens[p.pos+1].kind == tokenLeftParen:
+		return p.parseFunction()
+	}
+
+	return p.parseSimpleCommand()
+}
+
+// skipCompound skips over a compound command that we do not translate,
+// such as a `for` loop
+func (p *parser) skipCompound() error {
+	closers := []string{}
+
+	for {
+		tok := p.advance()
+
+		switch {
+		case tok.kind == tokenEOF:
+			return p.unexpected()
+		case tok.kind == tokenLeftParen:
+			closers = append(closers, ")")
+		case tok.kind == tokenRightParen && len(closers) > 0 && closers[len(closers)-1] == ")":
+			closers = closers[:len(closers)-1]
+		case tok.kind == tokenWord && !tok.word.quoted:
+			switch tok.text {
+			case "for", "while", "until", "select":
+				closers = append(closers, "done")
+			case "case":
+				closers = append(closers, "esac")
+			case "if":
+				closers = append(closers, "fi")
+			case "{":
+				closers = append(closers, "}")
+			case "done", "esac", "fi", "}":
+				if len(closers) > 0 && closers[len(closers)-1] == tok.text {
+					closers = closers[:len(closers)-1]
+				}
+			}
+		}
+
+		if len(closers) == 0 {
+			// pick up any redirects that apply to the whole command
+			_, err := p.parseRedirects()
+			return err
+		}
+	}
+}
+
+// parseIf parses an `if` statement, including any `elif` and `else`
+// parts
+func (p *parser) parseIf() (node, error) {
+	startPos := p.pos
+	p.advance()
+
+	retval := ifClause{}
+	var err error
+
+	retval.cond, err = p.parseList()
+	if err != nil {
+		return nil, err
+	}
+	err = p.expectKeyword("then")
+	if err != nil {
+		return nil, err
+	}
+	retval.body, err = p.parseList()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case p.peekKeyword("elif"):
+		// we treat `elif` as an `if` inside the `else`
+		elseIf, err := p.parseIf()
+		if err != nil {
+			return nil, err
+		}
+		retval.elseBody = []node{elseIf}
+		retval.span = p.heredocSpanFrom(startPos)
+		return &retval, nil
+
+	case p.peekKeyword("else"):
+		p.advance()
+		retval.elseBody, err = p.parseList()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = p.expectKeyword("fi")
+	if err != nil {
+		return nil, err
+	}
+
+	retval.redirects, err = p.parseRedirects()
+	if err != nil {
+		return nil, err
+	}
+
+	retval.span = p.heredocSpanFrom(startPos)
+	return &retval, nil
+}
+
+// parseLoop parses a `while` or `until` loop
+func (p *parser) parseLoop() (node, error) {
+	startPos := p.pos
+
+	retval := loopClause{keyword: p.advance().text}
+	var err error
+
+	retval.cond, err = p.parseList()
+	if err != nil {
+		return nil, err
+	}
+	err = p.expectKeyword("do")
+	if err != nil {
+		return nil, err
+	}
+	retval.body, err = p.parseList()
+	if err != nil {
+		return nil, err
+	}
+	err = p.expectKeyword("done")
+	if err != nil {
+		return nil, err
+	}
+
+	retval.redirects, err = p.parseRedirects()
+	if err != nil {
+		return nil, err
+	}
+
+	retval.span = p.heredocSpanFrom(startPos)
+	return &retval, nil
+}
+
+// parseFor parses a `for name in words ; do ... done` loop
+//
+// It returns nil if the loop is written some other way, such as
+// `for (( ... ))`.
+func (p *parser) parseFor() (node, error) {
+	startPos := p.pos
+	p.advance()
+
+	// what variable does the loop set?
+	tok := p.advance()
+	if tok.kind != tokenWord || !validName.MatchString(tok.text) || !p.peekKeyword("in") {
+		return nil, nil
+	}
+	p.advance()
+	retval := forClause{varName: tok.text}
+
+	// what are we looping over?
+	for p.peek().kind == tokenWord {
+		retval.words = append(retval.words, p.advance().word)
+	}
+	if p.peek().kind != tokenSemicolon && p.peek().kind != tokenNewline {
+		return nil, p.unexpected()
+	}
+	p.advance()
+	p.skipNewlines()
+
+	err := p.expectKeyword("do")
+	if err != nil {
+		return nil, err
+	}
+	retval.body, err = p.parseList()
+	if err != nil {
+		return nil, err
+	}
+	err = p.expectKeyword("done")
+	if err != nil {
+		return nil, err
+	}
+
+	retval.redirects, err = p.parseRedirects()
+	if err != nil {
+		return nil, err
+	}
+
+	retval.span = p.heredocSpanFrom(startPos)
+	return &retval, nil
+}
+
+// parseCase parses a `case word in pattern) ... ;; esac` statement
+//
+// It returns nil if the statement is written in a way that we do not
+// understand, such as arms that end in `;&`.
+func (p *parser) parseCase() (node, error) {
+	startPos := p.pos
+	p.advance()
+
+	// what are we matching against?
+	tok := p.advance()
+	if tok.kind != tokenWord {
+		return nil, nil
+	}
+	retval := caseClause{subject: tok.word}
+	p.skipNewlines()
+	if !p.peekKeyword("in") {
+		return nil, nil
+	}
+	p.advance()
+	p.skipNewlines()
+
+	for !p.peekKeyword("esac") {
+		arm := caseArm{}
+
+		// the patterns can start with an optional `(`
+		if p.peek().kind == tokenLeftParen {
+			p.advance()
+		}
+		for {
+			tok := p.advance()
+			if tok.kind != tokenWord {
+				return nil, nil
+			}
+			arm.patterns = append(arm.patterns, tok.word)
+
+			if p.peek().kind != tokenPipe {
+				break
+			}
+			p.advance()
+		}
+		if p.advance().kind != tokenRightParen {
+			return nil, nil
+		}
+
+		// what happens when the patterns match?
+		//
+		// we cannot parse bodies that end in `;&` or `;;&`, so we
+		// leave the whole statement for bash to run
+		var err error
+		arm.body, err = p.parseList()
+		if err != nil {
+			return nil, nil
+		}
+		retval.arms = append(retval.arms, arm)
+
+		// the last arm does not need to end with `;;`
+		if p.peek().kind != tokenDoubleSemicolon {
+			break
+		}
+		p.advance()
+		p.skipNewlines()
+	}
+
+	err := p.expectKeyword("esac")
+	if err != nil {
+		return nil, nil
+	}
+
+	retval.redirects, err = p.parseRedirects()
+	if err != nil {
+		return nil, err
+	}
+
+	retval.span = p.heredocSpanFrom(startPos)
+	return &retval, nil
+}
+
+// parseFunction parses `function name { ... }` or `name() { ... }`
+func (p *parser) parseFunction() (node, error) {
+	startPos := p.pos
+
+	if p.peekKeyword("function") {
+		p.advance()
+	}
+
+	// what is the function called?
+	tok := p.advance()
+	if tok.kind != tokenWord || !validName.MatchString(tok.text) {
+		return nil, fmt.Errorf("invalid function name %q at offset %d", tok.text, tok.start)
+	}
+
+	// the brackets are optional when the `function` keyword is used
+	if p.peek().kind == tokenLeftParen {
+		p.advance()
+		if p.advance().kind != tokenRightParen {
+			return nil, fmt.Errorf("expected \"()\" after function name %q", tok.text)
+		}
+	}
+	p.skipNewlines()
+
+	// we only understand functions that use `{ ... }`
+	if !p.peekKeyword("{") {
+		return nil, p.unexpected()
+	}
+	body, err := p.parseBraceGroup()
+	if err != nil {
+		return nil, err
+	}
+
+	return &funcDecl{
+		span: p.heredocSpanFrom(startPos),
+		name: tok.text,
+		body: body.(*braceGroup).body,
+	}, nil
+}
+
+// parseBraceGroup parses `{ ... }`
+func (p *parser) parseBraceGroup() (node, error) {
+	startPos := p.pos
+	p.advance()
+
+	body, err := p.parseList()
+	if err != nil {
+		return nil, err
+	}
+	err = p.expectKeyword("}")
+	if err != nil {
+		return nil, err
+	}
+
+	redirects, err := p.parseRedirects()
+	if err != nil {
+		return nil, err
+	}
+
+	return &braceGroup{
+		span:      p.heredocSpanFrom(startPos),
+		body:      body,
+		redirects: redirects,
+	}, nil
+}
+
+// parseRedirects parses any redirects that follow a compound command
+func (p *parser) parseRedirects() ([]*redirect, error) {
+	retval := []*redirect{}
+
+	for p.peek().kind == tokenRedirect {
+		r, err := p.parseRedirect()
+		if err != nil {
+			return nil, err
+		}
+		retval = append(retval, r)
+	}
+
+	return retval, nil
+}
+
+// parseRedirect parses a single redirect, and its target
+func (p *parser) parseRedirect() (*redirect, error) {
+	tok := p.advance()
+	retval := redirect{op: tok.text}
+
+	// some redirects do not have a target
+	if tok.heredocEnd > 0 || (tok.text[len(tok.text)-1] != '>' && tok.text[len(tok.text)-1] != '<' && tok.text[len(tok.text)-1] != '|') {
+		return &retval, nil
+	}
+
+	target := p.advance()
+	if target.kind != tokenWord {
+		return nil, fmt.Errorf("expected a filename after %q at offset %d", tok.text, tok.start)
+	}
+	retval.target = target.word
+	return &retval, nil
+}
+
+// parseSimpleCommand parses a command name, its arguments, and
+// any redirects
+func (p *parser) parseSimpleCommand() (node, error) {
+	startPos := p.pos
+	retval := simpleCommand{}
+
+	for {
+		tok := p.peek()
+
+		switch tok.kind {
+		case tokenWord:
+			p.advance()
+			if len(retval.args) == 0 && isAssignment(tok.word) {
+				retval.assigns = append(retval.assigns, tok.word)
+			} else {
+				retval.args = append(retval.args, tok.word)
+			}
+
+		case tokenRedirect:
+			r, err := p.parseRedirect()
+			if err != nil {
+				return nil, err
+			}
+			retval.redirects = append(retval.redirects, r)
+
+		default:
+			if p.pos == startPos {
+				return nil, p.unexpected()
+			}
+			retval.span = p.heredocSpanFrom(startPos)
+			return &retval, nil
+		}
+	}
+}
+
+// isAssignment returns true if the word looks like `name=value`
+func isAssignment(w *word) bool {
+	for i := 0; i < len(w.raw); i++ {
+		if w.raw[i] == '=' {
+			return i > 0 && validName.MatchString(w.raw[:i])
+		}
+	}
+	return false
+}
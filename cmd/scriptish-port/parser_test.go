@@ -0,0 +1,263 @@
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseScriptParsesSimpleCommands(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	src := "FOO=bar make build > build.log"
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	nodes, err := parseScript(src)
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Len(t, nodes, 1)
+
+	cmd, ok := nodes[0].(*simpleCommand)
+	assert.True(t, ok)
+	assert.Equal(t, src, cmd.source())
+	assert.Equal(t, "FOO=bar", cmd.assigns[0].value)
+	assert.Len(t, cmd.args, 2)
+	assert.Equal(t, ">", cmd.redirects[0].op)
+	assert.Equal(t, "build.log", cmd.redirects[0].target.value)
+}
+
+func TestParseScriptParsesPipelinesAndLogic(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	src := "cat file | sort && echo sorted || echo failed"
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	nodes, err := parseScript(src)
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Len(t, nodes, 1)
+
+	logic, ok := nodes[0].(*andOr)
+	assert.True(t, ok)
+	assert.Len(t, logic.rest, 2)
+	assert.Equal(t, tokenAnd, logic.rest[0].op)
+	assert.Equal(t, tokenOr, logic.rest[1].op)
+
+	pipe, ok := logic.first.(*pipeline)
+	assert.True(t, ok)
+	assert.Len(t, pipe.commands, 2)
+	assert.Equal(t, "cat file | sort", pipe.source())
+}
+
+func TestParseScriptParsesIfStatements(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	src := "if [ -e a ]; then\n  echo a\nelif [ -e b ]; then\n  echo b\nelse\n  echo c\nfi\n"
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	nodes, err := parseScript(src)
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Len(t, nodes, 1)
+
+	stmt, ok := nodes[0].(*ifClause)
+	assert.True(t, ok)
+	assert.Len(t, stmt.cond, 1)
+	assert.Len(t, stmt.body, 1)
+	assert.Len(t, stmt.elseBody, 1)
+
+	elif, ok := stmt.elseBody[0].(*ifClause)
+	assert.True(t, ok)
+	assert.Len(t, elif.elseBody, 1)
+}
+
+func TestParseScriptParsesFunctions(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	testData := []string{
+		"greet() {\n  echo hello\n}\n",
+		"function greet {\n  echo hello\n}\n",
+		"function greet() { echo hello; }\n",
+	}
+
+	for _, src := range testData {
+		// ----------------------------------------------------------------
+		// perform the change
+
+		nodes, err := parseScript(src)
+
+		// ----------------------------------------------------------------
+		// test the results
+
+		assert.Nil(t, err, src)
+		assert.Len(t, nodes, 1, src)
+
+		decl, ok := nodes[0].(*funcDecl)
+		assert.True(t, ok, src)
+		assert.Equal(t, "greet", decl.name)
+		assert.Len(t, decl.body, 1, src)
+	}
+}
+
+func TestParseScriptParsesCaseStatements(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	src := "case \"$1\" in\n  start|stop)\n    echo service\n    ;;\n  (*.tar.gz) echo archive;;\n  *) echo other\nesac\n"
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	nodes, err := parseScript(src)
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Len(t, nodes, 1)
+
+	stmt, ok := nodes[0].(*caseClause)
+	assert.True(t, ok)
+	assert.Equal(t, "$1", stmt.subject.value)
+	assert.Len(t, stmt.arms, 3)
+	assert.Len(t, stmt.arms[0].patterns, 2)
+	assert.Equal(t, "stop", stmt.arms[0].patterns[1].value)
+	assert.Len(t, stmt.arms[0].body, 1)
+	assert.Equal(t, "*.tar.gz", stmt.arms[1].patterns[0].value)
+	assert.Len(t, stmt.arms[2].body, 1)
+}
+
+func TestParseScriptMarksLoopsAsUnsupported(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	src := "for ((;;)); do\n  if true; then echo $f; fi\ndone > out\necho after\n"
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	nodes, err := parseScript(src)
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Len(t, nodes, 2)
+
+	stmt, ok := nodes[0].(*unsupported)
+	assert.True(t, ok)
+	assert.Equal(t, "for ((;;)); do\n  if true; then echo $f; fi\ndone > out", stmt.source())
+}
+
+func TestParseScriptMarksBackgroundJobsAsUnsupported(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	src := "sleep 10 &\n"
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	nodes, err := parseScript(src)
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Len(t, nodes, 1)
+
+	stmt, ok := nodes[0].(*unsupported)
+	assert.True(t, ok)
+	assert.Equal(t, "sleep 10 &", stmt.source())
+}
+
+func TestParseScriptReturnsAnErrorForInvalidScripts(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	testData := []string{
+		"if true; then echo",
+		"echo |",
+		"greet() echo",
+		"fi",
+	}
+
+	for _, src := range testData {
+		// ----------------------------------------------------------------
+		// perform the change
+
+		_, err := parseScript(src)
+
+		// ----------------------------------------------------------------
+		// test the results
+
+		assert.NotNil(t, err, src)
+	}
+}
@@ -0,0 +1,762 @@
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package main
+
+import (
+	"fmt"
+	"go/format"
+	"strconv"
+	"strings"
+)
+
+// todoPrefix starts every comment that we add to the generated code
+const todoPrefix = "// TODO: scriptish-port: "
+
+// reservedNames are names that we cannot use for the Go variables
+// that hold translated bash functions
+var reservedNames = map[string]bool{
+	"break": true, "case": true, "chan": true, "const": true,
+	"continue": true, "default": true, "defer": true, "else": true,
+	"fallthrough": true, "for": true, "func": true, "go": true,
+	"goto": true, "if": true, "import": true, "interface": true,
+	"map": true, "package": true, "range": true, "return": true,
+	"select": true, "struct": true, "switch": true, "type": true,
+	"var": true,
+
+	// predeclared identifiers that the generated code relies on
+	"append": true, "len": true, "nil": true, "string": true,
+
+	// names that the generated code uses
+	"init": true, "list": true, "main": true, "os": true, "scriptish": true,
+}
+
+// translator turns a parsed bash script into Go code
+type translator struct {
+	// functions maps the names of bash functions onto the Go variables
+	// that hold their translation
+	functions map[string]string
+
+	// decls holds the Go code for each translated function, in the
+	// order that they appear in the script
+	decls []string
+}
+
+// translate turns the given bash script into Go code
+//
+// name is the script's filename, and is only used in the generated
+// comments.
+func translate(name string, src string) ([]byte, error) {
+	nodes, err := parseScript(src)
+	if err != nil {
+		return nil, err
+	}
+
+	t := translator{functions: map[string]string{}}
+	t.collectFunctions(nodes)
+
+	// `set -euo pipefail` at the top of the script becomes the
+	// list's ShellOptions
+	var shellOptions []string
+	if len(nodes) > 0 {
+		var ok bool
+		shellOptions, ok = setShellOptions(nodes[0])
+		if ok {
+			nodes = nodes[1:]
+		}
+	}
+
+	steps := t.list(nodes)
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "// This file was generated by scriptish-port from %s.\n", name)
+	buf.WriteString("//\n")
+	buf.WriteString("// Search for \"TODO: scriptish-port\" to find anything that needs\n")
+	buf.WriteString("// checking by hand.\n\n")
+	buf.WriteString("package main\n\n")
+	buf.WriteString("import (\n\t\"os\"\n\n\tscriptish \"github.com/ganbarodigital/go_scriptish\"\n)\n\n")
+
+	for _, decl := range t.decls {
+		buf.WriteString(decl)
+		buf.WriteString("\n\n")
+	}
+
+	buf.WriteString("func main() {\n")
+	buf.WriteString("list := " + newSequence("NewList", steps) + "\n")
+	if len(shellOptions) > 0 {
+		buf.WriteString("list.ShellOptions = &scriptish.ShellOptions{" + strings.Join(shellOptions, ", ") + "}\n")
+	}
+	buf.WriteString("\n")
+	buf.WriteString("list.Exec(os.Args[1:]...)\n")
+	buf.WriteString("list.Flush(os.Stdout, os.Stderr)\n")
+	buf.WriteString("os.Exit(list.StatusCode())\n")
+	buf.WriteString("}\n")
+
+	return format.Source([]byte(buf.String()))
+}
+
+// setOptions maps the `set` options that we understand onto the fields
+// of scriptish.ShellOptions
+var setOptions = map[string]string{
+	"e":        "Errexit",
+	"u":        "Nounset",
+	"errexit":  "Errexit",
+	"nounset":  "Nounset",
+	"pipefail": "Pipefail",
+}
+
+// setShellOptions returns the scriptish.ShellOptions fields for a
+// command such as `set -euo pipefail`
+//
+// It returns false if the command is not `set`, or if it uses any
+// options that we do not understand.
+func setShellOptions(n node) ([]string, bool) {
+	c, ok := n.(*simpleCommand)
+	if !ok || len(c.assigns) > 0 || len(c.redirects) > 0 || len(c.args) < 2 || c.args[0].value != "set" {
+		return nil, false
+	}
+
+	retval := []string{}
+	seen := map[string]bool{}
+	add := func(option string) bool {
+		field, ok := setOptions[option]
+		if !ok {
+			return false
+		}
+		if !seen[field] {
+			seen[field] = true
+			retval = append(retval, field+": true")
+		}
+		return true
+	}
+
+	args := c.args[1:]
+	for i := 0; i < len(args); i++ {
+		flags := args[i].value
+		if len(flags) < 2 || flags[0] != '-' {
+			return nil, false
+		}
+
+		for _, flag := range flags[1:] {
+			if flag != 'o' {
+				if !add(string(flag)) {
+					return nil, false
+				}
+				continue
+			}
+
+			// `-o` takes the name of the option from the next word
+			i++
+			if i >= len(args) || !add(args[i].value) {
+				return nil, false
+			}
+		}
+	}
+
+	return retval, true
+}
+
+// collectFunctions finds every function in the script, so that we
+// know which commands are function calls
+func (t *translator) collectFunctions(nodes []node) {
+	for _, n := range nodes {
+		switch n := n.(type) {
+		case *funcDecl:
+			name := n.name
+			if reservedNames[name] {
+				name += "Fn"
+			}
+			t.functions[n.name] = name
+			t.collectFunctions(n.body)
+		case *ifClause:
+			t.collectFunctions(n.cond)
+			t.collectFunctions(n.body)
+			t.collectFunctions(n.elseBody)
+		case *loopClause:
+			t.collectFunctions(n.cond)
+			t.collectFunctions(n.body)
+		case *forClause:
+			t.collectFunctions(n.body)
+		case *caseClause:
+			for _, arm := range n.arms {
+				t.collectFunctions(arm.body)
+			}
+		case *braceGroup:
+			t.collectFunctions(n.body)
+		}
+	}
+}
+
+// list translates a list of bash commands into Scriptish steps, ready
+// to go into a Scriptish List
+func (t *translator) list(nodes []node) []string {
+	retval := []string{}
+	for _, n := range nodes {
+		retval = append(retval, t.listSteps(n)...)
+	}
+	return retval
+}
+
+// listSteps translates a single bash command into steps that we can
+// add to a Scriptish List
+func (t *translator) listSteps(n node) []string {
+	switch n := n.(type) {
+	case *andOr:
+		retval := t.listSteps(n.first)
+		for _, part := range n.rest {
+			logic := "And"
+			if part.op == tokenOr {
+				logic = "Or"
+			}
+			retval = append(retval, stepCall(logic, newSequence("NewList", t.listSteps(part.cmd))))
+		}
+		return retval
+
+	case *funcDecl:
+		t.decls = append(t.decls, fmt.Sprintf(
+			"// %s was translated from the bash function `%s`\nvar %s = %s",
+			t.functions[n.name],
+			n.name,
+			t.functions[n.name],
+			newSequence("NewList", t.list(n.body)),
+		))
+		return nil
+
+	case *simpleCommand:
+		// variable assignments on their own must set the list's
+		// variables, not the variables of a sub-pipeline
+		if len(n.args) == 0 {
+			return t.simpleCommand(n)
+		}
+	}
+
+	// anything else is a pipeline
+	steps := t.pipelineSteps(n)
+	if len(steps) == 1 {
+		return steps
+	}
+	return []string{stepCall("RunPipeline", newSequence("NewPipeline", steps))}
+}
+
+// pipelineSteps translates a single bash command into steps that we can
+// add to a Scriptish Pipeline
+func (t *translator) pipelineSteps(n node) []string {
+	switch n := n.(type) {
+	case *simpleCommand:
+		return t.simpleCommand(n)
+
+	case *pipeline:
+		retval := []string{}
+		for i := 0; i < len(n.commands); i++ {
+			// special case - `sort | uniq -c | sort -rn` has its own step
+			if isCountByIdiom(n.commands[i:]) {
+				retval = append(retval, stepCall("CountBy", `""`, "scriptish.FieldOptions{}"))
+				i += len(countByIdiom) - 1
+				continue
+			}
+			retval = append(retval, t.pipelineSteps(n.commands[i])...)
+		}
+		return retval
+
+	case *ifClause:
+		opts, reason := compoundRedirectOptions(n.redirects)
+		if reason != "" {
+			return []string{fallback(n.source(), reason)}
+		}
+
+		args := []string{
+			newSequence("NewList", t.list(n.cond)),
+			newSequence("NewList", t.list(n.body)),
+		}
+		if len(n.elseBody) == 0 {
+			return []string{stepCall("If", append(args, opts...)...)}
+		}
+		args = append(args, newSequence("NewList", t.list(n.elseBody)))
+		return []string{stepCall("IfElse", append(args, opts...)...)}
+
+	case *loopClause:
+		return t.loop(n)
+
+	case *forClause:
+		return t.forLoop(n)
+
+	case *caseClause:
+		return t.caseStatement(n)
+
+	case *braceGroup:
+		opts, reason := compoundRedirectOptions(n.redirects)
+		if reason != "" {
+			return []string{fallback(n.source(), reason)}
+		}
+		args := append([]string{newSequence("NewList", t.list(n.body))}, opts...)
+		return []string{stepCall("RunList", args...)}
+
+	case *negation:
+		return []string{fallback(n.source(), "negated pipelines are not supported")}
+
+	case *unsupported:
+		return []string{fallback(n.source(), n.reason)}
+	}
+
+	// if we get here, it's something that cannot appear in a pipeline
+	return []string{fallback(n.source(), "this cannot be used in a pipeline")}
+}
+
+// countByIdiom is the pipeline that CountBy() emulates
+var countByIdiom = []string{"sort", "uniq -c", "sort -rn"}
+
+// isCountByIdiom returns true if the given commands start with
+// `sort | uniq -c | sort -rn`
+func isCountByIdiom(commands []node) bool {
+	if len(commands) < len(countByIdiom) {
+		return false
+	}
+
+	for i, expected := range countByIdiom {
+		c, ok := commands[i].(*simpleCommand)
+		if !ok || len(c.assigns) > 0 || len(c.redirects) > 0 {
+			return false
+		}
+
+		values := make([]string, len(c.args))
+		for j, arg := range c.args {
+			values[j] = arg.value
+		}
+		actual := strings.Join(values, " ")
+		if actual != expected && !(expected == "sort -rn" && actual == "sort -nr") {
+			return false
+		}
+	}
+
+	return true
+}
+
+// loop translates a `while` or `until` loop
+func (t *translator) loop(n *loopClause) []string {
+	// `while read line` is a loop over the lines of stdin
+	varName, isRead := readLoopVar(n)
+	if isRead {
+		opts, input, reason := redirectOptions(n.redirects)
+		if reason != "" {
+			return []string{fallback(n.source(), reason)}
+		}
+
+		step := stepCall("ForEach", append([]string{strconv.Quote(varName), newSequence("NewList", t.list(n.body))}, opts...)...)
+		if input != nil {
+			return []string{stepCall("CatFile", goString(input)), step}
+		}
+		return []string{step}
+	}
+
+	opts, reason := compoundRedirectOptions(n.redirects)
+	if reason != "" {
+		return []string{fallback(n.source(), reason)}
+	}
+
+	runWhile := n.keyword == "while"
+	cond := n.cond
+
+	// `while ! expr` is the same as `until expr`
+	if len(cond) == 1 {
+		if negated, ok := cond[0].(*negation); ok {
+			runWhile = !runWhile
+			cond = []node{negated.cmd}
+		}
+	}
+
+	logic := "While"
+	if !runWhile {
+		logic = "Until"
+	}
+	args := []string{
+		newSequence("NewList", t.list(cond)),
+		newSequence("NewList", t.list(n.body)),
+	}
+	return []string{stepCall(logic, append(args, opts...)...)}
+}
+
+// readLoopVar returns the variable name used by a `while read name`
+// loop
+func readLoopVar(n *loopClause) (string, bool) {
+	if n.keyword != "while" || len(n.cond) != 1 {
+		return "", false
+	}
+	cmd, ok := n.cond[0].(*simpleCommand)
+	if !ok || len(cmd.assigns) > 0 || len(cmd.redirects) > 0 {
+		return "", false
+	}
+
+	args := cmd.args
+	if len(args) == 0 || args[0].value != "read" {
+		return "", false
+	}
+	args = args[1:]
+	if len(args) > 0 && args[0].value == "-r" {
+		args = args[1:]
+	}
+	if len(args) != 1 || !validName.MatchString(args[0].value) {
+		return "", false
+	}
+	return args[0].value, true
+}
+
+// forLoop translates a `for name in words` loop
+func (t *translator) forLoop(n *forClause) []string {
+	opts, reason := compoundRedirectOptions(n.redirects)
+	if reason != "" {
+		return []string{fallback(n.source(), reason)}
+	}
+
+	todos := []string{}
+	for _, w := range n.words {
+		if w.unsupported != "" {
+			return []string{fallback(n.source(), w.unsupported+" is not supported")}
+		}
+		todos = append(todos, w.warnings...)
+	}
+	globOpts, globTodos := globOptions(n.words)
+	todos = append(todos, globTodos...)
+
+	args := []string{
+		strconv.Quote(n.varName),
+		goStrings(n.words),
+		newSequence("NewList", t.list(n.body)),
+	}
+	opts = append(opts, globOpts...)
+	return []string{withTodos(stepCall("ForWords", append(args, opts...)...), todos)}
+}
+
+// caseStatement translates a `case` statement
+func (t *translator) caseStatement(n *caseClause) []string {
+	opts, reason := compoundRedirectOptions(n.redirects)
+	if reason != "" {
+		return []string{fallback(n.source(), reason)}
+	}
+
+	words := []*word{n.subject}
+	for _, arm := range n.arms {
+		words = append(words, arm.patterns...)
+	}
+	todos := []string{}
+	for _, w := range words {
+		if w.unsupported != "" {
+			return []string{fallback(n.source(), w.unsupported+" is not supported")}
+		}
+		todos = append(todos, w.warnings...)
+	}
+
+	arms := []string{}
+	for i, arm := range n.arms {
+		// Case() treats quoted glob characters as glob characters too
+		for _, w := range arm.patterns {
+			if w.quoted && strings.ContainsAny(w.value, "*?[") {
+				todos = append(todos, fmt.Sprintf("pattern `%s` is quoted, but Case() will treat it as a glob pattern", w.raw))
+			}
+		}
+
+		// a trailing `*)` is the default arm
+		if i == len(n.arms)-1 && len(arm.patterns) == 1 && arm.patterns[0].raw == "*" {
+			arms = append(arms, stepCall("DefaultArm", newSequence("NewList", t.list(arm.body))))
+			continue
+		}
+
+		fields := []string{"Patterns: " + goStrings(arm.patterns)}
+		if len(arm.body) > 0 {
+			fields = append(fields, "Body: "+newSequence("NewList", t.list(arm.body)))
+		}
+		arms = append(arms, "{\n"+strings.Join(fields, ",\n")+",\n}")
+	}
+
+	args := []string{
+		goString(n.subject),
+		"[]scriptish.CaseArm{\n" + strings.Join(arms, ",\n") + ",\n}",
+	}
+	return []string{withTodos(stepCall("Case", append(args, opts...)...), todos)}
+}
+
+// simpleCommand translates a single bash command, and its redirects
+func (t *translator) simpleCommand(c *simpleCommand) []string {
+	// are there any words that we cannot translate at all?
+	todos := []string{}
+	for _, w := range append(c.assigns, c.args...) {
+		if w.unsupported != "" {
+			return []string{fallback(c.source(), w.unsupported+" is not supported")}
+		}
+		todos = append(todos, w.warnings...)
+	}
+
+	opts, input, reason := redirectOptions(c.redirects)
+	if reason != "" {
+		return []string{fallback(c.source(), reason)}
+	}
+
+	// special case - variable assignments on their own
+	if len(c.args) == 0 {
+		if len(c.assigns) == 0 || len(c.redirects) > 0 {
+			return []string{fallback(c.source(), "redirects without a command are not supported")}
+		}
+
+		retval := []string{}
+		for _, assign := range c.assigns {
+			name, value := splitAssignment(assign)
+			retval = append(retval, stepCall("Assign", strconv.Quote(name), value))
+		}
+		retval[0] = withTodos(retval[0], todos)
+		return retval
+	}
+
+	name := c.args[0].value
+	var step string
+
+	switch {
+	case t.functions[name] != "" && !c.args[0].quoted:
+		if len(c.assigns) > 0 {
+			todos = append(todos, "the variables set before this function call have been ignored")
+		}
+		if len(c.args) == 1 {
+			step = stepCall("RunList", append([]string{t.functions[name]}, opts...)...)
+			break
+		}
+
+		// the arguments become the function's positional parameters
+		globOpts, globTodos := globOptions(c.args[1:])
+		todos = append(todos, globTodos...)
+		opts = append(opts, globOpts...)
+		step = stepCall("RunListWithArgs", append([]string{t.functions[name], goStrings(c.args[1:])}, opts...)...)
+
+	case len(c.assigns) > 0:
+		if shellBuiltins[name] {
+			return []string{fallback(c.source(), fmt.Sprintf("`%s` is a shell builtin", name))}
+		}
+		globOpts, globTodos := globOptions(c.args)
+		todos = append(todos, globTodos...)
+		opts = append(opts, globOpts...)
+		step = stepCall("ExecWithEnv", append([]string{goStrings(c.assigns), goStrings(c.args)}, opts...)...)
+
+	default:
+		var m *mapping
+		if mapper, ok := commandMappers[name]; ok {
+			m = mapper(c.args[1:])
+		}
+
+		switch {
+		case m != nil:
+			todos = append(todos, m.todos...)
+			if m.input != nil {
+				input = m.input
+			}
+
+			// some steps expand glob patterns in their arguments
+			if m.globs {
+				globOpts, globTodos := globOptions(c.args[1:])
+				todos = append(todos, globTodos...)
+				opts = append(opts, globOpts...)
+			}
+
+			// Exit() does not support step options
+			if m.step == "Exit" && len(opts) > 0 {
+				todos = append(todos, "Exit() does not support redirects")
+				opts = nil
+			}
+			step = stepCall(m.step, append(m.args, opts...)...)
+
+		case shellBuiltins[name]:
+			return []string{fallback(c.source(), fmt.Sprintf("`%s` is a shell builtin", name))}
+
+		default:
+			globOpts, globTodos := globOptions(c.args)
+			todos = append(todos, globTodos...)
+			opts = append(opts, globOpts...)
+			step = stepCall("Exec", append([]string{goStrings(c.args)}, opts...)...)
+		}
+	}
+
+	// do we need to read from a file first?
+	if input != nil {
+		return []string{
+			withTodos(stepCall("CatFile", goString(input)), todos),
+			step,
+		}
+	}
+
+	return []string{withTodos(step, todos)}
+}
+
+// redirectOptions translates bash redirects into Scriptish step options
+//
+// If any of the redirects read from a file, we return that filename
+// so that it can be added to the start of the pipeline.
+//
+// If we cannot translate any of the redirects, we return the reason.
+func redirectOptions(redirects []*redirect) ([]string, *word, string) {
+	opts := []string{}
+	var input *word
+
+	for _, r := range redirects {
+		if r.target != nil && r.target.unsupported != "" {
+			return nil, nil, r.target.unsupported + " is not supported"
+		}
+
+		var target string
+		isDevNull := false
+		if r.target != nil {
+			target = goString(r.target)
+			isDevNull = r.target.value == "/dev/null"
+		}
+
+		switch r.op {
+		case ">", "1>", ">|", "1>|":
+			if isDevNull {
+				opts = append(opts, stepCall("RedirectStdoutToDevNull"))
+			} else {
+				opts = append(opts, stepCall("OverwriteFilenameWithStdout", target))
+			}
+		case "2>", "2>|":
+			if isDevNull {
+				opts = append(opts, stepCall("RedirectStderrToDevNull"))
+			} else {
+				opts = append(opts, stepCall("OverwriteFilenameWithStderr", target))
+			}
+		case ">>", "1>>":
+			if isDevNull {
+				opts = append(opts, stepCall("RedirectStdoutToDevNull"))
+			} else {
+				opts = append(opts, stepCall("AppendStdoutToFilename", target))
+			}
+		case "2>>":
+			if isDevNull {
+				opts = append(opts, stepCall("RedirectStderrToDevNull"))
+			} else {
+				opts = append(opts, stepCall("AppendStderrToFilename", target))
+			}
+		case "&>":
+			if isDevNull {
+				opts = append(opts, stepCall("RedirectStdoutToDevNull"), stepCall("RedirectStderrToDevNull"))
+			} else {
+				opts = append(opts, stepCall("OverwriteFilenameWithStdout", target), stepCall("RedirectStderrToStdout"))
+			}
+		case "&>>":
+			if isDevNull {
+				opts = append(opts, stepCall("RedirectStdoutToDevNull"), stepCall("RedirectStderrToDevNull"))
+			} else {
+				opts = append(opts, stepCall("AppendStdoutToFilename", target), stepCall("RedirectStderrToStdout"))
+			}
+		case "2>&1":
+			opts = append(opts, stepCall("RedirectStderrToStdout"))
+		case ">&2", "1>&2":
+			opts = append(opts, stepCall("RedirectStdoutToStderr"))
+		case "<", "0<":
+			input = r.target
+		default:
+			if strings.HasPrefix(r.op, "<<") {
+				return nil, nil, "here documents and here strings are not supported"
+			}
+			return nil, nil, fmt.Sprintf("the redirect `%s` is not supported", r.op)
+		}
+	}
+
+	return opts, input, ""
+}
+
+// compoundRedirectOptions translates the redirects that apply to
+// a compound command, such as an `if` statement
+func compoundRedirectOptions(redirects []*redirect) ([]string, string) {
+	opts, input, reason := redirectOptions(redirects)
+	if reason == "" && input != nil {
+		reason = "reading from a file is only supported for simple commands"
+	}
+	return opts, reason
+}
+
+// fallback runs the original bash code, for anything that we cannot
+// translate
+//
+// bash gets the list's positional parameters as its own, so that the
+// code sees the same `$1`, `$2` (and so on) that it did before.
+func fallback(src string, reason string) string {
+	todos := []string{reason + "; running it in bash instead"}
+
+	// any here document will end with a newline that we do not need
+	src = strings.TrimSuffix(src, "\n")
+
+	// bash needs to see the code just as it was written, so Scriptish
+	// must not expand anything in it
+	src = fallbackEscaper.Replace(src)
+
+	// raw strings are easier to read
+	quoted := strconv.Quote(src)
+	if !strings.Contains(src, "`") {
+		quoted = "`" + src + "`"
+	}
+
+	// bash needs to see any glob characters, just as they are
+	args := []string{`[]string{"bash", "-c", ` + quoted + `, "scriptish", "$@"}`}
+	if strings.ContainsAny(src, globChars) {
+		args = append(args, stepCall("NoGlob"))
+	}
+
+	return withTodos(stepCall("Exec", args...), todos)
+}
+
+// fallbackEscaper escapes everything that Scriptish would expand in the
+// code that we hand over to bash
+var fallbackEscaper = strings.NewReplacer(`\`, `\\`, `$`, `\$`)
+
+// withTodos puts the given TODO comments in front of a step
+func withTodos(step string, todos []string) string {
+	if len(todos) == 0 {
+		return step
+	}
+
+	// we only want each TODO once, in the order that they were raised
+	seen := map[string]bool{}
+	var buf strings.Builder
+	for _, todo := range todos {
+		if seen[todo] {
+			continue
+		}
+		seen[todo] = true
+		buf.WriteString(todoPrefix + todo + "\n")
+	}
+	buf.WriteString(step)
+
+	return buf.String()
+}
+
+// newSequence returns the Go code that creates a new Scriptish
+// sequence, such as a List or a Pipeline
+func newSequence(kind string, steps []string) string {
+	if len(steps) == 0 {
+		return stepCall(kind)
+	}
+	return "scriptish." + kind + "(\n" + strings.Join(steps, ",\n") + ",\n)"
+}
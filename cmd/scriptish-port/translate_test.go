@@ -0,0 +1,753 @@
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package main
+
+import (
+	goast "go/ast"
+	goparser "go/parser"
+	gotoken "go/token"
+	"os/exec"
+	"strconv"
+	"strings"
+	"testing"
+
+	scriptish "github.com/ganbarodigital/go_scriptish"
+	"github.com/stretchr/testify/assert"
+)
+
+// translateBody returns everything inside the generated main(), with
+// the indentation removed, to keep our tests readable
+func translateBody(t *testing.T, src string) string {
+	code, err := translate("test.sh", src)
+	assert.Nil(t, err)
+
+	lines := strings.Split(string(code), "\n")
+	for i := range lines {
+		lines[i] = strings.TrimSpace(lines[i])
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestTranslateGeneratesAGoProgram(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	src := "#!/bin/bash\necho hello\n"
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	code, err := translate("hello.sh", src)
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	_, err = goparser.ParseFile(gotoken.NewFileSet(), "hello.go", code, 0)
+	assert.Nil(t, err)
+
+	actualResult := string(code)
+	assert.Contains(t, actualResult, "generated by scriptish-port from hello.sh")
+	assert.Contains(t, actualResult, "package main\n")
+	assert.Contains(t, actualResult, `scriptish "github.com/ganbarodigital/go_scriptish"`)
+	assert.Contains(t, actualResult, "list := scriptish.NewList(\n\t\tscriptish.Echo(\"hello\"),\n\t)\n")
+	assert.Contains(t, actualResult, "list.Exec(os.Args[1:]...)")
+	assert.Contains(t, actualResult, "os.Exit(list.StatusCode())")
+}
+
+func TestTranslateTurnsSetIntoShellOptions(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	src := "#!/bin/bash\nset -euo pipefail\necho hello\n"
+	expectedResult := "list := scriptish.NewList(\n\t\tscriptish.Echo(\"hello\"),\n\t)\n" +
+		"\tlist.ShellOptions = &scriptish.ShellOptions{Errexit: true, Nounset: true, Pipefail: true}\n"
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	code, err := translate("hello.sh", src)
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Contains(t, string(code), expectedResult)
+}
+
+func TestTranslateOnlyTurnsSetIntoShellOptionsAtTheStartOfTheScript(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	testData := []string{
+		"echo hello\nset -e\n",
+		"set -ex\necho hello\n",
+		"set -o posix\necho hello\n",
+	}
+
+	for _, src := range testData {
+		// ----------------------------------------------------------------
+		// perform the change
+
+		code, err := translate("hello.sh", src)
+
+		// ----------------------------------------------------------------
+		// test the results
+
+		assert.Nil(t, err, src)
+		assert.NotContains(t, string(code), "ShellOptions", src)
+		assert.Contains(t, string(code), "`set` is a shell builtin", src)
+	}
+}
+
+func TestTranslateReturnsParserErrors(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	src := "if true; then\n"
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	_, err := translate("broken.sh", src)
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.NotNil(t, err)
+}
+
+func TestTranslateTurnsPipesIntoPipelines(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	src := "cat names.txt | sort | uniq"
+	expectedResult := "scriptish.RunPipeline(scriptish.NewPipeline(\n" +
+		"scriptish.CatFile(\"names.txt\"),\n" +
+		"scriptish.Sort(),\n" +
+		"scriptish.Uniq(),\n" +
+		")),"
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult := translateBody(t, src)
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Contains(t, actualResult, expectedResult)
+}
+
+func TestTranslateTurnsSortUniqCountIntoCountBy(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	src := "awk '{print $1}' access.log | sort | uniq -c | sort -nr | head -n 5"
+	expectedResult := "scriptish.CatFile(\"access.log\"),\n" +
+		"scriptish.SelectFields(\"1\", scriptish.FieldOptions{}),\n" +
+		"scriptish.CountBy(\"\", scriptish.FieldOptions{}),\n" +
+		"scriptish.Head(5),\n" +
+		")),"
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult := translateBody(t, src)
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Contains(t, actualResult, expectedResult)
+}
+
+func TestTranslateTurnsInputFilesIntoPipelines(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	src := "sort -r < names.txt\ngrep foo config.yaml"
+	expectedResult := "scriptish.RunPipeline(scriptish.NewPipeline(\n" +
+		"scriptish.CatFile(\"names.txt\"),\n" +
+		"scriptish.Rsort(),\n" +
+		")),\n" +
+		"scriptish.RunPipeline(scriptish.NewPipeline(\n" +
+		"scriptish.CatFile(\"config.yaml\"),\n" +
+		"scriptish.Grep(\"foo\"),\n" +
+		")),"
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult := translateBody(t, src)
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Contains(t, actualResult, expectedResult)
+}
+
+func TestTranslateTurnsAndOrIntoLogicCalls(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	src := "mkdir build && cd build || echo failed"
+	expectedResult := "scriptish.Mkdir(\"build\", 0755),\n" +
+		"scriptish.And(scriptish.NewList(\n" +
+		"scriptish.Cd(\"build\"),\n" +
+		")),\n" +
+		"scriptish.Or(scriptish.NewList(\n" +
+		"scriptish.Echo(\"failed\"),\n" +
+		")),"
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult := translateBody(t, src)
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Contains(t, actualResult, expectedResult)
+}
+
+func TestTranslateTurnsIfStatementsIntoLogicCalls(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	src := "if [ -z \"$1\" ]; then\n  echo missing >&2\n  exit 1\nfi\n" +
+		"if [ -e a ]; then cat a; elif [ -e b ]; then cat b; else echo none; fi\n"
+	expectedResults := []string{
+		"scriptish.If(scriptish.NewList(\n" +
+			"scriptish.TestEmpty(\"$1\"),\n" +
+			"), scriptish.NewList(\n" +
+			"scriptish.Echo(\"missing\", scriptish.RedirectStdoutToStderr()),\n",
+		"scriptish.IfElse(scriptish.NewList(\n" +
+			"scriptish.TestFilepathExists(\"a\"),\n" +
+			"), scriptish.NewList(\n" +
+			"scriptish.CatFile(\"a\"),\n" +
+			"), scriptish.NewList(\n" +
+			"scriptish.IfElse(scriptish.NewList(\n" +
+			"scriptish.TestFilepathExists(\"b\"),\n" +
+			"), scriptish.NewList(\n" +
+			"scriptish.CatFile(\"b\"),\n" +
+			"), scriptish.NewList(\n" +
+			"scriptish.Echo(\"none\"),\n" +
+			")),\n" +
+			")),",
+	}
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult := translateBody(t, src)
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	for _, expectedResult := range expectedResults {
+		assert.Contains(t, actualResult, expectedResult)
+	}
+}
+
+func TestTranslateTurnsLoopsIntoLogicCalls(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	src := "while ! curl -sf http://localhost; do\n  sleep 1\ndone\nuntil [ -e done.txt ]; do touch done.txt; done > log.txt\n"
+	expectedResults := []string{
+		"scriptish.Until(scriptish.NewList(\n" +
+			"scriptish.Exec([]string{\"curl\", \"-sf\", \"http://localhost\"}),\n" +
+			"), scriptish.NewList(\n" +
+			"scriptish.Exec([]string{\"sleep\", \"1\"}),\n" +
+			")),",
+		"scriptish.Until(scriptish.NewList(\n" +
+			"scriptish.TestFilepathExists(\"done.txt\"),\n" +
+			"), scriptish.NewList(\n" +
+			"scriptish.Touch(\"done.txt\"),\n" +
+			"), scriptish.OverwriteFilenameWithStdout(\"log.txt\")),",
+	}
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult := translateBody(t, src)
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	for _, expectedResult := range expectedResults {
+		assert.Contains(t, actualResult, expectedResult)
+	}
+}
+
+func TestTranslateTurnsForLoopsIntoForWords(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	src := "for f in one.txt \"$1\"; do\n  cat \"$f\"\ndone\n"
+	expectedResult := "scriptish.ForWords(\"f\", []string{\"one.txt\", \"$1\"}, scriptish.NewList(\n" +
+		"scriptish.CatFile(\"$f\"),\n" +
+		")),"
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult := translateBody(t, src)
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Contains(t, actualResult, expectedResult)
+}
+
+func TestTranslateTurnsCaseStatementsIntoCase(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	src := "case \"$1\" in\n  start|stop) echo service ;;\n  debug) ;;\n  *) echo other ;;\nesac\n"
+	expectedResult := "scriptish.Case(\"$1\", []scriptish.CaseArm{\n" +
+		"{\n" +
+		"Patterns: []string{\"start\", \"stop\"},\n" +
+		"Body: scriptish.NewList(\n" +
+		"scriptish.Echo(\"service\"),\n" +
+		"),\n" +
+		"},\n" +
+		"{\n" +
+		"Patterns: []string{\"debug\"},\n" +
+		"},\n" +
+		"scriptish.DefaultArm(scriptish.NewList(\n" +
+		"scriptish.Echo(\"other\"),\n" +
+		")),\n" +
+		"}),"
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult := translateBody(t, src)
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Contains(t, actualResult, expectedResult)
+}
+
+func TestTranslateTurnsRedirectedCaseStatementsIntoCaseWithOptions(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	src := "case \"$1\" in\n  *) echo other ;;\nesac > out.txt\n"
+	expectedResult := "scriptish.DefaultArm(scriptish.NewList(\n" +
+		"scriptish.Echo(\"other\"),\n" +
+		")),\n" +
+		"}, scriptish.OverwriteFilenameWithStdout(\"out.txt\")),"
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult := translateBody(t, src)
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Contains(t, actualResult, expectedResult)
+}
+
+func TestTranslateTurnsWhileReadLoopsIntoForEach(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	src := "ls *.txt | while read -r f; do echo \"$f\"; done\nwhile read line; do echo $line; done < names.txt\n"
+	expectedResults := []string{
+		"scriptish.RunPipeline(scriptish.NewPipeline(\n" +
+			"scriptish.ListFiles(\"*.txt\"),\n" +
+			"scriptish.ForEach(\"f\", scriptish.NewList(\n" +
+			"scriptish.Echo(\"$f\"),\n" +
+			")),\n" +
+			")),",
+		"scriptish.RunPipeline(scriptish.NewPipeline(\n" +
+			"scriptish.CatFile(\"names.txt\"),\n" +
+			"scriptish.ForEach(\"line\", scriptish.NewList(\n" +
+			"scriptish.Echo(\"$line\"),\n" +
+			")),\n" +
+			")),",
+	}
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult := translateBody(t, src)
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	for _, expectedResult := range expectedResults {
+		assert.Contains(t, actualResult, expectedResult)
+	}
+}
+
+func TestTranslateTurnsRedirectsIntoStepOptions(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	testData := map[string]string{
+		"make > build.log":      `scriptish.Exec([]string{"make"}, scriptish.OverwriteFilenameWithStdout("build.log"))`,
+		"make >> build.log":     `scriptish.Exec([]string{"make"}, scriptish.AppendStdoutToFilename("build.log"))`,
+		"make 2> err.log":       `scriptish.Exec([]string{"make"}, scriptish.OverwriteFilenameWithStderr("err.log"))`,
+		"make 2>> err.log":      `scriptish.Exec([]string{"make"}, scriptish.AppendStderrToFilename("err.log"))`,
+		"make > /dev/null":      `scriptish.Exec([]string{"make"}, scriptish.RedirectStdoutToDevNull())`,
+		"make 2>/dev/null":      `scriptish.Exec([]string{"make"}, scriptish.RedirectStderrToDevNull())`,
+		"make > out.log 2>&1":   `scriptish.Exec([]string{"make"}, scriptish.OverwriteFilenameWithStdout("out.log"), scriptish.RedirectStderrToStdout())`,
+		"make &> out.log":       `scriptish.Exec([]string{"make"}, scriptish.OverwriteFilenameWithStdout("out.log"), scriptish.RedirectStderrToStdout())`,
+		"echo oops >&2":         `scriptish.Echo("oops", scriptish.RedirectStdoutToStderr())`,
+		"{ echo a; } > out.txt": "scriptish.RunList(scriptish.NewList(\nscriptish.Echo(\"a\"),\n), scriptish.OverwriteFilenameWithStdout(\"out.txt\"))",
+	}
+
+	for src, expectedResult := range testData {
+		// ----------------------------------------------------------------
+		// perform the change
+
+		actualResult := translateBody(t, src)
+
+		// ----------------------------------------------------------------
+		// test the results
+
+		assert.Contains(t, actualResult, expectedResult, src)
+	}
+}
+
+func TestTranslateTurnsFunctionsIntoLists(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	src := "greet() {\n  echo \"hello $1\"\n}\nmain() { greet; }\nmain\ngreet world\ngreet \"$@\" '*.go'\n"
+	expectedResults := []string{
+		"var greet = scriptish.NewList(\nscriptish.Echo(\"hello $1\"),\n)",
+		"var mainFn = scriptish.NewList(\nscriptish.RunList(greet),\n)",
+		"scriptish.RunList(mainFn),\n" +
+			"scriptish.RunListWithArgs(greet, []string{\"world\"}),\n" +
+			"scriptish.RunListWithArgs(greet, []string{\"$@\", \"*.go\"}, scriptish.NoGlob()),",
+	}
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult := translateBody(t, src)
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	for _, expectedResult := range expectedResults {
+		assert.Contains(t, actualResult, expectedResult)
+	}
+}
+
+func TestTranslateTurnsAssignmentsIntoAssigns(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	src := "NAME=world DIR=~/src\nn=$((n + 1))\nGOOS=linux go build ./...\n"
+	expectedResults := []string{
+		"scriptish.Assign(\"NAME\", \"world\"),\n" +
+			"scriptish.Assign(\"DIR\", \"$HOME/src\"),\n" +
+			"scriptish.Assign(\"n\", \"$((n + 1))\"),",
+		`scriptish.ExecWithEnv([]string{"GOOS=linux"}, []string{"go", "build", "./..."}),`,
+	}
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult := translateBody(t, src)
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	for _, expectedResult := range expectedResults {
+		assert.Contains(t, actualResult, expectedResult)
+	}
+	assert.NotContains(t, actualResult, "scriptish.Export(")
+}
+
+func TestTranslateOnlyTurnsExportIntoExport(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	src := "n=0\nexport n\nexport PAGER=cat\n"
+	expectedResults := []string{
+		`scriptish.Assign("n", "0"),`,
+		`scriptish.Export("PAGER", "cat"),`,
+	}
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult := translateBody(t, src)
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	for _, expectedResult := range expectedResults {
+		assert.Contains(t, actualResult, expectedResult)
+	}
+}
+
+func TestTranslateExecsCommandsThatHaveNoScriptishEquivalent(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	src := "git commit -m 'a message'\nrm -rf *.o\n"
+	expectedResults := []string{
+		"\nscriptish.Exec([]string{\"git\", \"commit\", \"-m\", \"a message\"}),",
+		"\nscriptish.Exec([]string{\"rm\", \"-rf\", \"*.o\"}),",
+	}
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult := translateBody(t, src)
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	for _, expectedResult := range expectedResults {
+		assert.Contains(t, actualResult, expectedResult)
+	}
+	assert.NotContains(t, actualResult, "TODO: scriptish-port: `git`")
+}
+
+func TestTranslateSwitchesOffGlobbingForQuotedGlobCharacters(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	src := "git ls-files '*.go'\nrm \"$dir\"/*.o\ncp {a,b}.txt \"[x]\"\nfor f in '*' {1..3}; do echo $f; done\n"
+	expectedResults := []string{
+		"\nscriptish.Exec([]string{\"git\", \"ls-files\", \"*.go\"}, scriptish.NoGlob()),",
+		"\nscriptish.RmFile(\"$dir/*.o\"),",
+		"// TODO: scriptish-port: Scriptish will also expand the glob characters that are quoted here\n" +
+			"scriptish.Exec([]string{\"cp\", \"{a,b}.txt\", \"[x]\"}),",
+		"// TODO: scriptish-port: Scriptish will also expand the glob characters that are quoted here\n" +
+			"scriptish.ForWords(\"f\", []string{\"*\", \"{1..3}\"}, scriptish.NewList(",
+	}
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult := translateBody(t, src)
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	for _, expectedResult := range expectedResults {
+		assert.Contains(t, actualResult, expectedResult)
+	}
+}
+
+func TestTranslateSwitchesOffGlobbingWhenFallingBackToBash(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	src := "for ((i=0; i<3; i++)); do echo $i{a,b}; done"
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult := translateBody(t, src)
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Contains(t, actualResult, "scriptish.Exec([]string{\"bash\", \"-c\", `"+fallbackEscaper.Replace(src)+"`, \"scriptish\", \"$@\"}, scriptish.NoGlob()),")
+}
+
+// runFallback finds the bash fallback in the given translation, and
+// runs it with Scriptish, just like the generated program would
+func runFallback(t *testing.T, body string, params ...string) string {
+	start := strings.Index(body, `scriptish.Exec([]string{"bash"`)
+	if !assert.True(t, start >= 0, body) {
+		return ""
+	}
+	end := strings.Index(body[start:], "\n")
+	expr, err := goparser.ParseExpr(strings.TrimSuffix(body[start:start+end], ","))
+	if !assert.Nil(t, err) {
+		return ""
+	}
+
+	call := expr.(*goast.CallExpr)
+	args := []string{}
+	for _, elt := range call.Args[0].(*goast.CompositeLit).Elts {
+		arg, err := strconv.Unquote(elt.(*goast.BasicLit).Value)
+		assert.Nil(t, err)
+		args = append(args, arg)
+	}
+	opts := []*scriptish.StepOption{}
+	if len(call.Args) > 1 {
+		opts = append(opts, scriptish.NoGlob())
+	}
+
+	list := scriptish.NewList(scriptish.Exec(args, opts...))
+	output, err := list.Exec(params...).String()
+	assert.Nil(t, err)
+	return output
+}
+
+func TestTranslateFallsBackToBashForAnythingItCannotTranslate(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	testData := map[string]string{
+		"for ((i=0; i<3; i++)); do echo $i; done": "this kind of `for` loop is not supported",
+		"for f in $(ls); do echo $f; done":        "command substitution is not supported",
+		"select x in a b; do break; done":         "`select` is not supported",
+		"if true; then cat; fi < in.txt":          "reading from a file is only supported for simple commands",
+		"case $x in a) echo a;& b) echo b;; esac": "this kind of `case` statement is not supported",
+		"(cd /tmp && ls)":                         "subshells are not supported",
+		"sleep 10 &":                              "background jobs are not supported",
+		"! grep foo":                              "negated pipelines are not supported",
+		"echo $(date)":                            "command substitution is not supported",
+		"read -r line":                            "`read` is a shell builtin",
+		"set -x":                                  "`set` is a shell builtin",
+		"cat <<EOF\nhello\nEOF\n":                 "here documents and here strings are not supported",
+		"exec 3>&1":                               "the redirect `3>&1` is not supported",
+	}
+
+	for src, expectedReason := range testData {
+		// ----------------------------------------------------------------
+		// perform the change
+
+		actualResult := translateBody(t, src)
+
+		// ----------------------------------------------------------------
+		// test the results
+
+		assert.Contains(t, actualResult, todoPrefix+expectedReason+"; running it in bash instead", src)
+		assert.Contains(t, actualResult, "scriptish.Exec([]string{\"bash\", \"-c\", `"+fallbackEscaper.Replace(strings.TrimSuffix(src, "\n"))+"`, \"scriptish\", \"$@\"}),", src)
+	}
+}
+
+func TestTranslateAddsTodosForWordsThatScriptishWouldExpandDifferently(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	src := `echo 'costs $5'`
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult := translateBody(t, src)
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Contains(t, actualResult, todoPrefix+"contains a single-quoted '$', which Scriptish will expand\nscriptish.Echo(\"costs $5\"),")
+}
+
+func TestTranslatePassesFallbackCodeToBashUntouched(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not found")
+	}
+
+	params := []string{"a b", "*", "$HOME"}
+	testData := []string{
+		`echo "$(echo hello) $1 ${4:-none} $#"`,
+		`(for f in "$@"; do echo "[$f]"; done)`,
+		`(printf '%s\n' "a\\b" '$x' \$y {1,2} "$2")`,
+		"echo `echo backticks` $((1 + 2)) \\$ \"$\"",
+	}
+
+	for _, src := range testData {
+		cmd := exec.Command(bash, append([]string{"-c", src, "scriptish"}, params...)...)
+		expectedResult, err := cmd.Output()
+		assert.Nil(t, err, src)
+
+		// ----------------------------------------------------------------
+		// perform the change
+
+		actualResult := runFallback(t, translateBody(t, src), params...)
+
+		// ----------------------------------------------------------------
+		// test the results
+
+		assert.Equal(t, string(expectedResult), actualResult, src)
+	}
+}
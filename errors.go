@@ -39,7 +39,292 @@
 
 package scriptish
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ErrCancelled is the error returned when a sequence stops because its
+// context.Context was cancelled, or because its deadline passed
+type ErrCancelled struct {
+	// Err is the error returned by the context's Err() method
+	Err error
+}
+
+func (e ErrCancelled) Error() string {
+	return "sequence cancelled: " + e.Err.Error()
+}
+
+// Unwrap returns the context's error, so that you can use errors.Is()
+// to tell a cancellation apart from a deadline
+func (e ErrCancelled) Unwrap() error {
+	return e.Err
+}
+
+// ErrTimeout is the error returned when a Timeout() sequence, or a step
+// that uses WithTimeout(), runs for longer than it is allowed to
+type ErrTimeout struct {
+	// Timeout is how long the sequence or step was allowed to run for
+	Timeout time.Duration
+}
+
+func (e ErrTimeout) Error() string {
+	return "timed out after " + e.Timeout.String()
+}
+
+// Unwrap returns context.DeadlineExceeded, so that you can use
+// errors.Is() to treat all deadlines the same way
+func (e ErrTimeout) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+// ErrDirStackEmpty is the error returned when Popd() is called, and
+// there is no directory to go back to
+type ErrDirStackEmpty struct{}
+
+func (e ErrDirStackEmpty) Error() string {
+	return "directory stack empty"
+}
+
+// ErrMaxIterations is the error returned when a While() or Until() loop
+// is stopped by WithMaxIterations()
+type ErrMaxIterations struct {
+	// MaxIterations is how many times the loop was allowed to run
+	MaxIterations int
+}
+
+func (e ErrMaxIterations) Error() string {
+	return fmt.Sprintf("loop stopped after %d iterations", e.MaxIterations)
+}
+
+// ErrExpansion is the error returned when a step is given a string that
+// it cannot expand
+type ErrExpansion struct {
+	// Arg is the string that could not be expanded
+	Arg string
+
+	// Err explains why; it is an ErrArithmetic, an ErrBadSubstitution,
+	// an ErrParameterNotSet, an ErrSubstitutionFailed, an
+	// ErrSubstitutionTooDeep, an ErrUnboundVariable or an
+	// ErrUnknownSubstitution
+	Err error
+}
+
+func (e ErrExpansion) Error() string {
+	return fmt.Sprintf("cannot expand %q: %s", e.Arg, e.Err.Error())
+}
+
+// Unwrap returns the reason why the string could not be expanded, so
+// that you can use errors.As() to find out what went wrong
+func (e ErrExpansion) Unwrap() error {
+	return e.Err
+}
+
+// ErrArithmetic is returned when an arithmetic expression cannot be
+// evaluated
+type ErrArithmetic struct {
+	// Expr is the expression that could not be evaluated
+	Expr string
+
+	// Message explains what went wrong
+	Message string
+}
+
+func (e ErrArithmetic) Error() string {
+	return e.Expr + ": " + e.Message
+}
+
+// ErrBadFindArg is returned when a FindPredicate is given an argument
+// that it does not understand
+type ErrBadFindArg struct {
+	// Predicate is the UNIX `find` name of the predicate (eg `-size`)
+	Predicate string
+
+	// Arg is the (expanded) argument
+	Arg string
+}
+
+func (e ErrBadFindArg) Error() string {
+	return fmt.Sprintf("%s: invalid argument %q", e.Predicate, e.Arg)
+}
+
+// XargsFailure describes one run of an Xargs() body that failed
+type XargsFailure struct {
+	// Args are the lines of input that the body was given
+	Args []string
+
+	// StatusCode and Err are what the body returned
+	StatusCode int
+	Err        error
+}
+
+// ErrXargsFailed is returned by Xargs() and XargsParallel() when the
+// body fails for one or more batches of input
+type ErrXargsFailed struct {
+	// Failures are the runs that failed, in the order that they
+	// finished
+	Failures []XargsFailure
+}
+
+func (e ErrXargsFailed) Error() string {
+	first := e.Failures[0]
+	retval := fmt.Sprintf("%d run(s) failed; first failure: %q: status code %d", len(e.Failures), first.Args, first.StatusCode)
+	if first.Err != nil {
+		retval += ": " + first.Err.Error()
+	}
+
+	return retval
+}
+
+// Unwrap returns the error from the first run that failed, so that you
+// can use errors.Is() and errors.As() on it
+func (e ErrXargsFailed) Unwrap() error {
+	return e.Failures[0].Err
+}
+
+// ErrTestSyntax is returned when Test() cannot make sense of its
+// expression
+type ErrTestSyntax struct {
+	// Expr is the expression that could not be evaluated
+	Expr string
+
+	// Message explains what went wrong
+	Message string
+}
+
+func (e ErrTestSyntax) Error() string {
+	return e.Expr + ": " + e.Message
+}
+
+// ErrSedSyntax is returned when Sed() cannot make sense of one of its
+// expressions
+type ErrSedSyntax struct {
+	// Expr is the expression that could not be parsed
+	Expr string
+
+	// Message explains what went wrong
+	Message string
+}
+
+func (e ErrSedSyntax) Error() string {
+	return e.Expr + ": " + e.Message
+}
+
+// ErrBadSubstitution is the reason for an ErrExpansion when a string
+// contains a `${...}` that cannot be expanded
+type ErrBadSubstitution struct {
+	// Expr is the `${...}` that cannot be expanded
+	Expr string
+}
+
+func (e ErrBadSubstitution) Error() string {
+	return e.Expr + ": bad substitution"
+}
+
+// ErrParameterNotSet is the reason for an ErrExpansion when a string
+// uses `${name:?message}` or `${name?message}`, and the variable has
+// not been set
+type ErrParameterNotSet struct {
+	// Name is the variable that has not been set
+	Name string
+
+	// Message is the (expanded) message from the string
+	Message string
+}
+
+func (e ErrParameterNotSet) Error() string {
+	return e.Name + ": " + e.Message
+}
+
+// ErrUnknownSubstitution is the reason for an ErrExpansion when a string
+// uses `$(name)`, and there is no sub-sequence with that name
+type ErrUnknownSubstitution struct {
+	// Name is what was inside the `$(...)`
+	Name string
+}
+
+func (e ErrUnknownSubstitution) Error() string {
+	return "$(" + e.Name + "): no sub-sequence with that name"
+}
+
+// ErrSubstitutionFailed is the reason for an ErrExpansion when a string
+// uses `$(name)`, and the sub-sequence fails
+type ErrSubstitutionFailed struct {
+	// Name is the sub-sequence that failed
+	Name string
+
+	// StatusCode is the sub-sequence's status code
+	StatusCode int
+
+	// Err is the sub-sequence's error, if it has one
+	Err error
+}
+
+func (e ErrSubstitutionFailed) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("$(%s) failed: %s", e.Name, e.Err.Error())
+	}
+
+	return fmt.Sprintf("$(%s) failed with status code %d", e.Name, e.StatusCode)
+}
+
+// Unwrap returns the sub-sequence's error
+func (e ErrSubstitutionFailed) Unwrap() error {
+	return e.Err
+}
+
+// ErrSubstitutionTooDeep is the reason for an ErrExpansion when `$(name)`
+// substitutions run inside each other too many times (eg, when a
+// sub-sequence uses `$(name)` to run itself)
+type ErrSubstitutionTooDeep struct {
+	// Name is the sub-sequence that we did not run
+	Name string
+
+	// MaxDepth is how many substitutions are allowed to run inside
+	// each other
+	MaxDepth int
+}
+
+func (e ErrSubstitutionTooDeep) Error() string {
+	return fmt.Sprintf("$(%s): more than %d substitutions inside each other", e.Name, e.MaxDepth)
+}
+
+// ErrUnboundVariable is the reason for an ErrExpansion when Nounset is
+// switched on, and a step tries to expand a variable that has not been set
+type ErrUnboundVariable struct {
+	// Name is the variable that has not been set
+	Name string
+}
+
+func (e ErrUnboundVariable) Error() string {
+	return e.Name + ": unbound variable"
+}
+
+// ErrNoGlobMatch is the error returned when Failglob is switched on,
+// and a glob pattern does not match any files or folders
+type ErrNoGlobMatch struct {
+	// Pattern is the glob pattern that did not match anything
+	Pattern string
+}
+
+func (e ErrNoGlobMatch) Error() string {
+	return "no match: " + e.Pattern
+}
+
+// ErrAmbiguousPath is the error returned when a step needs a single
+// filepath, and its input expands to none or to more than one
+type ErrAmbiguousPath struct {
+	// Path is the input that the step was given
+	Path string
+
+	// Matches is what Path expanded to
+	Matches []string
+}
+
+func (e ErrAmbiguousPath) Error() string {
+	return fmt.Sprintf("%s: ambiguous path; it expands to %d paths", e.Path, len(e.Matches))
+}
 
 // ErrMismatchedInputs is the error returned when two input arrays
 // aren't the same length
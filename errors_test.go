@@ -40,7 +40,10 @@
 package scriptish
 
 import (
+	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -67,3 +70,373 @@ func TestErrMismatchedInputs(t *testing.T) {
 
 	assert.Equal(t, expectedResult, actualResult)
 }
+
+func TestErrCancelled(t *testing.T) {
+	// ----------------------------------------------------------------
+	// setup your test
+
+	testData := ErrCancelled{context.Canceled}
+	expectedResult := "sequence cancelled: context canceled"
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult := testData.Error()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, expectedResult, actualResult)
+	assert.True(t, errors.Is(testData, context.Canceled))
+}
+
+func TestErrTimeout(t *testing.T) {
+	// ----------------------------------------------------------------
+	// setup your test
+
+	testData := ErrTimeout{30 * time.Second}
+	expectedResult := "timed out after 30s"
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult := testData.Error()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, expectedResult, actualResult)
+	assert.True(t, errors.Is(testData, context.DeadlineExceeded))
+}
+
+func TestErrDirStackEmpty(t *testing.T) {
+	// ----------------------------------------------------------------
+	// setup your test
+
+	testData := ErrDirStackEmpty{}
+	expectedResult := "directory stack empty"
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult := testData.Error()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestErrExpansion(t *testing.T) {
+	// ----------------------------------------------------------------
+	// setup your test
+
+	testData := ErrExpansion{"$BUILD_DIR/", ErrUnboundVariable{"BUILD_DIR"}}
+	expectedResult := `cannot expand "$BUILD_DIR/": BUILD_DIR: unbound variable`
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult := testData.Error()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestErrExpansionUnwrapsToTheReason(t *testing.T) {
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := ErrUnboundVariable{"BUILD_DIR"}
+	var testData error = ErrExpansion{"$BUILD_DIR/", expectedResult}
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	var actualResult ErrUnboundVariable
+	ok := errors.As(testData, &actualResult)
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.True(t, ok)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestErrArithmetic(t *testing.T) {
+	// ----------------------------------------------------------------
+	// setup your test
+
+	testData := ErrArithmetic{"1 / 0", "division by 0"}
+	expectedResult := "1 / 0: division by 0"
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult := testData.Error()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestErrBadFindArg(t *testing.T) {
+	// ----------------------------------------------------------------
+	// setup your test
+
+	testData := ErrBadFindArg{"-size", "10x"}
+	expectedResult := `-size: invalid argument "10x"`
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult := testData.Error()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestErrXargsFailed(t *testing.T) {
+	// ----------------------------------------------------------------
+	// setup your test
+
+	testData := ErrXargsFailed{
+		[]XargsFailure{
+			{[]string{"a.txt", "b.txt"}, 2, ErrTestSyntax{"(", "expression expected"}},
+			{[]string{"c.txt"}, 1, nil},
+		},
+	}
+	expectedResult := `2 run(s) failed; first failure: ["a.txt" "b.txt"]: status code 2: (: expression expected`
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult := testData.Error()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, expectedResult, actualResult)
+	assert.Equal(t, ErrTestSyntax{"(", "expression expected"}, testData.Unwrap())
+}
+
+func TestErrTestSyntax(t *testing.T) {
+	// ----------------------------------------------------------------
+	// setup your test
+
+	testData := ErrTestSyntax{"( -f file", "expected `)'"}
+	expectedResult := "( -f file: expected `)'"
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult := testData.Error()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestErrSedSyntax(t *testing.T) {
+	// ----------------------------------------------------------------
+	// setup your test
+
+	testData := ErrSedSyntax{"s/foo/bar", "unterminated `s' command"}
+	expectedResult := "s/foo/bar: unterminated `s' command"
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult := testData.Error()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestErrBadSubstitution(t *testing.T) {
+	// ----------------------------------------------------------------
+	// setup your test
+
+	testData := ErrBadSubstitution{"${BUILD DIR}"}
+	expectedResult := "${BUILD DIR}: bad substitution"
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult := testData.Error()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestErrParameterNotSet(t *testing.T) {
+	// ----------------------------------------------------------------
+	// setup your test
+
+	testData := ErrParameterNotSet{"BUILD_DIR", "parameter null or not set"}
+	expectedResult := "BUILD_DIR: parameter null or not set"
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult := testData.Error()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestErrUnknownSubstitution(t *testing.T) {
+	// ----------------------------------------------------------------
+	// setup your test
+
+	testData := ErrUnknownSubstitution{"now"}
+	expectedResult := "$(now): no sub-sequence with that name"
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult := testData.Error()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestErrSubstitutionFailed(t *testing.T) {
+	// ----------------------------------------------------------------
+	// setup your test
+
+	testData := ErrSubstitutionFailed{"now", 1, errors.New("date: not found")}
+	expectedResult := "$(now) failed: date: not found"
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult := testData.Error()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestErrSubstitutionFailedWithoutAnError(t *testing.T) {
+	// ----------------------------------------------------------------
+	// setup your test
+
+	testData := ErrSubstitutionFailed{"now", 3, nil}
+	expectedResult := "$(now) failed with status code 3"
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult := testData.Error()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestErrSubstitutionTooDeep(t *testing.T) {
+	// ----------------------------------------------------------------
+	// setup your test
+
+	testData := ErrSubstitutionTooDeep{"self", 100}
+	expectedResult := "$(self): more than 100 substitutions inside each other"
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult := testData.Error()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestErrUnboundVariable(t *testing.T) {
+	// ----------------------------------------------------------------
+	// setup your test
+
+	testData := ErrUnboundVariable{"BUILD_DIR"}
+	expectedResult := "BUILD_DIR: unbound variable"
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult := testData.Error()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestErrNoGlobMatch(t *testing.T) {
+	// ----------------------------------------------------------------
+	// setup your test
+
+	testData := ErrNoGlobMatch{"*.log"}
+	expectedResult := "no match: *.log"
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult := testData.Error()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestErrAmbiguousPath(t *testing.T) {
+	// ----------------------------------------------------------------
+	// setup your test
+
+	testData := ErrAmbiguousPath{"*.log", []string{"a.log", "b.log"}}
+	expectedResult := "*.log: ambiguous path; it expands to 2 paths"
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult := testData.Error()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestErrMaxIterations(t *testing.T) {
+	// ----------------------------------------------------------------
+	// setup your test
+
+	testData := ErrMaxIterations{100}
+	expectedResult := "loop stopped after 100 iterations"
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult := testData.Error()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, expectedResult, actualResult)
+}
@@ -53,15 +53,22 @@ func AppendToTempFile(dir string, pattern string, opts ...*StepOption) *Sequence
 	return NewSequenceStep(
 		func(p *Pipe) (int, error) {
 			// expand our inputs
-			expDir := p.Env.Expand(dir)
-			expPattern := p.Env.Expand(pattern)
+			expDir, err := expandPathArg(p, dir)
+			expPattern, patternErr := expandString(p, pattern)
 
 			// debugging support
 			Tracef("AppendToTempFile(%#v, %#v)", dir, pattern)
 			Tracef("=> AppendToTempFile(%#v, %#v)", expDir, expPattern)
 
+			if err != nil {
+				return StatusNotOkay, err
+			}
+			if patternErr != nil {
+				return StatusNotOkay, patternErr
+			}
+
 			// create the temporary file
-			fh, err := ioutil.TempFile(expDir, expPattern)
+			fh, err := ioutil.TempFile(resolvePipePath(p, expDir), expPattern)
 			if err != nil {
 				return StatusNotOkay, err
 			}
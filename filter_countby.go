@@ -0,0 +1,119 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"sort"
+	"strings"
+)
+
+// CountBy counts how many times each value appears in the pipeline, and
+// writes out `count value` pairs, most common value first
+//
+// The value is the fields in the given range spec (see SelectFields()),
+// or the whole line if the spec is empty. Unlike Uniq(), the lines do
+// not need to be sorted first.
+//
+// It is an emulation of `sort | uniq -c | sort -rn`, and writes out
+// exactly the same thing: values that appear the same number of times
+// come out in reverse order, just like they do from `sort -rn`.
+func CountBy(spec string, fopts FieldOptions, opts ...*StepOption) *SequenceStep {
+	// build our Scriptish command
+	return NewSequenceStep(
+		func(p *Pipe) (int, error) {
+			// debugging support
+			Tracef("CountBy(%#v, %#v)", spec, fopts)
+
+			// which fields do we want?
+			var fieldsSpec []Range
+			var err error
+			if spec != "" {
+				fieldsSpec, err = ParseRangeSpec(spec)
+				if err != nil {
+					return StatusNotOkay, err
+				}
+			}
+
+			// how do we find them?
+			splitter, err := newFieldSplitter(p, fopts)
+			if err != nil {
+				return StatusNotOkay, err
+			}
+
+			// count them all up
+			counts := map[string]int{}
+			for line := range p.Stdin.ReadLines() {
+				value := line
+				if fieldsSpec != nil {
+					fields := splitter.split(line)
+					buf := []string{}
+					for _, index := range rangeIndexes(fieldsSpec, len(fields)) {
+						buf = append(buf, fields[index])
+					}
+					value = strings.Join(buf, splitter.outputDelimiter)
+				}
+				counts[value]++
+			}
+
+			// most common first
+			values := make([]string, 0, len(counts))
+			for value := range counts {
+				values = append(values, value)
+			}
+			sort.Slice(values, func(i, j int) bool {
+				if counts[values[i]] != counts[values[j]] {
+					return counts[values[i]] > counts[values[j]]
+				}
+				return values[i] > values[j]
+			})
+
+			for _, value := range values {
+				line := uniqCountLine(counts[value], value)
+				TracePipeStdout("%s", line)
+				p.Stdout.WriteString(line)
+				p.Stdout.WriteRune('\n')
+			}
+
+			// all done
+			return StatusOkay, nil
+		},
+		opts...,
+	)
+}
@@ -0,0 +1,195 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// countByTestInput is the input for our CountBy() conformance tests
+var countByTestInput = []string{
+	"10.0.0.1 GET /index.html",
+	"10.0.0.2 GET /about.html",
+	"10.0.0.1 POST /login",
+	"10.0.0.3 GET /index.html",
+	"10.0.0.1 GET /index.html",
+	"10.0.0.2 GET /index.html",
+	"10.0.0.4 GET /about.html",
+	"10.0.0.3 GET /missing",
+	"",
+}
+
+func TestCountByMatchesSortUniqSort(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	bashCmd, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not found")
+	}
+
+	testData := []struct {
+		script string
+		spec   string
+	}{
+		{"sort | uniq -c | sort -rn", ""},
+		{"awk '{ print $1 }' | sort | uniq -c | sort -rn", "1"},
+		{"awk '{ print $3 }' | sort | uniq -c | sort -rn", "3"},
+	}
+
+	for _, testCase := range testData {
+		cmd := exec.Command(bashCmd, "-c", testCase.script)
+		cmd.Env = append(os.Environ(), "LC_ALL=C")
+		cmd.Stdin = strings.NewReader(strings.Join(countByTestInput, "\n") + "\n")
+		expectedResult, err := cmd.Output()
+		assert.Nil(t, err, testCase.script)
+
+		pipeline := NewPipeline(
+			EchoSlice(countByTestInput),
+			CountBy(testCase.spec, FieldOptions{}),
+		)
+
+		// ----------------------------------------------------------------
+		// perform the change
+
+		actualResult, err := pipeline.Exec().String()
+
+		// ----------------------------------------------------------------
+		// test the results
+
+		assert.Nil(t, err, testCase.script)
+		assert.Equal(t, string(expectedResult), actualResult, testCase.script)
+	}
+}
+
+func TestCountByUsesTheFieldOptions(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := []string{
+		"      2 /bin/bash",
+		"      1 /usr/sbin/nologin",
+	}
+	pipeline := NewPipeline(
+		EchoSlice([]string{
+			"root:x:0:0:root:/root:/bin/bash",
+			"daemon:x:1:1:daemon:/usr/sbin:/usr/sbin/nologin",
+			"stuart:x:1000:1000:Stuart:/home/stuart:/bin/bash",
+		}),
+		CountBy("NF", FieldOptions{Delimiter: ":"}),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := pipeline.Exec().Strings()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestCountByReturnsErrorForInvalidSpec(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	pipeline := NewPipeline(
+		EchoSlice([]string{"a"}),
+		CountBy("x", FieldOptions{}),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	_, err := pipeline.Exec().Strings()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.NotNil(t, err)
+}
+
+func TestCountByWritesToTheTraceOutput(t *testing.T) {
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := `+ EchoSlice([]string{"b", "a", "b"})
++ p.Stdout> b
++ p.Stdout> a
++ p.Stdout> b
++ CountBy("", scriptish.FieldOptions{Delimiter:"", DelimiterRegex:"", OutputDelimiter:""})
++ p.Stdout>       2 b
++ p.Stdout>       1 a
+`
+	dest := NewTextBuffer()
+	GetShellOptions().EnableTrace(dest)
+
+	// clean up after ourselves
+	defer GetShellOptions().DisableTrace()
+
+	pipeline := NewPipeline(
+		EchoSlice([]string{"b", "a", "b"}),
+		CountBy("", FieldOptions{}),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	pipeline.Exec()
+	actualResult := dest.String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, expectedResult, actualResult)
+}
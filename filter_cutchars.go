@@ -0,0 +1,89 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"strings"
+)
+
+// CutChars writes out the characters of each line that are in the given
+// range spec
+//
+// Characters are UTF-8 runes. Like SelectFields(), they are written out
+// in the order that the spec asks for them, and you can use `NF` for the
+// last character.
+//
+// It is an emulation of `cut -c`.
+func CutChars(spec string, opts ...*StepOption) *SequenceStep {
+	// build our Scriptish command
+	return NewSequenceStep(
+		func(p *Pipe) (int, error) {
+			// debugging support
+			Tracef("CutChars(%#v)", spec)
+
+			// which characters do we want?
+			charsSpec, err := ParseRangeSpec(spec)
+			if err != nil {
+				return StatusNotOkay, err
+			}
+
+			// go and get those characters
+			for line := range p.Stdin.ReadLines() {
+				chars := []rune(line)
+
+				// this will hold our final line
+				var buf strings.Builder
+				for _, index := range rangeIndexes(charsSpec, len(chars)) {
+					buf.WriteRune(chars[index])
+				}
+
+				finalLine := buf.String()
+
+				TracePipeStdout("%s", finalLine)
+				p.Stdout.WriteString(finalLine)
+				p.Stdout.WriteString("\n")
+			}
+
+			// all done
+			return StatusOkay, nil
+		},
+		opts...,
+	)
+}
@@ -62,13 +62,15 @@ func CutFields(spec string, opts ...*StepOption) *SequenceStep {
 				// this will hold our final line
 				var buf []string
 
-				for index, column := range strings.Fields(line) {
+				columns := strings.Fields(line)
+				for index, column := range columns {
 					// adjust for zero-index programming language,
 					// one-index range spec
 					index++
 
 					for _, singleRange := range columnsSpec {
-						if index >= singleRange.Lo && index <= singleRange.Hi {
+						lo, hi, ok := singleRange.Resolve(len(columns))
+						if ok && index >= lo && index <= hi {
 							buf = append(buf, column)
 						}
 					}
@@ -146,3 +146,25 @@ eight nine ten eleven
 
 	assert.Equal(t, expectedResult, actualResult)
 }
+
+func TestCutFieldsSupportsCountingBackFromTheEnd(t *testing.T) {
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := "one six seven\n"
+	pipeline := NewPipeline(
+		Echo("one two three four five six seven"),
+		CutFields("1,NF-1-NF"),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := pipeline.Exec().String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
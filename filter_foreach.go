@@ -0,0 +1,85 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+// ForEach executes the body once for each line of the pipeline's Stdin.
+//
+// Each line is put into the local variable varName in the body's
+// LocalVars, so that the body can use it as `$varName`.
+//
+// The body starts with an empty Stdin each time, and is given the
+// caller's positional parameters. Its output is written to the
+// pipeline's Stdout and Stderr. The StatusCode() and Error() are those
+// of the last time the body ran; if the body never runs, ForEach()
+// succeeds.
+//
+// It is an emulation of UNIX shell scripting's
+// `... | while read varName ; do body ; done`
+func ForEach(varName string, body *Sequence, opts ...*StepOption) *SequenceStep {
+	// build our Scriptish command
+	return NewSequenceStep(
+		func(p *Pipe) (int, error) {
+			// debugging support
+			Tracef("ForEach(%#v)", varName)
+
+			// get our parameters
+			params := getParamsFromEnv(p.Env)
+			ctx := PipeContext(p)
+
+			// like UNIX shells, we succeed if the body never runs
+			statusCode, err := StatusOkay, error(nil)
+
+			for line := range p.Stdin.ReadLines() {
+				// has the sequence been cancelled?
+				ctxErr := ctx.Err()
+				if ctxErr != nil {
+					statusCode, err = StatusNotOkay, ErrCancelled{ctxErr}
+					break
+				}
+
+				statusCode, err = runLoopBody(ctx, p, body, params, varName, line)
+			}
+
+			// all done
+			return statusCode, err
+		},
+		opts...,
+	)
+}
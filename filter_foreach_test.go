@@ -0,0 +1,256 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForEachExecutesTheBodyOncePerLine(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := "file: one.txt\nfile: two.txt\nfile: three.yaml\n"
+	pipeline := NewPipeline(
+		EchoSlice([]string{"one.txt", "two.txt", "three.yaml"}),
+		ForEach(
+			"f",
+			NewList(
+				Echo("file: $f"),
+			),
+		),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := pipeline.Exec().String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestForEachReturnsStatusCodeFromTheLastIteration(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	body := NewList(
+		TestNotEmpty("$line"),
+	)
+	firstPipeline := NewPipeline(
+		EchoSlice([]string{"", "not empty"}),
+		ForEach("line", body),
+	)
+	secondPipeline := NewPipeline(
+		EchoSlice([]string{"not empty", ""}),
+		ForEach("line", body),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	firstStatus, firstErr := firstPipeline.Exec().StatusError()
+	secondStatus, secondErr := secondPipeline.Exec().StatusError()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, firstErr)
+	assert.Equal(t, StatusOkay, firstStatus)
+	assert.Error(t, secondErr)
+	assert.Equal(t, StatusNotOkay, secondStatus)
+}
+
+func TestForEachSucceedsWhenThereAreNoLines(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	pipeline := NewPipeline(
+		ForEach(
+			"f",
+			NewList(
+				Return(1),
+			),
+		),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := pipeline.Exec().String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Empty(t, actualResult)
+}
+
+func TestForEachPassesThePositionalParametersIntoTheBody(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := "hello world\n"
+	pipeline := NewPipeline(
+		Echo("world"),
+		ForEach(
+			"name",
+			NewList(
+				Echo("$1 $name"),
+			),
+		),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := pipeline.Exec("hello").String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestForEachWorksInAStreamingPipeline(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := []string{"ONE", "TWO"}
+	pipeline := NewStreamingPipeline(
+		EchoSlice([]string{"one", "two"}),
+		ForEach(
+			"word",
+			NewPipeline(
+				Echo("$word"),
+				Tr([]string{"o", "n", "e", "t", "w"}, []string{"O", "N", "E", "T", "W"}),
+			),
+		),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := pipeline.Exec().Strings()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestForEachWritesToTheTraceOutput(t *testing.T) {
+	// ----------------------------------------------------------------
+	// setup your test
+
+	dest := NewTextBuffer()
+	GetShellOptions().EnableTrace(dest)
+	defer GetShellOptions().DisableTrace()
+
+	pipeline := NewPipeline(
+		Echo("one.txt"),
+		ForEach(
+			"f",
+			NewList(
+				Echo("$f"),
+			),
+		),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	pipeline.Exec()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	actualResult := dest.String()
+	assert.Contains(t, actualResult, "+ ForEach(\"f\")\n")
+	assert.Contains(t, actualResult, "+ => Echo(\"one.txt\")\n")
+}
+
+func TestForEachStopsWhenTheNextStepStopsReading(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := "got y\ngot y\n"
+
+	// `yes` never finishes on its own
+	pipeline := NewStreamingPipeline(
+		Exec([]string{"/usr/bin/env", "yes"}),
+		ForEach("line", NewList(Echo("got ${line}"))),
+		Head(2),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	actualResult, err := pipeline.ExecContext(ctx).String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
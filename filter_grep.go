@@ -49,12 +49,16 @@ func Grep(regex string, opts ...*StepOption) *SequenceStep {
 	return NewSequenceStep(
 		func(p *Pipe) (int, error) {
 			// expand our input
-			expRegex := p.Env.Expand(regex)
+			expRegex, err := expandString(p, regex)
 
 			// debugging support
 			Tracef("Grep(%#v)", regex)
 			Tracef("=> Grep(%#v)", expRegex)
 
+			if err != nil {
+				return StatusNotOkay, err
+			}
+
 			// do we have a valid regex?
 			re, err := regexp.Compile(expRegex)
 			if err != nil {
@@ -0,0 +1,155 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"strconv"
+)
+
+// GrepOptions tells GrepWith() how to match lines, and what to write out
+type GrepOptions struct {
+	// IgnoreCase matches without caring about upper and lowercase
+	// letters, like `grep -i`
+	IgnoreCase bool
+
+	// FixedStrings treats the patterns as plain strings, not regexes,
+	// like `grep -F`
+	FixedStrings bool
+
+	// MatchWords only matches whole words, like `grep -w`
+	//
+	// A match must have a non-word character (anything that is not a
+	// letter, digit or underscore), or the start or end of the line, on
+	// each side of it.
+	MatchWords bool
+
+	// MatchLines only matches whole lines, like `grep -x`
+	//
+	// It takes priority over MatchWords.
+	MatchLines bool
+
+	// Invert selects the lines that do not match, like `grep -v`
+	Invert bool
+
+	// Count writes out how many lines were selected, instead of the
+	// lines themselves, like `grep -c`
+	Count bool
+
+	// OnlyMatching writes out each match on a line of its own, instead
+	// of the whole line, like `grep -o`
+	OnlyMatching bool
+
+	// MaxCount stops after this many lines have been selected, like
+	// `grep -m`
+	//
+	// When it is 0, there is no limit.
+	MaxCount int
+
+	// LineNumbers puts the line number in front of each line that we
+	// write out, like `grep -n`
+	LineNumbers bool
+
+	// BeforeContext is how many lines to write out before each
+	// selected line, like `grep -B`
+	BeforeContext int
+
+	// AfterContext is how many lines to write out after each selected
+	// line, like `grep -A`
+	AfterContext int
+}
+
+// GrepWith writes out the lines that match any of the given regexes,
+// using the given options
+//
+// It is an emulation of `grep -E`. `grep -C N` is the same as setting
+// both BeforeContext and AfterContext to N. Like `grep`, groups of lines
+// that are not next to each other are separated by a `--` line.
+//
+// Like `grep`, it returns StatusNotOkay if no lines were selected.
+func GrepWith(patterns []string, gopts GrepOptions, opts ...*StepOption) *SequenceStep {
+	// build our Scriptish command
+	return NewSequenceStep(
+		func(p *Pipe) (int, error) {
+			// expand our input
+			expPatterns, err := expandGrepPatterns(p, patterns, gopts)
+
+			// debugging support
+			Tracef("GrepWith(%#v, %#v)", patterns, gopts)
+			Tracef("=> GrepWith(%#v, %#v)", expPatterns, gopts)
+
+			if err != nil {
+				return StatusNotOkay, err
+			}
+
+			// do we have valid patterns?
+			grepper, err := newGrepper(expPatterns, gopts)
+			if err != nil {
+				return StatusNotOkay, err
+			}
+
+			// let's apply them
+			lines := p.Stdin.ReadLines()
+			readLine := func() (string, bool) {
+				line, ok := <-lines
+				return line, ok
+			}
+			selected := grepper.grepLines(readLine, "", func(line string) {
+				TracePipeStdout("%s", line)
+				p.Stdout.WriteString(line)
+				p.Stdout.WriteRune('\n')
+			})
+
+			if gopts.Count {
+				TracePipeStdout("%d", selected)
+				p.Stdout.WriteString(strconv.Itoa(selected))
+				p.Stdout.WriteRune('\n')
+			}
+
+			// like grep, we fail if nothing was selected
+			if selected == 0 {
+				return StatusNotOkay, nil
+			}
+
+			// all done
+			return StatusOkay, nil
+		},
+		opts...,
+	)
+}
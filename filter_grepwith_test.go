@@ -0,0 +1,245 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGrepWithForwardsLinesThatMatchAnyPattern(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	testData := []string{
+		"this is the first line",
+		"this is the SECOND line",
+		"this is the third line",
+		"and this is the fourth line",
+	}
+	expectedResult := []string{
+		"this is the SECOND line",
+		"this is the third line",
+	}
+
+	pipeline := NewPipeline(
+		EchoSlice(testData),
+		GrepWith([]string{"second", "third"}, GrepOptions{IgnoreCase: true}),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := pipeline.Exec().Strings()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestGrepWithExpandsPatterns(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := []string{"1.5"}
+
+	pipeline := NewPipeline(
+		EchoSlice([]string{"1.5", "105"}),
+		GrepWith([]string{`^$1\.5$`}, GrepOptions{}),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := pipeline.Exec("1").Strings()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestGrepWithReturnsStatusNotOkayIfNothingIsSelected(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	pipeline := NewPipeline(
+		EchoSlice([]string{"one", "two"}),
+		GrepWith([]string{"three"}, GrepOptions{}),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	statusCode, err := pipeline.Exec().StatusError()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Error(t, err)
+	assert.Equal(t, StatusNotOkay, statusCode)
+}
+
+func TestGrepWithCanBeUsedInIf(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := "not found\n"
+
+	list := NewList(
+		IfElse(
+			NewPipeline(
+				EchoSlice([]string{"one", "two"}),
+				GrepWith([]string{"three"}, GrepOptions{}),
+			),
+			NewList(Echo("found")),
+			NewList(Echo("not found")),
+		),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec().String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestGrepWithReturnsErrorIfRegexInvalid(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	pipeline := NewPipeline(
+		EchoSlice([]string{"one", "two"}),
+		GrepWith([]string{"one", "[* "}, GrepOptions{}),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	_, err := pipeline.Exec().Strings()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Error(t, err)
+}
+
+func TestGrepWithWritesToTheTraceOutput(t *testing.T) {
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := `+ EchoSlice([]string{"one two", "three four"})
++ p.Stdout> one two
++ p.Stdout> three four
++ GrepWith([]string{"$1"}, scriptish.GrepOptions{IgnoreCase:false, FixedStrings:false, MatchWords:false, MatchLines:false, Invert:false, Count:true, OnlyMatching:false, MaxCount:0, LineNumbers:false, BeforeContext:0, AfterContext:0})
++ => GrepWith([]string{"one|two"}, scriptish.GrepOptions{IgnoreCase:false, FixedStrings:false, MatchWords:false, MatchLines:false, Invert:false, Count:true, OnlyMatching:false, MaxCount:0, LineNumbers:false, BeforeContext:0, AfterContext:0})
++ p.Stdout> 1
+`
+	dest := NewTextBuffer()
+	GetShellOptions().EnableTrace(dest)
+
+	// clean up after ourselves
+	defer GetShellOptions().DisableTrace()
+
+	pipeline := NewPipeline(
+		EchoSlice([]string{"one two", "three four"}),
+		GrepWith([]string{"$1"}, GrepOptions{Count: true}),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	pipeline.Exec("one|two")
+	actualResult := dest.String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestGrepWithStopsReadingOnceMaxCountLinesAreSelected(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := "y\n"
+
+	// `yes` never finishes on its own
+	pipeline := NewStreamingPipeline(
+		Exec([]string{"/usr/bin/env", "yes"}),
+		GrepWith([]string{"y"}, GrepOptions{MaxCount: 1}),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	actualResult, err := pipeline.ExecContext(ctx).String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
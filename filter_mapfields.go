@@ -0,0 +1,98 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"strings"
+)
+
+// FieldsMapper is the callback that MapFields() calls for each line
+//
+// It is given the line's fields, and returns the fields to write out.
+// Return nil to drop the line altogether.
+type FieldsMapper func(fields []string) ([]string, error)
+
+// MapFields splits each line up into fields, and passes them to the
+// given callback to transform
+//
+// The fields that the callback returns are joined back together, and
+// written out. If the callback returns an error, MapFields() stops and
+// returns that error.
+//
+// It is an emulation of `awk -F ... '{ ... }'`.
+func MapFields(fopts FieldOptions, mapper FieldsMapper, opts ...*StepOption) *SequenceStep {
+	// build our Scriptish command
+	return NewSequenceStep(
+		func(p *Pipe) (int, error) {
+			// debugging support
+			Tracef("MapFields(%#v)", fopts)
+
+			// how do we split the lines up?
+			splitter, err := newFieldSplitter(p, fopts)
+			if err != nil {
+				return StatusNotOkay, err
+			}
+
+			for line := range p.Stdin.ReadLines() {
+				var fields []string
+				fields, err = mapper(splitter.split(line))
+				if err != nil {
+					break
+				}
+				if fields == nil {
+					continue
+				}
+
+				finalLine := strings.Join(fields, splitter.outputDelimiter)
+
+				TracePipeStdout("%s", finalLine)
+				p.Stdout.WriteString(finalLine)
+				p.Stdout.WriteString("\n")
+			}
+			if err != nil {
+				return StatusNotOkay, err
+			}
+
+			// all done
+			return StatusOkay, nil
+		},
+		opts...,
+	)
+}
@@ -0,0 +1,179 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMapFieldsWritesOutTheFieldsThatTheCallbackReturns(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := []string{"ALICE=42", "BOB=7"}
+	pipeline := NewPipeline(
+		EchoSlice([]string{"alice,42", "bob,7"}),
+		MapFields(
+			FieldOptions{Delimiter: ",", OutputDelimiter: "="},
+			func(fields []string) ([]string, error) {
+				return []string{strings.ToUpper(fields[0]), fields[1]}, nil
+			},
+		),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := pipeline.Exec().Strings()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestMapFieldsDropsLinesWhenTheCallbackReturnsNil(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := []string{"one 1", "three 3"}
+	pipeline := NewPipeline(
+		EchoSlice([]string{"1 one", "", "3 three"}),
+		MapFields(
+			FieldOptions{},
+			func(fields []string) ([]string, error) {
+				if len(fields) == 0 {
+					return nil, nil
+				}
+				return []string{fields[1], fields[0]}, nil
+			},
+		),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := pipeline.Exec().Strings()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestMapFieldsStopsWhenTheCallbackFails(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := []string{"2"}
+	pipeline := NewPipeline(
+		EchoSlice([]string{"a 1", "b x", "c 3"}),
+		MapFields(
+			FieldOptions{},
+			func(fields []string) ([]string, error) {
+				n, err := strconv.Atoi(fields[1])
+				if err != nil {
+					return nil, errors.New("not a number: " + fields[1])
+				}
+				return []string{strconv.Itoa(n * 2)}, nil
+			},
+		),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := pipeline.Exec().Strings()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, errors.New("not a number: x"), err)
+	assert.Equal(t, StatusNotOkay, pipeline.StatusCode())
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestMapFieldsStopsReadingWhenTheCallbackFails(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedErr := errors.New("stop here")
+
+	// `yes` never finishes on its own
+	pipeline := NewStreamingPipeline(
+		Exec([]string{"/usr/bin/env", "yes"}),
+		MapFields(
+			FieldOptions{},
+			func(fields []string) ([]string, error) {
+				return nil, expectedErr
+			},
+		),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	actualResult, err := pipeline.ExecContext(ctx).String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, expectedErr, err)
+	assert.Equal(t, "", actualResult)
+}
@@ -54,7 +54,7 @@ func RunList(pl *List, opts ...*StepOption) *SequenceStep {
 			params := getParamsFromEnv(p.Env)
 
 			// run our sub-list w/ our parameters
-			pl.Exec(params...)
+			pl.execFromPipe(PipeContext(p), p, params...)
 
 			// append the sub-list's stdout to our own
 			io.Copy(p.Stdout, pl.Pipe.Stdout)
@@ -0,0 +1,82 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"io"
+)
+
+// RunListWithArgs allows you to call one list from another, passing
+// in your own positional parameters.
+//
+// args are expanded in the same way as Exec() arguments. The expanded
+// args become the sub-list's $1, $2 ... $#, instead of the caller's.
+//
+// It is an emulation of UNIX shell scripting's `function_name arg1 arg2 ...`
+func RunListWithArgs(pl *List, args []string, opts ...*StepOption) *SequenceStep {
+	// build our Scriptish command
+	return NewSequenceStep(
+		func(p *Pipe) (int, error) {
+			// expand our input
+			params, err := expandArgs(p, args)
+
+			// debugging support
+			Tracef("RunListWithArgs(%#v)", args)
+			Tracef("=> RunListWithArgs(%#v)", params)
+
+			if err != nil {
+				return StatusNotOkay, err
+			}
+
+			// run our sub-list w/ these parameters
+			pl.execFromPipe(PipeContext(p), p, params...)
+
+			// append the sub-list's stdout to our own
+			io.Copy(p.Stdout, pl.Pipe.Stdout)
+
+			// append the sub-list's stderr to our own
+			io.Copy(p.Stderr, pl.Pipe.Stderr)
+
+			// all done
+			return pl.StatusError()
+		},
+		opts...,
+	)
+}
@@ -0,0 +1,135 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunListWithArgsPassesInTheArgsAsPositionalParameters(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	subList := NewList(
+		Echo("hello $1, from $2 ($#)"),
+	)
+
+	list := NewList(
+		RunListWithArgs(subList, []string{"world", "$1"}),
+		Echo("caller still has $1 ($#)"),
+	)
+	expectedResult := "hello world, from caller (2)\ncaller still has caller (1)\n"
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec("caller").String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestRunListWithArgsPassesOnEachOfTheCallersParametersForDollarAt(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	subList := NewList(
+		Echo("$# params: $1 | $2"),
+	)
+
+	list := NewList(
+		RunListWithArgs(subList, []string{"$@"}),
+	)
+	expectedResult := "2 params: first one | second one\n"
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec("first one", "second one").String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestRunListWithArgsReturnsSubListsError(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedError := errors.New("this is the error from our sub-list")
+	op1 := NewSequenceStep(
+		func(p *Pipe) (int, error) {
+			return StatusNotOkay, expectedError
+		},
+	)
+
+	subList := NewList(
+		op1,
+	)
+
+	pipeline := NewPipeline(
+		RunListWithArgs(subList, []string{"a"}),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	_, err := pipeline.Exec().String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.NotNil(t, err)
+	assert.Equal(t, expectedError, err)
+}
@@ -53,6 +53,13 @@ func RunPipeline(pl *Pipeline, opts ...*StepOption) *SequenceStep {
 			// make sure our sub pipeline starts nice and empty
 			pl.NewPipe()
 
+			// and that it can be cancelled along with us, starts
+			// in our working directory, and can see our exported
+			// variables
+			setPipeContext(pl.Pipe, PipeContext(p))
+			inheritPipeDir(pl.Pipe, p, pl.Dir)
+			inheritPipeCaller(pl.Pipe, p)
+
 			// copy the pipeline's content into our sub pipeline
 			for line := range p.Stdin.ReadLines() {
 				pl.Pipe.Stdout.WriteString(line)
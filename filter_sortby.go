@@ -0,0 +1,211 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"os"
+)
+
+// SortCompare says how SortBy() compares the keys of two lines
+type SortCompare int
+
+// These are the comparisons that SortBy() supports
+const (
+	// SortText compares the keys byte by byte, like `LC_ALL=C sort`
+	SortText SortCompare = iota
+
+	// SortNumeric compares the numbers at the start of the keys, like
+	// `sort -n`
+	SortNumeric
+
+	// SortHumanNumeric compares numbers with SI suffixes (eg 2K or
+	// 1.5G), like `sort -h`
+	SortHumanNumeric
+
+	// SortVersion compares version numbers (eg v1.10.2), like `sort -V`
+	SortVersion
+
+	// SortMonth compares month names (eg JAN or Feb), like `sort -M`
+	SortMonth
+)
+
+// DefaultSortBufferSize is how many bytes of input SortBy() holds in
+// memory before it starts writing sorted runs out to temp files
+const DefaultSortBufferSize = 64 * 1024 * 1024
+
+// SortOptions tells SortBy() how to sort the pipeline
+type SortOptions struct {
+	// Compare is how we compare the keys
+	Compare SortCompare
+
+	// Keys is a range spec (see ParseRangeSpec()) of the fields to sort
+	// on
+	//
+	// Each range is a key, and the keys are compared in order: "3,1" is
+	// the same as `sort -k3,3 -k1,1`, and "2-" is the same as `sort -k2`.
+	// When Keys is empty, we sort on the whole line.
+	Keys string
+
+	// Separator is the string that separates the fields, like
+	// `sort -t`
+	//
+	// When it is empty, each field is a run of non-blank characters,
+	// along with any blanks in front of it.
+	Separator string
+
+	// FoldCase compares lowercase letters as if they were uppercase,
+	// like `sort -f`
+	FoldCase bool
+
+	// Reverse sorts into descending order, like `sort -r`
+	Reverse bool
+
+	// Unique only writes out the first of any lines that have the same
+	// keys, like `sort -u`
+	Unique bool
+
+	// Stable keeps lines that have the same keys in the order that we
+	// read them in, like `sort -s`
+	Stable bool
+
+	// BufferSize is how many bytes of input we hold in memory before we
+	// start writing sorted runs out to temp files
+	//
+	// When it is 0, we use DefaultSortBufferSize.
+	BufferSize int
+
+	// TempDir is where we create the temp files
+	//
+	// When it is empty, we use the operating system's temp folder.
+	TempDir string
+}
+
+// SortBy sorts the contents of the pipeline, using the given options
+//
+// When there is more input than will fit into opts.BufferSize, it writes
+// sorted runs out to temp files, and merges them back together at the
+// end, like GNU `sort` does. The temp files are removed afterwards.
+func SortBy(sopts SortOptions, opts ...*StepOption) *SequenceStep {
+	// build our Scriptish command
+	return NewSequenceStep(
+		func(p *Pipe) (int, error) {
+			// debugging support
+			Tracef("SortBy(%#v)", sopts)
+
+			// expand our input
+			sorter := lineSorter{opts: sopts}
+			var err error
+			sorter.opts.Separator, err = expandString(p, sopts.Separator)
+			if err != nil {
+				return StatusNotOkay, err
+			}
+			if sopts.TempDir != "" {
+				sorter.opts.TempDir, err = expandPathArg(p, sopts.TempDir)
+				if err != nil {
+					return StatusNotOkay, err
+				}
+				sorter.opts.TempDir = resolvePipePath(p, sorter.opts.TempDir)
+			}
+			if sorter.opts.BufferSize <= 0 {
+				sorter.opts.BufferSize = DefaultSortBufferSize
+			}
+
+			// which keys are we sorting on?
+			if sopts.Keys != "" {
+				sorter.keys, err = ParseRangeSpec(sopts.Keys)
+				if err != nil {
+					return StatusNotOkay, err
+				}
+			}
+
+			// read in our lines, spilling them to temp files as we go
+			runs := []string{}
+			defer func() {
+				for _, run := range runs {
+					os.Remove(run)
+				}
+			}()
+
+			lines := []*sortLine{}
+			size := 0
+			for line := range p.Stdin.ReadLines() {
+				lines = append(lines, sorter.newSortLine(line))
+				size += len(line) + sortLineOverhead
+				if size < sorter.opts.BufferSize {
+					continue
+				}
+
+				var run string
+				run, err = sorter.spill(lines)
+				if run != "" {
+					runs = append(runs, run)
+				}
+				if err != nil {
+					break
+				}
+				lines = []*sortLine{}
+				size = 0
+			}
+			if err != nil {
+				return StatusNotOkay, err
+			}
+
+			// write out the sorted lines
+			var last *sortLine
+			err = sorter.merge(runs, lines, func(line *sortLine) {
+				if sopts.Unique && last != nil && sorter.compare(last, line) == 0 {
+					return
+				}
+				last = line
+
+				TracePipeStdout("%s", line.line)
+				p.Stdout.WriteString(line.line)
+				p.Stdout.WriteRune('\n')
+			})
+			if err != nil {
+				return StatusNotOkay, err
+			}
+
+			// all done
+			return StatusOkay, nil
+		},
+		opts...,
+	)
+}
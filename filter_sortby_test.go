@@ -0,0 +1,339 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortBySortsNumerically(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := []string{"-5", "2", "10", "100"}
+	pipeline := NewPipeline(
+		EchoSlice([]string{"10", "2", "100", "-5"}),
+		SortBy(SortOptions{Compare: SortNumeric}),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := pipeline.Exec().Strings()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestSortBySortsOnKeys(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := []string{
+		"bin:x:2:2",
+		"daemon:x:1:1",
+		"root:x:0:0",
+		"sys:x:3:3",
+	}
+	pipeline := NewPipeline(
+		EchoSlice([]string{"sys:x:3:3", "root:x:0:0", "daemon:x:1:1", "bin:x:2:2"}),
+		SortBy(SortOptions{Keys: "1", Separator: ":"}),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := pipeline.Exec().Strings()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestSortByCanSortOnTheLastField(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := []string{"c 1", "a b 2", "b 3"}
+	pipeline := NewPipeline(
+		EchoSlice([]string{"b 3", "a b 2", "c 1"}),
+		SortBy(SortOptions{Compare: SortNumeric, Keys: "NF"}),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := pipeline.Exec().Strings()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestSortByUniqueKeepsTheFirstOfEachKey(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := []string{"a 2", "b 1"}
+	pipeline := NewPipeline(
+		EchoSlice([]string{"b 1", "a 2", "b 3", "a 4"}),
+		SortBy(SortOptions{Keys: "1", Unique: true}),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := pipeline.Exec().Strings()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestSortBySpillsLargeInputsToTempFiles(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	tmpDir, err := ioutil.TempDir("", "scriptish-sortby-")
+	assert.Nil(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	// a repeatable shuffle, with plenty of duplicate keys
+	testData := []string{}
+	for i := 0; i < 1000; i++ {
+		testData = append(testData, fmt.Sprintf("%d line%04d", (i*7919)%101, i))
+	}
+	expectedResult := append([]string{}, testData...)
+	sort.SliceStable(expectedResult, func(i, j int) bool {
+		var a, b int
+		fmt.Sscanf(expectedResult[i], "%d", &a)
+		fmt.Sscanf(expectedResult[j], "%d", &b)
+		return a > b
+	})
+
+	leftoverFiles := []string{}
+	pipeline := NewPipeline(
+		EchoSlice(testData),
+		SortBy(SortOptions{
+			Compare:    SortNumeric,
+			Keys:       "1",
+			Reverse:    true,
+			Stable:     true,
+			BufferSize: 1024,
+			TempDir:    tmpDir,
+		}),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := pipeline.Exec().Strings()
+	files, _ := ioutil.ReadDir(tmpDir)
+	for _, file := range files {
+		leftoverFiles = append(leftoverFiles, file.Name())
+	}
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+	assert.Empty(t, leftoverFiles)
+}
+
+func TestSortByMergesManyRunsInBatches(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	tmpDir, err := ioutil.TempDir("", "scriptish-sortby-")
+	assert.Nil(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	// with a BufferSize of 1, every line is a run of its own, so
+	// there are far too many runs to merge in one go
+	testData := []string{}
+	for i := 0; i < 1000; i++ {
+		testData = append(testData, fmt.Sprintf("%d line%04d", (i*7919)%101, i))
+	}
+	expectedResult := append([]string{}, testData...)
+	sort.SliceStable(expectedResult, func(i, j int) bool {
+		var a, b int
+		fmt.Sscanf(expectedResult[i], "%d", &a)
+		fmt.Sscanf(expectedResult[j], "%d", &b)
+		return a < b
+	})
+
+	leftoverFiles := []string{}
+	pipeline := NewPipeline(
+		EchoSlice(testData),
+		SortBy(SortOptions{
+			Compare:    SortNumeric,
+			Keys:       "1",
+			Stable:     true,
+			BufferSize: 1,
+			TempDir:    tmpDir,
+		}),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := pipeline.Exec().Strings()
+	files, _ := ioutil.ReadDir(tmpDir)
+	for _, file := range files {
+		leftoverFiles = append(leftoverFiles, file.Name())
+	}
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+	assert.Empty(t, leftoverFiles)
+}
+
+func TestSortBySpillingGivesTheSameResultAsSortingInMemory(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	testData := []string{}
+	for i := 0; i < 500; i++ {
+		testData = append(testData, fmt.Sprintf("v1.%d.%d", (i*31)%17, (i*13)%11))
+	}
+	sopts := SortOptions{Compare: SortVersion, Unique: true}
+	expectedResult, err := NewPipeline(
+		EchoSlice(testData),
+		SortBy(sopts),
+	).Exec().Strings()
+	assert.Nil(t, err)
+
+	sopts.BufferSize = 100
+	pipeline := NewPipeline(
+		EchoSlice(testData),
+		SortBy(sopts),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := pipeline.Exec().Strings()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+	assert.Len(t, actualResult, 17*11)
+}
+
+func TestSortByReturnsErrorIfKeysInvalid(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	pipeline := NewPipeline(
+		EchoSlice([]string{"b", "a"}),
+		SortBy(SortOptions{Keys: "alfred"}),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	_, err := pipeline.Exec().String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Error(t, err)
+	assert.Equal(t, StatusNotOkay, pipeline.StatusCode())
+}
+
+func TestSortByReturnsErrorIfItCannotSpill(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	pipeline := NewPipeline(
+		EchoSlice([]string{"b", "a", "c"}),
+		SortBy(SortOptions{BufferSize: 1, TempDir: "/does/not/exist"}),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	_, err := pipeline.Exec().String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Error(t, err)
+	assert.Equal(t, StatusNotOkay, pipeline.StatusCode())
+}
@@ -66,8 +66,14 @@ func Tr(old []string, new []string, opts ...*StepOption) *SequenceStep {
 			for line := range p.Stdin.ReadLines() {
 				for i := range old {
 					// expand our inputs
-					expOld := p.Env.Expand(old[i])
-					expNew := p.Env.Expand(new[i])
+					expOld, err := expandString(p, old[i])
+					if err != nil {
+						return StatusNotOkay, err
+					}
+					expNew, err := expandString(p, new[i])
+					if err != nil {
+						return StatusNotOkay, err
+					}
 
 					// do the replacement
 					line = strings.ReplaceAll(line, expOld, expNew)
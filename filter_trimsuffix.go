@@ -51,12 +51,16 @@ func TrimSuffix(ext string, opts ...*StepOption) *SequenceStep {
 	return NewSequenceStep(
 		func(p *Pipe) (int, error) {
 			// expand our input
-			expExt := p.Env.Expand(ext)
+			expExt, err := expandString(p, ext)
 
 			// debugging support
 			Tracef("TrimSuffix(%#v)", ext)
 			Tracef("=> TrimSuffix(%#v)", expExt)
 
+			if err != nil {
+				return StatusNotOkay, err
+			}
+
 			for line := range p.Stdin.ReadLines() {
 				newPath := strings.TrimSuffix(line, expExt)
 
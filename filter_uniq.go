@@ -39,7 +39,10 @@
 
 package scriptish
 
-// Uniq removes duplicated lines from the pipeline
+// Uniq removes adjacent duplicated lines from the pipeline
+//
+// Like `uniq`, it only compares each line with the line before it. Use
+// Sort() first to remove every duplicated line.
 func Uniq(opts ...*StepOption) *SequenceStep {
 	// build our Scriptish command
 	return NewSequenceStep(
@@ -47,19 +50,8 @@ func Uniq(opts ...*StepOption) *SequenceStep {
 			// debugging support
 			Tracef("Uniq()")
 
-			// keep track of which lines we have already seen
-			var seen = make(map[string]bool)
-
 			// do the filtering
-			for line := range p.Stdin.ReadLines() {
-				// have we seen this line before?
-				if !seen[line] {
-					seen[line] = true
-					TracePipeStdout("%s", line)
-					p.Stdout.WriteString(line)
-					p.Stdout.WriteRune('\n')
-				}
-			}
+			runUniq(p, UniqOptions{})
 
 			// all done
 			return StatusOkay, nil
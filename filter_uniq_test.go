@@ -51,17 +51,17 @@ func TestUniq(t *testing.T) {
 
 	testData := []string{
 		"this is a line of data",
-		"this is the second line of test data",
 		"this is a line of data",
-		"this is the fourth line of test data",
+		"this is the third line of test data",
 		"this is a line of data",
-		"this is the sixth line of test data",
+		"this is the fifth line of test data",
+		"this is the fifth line of test data",
 	}
 	expectedResult := []string{
 		"this is a line of data",
-		"this is the second line of test data",
-		"this is the fourth line of test data",
-		"this is the sixth line of test data",
+		"this is the third line of test data",
+		"this is a line of data",
+		"this is the fifth line of test data",
 	}
 
 	pipeline := NewPipeline(
@@ -86,18 +86,18 @@ func TestUniqWritesToTheTraceOutput(t *testing.T) {
 	// ----------------------------------------------------------------
 	// setup your test
 
-	expectedResult := `+ EchoSlice([]string{"this is a line of data", "this is the second line of test data", "this is a line of data", "this is the fourth line of test data", "this is a line of data", "this is the sixth line of test data"})
+	expectedResult := `+ EchoSlice([]string{"this is a line of data", "this is a line of data", "this is the third line of test data", "this is a line of data", "this is the fifth line of test data", "this is the fifth line of test data"})
 + p.Stdout> this is a line of data
-+ p.Stdout> this is the second line of test data
 + p.Stdout> this is a line of data
-+ p.Stdout> this is the fourth line of test data
++ p.Stdout> this is the third line of test data
 + p.Stdout> this is a line of data
-+ p.Stdout> this is the sixth line of test data
++ p.Stdout> this is the fifth line of test data
++ p.Stdout> this is the fifth line of test data
 + Uniq()
 + p.Stdout> this is a line of data
-+ p.Stdout> this is the second line of test data
-+ p.Stdout> this is the fourth line of test data
-+ p.Stdout> this is the sixth line of test data
++ p.Stdout> this is the third line of test data
++ p.Stdout> this is a line of data
++ p.Stdout> this is the fifth line of test data
 `
 	dest := NewTextBuffer()
 	GetShellOptions().EnableTrace(dest)
@@ -107,11 +107,11 @@ func TestUniqWritesToTheTraceOutput(t *testing.T) {
 
 	testData := []string{
 		"this is a line of data",
-		"this is the second line of test data",
 		"this is a line of data",
-		"this is the fourth line of test data",
+		"this is the third line of test data",
 		"this is a line of data",
-		"this is the sixth line of test data",
+		"this is the fifth line of test data",
+		"this is the fifth line of test data",
 	}
 
 	pipeline := NewPipeline(
@@ -0,0 +1,172 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// UniqOptions tells UniqBy() how to compare lines, and which lines to
+// write out
+type UniqOptions struct {
+	// Count puts the number of times that each line was repeated in
+	// front of the line, like `uniq -c`
+	Count bool
+
+	// Repeated only writes out lines that were repeated, like `uniq -d`
+	Repeated bool
+
+	// Unique only writes out lines that were not repeated, like
+	// `uniq -u`
+	Unique bool
+
+	// IgnoreCase compares lines without caring about upper and
+	// lowercase letters, like `uniq -i`
+	IgnoreCase bool
+
+	// SkipFields is how many fields to skip before comparing lines, like
+	// `uniq -f`
+	//
+	// A field is a run of blanks, followed by a run of non-blanks.
+	SkipFields int
+
+	// SkipChars is how many characters to skip before comparing lines,
+	// like `uniq -s`
+	//
+	// They are skipped after SkipFields.
+	SkipChars int
+}
+
+// UniqBy removes adjacent duplicated lines from the pipeline, using
+// the given options
+//
+// Like `uniq`, it only compares each line with the line before it, and
+// writes out the first line of each group of duplicated lines. It only
+// holds one group in memory at a time.
+func UniqBy(uopts UniqOptions, opts ...*StepOption) *SequenceStep {
+	// build our Scriptish command
+	return NewSequenceStep(
+		func(p *Pipe) (int, error) {
+			// debugging support
+			Tracef("UniqBy(%#v)", uopts)
+
+			// do the filtering
+			runUniq(p, uopts)
+
+			// all done
+			return StatusOkay, nil
+		},
+		opts...,
+	)
+}
+
+// runUniq does the work for Uniq() and UniqBy()
+func runUniq(p *Pipe, uopts UniqOptions) {
+	// the group of duplicated lines that we are looking at
+	var first, firstKey string
+	count := 0
+
+	writeGroup := func() {
+		if count == 0 || (uopts.Repeated && count == 1) || (uopts.Unique && count > 1) {
+			return
+		}
+
+		line := first
+		if uopts.Count {
+			line = uniqCountLine(count, first)
+		}
+		TracePipeStdout("%s", line)
+		p.Stdout.WriteString(line)
+		p.Stdout.WriteRune('\n')
+	}
+
+	for line := range p.Stdin.ReadLines() {
+		key := uniqKey(line, uopts)
+		if count > 0 && uniqKeysMatch(firstKey, key, uopts.IgnoreCase) {
+			count++
+			continue
+		}
+
+		writeGroup()
+		first = line
+		firstKey = key
+		count = 1
+	}
+	writeGroup()
+}
+
+// uniqKey returns the part of the line that `uniq` compares
+func uniqKey(line string, uopts UniqOptions) string {
+	// skip over the fields
+	for i := 0; i < uopts.SkipFields; i++ {
+		line = strings.TrimLeft(line, " \t")
+		end := strings.IndexAny(line, " \t")
+		if end < 0 {
+			return ""
+		}
+		line = line[end:]
+	}
+
+	// skip over the characters
+	for i := 0; i < uopts.SkipChars && line != ""; i++ {
+		_, size := utf8.DecodeRuneInString(line)
+		line = line[size:]
+	}
+
+	// all done
+	return line
+}
+
+// uniqKeysMatch returns true if the two keys are duplicates of each
+// other
+func uniqKeysMatch(a, b string, ignoreCase bool) bool {
+	if ignoreCase {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
+
+// uniqCountLine puts the count in front of the line, in the same format
+// that `uniq -c` uses
+func uniqCountLine(count int, line string) string {
+	return fmt.Sprintf("%7d %s", count, line)
+}
@@ -0,0 +1,184 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// uniqTestInput is the input for our `uniq` conformance tests
+var uniqTestInput = []string{
+	"apple",
+	"apple",
+	"Apple",
+	"banana",
+	"apple",
+	"1 x cherry",
+	"2 y cherry",
+	"3  y cherry",
+	"4\tz date",
+	"date",
+	"",
+	"",
+	"xdate",
+	"ydate",
+	"fig",
+}
+
+func TestUniqByMatchesUniq(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	uniqCmd, err := exec.LookPath("uniq")
+	if err != nil {
+		t.Skip("uniq not found")
+	}
+
+	testData := []struct {
+		uniqArgs []string
+		uopts    UniqOptions
+	}{
+		{[]string{}, UniqOptions{}},
+		{[]string{"-c"}, UniqOptions{Count: true}},
+		{[]string{"-d"}, UniqOptions{Repeated: true}},
+		{[]string{"-u"}, UniqOptions{Unique: true}},
+		{[]string{"-d", "-u"}, UniqOptions{Repeated: true, Unique: true}},
+		{[]string{"-c", "-d"}, UniqOptions{Count: true, Repeated: true}},
+		{[]string{"-c", "-u"}, UniqOptions{Count: true, Unique: true}},
+		{[]string{"-i"}, UniqOptions{IgnoreCase: true}},
+		{[]string{"-i", "-c"}, UniqOptions{IgnoreCase: true, Count: true}},
+		{[]string{"-f", "1"}, UniqOptions{SkipFields: 1}},
+		{[]string{"-f", "2"}, UniqOptions{SkipFields: 2}},
+		{[]string{"-f", "5"}, UniqOptions{SkipFields: 5}},
+		{[]string{"-s", "1"}, UniqOptions{SkipChars: 1}},
+		{[]string{"-f", "1", "-s", "2", "-c"}, UniqOptions{SkipFields: 1, SkipChars: 2, Count: true}},
+		{[]string{"-s", "20"}, UniqOptions{SkipChars: 20}},
+	}
+
+	for _, testCase := range testData {
+		cmd := exec.Command(uniqCmd, testCase.uniqArgs...)
+		cmd.Env = append(os.Environ(), "LC_ALL=C")
+		cmd.Stdin = strings.NewReader(strings.Join(uniqTestInput, "\n") + "\n")
+		expectedResult, err := cmd.Output()
+		assert.Nil(t, err, testCase.uniqArgs)
+
+		pipeline := NewPipeline(
+			EchoSlice(uniqTestInput),
+			UniqBy(testCase.uopts),
+		)
+
+		// ----------------------------------------------------------------
+		// perform the change
+
+		actualResult, err := pipeline.Exec().String()
+
+		// ----------------------------------------------------------------
+		// test the results
+
+		assert.Nil(t, err, testCase.uniqArgs)
+		assert.Equal(t, string(expectedResult), actualResult, testCase.uniqArgs)
+	}
+}
+
+func TestUniqBySkipsCharactersNotBytes(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := []string{"é apple", "ü pear"}
+	pipeline := NewPipeline(
+		EchoSlice([]string{"é apple", "ö apple", "ü pear"}),
+		UniqBy(UniqOptions{SkipChars: 1}),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := pipeline.Exec().Strings()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestUniqByWritesToTheTraceOutput(t *testing.T) {
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := `+ EchoSlice([]string{"a", "a", "b"})
++ p.Stdout> a
++ p.Stdout> a
++ p.Stdout> b
++ UniqBy(scriptish.UniqOptions{Count:true, Repeated:false, Unique:false, IgnoreCase:false, SkipFields:0, SkipChars:0})
++ p.Stdout>       2 a
++ p.Stdout>       1 b
+`
+	dest := NewTextBuffer()
+	GetShellOptions().EnableTrace(dest)
+
+	// clean up after ourselves
+	defer GetShellOptions().DisableTrace()
+
+	pipeline := NewPipeline(
+		EchoSlice([]string{"a", "a", "b"}),
+		UniqBy(UniqOptions{Count: true}),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	pipeline.Exec()
+	actualResult := dest.String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, expectedResult, actualResult)
+}
@@ -0,0 +1,272 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+)
+
+// StatusXargsFailed is the status code that Xargs() and XargsParallel()
+// return when the body fails for one or more batches of input.
+//
+// It is the same status code that the UNIX `xargs` command uses.
+const StatusXargsFailed = 123
+
+// XargsOptions change how Xargs() and XargsParallel() split up their
+// input
+type XargsOptions struct {
+	// MaxArgs is the most lines that are passed to each run of the body,
+	// as $1, $2 and so on. 0 means one line per run.
+	//
+	// It is an emulation of `xargs -n`.
+	MaxArgs int
+
+	// KeepOrder makes XargsParallel() write out each run's output in
+	// the same order as its input, instead of as soon as each run
+	// finishes. Xargs() always keeps everything in order.
+	//
+	// It is an emulation of GNU parallel's `--keep-order`.
+	KeepOrder bool
+}
+
+// Xargs runs the body once for each line of the pipeline's Stdin, or
+// once for each batch of XargsOptions.MaxArgs lines. The lines are the
+// body's positional parameters: $1, $2 and so on. Blank lines are
+// skipped.
+//
+// The body starts with an empty Stdin each time. Its output is written
+// to the pipeline's Stdout and Stderr.
+//
+// Like UNIX `xargs`, it carries on if the body fails. If the body fails
+// for any batch, Xargs() returns StatusXargsFailed and an
+// ErrXargsFailed that lists every batch that failed.
+//
+// It is an emulation of UNIX shell scripting's `... | xargs -n N command`
+func Xargs(body *Sequence, xopts XargsOptions, opts ...*StepOption) *SequenceStep {
+	// build our Scriptish command
+	return NewSequenceStep(
+		func(p *Pipe) (int, error) {
+			// debugging support
+			Tracef("Xargs(%d)", xopts.MaxArgs)
+
+			// all done
+			return runXargs(p, 1, func() *Sequence { return body }, xopts)
+		},
+		opts...,
+	)
+}
+
+// XargsParallel is like Xargs(), except that it runs the body for up to
+// maxProcs batches at the same time.
+//
+// A Sequence can only run one batch at a time, so XargsParallel() calls
+// newBody to create a body for each batch that it runs at the same time.
+// newBody must return a new Sequence (and new steps) every time.
+//
+// Each batch's output is written to the pipeline's Stdout and Stderr
+// when the batch has finished. Use XargsOptions.KeepOrder to write them
+// in the same order as the input.
+//
+// It is an emulation of UNIX shell scripting's
+// `... | xargs -P maxProcs -n N command`
+func XargsParallel(maxProcs int, newBody func() *Sequence, xopts XargsOptions, opts ...*StepOption) *SequenceStep {
+	// build our Scriptish command
+	return NewSequenceStep(
+		func(p *Pipe) (int, error) {
+			// debugging support
+			Tracef("XargsParallel(%d, %d)", maxProcs, xopts.MaxArgs)
+
+			// robustness
+			if maxProcs < 1 {
+				maxProcs = 1
+			}
+
+			// all done
+			return runXargs(p, maxProcs, newBody, xopts)
+		},
+		opts...,
+	)
+}
+
+// xargsBatch is one run of the body
+type xargsBatch struct {
+	// index is where the batch appears in the input
+	index int
+
+	// args are the lines of input that the body is given
+	args []string
+}
+
+// xargsResult is what happened when we ran the body for one batch
+type xargsResult struct {
+	xargsBatch
+
+	stdout     []byte
+	stderr     []byte
+	statusCode int
+	err        error
+}
+
+// runXargs does the work for Xargs() and XargsParallel()
+func runXargs(p *Pipe, maxProcs int, newBody func() *Sequence, xopts XargsOptions) (int, error) {
+	// get our parameters
+	ctx := PipeContext(p)
+	maxArgs := xopts.MaxArgs
+	if maxArgs < 1 {
+		maxArgs = 1
+	}
+
+	batches := make(chan xargsBatch)
+	results := make(chan xargsResult)
+
+	// split our input into batches
+	var ctxErr error
+	go func() {
+		defer close(batches)
+
+		index := 0
+		var args []string
+		for line := range p.Stdin.ReadLines() {
+			// has the sequence been cancelled?
+			ctxErr = ctx.Err()
+			if ctxErr != nil {
+				break
+			}
+
+			// like UNIX xargs, we skip blank lines
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+
+			args = append(args, line)
+			if len(args) == maxArgs {
+				batches <- xargsBatch{index, args}
+				index++
+				args = nil
+			}
+		}
+
+		if len(args) > 0 && ctxErr == nil {
+			batches <- xargsBatch{index, args}
+		}
+	}()
+
+	// run the body
+	var wg sync.WaitGroup
+	for i := 0; i < maxProcs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			body := newBody()
+			for batch := range batches {
+				body.execFromPipe(ctx, p, batch.args...)
+
+				var stdout, stderr bytes.Buffer
+				io.Copy(&stdout, body.Pipe.Stdout)
+				io.Copy(&stderr, body.Pipe.Stderr)
+				statusCode, err := body.StatusError()
+
+				results <- xargsResult{batch, stdout.Bytes(), stderr.Bytes(), statusCode, err}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// write out the results
+	var failures []XargsFailure
+	pending := map[int]xargsResult{}
+	next := 0
+	for result := range results {
+		if !xopts.KeepOrder && maxProcs > 1 {
+			failures = writeXargsResult(p, result, failures)
+			continue
+		}
+
+		// keep everything in order
+		pending[result.index] = result
+		for {
+			result, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			failures = writeXargsResult(p, result, failures)
+			next++
+		}
+	}
+
+	// what happened?
+	if ctxErr != nil {
+		return StatusNotOkay, ErrCancelled{ctxErr}
+	}
+	if len(failures) > 0 {
+		return StatusXargsFailed, ErrXargsFailed{failures}
+	}
+
+	// all done
+	return StatusOkay, nil
+}
+
+// writeXargsResult copies the output of one run of the body to our pipe,
+// and keeps track of the runs that failed
+func writeXargsResult(p *Pipe, result xargsResult, failures []XargsFailure) []XargsFailure {
+	p.Stdout.Write(result.stdout)
+	p.Stderr.Write(result.stderr)
+
+	if result.statusCode == StatusOkay {
+		return failures
+	}
+
+	// debugging support
+	Tracef("Xargs(%#v) => %d", result.args, result.statusCode)
+
+	return append(failures, XargsFailure{
+		Args:       result.args,
+		StatusCode: result.statusCode,
+		Err:        result.err,
+	})
+}
@@ -0,0 +1,335 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestXargsExecutesTheBodyOncePerLine(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := "file: one.txt\nfile: two.txt\nfile: three.yaml\n"
+	pipeline := NewPipeline(
+		EchoSlice([]string{"one.txt", "two.txt", "three.yaml"}),
+		Xargs(
+			NewList(Echo("file: $1")),
+			XargsOptions{},
+		),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := pipeline.Exec().String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestXargsPassesBatchesOfLinesAsPositionalParameters(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := "2: a b\n2: c d\n1: e\n"
+	pipeline := NewPipeline(
+		EchoSlice([]string{"a", "b", "", "c", "d", "e"}),
+		Xargs(
+			NewList(Echo("${#}: $*")),
+			XargsOptions{MaxArgs: 2},
+		),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := pipeline.Exec().String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestXargsSucceedsWhenThereAreNoLines(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	pipeline := NewPipeline(
+		Xargs(
+			NewList(Return(1)),
+			XargsOptions{},
+		),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := pipeline.Exec().StatusError()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, StatusOkay, actualResult)
+}
+
+func TestXargsCarriesOnAndReportsEveryBatchThatFailed(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedOutput := []string{"checked 1", "checked 2", "checked 3", "checked 4"}
+	expectedFailedArgs := [][]string{{"2"}, {"4"}}
+	pipeline := NewPipeline(
+		EchoSlice([]string{"1", "2", "3", "4"}),
+		Xargs(
+			NewList(
+				Echo("checked $1"),
+				TestArith("$1 % 2"),
+			),
+			XargsOptions{},
+		),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	pipeline.Exec()
+	actualOutput, _ := pipeline.Strings()
+	statusCode, err := pipeline.StatusError()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, expectedOutput, actualOutput)
+	assert.Equal(t, StatusXargsFailed, statusCode)
+	var xargsErr ErrXargsFailed
+	if assert.True(t, errors.As(err, &xargsErr)) {
+		actualFailedArgs := [][]string{}
+		for _, failure := range xargsErr.Failures {
+			actualFailedArgs = append(actualFailedArgs, failure.Args)
+			assert.Equal(t, StatusNotOkay, failure.StatusCode)
+		}
+		assert.Equal(t, expectedFailedArgs, actualFailedArgs)
+	}
+}
+
+func TestXargsWorksInAStreamingPipeline(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := []string{"ONE", "TWO"}
+	pipeline := NewStreamingPipeline(
+		EchoSlice([]string{"one", "two"}),
+		Xargs(
+			NewPipeline(
+				Echo("$1"),
+				Tr([]string{"o", "n", "e", "t", "w"}, []string{"O", "N", "E", "T", "W"}),
+			),
+			XargsOptions{},
+		),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := pipeline.Exec().Strings()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestXargsParallelRunsEveryBatch(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	var input, expectedResult []string
+	for i := 0; i < 50; i++ {
+		line := string(rune('a'+i%26)) + string(rune('a'+i/26))
+		input = append(input, line)
+		expectedResult = append(expectedResult, "got "+line)
+	}
+	sort.Strings(expectedResult)
+
+	var bodies int32
+	pipeline := NewPipeline(
+		EchoSlice(input),
+		XargsParallel(
+			4,
+			func() *Sequence {
+				atomic.AddInt32(&bodies, 1)
+				return NewList(Echo("got $1"))
+			},
+			XargsOptions{},
+		),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := pipeline.Exec().Strings()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	sort.Strings(actualResult)
+	assert.Equal(t, expectedResult, actualResult)
+	assert.Equal(t, int32(4), atomic.LoadInt32(&bodies))
+}
+
+func TestXargsParallelCanKeepTheOutputInOrder(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := []string{"0.03 0.02", "0.01 0", "0.02 0.01", "0"}
+	pipeline := NewPipeline(
+		EchoSlice([]string{"0.03", "0.02", "0.01", "0", "0.02", "0.01", "0"}),
+		XargsParallel(
+			3,
+			func() *Sequence {
+				return NewList(
+					Exec([]string{"sleep", "$1"}),
+					Echo("$*"),
+				)
+			},
+			XargsOptions{MaxArgs: 2, KeepOrder: true},
+		),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := pipeline.Exec().Strings()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestXargsParallelErrorsCanBeUnwrapped(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	pipeline := NewPipeline(
+		EchoSlice([]string{"a", "b"}),
+		XargsParallel(
+			2,
+			func() *Sequence {
+				return NewList(Test([]string{"("}))
+			},
+			XargsOptions{},
+		),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := pipeline.Exec().StatusError()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, StatusXargsFailed, actualResult)
+	var syntaxErr ErrTestSyntax
+	assert.True(t, errors.As(err, &syntaxErr))
+}
+
+func TestXargsStopsWhenTheNextStepStopsReading(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := "got y\ngot y\n"
+
+	// `yes` never finishes on its own
+	pipeline := NewStreamingPipeline(
+		Exec([]string{"/usr/bin/env", "yes"}),
+		Xargs(NewList(Echo("got ${1}")), XargsOptions{}),
+		Head(2),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	actualResult, err := pipeline.ExecContext(ctx).String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
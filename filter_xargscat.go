@@ -58,7 +58,7 @@ func XargsCat(opts ...*StepOption) *SequenceStep {
 				Tracef("reading from file %#v", line)
 
 				// can we read the file?
-				contents, err := ioutil.ReadFile(line)
+				contents, err := ioutil.ReadFile(resolvePipePath(p, line))
 				if err != nil {
 					return StatusNotOkay, err
 				}
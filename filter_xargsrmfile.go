@@ -58,7 +58,7 @@ func XargsRmFile(opts ...*StepOption) *SequenceStep {
 			Tracef("XargsRmFile()")
 
 			for line := range p.Stdin.ReadLines() {
-				err := os.Remove(line)
+				err := os.Remove(resolvePipePath(p, line))
 				if err != nil {
 					return StatusNotOkay, err
 				}
@@ -59,7 +59,7 @@ func XargsTestFilepathExists(opts ...*StepOption) *SequenceStep {
 
 			for line := range p.Stdin.ReadLines() {
 				// does the file exist?
-				_, err := os.Stat(line)
+				_, err := os.Stat(resolvePipePath(p, line))
 				if err != nil {
 					// skip to the next
 					continue
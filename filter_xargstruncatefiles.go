@@ -58,7 +58,7 @@ func XargsTruncateFiles(opts ...*StepOption) *SequenceStep {
 
 			for line := range p.Stdin.ReadLines() {
 				// open / create the file
-				fh, err := os.OpenFile(line, os.O_TRUNC|os.O_CREATE|os.O_WRONLY, 0644)
+				fh, err := os.OpenFile(resolvePipePath(p, line), os.O_TRUNC|os.O_CREATE|os.O_WRONLY, 0644)
 				if err != nil {
 					return StatusNotOkay, err
 				}
@@ -0,0 +1,675 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FindPredicate is a test that Find() applies to every path that it
+// finds. Find() only writes out the paths that pass all of its
+// predicates.
+//
+// Some predicates (such as FindMaxDepth()) change how Find() walks the
+// tree instead. Like UNIX `find`, they apply no matter where they
+// appear, and they always pass.
+type FindPredicate struct {
+	// prepare expands the predicate's arguments, and returns the test
+	// to apply to each path
+	prepare func(p *Pipe, settings *findSettings) (findTest, error)
+}
+
+// findTest returns true if the given path passes the predicate
+type findTest func(f *foundPath) (bool, error)
+
+// findAlwaysTrue is the test for predicates that change how Find()
+// walks the tree
+func findAlwaysTrue(f *foundPath) (bool, error) {
+	return true, nil
+}
+
+// newFindSetting creates a predicate that changes how Find() walks
+// the tree
+func newFindSetting(apply func(settings *findSettings)) FindPredicate {
+	return FindPredicate{
+		prepare: func(p *Pipe, settings *findSettings) (findTest, error) {
+			apply(settings)
+			return findAlwaysTrue, nil
+		},
+	}
+}
+
+// newFindStringTest creates a predicate that takes a single argument,
+// which is expanded before the tree is walked
+func newFindStringTest(arg string, build func(expArg string) (findTest, error)) FindPredicate {
+	return FindPredicate{
+		prepare: func(p *Pipe, settings *findSettings) (findTest, error) {
+			expArg, err := expandString(p, arg)
+			if err != nil {
+				return nil, err
+			}
+
+			return build(expArg)
+		},
+	}
+}
+
+// FindAnd passes if all of the given predicates pass. It stops at the
+// first predicate that fails.
+//
+// It is an emulation of UNIX `find`'s `expr1 -a expr2`.
+func FindAnd(preds ...FindPredicate) FindPredicate {
+	return FindPredicate{
+		prepare: func(p *Pipe, settings *findSettings) (findTest, error) {
+			tests, err := prepareFindPredicates(p, settings, preds)
+			if err != nil {
+				return nil, err
+			}
+
+			return func(f *foundPath) (bool, error) {
+				for _, test := range tests {
+					ok, err := test(f)
+					if err != nil || !ok {
+						return false, err
+					}
+				}
+
+				return true, nil
+			}, nil
+		},
+	}
+}
+
+// FindOr passes if any of the given predicates pass. It stops at the
+// first predicate that passes.
+//
+// It is an emulation of UNIX `find`'s `expr1 -o expr2`.
+func FindOr(preds ...FindPredicate) FindPredicate {
+	return FindPredicate{
+		prepare: func(p *Pipe, settings *findSettings) (findTest, error) {
+			tests, err := prepareFindPredicates(p, settings, preds)
+			if err != nil {
+				return nil, err
+			}
+
+			return func(f *foundPath) (bool, error) {
+				for _, test := range tests {
+					ok, err := test(f)
+					if err != nil || ok {
+						return ok, err
+					}
+				}
+
+				return false, nil
+			}, nil
+		},
+	}
+}
+
+// FindNot passes if the given predicate fails.
+//
+// It is an emulation of UNIX `find`'s `! expr`.
+func FindNot(pred FindPredicate) FindPredicate {
+	return FindPredicate{
+		prepare: func(p *Pipe, settings *findSettings) (findTest, error) {
+			test, err := pred.prepare(p, settings)
+			if err != nil {
+				return nil, err
+			}
+
+			return func(f *foundPath) (bool, error) {
+				ok, err := test(f)
+				return !ok, err
+			}, nil
+		},
+	}
+}
+
+// prepareFindPredicates prepares each of the given predicates in turn
+func prepareFindPredicates(p *Pipe, settings *findSettings, preds []FindPredicate) ([]findTest, error) {
+	retval := make([]findTest, len(preds))
+	for i, pred := range preds {
+		test, err := pred.prepare(p, settings)
+		if err != nil {
+			return nil, err
+		}
+		retval[i] = test
+	}
+
+	return retval, nil
+}
+
+// FindType passes if the path is of the given type:
+//
+//   - `b` for a block device
+//   - `c` for a character device
+//   - `d` for a folder
+//   - `f` for a regular file
+//   - `l` for a symbolic link
+//   - `p` for a named pipe
+//   - `s` for a socket
+//
+// You can pass more than one type, separated by commas (eg `f,l`).
+//
+// It is an emulation of UNIX `find`'s `-type`.
+func FindType(fileType string) FindPredicate {
+	return newFindStringTest(fileType, func(expType string) (findTest, error) {
+		var want os.FileMode
+		var regular bool
+		for _, t := range strings.Split(expType, ",") {
+			switch t {
+			case "b":
+				want |= os.ModeDevice
+			case "c":
+				want |= os.ModeCharDevice
+			case "d":
+				want |= os.ModeDir
+			case "f":
+				regular = true
+			case "l":
+				want |= os.ModeSymlink
+			case "p":
+				want |= os.ModeNamedPipe
+			case "s":
+				want |= os.ModeSocket
+			default:
+				return nil, ErrBadFindArg{"-type", expType}
+			}
+		}
+
+		return func(f *foundPath) (bool, error) {
+			mode := f.info.Mode()
+			switch {
+			case mode.IsRegular():
+				return regular, nil
+			case mode&os.ModeCharDevice != 0:
+				return want&os.ModeCharDevice != 0, nil
+			case mode&os.ModeDevice != 0:
+				return want&os.ModeDevice != 0, nil
+			}
+			return mode&os.ModeType&want != 0, nil
+		}, nil
+	})
+}
+
+// FindName passes if the path's last element matches the given glob
+// pattern.
+//
+// It is an emulation of UNIX `find`'s `-name`.
+func FindName(pattern string) FindPredicate {
+	return newFindGlobTest(pattern, false, func(f *foundPath) string {
+		return f.name()
+	})
+}
+
+// FindIName is a case-insensitive version of FindName().
+//
+// It is an emulation of UNIX `find`'s `-iname`.
+func FindIName(pattern string) FindPredicate {
+	return newFindGlobTest(pattern, true, func(f *foundPath) string {
+		return f.name()
+	})
+}
+
+// FindPath passes if the whole path matches the given glob pattern.
+// Like UNIX shells, `*` matches `/` too.
+//
+// It is an emulation of UNIX `find`'s `-path`.
+func FindPath(pattern string) FindPredicate {
+	return newFindGlobTest(pattern, false, func(f *foundPath) string {
+		return f.path
+	})
+}
+
+// FindIPath is a case-insensitive version of FindPath().
+//
+// It is an emulation of UNIX `find`'s `-ipath`.
+func FindIPath(pattern string) FindPredicate {
+	return newFindGlobTest(pattern, true, func(f *foundPath) string {
+		return f.path
+	})
+}
+
+// newFindGlobTest creates a predicate that matches part of the path
+// against a glob pattern
+func newFindGlobTest(pattern string, ignoreCase bool, subject func(f *foundPath) string) FindPredicate {
+	return newFindStringTest(pattern, func(expPattern string) (findTest, error) {
+		reStr := globToRegexp(expPattern)
+		if ignoreCase {
+			reStr = "(?i)" + reStr
+		}
+		re, err := regexp.Compile(reStr)
+		if err != nil {
+			return nil, err
+		}
+
+		return func(f *foundPath) (bool, error) {
+			return re.MatchString(subject(f)), nil
+		}, nil
+	})
+}
+
+// FindRegex passes if the whole path matches the given regular
+// expression. It uses Golang's regexp syntax.
+//
+// It is an emulation of UNIX `find`'s `-regex`.
+func FindRegex(regex string) FindPredicate {
+	return newFindRegexTest(regex, "")
+}
+
+// FindIRegex is a case-insensitive version of FindRegex().
+//
+// It is an emulation of UNIX `find`'s `-iregex`.
+func FindIRegex(regex string) FindPredicate {
+	return newFindRegexTest(regex, "(?i)")
+}
+
+func newFindRegexTest(regex string, flags string) FindPredicate {
+	return newFindStringTest(regex, func(expRegex string) (findTest, error) {
+		re, err := regexp.Compile(flags + `^(?:` + expRegex + `)$`)
+		if err != nil {
+			return nil, err
+		}
+
+		return func(f *foundPath) (bool, error) {
+			return re.MatchString(f.path), nil
+		}, nil
+	})
+}
+
+// findSizeUnits are the suffixes that FindSize() understands
+var findSizeUnits = map[byte]int64{
+	'b': 512,
+	'c': 1,
+	'w': 2,
+	'k': 1024,
+	'M': 1024 * 1024,
+	'G': 1024 * 1024 * 1024,
+}
+
+// FindSize passes if the size of the path matches the given size.
+//
+// The size is a number, followed by an optional unit: `c` (bytes), `w`
+// (2 bytes), `b` (512 bytes; the default), `k` (KiB), `M` (MiB) or `G`
+// (GiB). Like UNIX `find`, the path's size is rounded up to the next
+// unit. Put `+` in front for "more than", or `-` for "less than".
+//
+// It is an emulation of UNIX `find`'s `-size`.
+func FindSize(size string) FindPredicate {
+	return newFindStringTest(size, func(expSize string) (findTest, error) {
+		cmp, digits := splitFindNumber(expSize)
+		unit := int64(512)
+		if len(digits) > 0 {
+			if u, ok := findSizeUnits[digits[len(digits)-1]]; ok {
+				unit = u
+				digits = digits[:len(digits)-1]
+			}
+		}
+		n, err := strconv.ParseInt(digits, 10, 64)
+		if err != nil || n < 0 {
+			return nil, ErrBadFindArg{"-size", expSize}
+		}
+
+		return func(f *foundPath) (bool, error) {
+			units := (f.info.Size() + unit - 1) / unit
+			return compareFindNumber(cmp, units, n), nil
+		}, nil
+	})
+}
+
+// FindMtime passes if the path was last modified the given number of
+// days ago. Like UNIX `find`, any fraction of a day is ignored. Put `+`
+// in front for "more than", or `-` for "less than".
+//
+// It is an emulation of UNIX `find`'s `-mtime`.
+func FindMtime(days string) FindPredicate {
+	return FindPredicate{
+		prepare: func(p *Pipe, settings *findSettings) (findTest, error) {
+			expDays, err := expandString(p, days)
+			if err != nil {
+				return nil, err
+			}
+
+			cmp, digits := splitFindNumber(expDays)
+			n, err := strconv.ParseInt(digits, 10, 64)
+			if err != nil || n < 0 {
+				return nil, ErrBadFindArg{"-mtime", expDays}
+			}
+
+			now := settings.now
+			return func(f *foundPath) (bool, error) {
+				age := int64(now.Sub(f.info.ModTime()) / (24 * time.Hour))
+				return compareFindNumber(cmp, age, n), nil
+			}, nil
+		},
+	}
+}
+
+// FindNewer passes if the path was modified more recently than the
+// given file.
+//
+// It is an emulation of UNIX `find`'s `-newer`.
+func FindNewer(filepath string) FindPredicate {
+	return FindPredicate{
+		prepare: func(p *Pipe, settings *findSettings) (findTest, error) {
+			expFilepath, err := expandString(p, filepath)
+			if err != nil {
+				return nil, err
+			}
+
+			info, err := os.Stat(resolvePipePath(p, expFilepath))
+			if err != nil {
+				return nil, err
+			}
+
+			return func(f *foundPath) (bool, error) {
+				return f.info.ModTime().After(info.ModTime()), nil
+			}, nil
+		},
+	}
+}
+
+// FindPerm passes if the path's permissions match the given mode. The
+// mode is either octal (eg `644`), or symbolic (eg `u=rw,go=r`). Put `-`
+// in front to pass if all of the given bits are set, or `/` in front to
+// pass if any of the given bits are set.
+//
+// It is an emulation of UNIX `find`'s `-perm`. Like `find`, a symbolic
+// mode is applied to a path that has no permissions at all, and `X`
+// only adds execute permission for folders.
+func FindPerm(mode string) FindPredicate {
+	return newFindStringTest(mode, func(expMode string) (findTest, error) {
+		cmp := byte(0)
+		digits := expMode
+		if len(digits) > 0 && (digits[0] == '-' || digits[0] == '/') {
+			cmp = digits[0]
+			digits = digits[1:]
+		}
+
+		var filePerm, dirPerm uint32
+		perm, err := strconv.ParseUint(digits, 8, 32)
+		switch {
+		case err == nil && perm <= 07777:
+			filePerm = uint32(perm)
+			dirPerm = filePerm
+		case err == nil:
+			return nil, ErrBadFindArg{"-perm", expMode}
+		default:
+			var ok bool
+			filePerm, ok = parseSymbolicPerm(digits, false)
+			dirPerm, _ = parseSymbolicPerm(digits, true)
+			if !ok {
+				return nil, ErrBadFindArg{"-perm", expMode}
+			}
+		}
+
+		return func(f *foundPath) (bool, error) {
+			perm := filePerm
+			if f.info.IsDir() {
+				perm = dirPerm
+			}
+
+			actual := unixPermBits(f.info.Mode())
+			switch cmp {
+			case '-':
+				return actual&perm == perm, nil
+			case '/':
+				return perm == 0 || actual&perm != 0, nil
+			}
+			return actual == perm, nil
+		}, nil
+	})
+}
+
+// permWhoBits are the permission bits that each `who` of a symbolic mode
+// can change
+var permWhoBits = map[byte]uint32{
+	'u': 04700,
+	'g': 02070,
+	'o': 01007,
+	'a': 07777,
+}
+
+// permLetterBits are the permission bits that each permission letter of
+// a symbolic mode stands for
+var permLetterBits = map[byte]uint32{
+	'r': 0444,
+	'w': 0222,
+	'x': 0111,
+	's': 06000,
+	't': 01000,
+}
+
+// parseSymbolicPerm applies the given symbolic mode (eg `u+x,g=rw`) to a
+// path that has no permissions, and returns the permission bits that
+// the path would end up with
+//
+// isDir tells us whether `X` adds execute permission or not. ok is false
+// if the mode is not valid.
+func parseSymbolicPerm(mode string, isDir bool) (retval uint32, ok bool) {
+	for _, clause := range strings.Split(mode, ",") {
+		// who are we changing?
+		i := 0
+		affected := uint32(0)
+		for ; i < len(clause) && permWhoBits[clause[i]] != 0; i++ {
+			affected |= permWhoBits[clause[i]]
+		}
+		if affected == 0 {
+			affected = 07777
+		}
+
+		// there must be at least one change
+		if i >= len(clause) {
+			return 0, false
+		}
+
+		for i < len(clause) {
+			op := clause[i]
+			if op != '+' && op != '-' && op != '=' {
+				return 0, false
+			}
+			i++
+
+			// what are we changing them to?
+			value := uint32(0)
+			if i < len(clause) && strings.IndexByte("ugo", clause[i]) >= 0 {
+				value = copyPermBits(retval, clause[i])
+				i++
+			} else {
+				for ; i < len(clause) && strings.IndexByte("rwxXst", clause[i]) >= 0; i++ {
+					switch {
+					case clause[i] != 'X':
+						value |= permLetterBits[clause[i]]
+					case isDir || retval&0111 != 0:
+						value |= 0111
+					}
+				}
+			}
+			value &= affected
+
+			switch op {
+			case '+':
+				retval |= value
+			case '-':
+				retval &^= value
+			case '=':
+				retval = retval&^affected | value
+			}
+		}
+	}
+
+	return retval, true
+}
+
+// copyPermBits returns the `rwx` bits of the given `who` in mode, for
+// the `u=g` form of a symbolic mode
+func copyPermBits(mode uint32, who byte) uint32 {
+	var bits uint32
+	switch who {
+	case 'u':
+		bits = mode >> 6 & 07
+	case 'g':
+		bits = mode >> 3 & 07
+	default:
+		bits = mode & 07
+	}
+
+	return bits<<6 | bits<<3 | bits
+}
+
+// unixPermBits converts a Golang file mode into UNIX permission bits
+func unixPermBits(mode os.FileMode) uint32 {
+	retval := uint32(mode.Perm())
+	if mode&os.ModeSetuid != 0 {
+		retval |= 04000
+	}
+	if mode&os.ModeSetgid != 0 {
+		retval |= 02000
+	}
+	if mode&os.ModeSticky != 0 {
+		retval |= 01000
+	}
+
+	return retval
+}
+
+// FindEmpty passes if the path is an empty file or an empty folder.
+//
+// It is an emulation of UNIX `find`'s `-empty`.
+func FindEmpty() FindPredicate {
+	return FindPredicate{
+		prepare: func(p *Pipe, settings *findSettings) (findTest, error) {
+			return func(f *foundPath) (bool, error) {
+				switch {
+				case f.info.Mode().IsRegular():
+					return f.info.Size() == 0, nil
+				case f.info.IsDir():
+					dir, err := os.Open(f.osPath)
+					if err != nil {
+						return false, err
+					}
+					defer dir.Close()
+					names, _ := dir.Readdirnames(1)
+					return len(names) == 0, nil
+				}
+
+				return false, nil
+			}, nil
+		},
+	}
+}
+
+// FindPrune stops Find() from looking inside the folder that it is
+// looking at. It always passes.
+//
+// Combine it with FindNot() to skip a folder altogether:
+//
+//	FindNot(FindAnd(FindPath("./vendor"), FindPrune()))
+//
+// It is an emulation of UNIX `find`'s `-prune`.
+func FindPrune() FindPredicate {
+	return FindPredicate{
+		prepare: func(p *Pipe, settings *findSettings) (findTest, error) {
+			return func(f *foundPath) (bool, error) {
+				f.prune = true
+				return true, nil
+			}, nil
+		},
+	}
+}
+
+// FindMaxDepth stops Find() from looking more than the given number of
+// folders below the starting point. 0 means only look at the starting
+// point itself.
+//
+// It is an emulation of UNIX `find`'s `-maxdepth`.
+func FindMaxDepth(depth int) FindPredicate {
+	return newFindSetting(func(settings *findSettings) {
+		settings.maxDepth = depth
+	})
+}
+
+// FindMinDepth stops Find() from testing or writing out anything that
+// is less than the given number of folders below the starting point.
+// 1 means everything except the starting point itself.
+//
+// It is an emulation of UNIX `find`'s `-mindepth`.
+func FindMinDepth(depth int) FindPredicate {
+	return newFindSetting(func(settings *findSettings) {
+		settings.minDepth = depth
+	})
+}
+
+// FindFollowSymlinks makes Find() follow symbolic links. Predicates
+// look at whatever the link points to, and Find() looks inside linked
+// folders. Only broken links are treated as links.
+//
+// It is an emulation of UNIX `find -L`.
+func FindFollowSymlinks() FindPredicate {
+	return newFindSetting(func(settings *findSettings) {
+		settings.followSymlinks = true
+	})
+}
+
+// splitFindNumber splits the `+` or `-` off the front of a number
+func splitFindNumber(input string) (byte, string) {
+	if len(input) > 0 && (input[0] == '+' || input[0] == '-') {
+		return input[0], input[1:]
+	}
+
+	return 0, input
+}
+
+// compareFindNumber compares two numbers, the way that UNIX `find`
+// does
+func compareFindNumber(cmp byte, actual, expected int64) bool {
+	switch cmp {
+	case '+':
+		return actual > expected
+	case '-':
+		return actual < expected
+	}
+
+	return actual == expected
+}
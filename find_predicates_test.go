@@ -0,0 +1,250 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// makeFindFolder creates a tree of files for testing Find() against
+func makeFindFolder(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "scriptish-find-")
+	assert.Nil(t, err)
+
+	files := map[string]string{
+		"a.go":             "package a\n",
+		"b.txt":            "",
+		"C.GO":             strings.Repeat("x", 3000),
+		"old.txt":          "old\n",
+		"script.sh":        "#!/bin/sh\n",
+		"secret":           "shh\n",
+		"sub/d.go":         "package sub\n",
+		"sub/deeper/e.go":  "package deeper\n",
+		"vendor/x.go":      "package x\n",
+		"vendor/lib/y.txt": "y\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		assert.Nil(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.Nil(t, ioutil.WriteFile(path, []byte(content), 0644))
+		assert.Nil(t, os.Chmod(path, 0644))
+	}
+	assert.Nil(t, os.Mkdir(filepath.Join(dir, "emptydir"), 0755))
+	assert.Nil(t, os.Chmod(filepath.Join(dir, "script.sh"), 0755))
+	assert.Nil(t, os.Chmod(filepath.Join(dir, "secret"), 0600))
+	assert.Nil(t, os.Symlink("a.go", filepath.Join(dir, "link")))
+	assert.Nil(t, os.Symlink("sub", filepath.Join(dir, "dirlink")))
+	assert.Nil(t, os.Symlink("missing", filepath.Join(dir, "broken")))
+
+	past := time.Now().Add(-10 * 24 * time.Hour)
+	assert.Nil(t, os.Chtimes(filepath.Join(dir, "old.txt"), past, past))
+
+	return dir
+}
+
+func TestFindPredicatesMatchFind(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	findCmd, err := exec.LookPath("find")
+	if err != nil {
+		t.Skip("find not found")
+	}
+
+	dir := makeFindFolder(t)
+	defer os.RemoveAll(dir)
+
+	testData := []struct {
+		findArgs []string
+		preds    []FindPredicate
+	}{
+		{[]string{"."}, nil},
+		{[]string{".", "-type", "f"}, []FindPredicate{FindType("f")}},
+		{[]string{".", "-type", "d"}, []FindPredicate{FindType("d")}},
+		{[]string{".", "-type", "l"}, []FindPredicate{FindType("l")}},
+		{[]string{".", "-type", "f,l"}, []FindPredicate{FindType("f,l")}},
+		{[]string{".", "-name", "*.go"}, []FindPredicate{FindName("*.go")}},
+		{[]string{".", "-iname", "*.go"}, []FindPredicate{FindIName("*.go")}},
+		{[]string{".", "-path", "./sub/*"}, []FindPredicate{FindPath("./sub/*")}},
+		{[]string{".", "-ipath", "./SUB/*"}, []FindPredicate{FindIPath("./SUB/*")}},
+		{[]string{".", "-regex", `.*/[a-c]\.go`}, []FindPredicate{FindRegex(`.*/[a-c]\.go`)}},
+		{[]string{".", "-iregex", `.*/[a-c]\.go`}, []FindPredicate{FindIRegex(`.*/[a-c]\.go`)}},
+		{[]string{".", "-type", "f", "-size", "+1k"}, []FindPredicate{FindType("f"), FindSize("+1k")}},
+		{[]string{".", "-type", "f", "-size", "-1k"}, []FindPredicate{FindType("f"), FindSize("-1k")}},
+		{[]string{".", "-type", "f", "-size", "1"}, []FindPredicate{FindType("f"), FindSize("1")}},
+		{[]string{".", "-size", "10c"}, []FindPredicate{FindSize("10c")}},
+		{[]string{".", "-mtime", "+7"}, []FindPredicate{FindMtime("+7")}},
+		{[]string{".", "-type", "f", "-mtime", "-1"}, []FindPredicate{FindType("f"), FindMtime("-1")}},
+		{[]string{".", "-newer", "old.txt"}, []FindPredicate{FindNewer("old.txt")}},
+		{[]string{".", "-type", "f", "-perm", "644"}, []FindPredicate{FindType("f"), FindPerm("644")}},
+		{[]string{".", "-perm", "-100"}, []FindPredicate{FindPerm("-100")}},
+		{[]string{".", "-type", "f", "-perm", "/044"}, []FindPredicate{FindType("f"), FindPerm("/044")}},
+		{[]string{".", "-type", "f", "-perm", "u=rw,go=r"}, []FindPredicate{FindType("f"), FindPerm("u=rw,go=r")}},
+		{[]string{".", "-perm", "-u+x"}, []FindPredicate{FindPerm("-u+x")}},
+		{[]string{".", "-perm", "/g+w,o+x"}, []FindPredicate{FindPerm("/g+w,o+x")}},
+		{[]string{".", "-type", "f", "-perm", "-u=rw"}, []FindPredicate{FindType("f"), FindPerm("-u=rw")}},
+		{[]string{".", "-perm", "a=rX,u+w"}, []FindPredicate{FindPerm("a=rX,u+w")}},
+		{[]string{".", "-perm", "-g+r-w"}, []FindPredicate{FindPerm("-g+r-w")}},
+		{[]string{".", "-perm", "u=rw,g=u-w,o=g"}, []FindPredicate{FindPerm("u=rw,g=u-w,o=g")}},
+		{[]string{".", "-perm", "=rwx,go-w"}, []FindPredicate{FindPerm("=rwx,go-w")}},
+		{[]string{".", "-empty"}, []FindPredicate{FindEmpty()}},
+		{[]string{".", "-maxdepth", "1"}, []FindPredicate{FindMaxDepth(1)}},
+		{[]string{".", "-maxdepth", "0"}, []FindPredicate{FindMaxDepth(0)}},
+		{[]string{".", "-mindepth", "2"}, []FindPredicate{FindMinDepth(2)}},
+		{
+			[]string{".", "!", "(", "-path", "./vendor", "-prune", ")", "-type", "f"},
+			[]FindPredicate{FindNot(FindAnd(FindPath("./vendor"), FindPrune())), FindType("f")},
+		},
+		{
+			[]string{".", "-name", "*.go", "-o", "-name", "*.txt"},
+			[]FindPredicate{FindOr(FindName("*.go"), FindName("*.txt"))},
+		},
+		{[]string{".", "-not", "-name", "*.go"}, []FindPredicate{FindNot(FindName("*.go"))}},
+		{[]string{"-L", ".", "-type", "f"}, []FindPredicate{FindFollowSymlinks(), FindType("f")}},
+		{[]string{"-L", ".", "-type", "l"}, []FindPredicate{FindFollowSymlinks(), FindType("l")}},
+		{[]string{"sub", "-name", "*.go"}, []FindPredicate{FindName("*.go")}},
+		{[]string{"./sub/", "-type", "d"}, []FindPredicate{FindType("d")}},
+	}
+
+	for _, testCase := range testData {
+		cmd := exec.Command(findCmd, testCase.findArgs...)
+		cmd.Dir = dir
+		output, err := cmd.Output()
+		assert.Nil(t, err, testCase.findArgs)
+		expectedResult := strings.Split(strings.TrimSpace(string(output)), "\n")
+		sort.Strings(expectedResult)
+
+		root := testCase.findArgs[0]
+		if root == "-L" {
+			root = testCase.findArgs[1]
+		}
+		list := NewList(
+			Cd(dir),
+			Find(root, testCase.preds),
+		)
+
+		// ----------------------------------------------------------------
+		// perform the change
+
+		actualResult, err := list.Exec().Strings()
+
+		// ----------------------------------------------------------------
+		// test the results
+
+		assert.Nil(t, err, testCase.findArgs)
+		sort.Strings(actualResult)
+		assert.Equal(t, expectedResult, actualResult, testCase.findArgs)
+	}
+}
+
+func TestFindPredicatesExpandTheirArguments(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	dir := makeFindFolder(t)
+	defer os.RemoveAll(dir)
+
+	expectedResult := []string{"./sub/d.go", "./sub/deeper/e.go"}
+	list := NewList(
+		Cd(dir),
+		Find(".", []FindPredicate{FindPath("./$1/*"), FindName("*.$2")}),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec("sub", "go").Strings()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestFindPredicatesReturnErrBadFindArg(t *testing.T) {
+	t.Parallel()
+
+	testData := []struct {
+		pred        FindPredicate
+		expectedErr error
+	}{
+		{FindType("q"), ErrBadFindArg{"-type", "q"}},
+		{FindSize("10x"), ErrBadFindArg{"-size", "10x"}},
+		{FindSize(""), ErrBadFindArg{"-size", ""}},
+		{FindMtime("yesterday"), ErrBadFindArg{"-mtime", "yesterday"}},
+		{FindPerm("u+q"), ErrBadFindArg{"-perm", "u+q"}},
+		{FindPerm("u"), ErrBadFindArg{"-perm", "u"}},
+		{FindPerm("x+u"), ErrBadFindArg{"-perm", "x+u"}},
+		{FindPerm("/u+x,"), ErrBadFindArg{"-perm", "/u+x,"}},
+		{FindPerm("17777"), ErrBadFindArg{"-perm", "17777"}},
+	}
+
+	for _, testCase := range testData {
+		// ----------------------------------------------------------------
+		// setup your test
+
+		pipeline := NewPipeline(Find(".", []FindPredicate{testCase.pred}))
+
+		// ----------------------------------------------------------------
+		// perform the change
+
+		actualResult, err := pipeline.Exec().StatusError()
+
+		// ----------------------------------------------------------------
+		// test the results
+
+		assert.Equal(t, testCase.expectedErr, err)
+		assert.Equal(t, StatusNotOkay, actualResult)
+	}
+}
@@ -45,6 +45,7 @@ func NewList(steps ...*SequenceStep) *List {
 
 	// tell the underlying sequence how we want these commands to run
 	retval.Controller = ListController(retval)
+	retval.newController = ListController
 
 	// all done
 	return retval
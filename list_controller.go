@@ -41,6 +41,10 @@ package scriptish
 
 // ListController executes a sequence of commands as if they were
 // a UNIX shell list
+//
+// Every step is executed, even if an earlier step fails - unless the
+// list is running with Errexit switched on, or a step fails because of
+// Nounset.
 func ListController(sq *Sequence) SequenceController {
 	return func() {
 		// do we have a list to play with?
@@ -48,10 +52,24 @@ func ListController(sq *Sequence) SequenceController {
 			return
 		}
 
+		// we start with no results
+		sq.stepResults = nil
+
 		// execute everything in our pipeline
-		for _, step := range sq.Steps {
+		for i := range sq.Steps {
+			// have we been cancelled?
+			if checkPipeContext(sq.Pipe) {
+				return
+			}
+
 			// run the next step
-			step.RunStep(sq.Pipe)
+			resetIgnoreErrexit(sq.Pipe)
+			result := runStepWithResult(sq.Steps[i], i, sq.Pipe)
+			sq.stepResults = append(sq.stepResults, result)
+
+			// like UNIX shells, the next step can see how this
+			// step went
+			setLastStatusCode(sq, result.StatusCode)
 
 			// debugging support
 			statusCode := sq.StatusCode()
@@ -62,6 +80,12 @@ func ListController(sq *Sequence) SequenceController {
 			if err != nil {
 				Tracef("error: %s", err.Error())
 			}
+
+			// are we allowed to carry on?
+			if listMustStop(sq, i) {
+				Tracef("not executing any more steps")
+				return
+			}
 		}
 	}
 }
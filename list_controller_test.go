@@ -40,6 +40,7 @@
 package scriptish
 
 import (
+	"context"
 	"errors"
 	"testing"
 
@@ -191,3 +192,273 @@ func TestListControllerWritesErrorsToTheTraceOutput(t *testing.T) {
 
 	assert.Equal(t, expectedResult, actualResult)
 }
+
+func TestListControllerStopsWhenTheContextIsCancelled(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	expectedResult := "hello world\n"
+	op1 := NewSequenceStep(
+		func(p *Pipe) (int, error) {
+			p.Stdout.WriteString("hello world\n")
+
+			// nothing after this step should run
+			cancel()
+
+			return StatusOkay, nil
+		},
+	)
+	list := NewList(
+		op1,
+		Echo("this should not be seen"),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.ExecContext(ctx).String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, expectedResult, actualResult)
+	_, ok := err.(ErrCancelled)
+	assert.True(t, ok)
+}
+
+func TestListControllerRunsEveryStepWhenErrexitIsSwitchedOff(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := "after\n"
+	list := NewList(
+		TestFilepathExists("./testdata/missing"),
+		Echo("after"),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec().String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestListControllerStopsAtTheFirstFailureWhenErrexitIsSwitchedOn(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := "before\n"
+	expectedErr := errors.New("step failed")
+	list := NewList(
+		Echo("before"),
+		NewSequenceStep(
+			func(p *Pipe) (int, error) {
+				return 3, expectedErr
+			},
+		),
+		Echo("this should not be seen"),
+	)
+	list.ShellOptions = &ShellOptions{Errexit: true}
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec().String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, expectedErr, err)
+	assert.Equal(t, 3, list.StatusCode())
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestListControllerUsesThePackageWideErrexitSetting(t *testing.T) {
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := ""
+	list := NewList(
+		TestFilepathExists("./testdata/missing"),
+		Echo("this should not be seen"),
+	)
+
+	GetShellOptions().Errexit = true
+	defer func() {
+		GetShellOptions().Errexit = false
+	}()
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec().String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Error(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestListControllerErrexitIgnoresFailuresDealtWithByAndOr(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := "or\nafter\n"
+	list := NewList(
+		TestFilepathExists("./testdata/missing"),
+		And(NewList(Echo("this should not be seen"))),
+		Or(NewList(Echo("or"))),
+		Echo("after"),
+	)
+	list.ShellOptions = &ShellOptions{Errexit: true}
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec().String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestListControllerErrexitStopsAfterAFailedAnd(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := "before\n"
+	list := NewList(
+		Echo("before"),
+		And(NewList(TestFilepathExists("./testdata/missing"))),
+		Echo("this should not be seen"),
+	)
+	list.ShellOptions = &ShellOptions{Errexit: true}
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec().String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Error(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestListControllerErrexitIgnoresFailedConditions(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := "condition\nbody\nafter\n"
+	list := NewList(
+		If(
+			NewList(
+				TestFilepathExists("./testdata/missing"),
+				Echo("condition"),
+			),
+			NewList(Echo("body")),
+		),
+		If(
+			NewList(TestFilepathExists("./testdata/missing")),
+			NewList(Echo("this should not be seen")),
+		),
+		Echo("after"),
+	)
+	list.ShellOptions = &ShellOptions{Errexit: true}
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec().String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestListControllerErrexitIsInheritedByNestedLists(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := "before\n"
+	list := NewList(
+		RunList(
+			NewList(
+				Echo("before"),
+				TestFilepathExists("./testdata/missing"),
+				Echo("this should not be seen"),
+			),
+		),
+		Echo("this should not be seen either"),
+	)
+	list.ShellOptions = &ShellOptions{Errexit: true}
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec().String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Error(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestListControllerNestedListsCanSwitchOffErrexit(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := "before\nafter\n"
+	nested := NewList(
+		TestFilepathExists("./testdata/missing"),
+		Echo("before"),
+	)
+	nested.ShellOptions = &ShellOptions{}
+	list := NewList(
+		RunList(nested),
+		Echo("after"),
+	)
+	list.ShellOptions = &ShellOptions{Errexit: true}
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec().String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
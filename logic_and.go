@@ -51,7 +51,7 @@ import "io"
 // It is an emulation of UNIX shell scripting's `list1 && command`
 func And(sq *Sequence, opts ...*StepOption) *SequenceStep {
 	// we're going to wrap our sequences up as a Scriptish Command
-	return NewSequenceStep(
+	retval := NewSequenceStep(
 		func(p *Pipe) (int, error) {
 			// do we need to do anything?
 			statusCode, err := p.StatusError()
@@ -59,6 +59,10 @@ func And(sq *Sequence, opts ...*StepOption) *SequenceStep {
 				// debugging support
 				Tracef("And(): not executing the given sequence")
 
+				// like UNIX shells, Errexit only cares about the
+				// command after the last `&&`
+				ignoreErrexit(p)
+
 				// make sure we do not lose the output of the sequence so far
 				p.DrainStdinToStdout()
 
@@ -73,7 +77,7 @@ func And(sq *Sequence, opts ...*StepOption) *SequenceStep {
 			params := getParamsFromEnv(p.Env)
 
 			// run it
-			sq.Exec(params...)
+			sq.execFromPipe(PipeContext(p), p, params...)
 
 			// copy the results into our pipe
 			io.Copy(p.Stdout, sq.Pipe.Stdout)
@@ -84,4 +88,11 @@ func And(sq *Sequence, opts ...*StepOption) *SequenceStep {
 		},
 		opts...,
 	)
+
+	// Errexit needs to know that the previous step's failure is
+	// dealt with by us
+	retval.isAndOr = true
+
+	// all done
+	return retval
 }
@@ -0,0 +1,160 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import "io"
+
+// CaseArm is one of the choices in a Case() statement.
+//
+// Body is executed if the subject of the Case() matches any of the
+// Patterns. Patterns are UNIX shell glob patterns, and are expanded
+// before they are matched. Body can be nil, if there is nothing to do
+// for these patterns.
+type CaseArm struct {
+	Patterns []string
+	Body     *Sequence
+
+	// isDefault is true for the arm created by DefaultArm()
+	isDefault bool
+}
+
+// DefaultArm creates the CaseArm that a Case() statement uses when none
+// of the other arms match.
+//
+// It is used no matter where it appears in the list of arms.
+func DefaultArm(body *Sequence) CaseArm {
+	return CaseArm{
+		Body:      body,
+		isDefault: true,
+	}
+}
+
+// Case executes the body of the first arm that has a pattern that
+// matches the subject. If no arms match, it executes the body of the
+// DefaultArm(), if there is one.
+//
+// The subject and the patterns are expanded before they are matched.
+//
+// The body starts with an empty Stdin, and is given the caller's
+// positional parameters. Its output is written back to the Stdout and
+// Stderr of the calling list or pipeline - along with the StatusCode()
+// and Error(). If no body is executed, Case() succeeds.
+//
+// It is an emulation of UNIX shell scripting's
+// `case subject in pattern|pattern) body ;; *) default ;; esac`
+func Case(subject string, arms []CaseArm, opts ...*StepOption) *SequenceStep {
+	// build our Scriptish command
+	return NewSequenceStep(
+		func(p *Pipe) (int, error) {
+			// expand our input
+			expSubject, err := expandString(p, subject)
+
+			// debugging support
+			Tracef("Case(%#v)", subject)
+			Tracef("=> Case(%#v)", expSubject)
+
+			if err != nil {
+				return StatusNotOkay, err
+			}
+
+			// which arm do we want?
+			chosen, err := chooseCaseArm(p, expSubject, arms)
+			if err != nil {
+				return StatusNotOkay, err
+			}
+
+			// like UNIX shells, we succeed if there is nothing to do
+			if chosen == nil || chosen.Body == nil {
+				Tracef("Case(): no body to execute")
+				return StatusOkay, nil
+			}
+
+			// run it
+			params := getParamsFromEnv(p.Env)
+			chosen.Body.execFromPipe(PipeContext(p), p, params...)
+
+			// copy the results into our pipe
+			io.Copy(p.Stdout, chosen.Body.Pipe.Stdout)
+			io.Copy(p.Stderr, chosen.Body.Pipe.Stderr)
+
+			// all done
+			return chosen.Body.StatusError()
+		},
+		opts...,
+	)
+}
+
+// chooseCaseArm returns the first arm that matches the subject, or the
+// default arm if there is no match
+//
+// It returns nil if no arms match, and there is no default arm.
+func chooseCaseArm(p *Pipe, subject string, arms []CaseArm) (*CaseArm, error) {
+	var defaultArm *CaseArm
+
+	for i := range arms {
+		arm := &arms[i]
+
+		// we only use the default arm if nothing else matches
+		if arm.isDefault {
+			if defaultArm == nil {
+				defaultArm = arm
+			}
+			continue
+		}
+
+		for _, pattern := range arm.Patterns {
+			// we keep the backslashes, so that `\*` only matches `*`
+			expPattern, err := expandKeepingEscapes(p, pattern)
+			if err != nil {
+				return nil, err
+			}
+			matched, err := matchGlob(expPattern, subject)
+			if err != nil {
+				return nil, err
+			}
+			if matched {
+				Tracef("Case(): matched %#v", expPattern)
+				return arm, nil
+			}
+		}
+	}
+
+	return defaultArm, nil
+}
@@ -0,0 +1,405 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCaseExecutesTheFirstMatchingArm(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := "archive\n"
+	list := NewList(
+		Case(
+			"$1",
+			[]CaseArm{
+				{
+					Patterns: []string{"start", "stop"},
+					Body:     NewList(Echo("service")),
+				},
+				{
+					Patterns: []string{"*.tar.gz", "*.tgz"},
+					Body:     NewList(Echo("archive")),
+				},
+				{
+					Patterns: []string{"backup.*"},
+					Body:     NewList(Echo("backup")),
+				},
+			},
+		),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec("backup.tar.gz").String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestCaseExecutesTheDefaultArmWhenNothingMatches(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := "unknown command: restart\n"
+	list := NewList(
+		Case(
+			"$1",
+			[]CaseArm{
+				DefaultArm(NewList(Echo("unknown command: $1"))),
+				{
+					Patterns: []string{"start", "stop"},
+					Body:     NewList(Echo("service")),
+				},
+			},
+		),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec("restart").String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestCaseSkipsTheDefaultArmWhenAnotherArmMatches(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := "service\n"
+	list := NewList(
+		Case(
+			"$1",
+			[]CaseArm{
+				DefaultArm(NewList(Echo("unknown command: $1"))),
+				{
+					Patterns: []string{"start", "stop"},
+					Body:     NewList(Echo("service")),
+				},
+			},
+		),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec("stop").String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestCaseExpandsThePatterns(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := "same\n"
+	list := NewList(
+		Case(
+			"$1",
+			[]CaseArm{
+				{
+					Patterns: []string{"$2"},
+					Body:     NewList(Echo("same")),
+				},
+				DefaultArm(NewList(Echo("different"))),
+			},
+		),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec("hello", "hel*").String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestCaseTreatsEscapedGlobCharactersAsLiterals(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not found")
+	}
+
+	testData := []struct {
+		pattern string
+		subject string
+	}{
+		{`\*`, "abc"},
+		{`\*`, "*"},
+		{`[*]`, "abc"},
+		{`[*]`, "*"},
+		{`\?`, "a"},
+		{`\?`, "?"},
+		{`a\*b`, "axxb"},
+		{`a\*b`, "a*b"},
+		{`\[ab]`, "a"},
+		{`\[ab]`, "[ab]"},
+		{`$2\*`, "v1x"},
+		{`$2\*`, "v1*"},
+	}
+
+	for _, testCase := range testData {
+		cmd := exec.Command(
+			bash,
+			"-c",
+			`case "$1" in `+testCase.pattern+`) echo matched;; *) echo different;; esac`,
+			"bash",
+			testCase.subject,
+			"v1",
+		)
+		expectedResult, err := cmd.Output()
+		assert.Nil(t, err, testCase.pattern)
+
+		list := NewList(
+			Case(
+				"$1",
+				[]CaseArm{
+					{
+						Patterns: []string{testCase.pattern},
+						Body:     NewList(Echo("matched")),
+					},
+					DefaultArm(NewList(Echo("different"))),
+				},
+			),
+		)
+
+		// ----------------------------------------------------------------
+		// perform the change
+
+		actualResult, err := list.Exec(testCase.subject, "v1").String()
+
+		// ----------------------------------------------------------------
+		// test the results
+
+		assert.Nil(t, err, testCase.pattern)
+		assert.Equal(t, string(expectedResult), actualResult, testCase.pattern+" vs "+testCase.subject)
+	}
+}
+
+func TestCaseSucceedsWhenNothingMatches(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	list := NewList(
+		Case(
+			"$1",
+			[]CaseArm{
+				{
+					Patterns: []string{"start"},
+					Body:     NewList(Echo("service")),
+				},
+			},
+		),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec("restart").String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, "", actualResult)
+	assert.Equal(t, StatusOkay, list.StatusCode())
+}
+
+func TestCaseAcceptsArmsWithNoBody(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	list := NewList(
+		Case(
+			"$1",
+			[]CaseArm{
+				{
+					Patterns: []string{"start"},
+				},
+				DefaultArm(NewList(Echo("unknown"))),
+			},
+		),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec("start").String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, "", actualResult)
+}
+
+func TestCaseReturnsTheStatusCodeOfTheArm(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	list := NewList(
+		Case(
+			"$1",
+			[]CaseArm{
+				{
+					Patterns: []string{"*"},
+					Body:     NewList(TestFilepathExists("./testdata/missing")),
+				},
+			},
+		),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	list.Exec("anything")
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, StatusNotOkay, list.StatusCode())
+}
+
+func TestCaseReturnsErrorForInvalidPatterns(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	list := NewList(
+		Case(
+			"m",
+			[]CaseArm{
+				{
+					Patterns: []string{"[z-a]"},
+					Body:     NewList(Echo("matched")),
+				},
+			},
+		),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	list.Exec()
+	err := list.Error()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Error(t, err)
+	assert.Equal(t, StatusNotOkay, list.StatusCode())
+}
+
+func TestCaseSupportsStepOptions(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedStderr := "service\n"
+	list := NewList(
+		Case(
+			"$1",
+			[]CaseArm{
+				{
+					Patterns: []string{"start", "stop"},
+					Body:     NewList(Echo("service")),
+				},
+			},
+			RedirectStdoutToStderr(),
+			WithLabel("service"),
+		),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec("start").String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, "", actualResult)
+	assert.Equal(t, expectedStderr, list.Pipe.Stderr.String())
+	assert.Equal(t, "service", list.StepResults()[0].Label)
+}
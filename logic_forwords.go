@@ -0,0 +1,97 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+// ForWords executes the body once for each of the given words.
+//
+// Each word is expanded, and then put into the local variable varName
+// in the body's LocalVars, so that the body can use it as `${varName}`.
+// A word that contains a glob pattern or a brace expression becomes
+// one word per match; apart from that, we do not split the expanded
+// word up any further.
+//
+// The body starts with an empty Stdin each time, and is given the
+// caller's positional parameters. Its output is written to the
+// pipeline's Stdout and Stderr. The StatusCode() and Error() are those
+// of the last time the body ran; if the body never runs, ForWords()
+// succeeds.
+//
+// It ignores the contents of the pipeline.
+//
+// It is an emulation of UNIX shell scripting's
+// `for varName in words ; do body ; done`
+func ForWords(varName string, words []string, body *Sequence, opts ...*StepOption) *SequenceStep {
+	// build our Scriptish command
+	return NewSequenceStep(
+		func(p *Pipe) (int, error) {
+			// expand our input
+			expWords, err := expandArgs(p, words)
+
+			// debugging support
+			Tracef("ForWords(%#v, %#v)", varName, words)
+			Tracef("=> ForWords(%#v, %#v)", varName, expWords)
+
+			if err != nil {
+				return StatusNotOkay, err
+			}
+
+			// get our parameters
+			params := getParamsFromEnv(p.Env)
+			ctx := PipeContext(p)
+
+			// like UNIX shells, we succeed if the body never runs
+			statusCode := StatusOkay
+
+			for _, word := range expWords {
+				// has the sequence been cancelled?
+				ctxErr := ctx.Err()
+				if ctxErr != nil {
+					return StatusNotOkay, ErrCancelled{ctxErr}
+				}
+
+				statusCode, err = runLoopBody(ctx, p, body, params, varName, word)
+			}
+
+			// all done
+			return statusCode, err
+		},
+		opts...,
+	)
+}
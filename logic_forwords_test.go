@@ -0,0 +1,225 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForWordsExecutesTheBodyOncePerWord(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := "<one>\n<two>\n<three>\n"
+	list := NewList(
+		ForWords(
+			"w",
+			[]string{"one", "$1", "three"},
+			NewList(
+				Echo("<${w}>"),
+			),
+		),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec("two").String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestForWordsDoesNotSplitExpandedWords(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := "<hello world>\n"
+	list := NewList(
+		ForWords(
+			"w",
+			[]string{"$*"},
+			NewList(
+				Echo("<${w}>"),
+			),
+		),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec("hello", "world").String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestForWordsExpandsGlobPatternsAndBraces(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := "<testdata/listfiles/one.txt>\n" +
+		"<testdata/listfiles/two.txt>\n" +
+		"<a>\n" +
+		"<b>\n"
+	list := NewList(
+		ForWords(
+			"w",
+			[]string{"testdata/listfiles/*.txt", "{a,b}"},
+			NewList(
+				Echo("<${w}>"),
+			),
+		),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec().String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestForWordsReturnsStatusCodeFromTheLastIteration(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	body := NewList(
+		TestFilepathExists("${f}"),
+	)
+	firstList := NewList(
+		ForWords("f", []string{"./testdata/missing", "./testdata/listfiles/one.txt"}, body),
+	)
+	secondList := NewList(
+		ForWords("f", []string{"./testdata/listfiles/one.txt", "./testdata/missing"}, body),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	firstStatus, firstErr := firstList.Exec().StatusError()
+	secondStatus, secondErr := secondList.Exec().StatusError()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, firstErr)
+	assert.Equal(t, StatusOkay, firstStatus)
+	assert.Error(t, secondErr)
+	assert.Equal(t, StatusNotOkay, secondStatus)
+}
+
+func TestForWordsSucceedsWhenThereAreNoWords(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	list := NewList(
+		ForWords(
+			"w",
+			[]string{},
+			NewList(
+				Return(1),
+			),
+		),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec().String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Empty(t, actualResult)
+	assert.Equal(t, StatusOkay, list.StatusCode())
+}
+
+func TestForWordsWritesToTheTraceOutput(t *testing.T) {
+	// ----------------------------------------------------------------
+	// setup your test
+
+	dest := NewTextBuffer()
+	GetShellOptions().EnableTrace(dest)
+	defer GetShellOptions().DisableTrace()
+
+	list := NewList(
+		ForWords(
+			"w",
+			[]string{"$1"},
+			NewList(),
+		),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	list.Exec("hello")
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	actualResult := dest.String()
+	assert.Contains(t, actualResult, "+ ForWords(\"w\", []string{\"$1\"})\n")
+	assert.Contains(t, actualResult, "+ => ForWords(\"w\", []string{\"hello\"})\n")
+}
@@ -53,9 +53,10 @@ func If(expr, body *Sequence, opts ...*StepOption) *SequenceStep {
 
 			// get our parameters
 			params := getParamsFromEnv(p.Env)
+			ctx := PipeContext(p)
 
 			// run the test expression first
-			expr.Exec(params...)
+			expr.execConditionFromPipe(ctx, p, params...)
 
 			// copy the output over to our pipe
 			io.Copy(p.Stdout, expr.Pipe.Stdout)
@@ -64,6 +65,9 @@ func If(expr, body *Sequence, opts ...*StepOption) *SequenceStep {
 			// can we proceed?
 			statusCode, err := expr.StatusError()
 			if err != nil {
+				// like UNIX shells, a failed condition does not
+				// trigger Errexit
+				ignoreErrexit(p)
 				return statusCode, err
 			}
 
@@ -71,7 +75,7 @@ func If(expr, body *Sequence, opts ...*StepOption) *SequenceStep {
 			Tracef("If() passed ... executing the body sequence")
 
 			// yes we can!
-			body.Exec(params...)
+			body.execFromPipe(ctx, p, params...)
 
 			// copy the output over to our pipe
 			io.Copy(p.Stdout, body.Pipe.Stdout)
@@ -55,9 +55,10 @@ func IfElse(expr, body, elseBlock *Sequence, opts ...*StepOption) *SequenceStep
 
 			// get our parameters
 			params := getParamsFromEnv(p.Env)
+			ctx := PipeContext(p)
 
 			// run the test expression first
-			expr.Exec(params...)
+			expr.execConditionFromPipe(ctx, p, params...)
 
 			// copy the output over to our pipe
 			io.Copy(p.Stdout, expr.Pipe.Stdout)
@@ -70,7 +71,7 @@ func IfElse(expr, body, elseBlock *Sequence, opts ...*StepOption) *SequenceStep
 				Tracef("If() passed ... executing the body sequence")
 
 				// yes we can!
-				body.Exec(params...)
+				body.execFromPipe(ctx, p, params...)
 
 				// copy the output over to our pipe
 				io.Copy(p.Stdout, body.Pipe.Stdout)
@@ -84,7 +85,7 @@ func IfElse(expr, body, elseBlock *Sequence, opts ...*StepOption) *SequenceStep
 			Tracef("If() failed ... executing the elseBlock sequence")
 
 			// if we get here, we need to execute the other thing
-			elseBlock.Exec()
+			elseBlock.execFromPipe(ctx, p)
 
 			// copy the output over to our pipe
 			io.Copy(p.Stdout, elseBlock.Pipe.Stdout)
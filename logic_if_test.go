@@ -40,6 +40,7 @@
 package scriptish
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -214,3 +215,41 @@ func TestIfWritesToTheTraceOutputWhenExecutingThenBranch(t *testing.T) {
 
 	assert.Equal(t, expectedResult, actualResult)
 }
+
+func TestIfRunsTheExprAndBodyUnderTheCallersContext(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// the expr cancels the context, so the body must not run
+	expr := NewList(
+		NewSequenceStep(
+			func(p *Pipe) (int, error) {
+				cancel()
+				return StatusOkay, nil
+			},
+		),
+	)
+	body := NewList(
+		Echo("this should not be seen"),
+	)
+	list := NewList(
+		If(expr, body),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.ExecContext(ctx).String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Empty(t, actualResult)
+	_, ok := err.(ErrCancelled)
+	assert.True(t, ok)
+}
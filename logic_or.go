@@ -51,7 +51,7 @@ import "io"
 // It is an emulation of UNIX shell scripting's `list1 || command`
 func Or(sq *Sequence, opts ...*StepOption) *SequenceStep {
 	// we're going to wrap our sequences up as a Scriptish Command
-	return NewSequenceStep(
+	retval := NewSequenceStep(
 		func(p *Pipe) (int, error) {
 			// do we need to do anything?
 			statusCode, err := p.StatusError()
@@ -73,7 +73,7 @@ func Or(sq *Sequence, opts ...*StepOption) *SequenceStep {
 			params := getParamsFromEnv(p.Env)
 
 			// run it
-			sq.Exec(params...)
+			sq.execFromPipe(PipeContext(p), p, params...)
 
 			// copy the results into our pipe
 			io.Copy(p.Stdout, sq.Pipe.Stdout)
@@ -84,4 +84,11 @@ func Or(sq *Sequence, opts ...*StepOption) *SequenceStep {
 		},
 		opts...,
 	)
+
+	// Errexit needs to know that the previous step's failure is
+	// dealt with by us
+	retval.isAndOr = true
+
+	// all done
+	return retval
 }
@@ -0,0 +1,95 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// Timeout executes the given sequence, and stops it if it is still
+// running after the given duration.
+//
+// Any Exec() process (and any processes it has started) is killed when
+// the timeout expires.
+//
+// If the sequence is stopped, we set the StatusCode() to StatusTimeout
+// (124) and the Error() to an ErrTimeout. Otherwise, the StatusCode()
+// and Error() are those of the sequence.
+//
+// The sequence starts with an empty Stdin. The sequence's output is written
+// back to the Stdout and Stderr of the calling list or pipeline.
+//
+// It is an emulation of UNIX shell scripting's `timeout <duration> command`
+func Timeout(d time.Duration, sq *Sequence, opts ...*StepOption) *SequenceStep {
+	// build our Scriptish command
+	return NewSequenceStep(
+		func(p *Pipe) (int, error) {
+			// debugging support
+			Tracef("Timeout(%s)", d)
+
+			// get our parameters
+			params := getParamsFromEnv(p.Env)
+
+			// we only have so long to run the sequence
+			parent := PipeContext(p)
+			ctx, cancel := context.WithTimeout(parent, d)
+			defer cancel()
+
+			// run it
+			sq.execFromPipe(ctx, p, params...)
+
+			// copy the results into our pipe
+			io.Copy(p.Stdout, sq.Pipe.Stdout)
+			io.Copy(p.Stderr, sq.Pipe.Stderr)
+
+			// did we run out of time?
+			statusCode, err := timeoutStatusError(ctx, parent, d, sq.StatusCode(), sq.Error())
+			if statusCode == StatusTimeout {
+				Tracef("Timeout(%s): sequence timed out", d)
+			}
+
+			// all done
+			return statusCode, err
+		},
+		opts...,
+	)
+}
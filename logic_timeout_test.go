@@ -0,0 +1,233 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeoutReturnsTheOutputOfASequenceThatFinishesInTime(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := "hello world\n"
+	list := NewList(
+		Timeout(
+			5*time.Second,
+			NewList(
+				Echo("hello world"),
+			),
+		),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec().String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+	assert.Equal(t, StatusOkay, list.StatusCode())
+}
+
+func TestTimeoutReturnsTheStatusCodeOfASequenceThatFailsInTime(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	list := NewList(
+		Timeout(
+			5*time.Second,
+			NewList(
+				Return(3),
+			),
+		),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	err := list.Exec().Error()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.NotNil(t, err)
+	_, ok := err.(ErrTimeout)
+	assert.False(t, ok)
+	assert.Equal(t, 3, list.StatusCode())
+}
+
+func TestTimeoutStopsASequenceThatRunsForTooLong(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	list := NewList(
+		Timeout(
+			100*time.Millisecond,
+			NewList(
+				Exec([]string{"/usr/bin/env", "sleep", "10"}),
+			),
+		),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	start := time.Now()
+	err := list.Exec().Error()
+	duration := time.Since(start)
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, ErrTimeout{100 * time.Millisecond}, err)
+	assert.Equal(t, StatusTimeout, list.StatusCode())
+	assert.True(t, duration < 5*time.Second)
+}
+
+func TestTimeoutKillsAnyProcessesStartedByTheCommand(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	// if the backgrounded sleep survives, it keeps our Stdout open,
+	// and Exec() has to wait for it to finish
+	list := NewList(
+		Timeout(
+			100*time.Millisecond,
+			NewList(
+				Exec([]string{"/bin/sh", "-c", "sleep 10 & wait"}),
+			),
+		),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	start := time.Now()
+	err := list.Exec().Error()
+	duration := time.Since(start)
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, ErrTimeout{100 * time.Millisecond}, err)
+	assert.Equal(t, StatusTimeout, list.StatusCode())
+	assert.True(t, duration < 5*time.Second)
+}
+
+func TestTimeoutErrorCanBeHandledByOr(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := "fallback\n"
+	list := NewList(
+		Timeout(
+			100*time.Millisecond,
+			NewList(
+				Exec([]string{"/usr/bin/env", "sleep", "10"}),
+			),
+		),
+		Or(
+			NewList(
+				Echo("fallback"),
+			),
+		),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec().String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+	assert.Equal(t, StatusOkay, list.StatusCode())
+}
+
+func TestTimeoutReportsCancellationOfTheCallingSequence(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	list := NewList(
+		Timeout(
+			5*time.Second,
+			NewList(
+				Exec([]string{"/usr/bin/env", "sleep", "10"}),
+			),
+		),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	err := list.ExecContext(ctx).Error()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	_, ok := err.(ErrCancelled)
+	assert.True(t, ok)
+	assert.NotEqual(t, StatusTimeout, list.StatusCode())
+}
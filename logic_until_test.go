@@ -0,0 +1,176 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUntilExecutesTheBodyForAsLongAsTheExprFails(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	tmpDir, err := ExecPipeline(MkTempDir(os.TempDir(), "scriptish-until-")).TrimmedString()
+	assert.Nil(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	expectedResult := "waiting\n"
+	list := NewList(
+		Until(
+			NewList(
+				TestFilepathExists("$1/stamp"),
+			),
+			NewList(
+				Echo("waiting"),
+				Touch("$1/stamp"),
+			),
+		),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec(tmpDir).String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+	assert.Equal(t, StatusOkay, list.StatusCode())
+}
+
+func TestUntilDoesNotExecuteTheBodyIfTheExprSucceeds(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	list := NewList(
+		Until(
+			NewList(
+				Return(0),
+			),
+			NewList(
+				Echo("waiting"),
+			),
+		),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec().String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Empty(t, actualResult)
+	assert.Equal(t, StatusOkay, list.StatusCode())
+}
+
+func TestUntilStopsAfterMaxIterations(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	list := NewList(
+		Until(
+			NewList(
+				Return(1),
+			),
+			NewList(
+				Echo("waiting"),
+			),
+			WithMaxIterations(2),
+		),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	err := list.Exec().Error()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, ErrMaxIterations{2}, err)
+	actualStdout, _ := list.String()
+	assert.Equal(t, "waiting\nwaiting\n", actualStdout)
+}
+
+func TestUntilWritesToTheTraceOutput(t *testing.T) {
+	// ----------------------------------------------------------------
+	// setup your test
+
+	dest := NewTextBuffer()
+	GetShellOptions().EnableTrace(dest)
+	defer GetShellOptions().DisableTrace()
+
+	list := NewList(
+		Until(
+			NewList(
+				Return(0),
+			),
+			NewList(
+				Echo("waiting"),
+			),
+		),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	list.Exec()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	actualResult := dest.String()
+	assert.Contains(t, actualResult, "+ Until()\n")
+	assert.Contains(t, actualResult, "+ loop finished after 0 iteration(s)\n")
+}
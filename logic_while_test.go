@@ -0,0 +1,281 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// countdown returns a step that succeeds the given number of times,
+// and then fails
+func countdown(n int) *SequenceStep {
+	return NewSequenceStep(func(p *Pipe) (int, error) {
+		if n == 0 {
+			return StatusNotOkay, nil
+		}
+		n--
+		return StatusOkay, nil
+	})
+}
+
+func TestWhileExecutesTheBodyForAsLongAsTheExprSucceeds(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := "tick\ntick\ntick\n"
+	list := NewList(
+		While(
+			NewList(
+				countdown(3),
+			),
+			NewList(
+				Echo("tick"),
+			),
+		),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec().String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+	assert.Equal(t, StatusOkay, list.StatusCode())
+}
+
+func TestWhileDoesNotExecuteTheBodyIfTheExprFails(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	list := NewList(
+		While(
+			NewList(
+				Return(1),
+			),
+			NewList(
+				Echo("tick"),
+			),
+		),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec().String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Empty(t, actualResult)
+	assert.Equal(t, StatusOkay, list.StatusCode())
+}
+
+func TestWhileReturnsStatusCodeFromTheLastTimeTheBodyRan(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := 3
+	list := NewList(
+		While(
+			NewList(
+				countdown(2),
+			),
+			NewList(
+				Echo("tick"),
+				Return(expectedResult),
+			),
+		),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec().StatusError()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Error(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+	actualStdout, _ := list.String()
+	assert.Equal(t, "tick\ntick\n", actualStdout)
+}
+
+func TestWhilePassesThePositionalParametersIntoTheExprAndBody(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := "expr: hello\nbody: hello\nexpr: hello\n"
+	list := NewList(
+		While(
+			NewList(
+				Echo("expr: $1"),
+				countdown(1),
+			),
+			NewList(
+				Echo("body: $1"),
+			),
+		),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec("hello").String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestWhileStopsWhenTheSequenceIsCancelled(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	list := NewList(
+		While(
+			NewList(
+				Return(0),
+			),
+			NewList(
+				Exec([]string{"/usr/bin/env", "sleep", "0.01"}),
+			),
+		),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	err := list.ExecContext(ctx).Error()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Error(t, err)
+	assert.IsType(t, ErrCancelled{}, err)
+}
+
+func TestWhileStopsAfterMaxIterations(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	list := NewList(
+		While(
+			NewList(
+				Return(0),
+			),
+			NewList(
+				Echo("tick"),
+			),
+			WithMaxIterations(3),
+		),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	err := list.Exec().Error()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, ErrMaxIterations{3}, err)
+	assert.Equal(t, StatusNotOkay, list.StatusCode())
+	actualStdout, _ := list.String()
+	assert.Equal(t, "tick\ntick\ntick\n", actualStdout)
+}
+
+func TestWhileWritesToTheTraceOutput(t *testing.T) {
+	// ----------------------------------------------------------------
+	// setup your test
+
+	dest := NewTextBuffer()
+	GetShellOptions().EnableTrace(dest)
+	defer GetShellOptions().DisableTrace()
+
+	list := NewList(
+		While(
+			NewList(
+				countdown(1),
+			),
+			NewList(
+				Echo("tick"),
+			),
+		),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	list.Exec()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	actualResult := dest.String()
+	assert.Contains(t, actualResult, "While()\n")
+	assert.Contains(t, actualResult, "loop finished after 1 iteration(s)\n")
+}
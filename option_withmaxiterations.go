@@ -0,0 +1,83 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+// WithMaxIterations stops a While() or Until() loop once its body has
+// run the given number of times.
+//
+// If the loop is stopped, we set the StatusCode() to StatusNotOkay and
+// the Error() to an ErrMaxIterations.
+//
+// Use it as a safety net, for loops that wait for something that might
+// never happen.
+func WithMaxIterations(n int) *StepOption {
+	var oldMax int
+	var changed bool
+
+	return NewStepOption(
+		func(p *Pipe) (int, error) {
+			// debugging support
+			Tracef("WithMaxIterations(%d)", n)
+
+			// remember the previous limit
+			oldMax = setPipeMaxIterations(p, n)
+			changed = true
+
+			// all done
+			return StatusOkay, nil
+		},
+		func(p *Pipe) (int, error) {
+			// robustness!
+			//
+			// our setup phase does not run if an earlier StepOption's
+			// setup phase failed
+			if !changed {
+				return StatusOkay, nil
+			}
+
+			// put the previous limit back
+			setPipeMaxIterations(p, oldMax)
+			changed = false
+
+			// all done
+			return StatusOkay, nil
+		},
+	)
+}
@@ -0,0 +1,140 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"context"
+	"time"
+)
+
+// WithTimeout stops the step if it is still running after the given
+// duration.
+//
+// Any Exec() process (and any processes it has started) is killed when
+// the timeout expires.
+//
+// If the step is stopped, we set the StatusCode() to StatusTimeout (124)
+// and the Error() to an ErrTimeout.
+//
+// It is an emulation of UNIX shell scripting's `timeout <duration> command`
+func WithTimeout(d time.Duration) *StepOption {
+	// every run of the step gets its own context, which we keep in the
+	// pipe's environment; this tells us which one is ours
+	owner := new(int)
+
+	return NewStepOption(
+		func(p *Pipe) (int, error) {
+			// debugging support
+			Tracef("WithTimeout(%s)", d)
+
+			// robustness!
+			env, ok := getSequenceEnv(p)
+			if !ok {
+				return StatusOkay, nil
+			}
+
+			// the step only has so long to run
+			parent := PipeContext(p)
+			ctx, cancel := context.WithTimeout(parent, d)
+			env.timeout = &stepTimeout{
+				owner:  owner,
+				parent: parent,
+				ctx:    ctx,
+				cancel: cancel,
+				prev:   env.timeout,
+			}
+			setPipeContext(p, ctx)
+
+			// all done
+			return StatusOkay, nil
+		},
+		func(p *Pipe) (int, error) {
+			// robustness!
+			//
+			// our setup phase does not run if an earlier StepOption's
+			// setup phase failed
+			env, ok := getSequenceEnv(p)
+			if !ok || env.timeout == nil || env.timeout.owner != owner {
+				return StatusOkay, nil
+			}
+			timeout := env.timeout
+			env.timeout = timeout.prev
+
+			// put the previous context back
+			setPipeContext(p, timeout.parent)
+			timeout.cancel()
+
+			// did the step run out of time?
+			//
+			// NOTE that we deliberately change the pipe's status here,
+			// because that's the whole point of this option
+			statusCode, err := p.StatusError()
+			statusCode, err = timeoutStatusError(timeout.ctx, timeout.parent, d, statusCode, err)
+			if statusCode == StatusTimeout {
+				Tracef("WithTimeout(%s): step timed out", d)
+				p.RunCommand(func(p *Pipe) (int, error) {
+					return statusCode, err
+				})
+			}
+
+			// all done
+			return StatusOkay, nil
+		},
+	)
+}
+
+// stepTimeout is the context that WithTimeout() has created for a single
+// run of a step.
+//
+// They form a stack, so that a step can have more than one WithTimeout().
+type stepTimeout struct {
+	// owner identifies the WithTimeout() that created this context
+	owner *int
+
+	// parent is the context that we put back afterwards
+	parent context.Context
+
+	// ctx is the context that the step runs under
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// prev is the stepTimeout that was there before us
+	prev *stepTimeout
+}
@@ -0,0 +1,188 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithTimeoutStopsAStepThatRunsForTooLong(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	list := NewList(
+		Exec(
+			[]string{"/usr/bin/env", "sleep", "10"},
+			WithTimeout(100*time.Millisecond),
+		),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	start := time.Now()
+	err := list.Exec().Error()
+	duration := time.Since(start)
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, ErrTimeout{100 * time.Millisecond}, err)
+	assert.Equal(t, StatusTimeout, list.StatusCode())
+	assert.True(t, duration < 5*time.Second)
+}
+
+func TestWithTimeoutDoesNotChangeTheResultsOfAStepThatFinishesInTime(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := "hello world\n"
+	list := NewList(
+		Exec(
+			[]string{"/usr/bin/env", "echo", "hello world"},
+			WithTimeout(5*time.Second),
+		),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec().String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+	assert.Equal(t, StatusOkay, list.StatusCode())
+}
+
+func TestWithTimeoutOnlyAppliesToItsOwnStep(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	list := NewList(
+		Exec(
+			[]string{"/usr/bin/env", "true"},
+			WithTimeout(50*time.Millisecond),
+		),
+		Exec([]string{"/usr/bin/env", "sleep", "0.2"}),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	err := list.Exec().Error()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, StatusOkay, list.StatusCode())
+}
+
+func TestWithTimeoutWorksInAStreamingPipeline(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	pipeline := NewStreamingPipeline(
+		Exec(
+			[]string{"/usr/bin/env", "sleep", "10"},
+			WithTimeout(100*time.Millisecond),
+		),
+		Exec([]string{"/usr/bin/env", "cat"}),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	err := pipeline.Exec().Error()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, ErrTimeout{100 * time.Millisecond}, err)
+	assert.Equal(t, StatusTimeout, pipeline.StatusCode())
+}
+
+func TestWithTimeoutCanBeSharedByStepsThatRunAtTheSameTime(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	timeout := WithTimeout(200 * time.Millisecond)
+	slowList := NewList(
+		Exec([]string{"/usr/bin/env", "sleep", "10"}, timeout),
+	)
+	fastList := NewList(
+		Exec([]string{"/usr/bin/env", "true"}, timeout),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	done := make(chan error)
+	go func() {
+		done <- slowList.Exec().Error()
+	}()
+	time.Sleep(50 * time.Millisecond)
+	fastErr := fastList.Exec().Error()
+	slowErr := <-done
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, fastErr)
+	assert.Equal(t, StatusOkay, fastList.StatusCode())
+	assert.Equal(t, ErrTimeout{200 * time.Millisecond}, slowErr)
+	assert.Equal(t, StatusTimeout, slowList.StatusCode())
+}
@@ -46,6 +46,7 @@ func NewPipeline(steps ...*SequenceStep) *Sequence {
 
 	// tell the underlying sequence how we want these commands to run
 	retval.Controller = PipelineController(retval)
+	retval.newController = PipelineController
 
 	// tell the commands what context they are running in
 	retval.Flags = contextIsPipeline
@@ -48,14 +48,27 @@ func PipelineController(sq *Sequence) SequenceController {
 			return
 		}
 
+		// we start with no results
+		sq.stepResults = nil
+
 		// execute everything in our pipeline
-		for _, step := range sq.Steps {
+		for i := range sq.Steps {
+			// have we been cancelled?
+			if checkPipeContext(sq.Pipe) {
+				return
+			}
+
 			// at this point, stdout needs to become the next
 			// stdin
 			preparePipeForNextCommand(sq.Pipe)
 
 			// run the command
-			step.RunStep(sq.Pipe)
+			result := runStepWithResult(sq.Steps[i], i, sq.Pipe)
+			sq.stepResults = append(sq.stepResults, result)
+
+			// like UNIX shells, the next step can see how this
+			// step went
+			setLastStatusCode(sq, result.StatusCode)
 
 			// we stop executing the moment something goes wrong
 			err := sq.Pipe.Error()
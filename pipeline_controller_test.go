@@ -40,6 +40,7 @@
 package scriptish
 
 import (
+	"context"
 	"errors"
 	"testing"
 
@@ -151,3 +152,42 @@ func TestPipelineControllerWritesErrorsToTheTraceOutput(t *testing.T) {
 
 	assert.Equal(t, expectedResult, actualResult)
 }
+
+func TestPipelineControllerStopsWhenTheContextIsCancelled(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	op1 := NewSequenceStep(
+		func(p *Pipe) (int, error) {
+			p.Stdout.WriteString("hello world\n")
+
+			// nothing after this step should run
+			cancel()
+
+			return StatusOkay, nil
+		},
+	)
+	pipeline := NewPipeline(
+		op1,
+		Echo("this should not be seen"),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	pipeline.ExecContext(ctx)
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	err := pipeline.Error()
+	_, ok := err.(ErrCancelled)
+	assert.True(t, ok)
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Equal(t, "hello world\n", pipeline.Pipe.Stdout.String())
+}
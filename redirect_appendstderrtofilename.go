@@ -50,7 +50,6 @@ import "os"
 // It is an emulation of UNIX shell scripting's `2>> <filename>`.
 func AppendStderrToFilename(filename string) *StepOption {
 	var fh *os.File
-	var err error
 
 	return NewStepOption(
 		func(p *Pipe) (int, error) {
@@ -58,13 +57,17 @@ func AppendStderrToFilename(filename string) *StepOption {
 			Tracef("AppendStderrToFilename(%#v)", filename)
 
 			// expand our input
-			expFilename := p.Env.Expand(filename)
+			expFilename, err := expandPathArg(p, filename)
 
 			// now we show what the filename expanded to
 			Tracef("=> AppendStderrToFilename(%#v)", expFilename)
 
+			if err != nil {
+				return StatusNotOkay, err
+			}
+
 			// open / create the file
-			fh, err = os.OpenFile(expFilename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+			fh, err = os.OpenFile(resolvePipePath(p, expFilename), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 			if err != nil {
 				return StatusNotOkay, err
 			}
@@ -52,7 +52,6 @@ import "os"
 // It is an emulation of UNIX shell scripting's `>> <filename>`.
 func AppendStdoutToFilename(filename string) *StepOption {
 	var fh *os.File
-	var err error
 
 	return NewStepOption(
 		func(p *Pipe) (int, error) {
@@ -60,13 +59,17 @@ func AppendStdoutToFilename(filename string) *StepOption {
 			Tracef("AppendStdoutToFilename(%#v)", filename)
 
 			// expand our input
-			expFilename := p.Env.Expand(filename)
+			expFilename, err := expandPathArg(p, filename)
 
 			// now we show what the filename expanded to
 			Tracef("=> AppendStdoutToFilename(%#v)", expFilename)
 
+			if err != nil {
+				return StatusNotOkay, err
+			}
+
 			// open / create the file
-			fh, err = os.OpenFile(expFilename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+			fh, err = os.OpenFile(resolvePipePath(p, expFilename), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 			if err != nil {
 				return StatusNotOkay, err
 			}
@@ -52,19 +52,22 @@ import (
 // It is an emulation of UNIX shell scripting's `> <filename>`.
 func OverwriteFilenameWithStdout(filename string) *StepOption {
 	var fh *os.File
-	var err error
 
 	return NewStepOption(
 		func(p *Pipe) (int, error) {
 			// expand our input
-			expFilename := p.Env.Expand(filename)
+			expFilename, err := expandPathArg(p, filename)
 
 			// debugging support
 			Tracef("OverwriteFilenameWithStdout(%#v)", filename)
 			Tracef("=> OverwriteFilenameWithStdout(%#v)", expFilename)
 
+			if err != nil {
+				return StatusNotOkay, err
+			}
+
 			// open / create the file
-			fh, err = os.OpenFile(expFilename, os.O_CREATE|os.O_WRONLY, 0644)
+			fh, err = os.OpenFile(resolvePipePath(p, expFilename), os.O_CREATE|os.O_WRONLY, 0644)
 			if err != nil {
 				return StatusNotOkay, err
 			}
@@ -40,10 +40,12 @@
 package scriptish
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
+	"strings"
 
 	envish "github.com/ganbarodigital/go_envish/v3"
 )
@@ -51,11 +53,18 @@ import (
 // flag we set if we are executing commands in a pipeline
 const contextIsPipeline = 1
 
+// flag we set if we are executing commands in a streaming pipeline
+const contextIsStreaming = 2
+
 // SequenceStep bundles up both a Command to run, and the options to apply
 // to the pipe when that command runs
 type SequenceStep struct {
 	Command Command
 	Opts    []*StepOption
+
+	// isAndOr is true for And() and Or() steps; Errexit ignores any
+	// failure that they get to deal with
+	isAndOr bool
 }
 
 // NewSequenceStep creates a new runnable step for a pipeline or list
@@ -104,18 +113,54 @@ type Sequence struct {
 	// How we will run the sequence
 	Controller SequenceController
 
+	// newController builds our Controller for a copy of the sequence;
+	// it is nil if the Controller was not set by one of our constructors
+	newController func(*Sequence) SequenceController
+
 	// we store local variables here
 	LocalVars *envish.LocalEnv
 
+	// which of our LocalVars have been exported
+	exports *exportedVars
+
 	// the flags we pass into new pipes
 	Flags int
+
+	// Dir is the working directory that the sequence starts in.
+	//
+	// If it is empty, the sequence starts in the program's current
+	// working directory.
+	Dir string
+
+	// ShellOptions holds the Errexit, Nounset, Pipefail and globbing
+	// settings that the sequence runs with.
+	//
+	// If it is nil, the sequence uses the settings of whatever runs it:
+	// the package-wide GetShellOptions() when you call Exec(), or the
+	// calling sequence when it is run from a logic call.
+	ShellOptions *ShellOptions
+
+	// Substitutions are the sub-sequences that you can run from any
+	// string that Scriptish expands, by writing `$(name)`.
+	//
+	// Any sequence run by a logic call can use them too.
+	Substitutions map[string]*Sequence
+
+	// what happened to each step, the last time that the sequence ran
+	stepResults []StepResult
+
+	// substitutionDepth is how many `$(name)` substitutions are running
+	// this sequence, one inside the other
+	substitutionDepth int
 }
 
 // NewSequence creates a sequence that's ready to run
 func NewSequence(steps ...*SequenceStep) *Sequence {
 	sq := Sequence{
-		Steps:     steps,
-		LocalVars: envish.NewLocalEnv(),
+		Steps:         steps,
+		LocalVars:     envish.NewLocalEnv(),
+		exports:       newExportedVars(),
+		Substitutions: map[string]*Sequence{},
 	}
 
 	// make sure we have a pipe, and its environment knows about our
@@ -129,6 +174,7 @@ func NewSequence(steps ...*SequenceStep) *Sequence {
 	sq.LocalVars.Setenv("$0", os.Args[0])
 	sq.LocalVars.Setenv("$-", os.Args[0])
 	sq.LocalVars.Setenv("$$", fmt.Sprintf("%d", os.Getpid()))
+	sq.LocalVars.Setenv("$?", "0")
 
 	// all done
 	return &sq
@@ -167,11 +213,77 @@ func (sq *Sequence) Error() error {
 // If you embed the sequence in another struct, make sure to override this
 // to return your own return type!
 func (sq *Sequence) Exec(params ...string) *Sequence {
+	return sq.ExecContext(context.Background(), params...)
+}
+
+// ExecContext executes a sequence under the given context.Context
+//
+// If the context is cancelled (or its deadline passes), any running
+// Exec() commands are killed, no further steps are run, and Error()
+// returns an ErrCancelled.
+//
+// If you embed the sequence in another struct, make sure to override this
+// to return your own return type!
+func (sq *Sequence) ExecContext(ctx context.Context, params ...string) *Sequence {
+	// do we have a sequence to work with?
+	if sq == nil {
+		return sq
+	}
+
+	// do we have a controller?
+	if sq.Controller == nil {
+		return sq
+	}
+
+	// we start with a new Pipe
+	sq.NewPipe()
+
+	// every step needs to be able to see the context
+	setPipeContext(sq.Pipe, ctx)
+	setPipeShellOptions(sq.Pipe, shellOptionsFor(sq, shopt))
+
+	// we need to set the parameters
+	sq.SetParams(params...)
+
+	// use the embedded controller to animate the sequence
+	sq.Controller()
+
+	// all done
+	return sq
+}
+
+// execFromPipe executes a sequence from inside a step that is running
+// in the given pipe (eg, from a logic call).
+//
+// The sequence runs under the given context. It starts in the working
+// directory of the given pipe (unless the sequence has its own Dir).
+func (sq *Sequence) execFromPipe(ctx context.Context, p *Pipe, params ...string) *Sequence {
 	// do we have a sequence to work with?
 	if sq == nil {
 		return sq
 	}
 
+	return sq.execFromPipeWithOptions(ctx, p, shellOptionsFor(sq, pipeShellOptions(p)), params...)
+}
+
+// execConditionFromPipe executes a sequence that is the condition of a
+// logic call, such as If() or While()
+//
+// Like UNIX shells, Errexit is switched off while the condition runs.
+func (sq *Sequence) execConditionFromPipe(ctx context.Context, p *Pipe, params ...string) *Sequence {
+	// do we have a sequence to work with?
+	if sq == nil {
+		return sq
+	}
+
+	opts := shellOptionsFor(sq, pipeShellOptions(p))
+	opts.Errexit = false
+	return sq.execFromPipeWithOptions(ctx, p, opts, params...)
+}
+
+// execFromPipeWithOptions does the work for execFromPipe() and
+// execConditionFromPipe()
+func (sq *Sequence) execFromPipeWithOptions(ctx context.Context, p *Pipe, opts ShellOptions, params ...string) *Sequence {
 	// do we have a controller?
 	if sq.Controller == nil {
 		return sq
@@ -180,6 +292,12 @@ func (sq *Sequence) Exec(params ...string) *Sequence {
 	// we start with a new Pipe
 	sq.NewPipe()
 
+	// it carries on from where the caller is
+	setPipeContext(sq.Pipe, ctx)
+	inheritPipeDir(sq.Pipe, p, sq.Dir)
+	inheritPipeCaller(sq.Pipe, p)
+	setPipeShellOptions(sq.Pipe, opts)
+
 	// we need to set the parameters
 	sq.SetParams(params...)
 
@@ -190,6 +308,35 @@ func (sq *Sequence) Exec(params ...string) *Sequence {
 	return sq
 }
 
+// clone returns a copy of the sequence that can run at the same time
+// as the sequence does
+//
+// The copy has its own Pipe and its own copy of the LocalVars, so that
+// neither one can change the other. If we do not know how to build
+// the Controller for the copy, we return the sequence itself.
+func (sq *Sequence) clone() *Sequence {
+	// do we know how to make a copy?
+	if sq == nil || sq.newController == nil {
+		return sq
+	}
+
+	retval := *sq
+	retval.Pipe = nil
+	retval.stepResults = nil
+	retval.Controller = sq.newController(&retval)
+
+	// the copy gets its own variables
+	retval.LocalVars = envish.NewLocalEnv()
+	for _, pair := range sq.LocalVars.Environ() {
+		parts := strings.SplitN(pair, "=", 2)
+		retval.LocalVars.Setenv(parts[0], parts[1])
+	}
+	retval.exports = sq.exports.clone()
+
+	// all done
+	return &retval
+}
+
 // Flush writes the output from running this sequence to the given
 // stdout and stderr
 func (sq *Sequence) Flush(stdout io.Writer, stderr io.Writer) {
@@ -224,11 +371,16 @@ func (sq *Sequence) NewPipe() {
 	// we start with a new Pipe
 	sq.Pipe = NewPipe()
 
+	// robustness!
+	if sq.exports == nil {
+		sq.exports = newExportedVars()
+	}
+
 	// the new pipe needs a new environment establishing
-	sq.Pipe.Env = envish.NewOverlayEnv(
-		sq.LocalVars,
-		envish.NewProgramEnv(),
-	)
+	env := newSequenceEnv(sq.LocalVars, sq.exports, sq.Dir)
+	env.substitutions = sq.Substitutions
+	env.substitutionDepth = sq.substitutionDepth
+	sq.Pipe.Env = env
 
 	// set the flags
 	sq.Pipe.Flags = sq.Flags
@@ -0,0 +1,312 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"context"
+	"os"
+	"strings"
+	"sync"
+
+	envish "github.com/ganbarodigital/go_envish/v3"
+)
+
+// sequenceEnv is the environment that every Sequence gives to its Pipe.
+//
+// It is an envish.OverlayEnv with some extra state attached. Scriptish
+// commands only get to see the Pipe, so this is how we make that state
+// available to them.
+type sequenceEnv struct {
+	*envish.OverlayEnv
+
+	// ctx is the context.Context that the sequence is running under
+	ctx context.Context
+
+	// timeout is the context created by WithTimeout() for the step that
+	// is currently running, if any
+	timeout *stepTimeout
+
+	// localVars is the sequence's LocalVars
+	localVars *envish.LocalEnv
+
+	// exports keeps track of which localVars are passed into
+	// the environment of any Exec()'d commands
+	//
+	// it belongs to the Sequence, just like localVars does
+	exports *exportedVars
+
+	// dir is the sequence's working directory
+	//
+	// if it is empty, we use the program's current working directory
+	dir string
+
+	// dirStack is where Pushd() and Popd() keep their directories
+	dirStack []string
+
+	// maxIterations is how many times a While() or Until() loop is
+	// allowed to run its body
+	//
+	// if it is zero, there is no limit
+	maxIterations int
+
+	// shopt holds the Errexit, Nounset, Pipefail and globbing settings
+	// that the sequence is running with
+	shopt ShellOptions
+
+	// ignoreErrexit is set by a step whose failure must not stop
+	// the list, such as an If() whose condition failed
+	ignoreErrexit bool
+
+	// substitutions is the sequence's Substitutions
+	substitutions map[string]*Sequence
+
+	// caller is the environment of whatever is running the sequence
+	// (eg, a logic call); we look there for any `$(name)` that the
+	// sequence does not know about, and for any exported variables
+	caller *sequenceEnv
+
+	// callerPipe is the Pipe of whatever is running the sequence
+	callerPipe *Pipe
+
+	// substitutionDepth is how many `$(name)` substitutions are running
+	// inside each other, all the way up to the top-level sequence
+	substitutionDepth int
+}
+
+// exportedVars is the set of local variables that have been exported
+type exportedVars struct {
+	mu    sync.Mutex
+	names map[string]bool
+}
+
+// newExportedVars creates an empty set of exported variables
+func newExportedVars() *exportedVars {
+	return &exportedVars{names: map[string]bool{}}
+}
+
+// clone returns a copy of the set of exported variables
+func (x *exportedVars) clone() *exportedVars {
+	retval := newExportedVars()
+
+	// robustness
+	if x == nil {
+		return retval
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	for name := range x.names {
+		retval.names[name] = true
+	}
+
+	return retval
+}
+
+// newSequenceEnv creates the environment for a Sequence's new Pipe
+func newSequenceEnv(localVars *envish.LocalEnv, exports *exportedVars, dir string) *sequenceEnv {
+	return &sequenceEnv{
+		OverlayEnv: envish.NewOverlayEnv(
+			localVars,
+			envish.NewProgramEnv(),
+		),
+		ctx:       context.Background(),
+		localVars: localVars,
+		exports:   exports,
+		dir:       dir,
+	}
+}
+
+// Expand replaces any variables in the given string with their values.
+//
+// It never fails. Anything that cannot be expanded is left as it is.
+// Use expandString() if you need to know when that happens.
+func (e *sequenceEnv) Expand(fmt string) string {
+	return e.OverlayEnv.Expand(rewriteForExpander(e, fmt))
+}
+
+// export marks the given local variable as one that is passed into
+// the environment of any Exec()'d commands
+func (e *sequenceEnv) export(key string) {
+	e.exports.mu.Lock()
+	defer e.exports.mu.Unlock()
+
+	e.exports.names[key] = true
+}
+
+// Environ returns the environment that any Exec()'d commands run with,
+// in the same 'key=value' format that os.Environ() uses.
+//
+// It is made up of the program's environment, plus any local variables
+// that have been exported by the sequence or by whatever is running it.
+// Like UNIX shells, any local variable that has the same name as a program
+// environment variable is exported too.
+func (e *sequenceEnv) Environ() []string {
+	// exec.Cmd only uses the last value of any duplicated keys, so
+	// the local variables win
+	return append(e.OverlayEnv.Environ(), e.exportedLocalVars()...)
+}
+
+// exportedLocalVars returns the local variables that have been exported,
+// in the same 'key=value' format that os.Environ() uses
+//
+// Anything exported by whatever is running the sequence comes first, so
+// that our own variables win.
+func (e *sequenceEnv) exportedLocalVars() []string {
+	var retval []string
+	if e.caller != nil {
+		retval = e.caller.exportedLocalVars()
+	}
+
+	// robustness
+	if e.localVars == nil || e.exports == nil {
+		return retval
+	}
+
+	e.exports.mu.Lock()
+	defer e.exports.mu.Unlock()
+
+	for _, pair := range e.localVars.Environ() {
+		parts := strings.SplitN(pair, "=", 2)
+		_, inProgramEnv := os.LookupEnv(parts[0])
+		if e.exports.names[parts[0]] || inProgramEnv {
+			retval = append(retval, pair)
+		}
+	}
+
+	return retval
+}
+
+// shareSequenceEnv returns an environment for a Pipe that runs at the
+// same time as the given Pipe.
+//
+// Both environments share the same variables. Each one gets its own copy
+// of the extra state, so that a StepOption (such as WithTimeout()) cannot
+// change it for the other Pipe.
+func shareSequenceEnv(p *Pipe) envish.Expander {
+	env, ok := getSequenceEnv(p)
+	if !ok {
+		return p.Env
+	}
+
+	retval := *env
+	return &retval
+}
+
+// getSequenceEnv returns the Pipe's environment, if it was created
+// by a Sequence
+func getSequenceEnv(p *Pipe) (*sequenceEnv, bool) {
+	// robustness
+	if p == nil {
+		return nil, false
+	}
+
+	retval, ok := p.Env.(*sequenceEnv)
+	return retval, ok
+}
+
+// getCallerPipe returns the Pipe of whatever is running the sequence
+// that the given Pipe belongs to, or nil if there isn't one
+func getCallerPipe(p *Pipe) *Pipe {
+	env, ok := getSequenceEnv(p)
+	if !ok {
+		return nil
+	}
+
+	return env.callerPipe
+}
+
+// getExecEnviron returns the environment that any Exec()'d commands
+// run with
+func getExecEnviron(p *Pipe) []string {
+	env, ok := getSequenceEnv(p)
+	if !ok {
+		return os.Environ()
+	}
+
+	return env.Environ()
+}
+
+// setLocalVar sets the named variable
+//
+// Inside a sequence, we always set the local variable, even if the
+// program's environment has a variable of the same name.
+func setLocalVar(p *Pipe, key string, value string) error {
+	env, ok := getSequenceEnv(p)
+	if !ok {
+		return p.Env.Setenv(key, value)
+	}
+
+	return env.localVars.Setenv(key, value)
+}
+
+// unsetLocalVar removes the named variable
+//
+// Inside a sequence, we only remove the local variable. Any variable of
+// the same name in the program's environment is left alone.
+func unsetLocalVar(p *Pipe, key string) {
+	env, ok := getSequenceEnv(p)
+	if !ok {
+		p.Env.Unsetenv(key)
+		return
+	}
+
+	env.localVars.Unsetenv(key)
+}
+
+// inheritPipeCaller makes the named sub-sequences and the exported
+// variables of the parent pipe available to the given pipe
+//
+// The given pipe also carries on counting the parent pipe's `$(name)`
+// substitutions, so that we can stop any that never end.
+func inheritPipeCaller(p *Pipe, parent *Pipe) {
+	env, ok := getSequenceEnv(p)
+	if !ok {
+		return
+	}
+
+	env.callerPipe = parent
+
+	parentEnv, ok := getSequenceEnv(parent)
+	if ok {
+		env.caller = parentEnv
+		env.substitutionDepth += parentEnv.substitutionDepth
+	}
+}
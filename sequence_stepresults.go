@@ -0,0 +1,174 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StepResult is what happened when a single step of a sequence ran
+type StepResult struct {
+	// Index is the step's position in the sequence's Steps, starting
+	// from zero
+	Index int
+
+	// Label describes the step. It is set by WithLabel(). If the step
+	// does not have a label, we use the name of the Scriptish command
+	// (such as `Exec`) instead.
+	Label string
+
+	// StatusCode is the UNIX-like status code that the step returned
+	StatusCode int
+
+	// Err is the error that the step returned
+	Err error
+
+	// Duration is how long the step took to run
+	Duration time.Duration
+}
+
+// StepResults returns what happened to each step, the last time that
+// the sequence ran. It is an emulation of UNIX shell scripting's
+// `$PIPESTATUS`.
+//
+// The results are in the same order as the sequence's Steps. Steps that
+// did not run (eg, because an earlier step in a pipeline failed) are not
+// included.
+func (sq *Sequence) StepResults() []StepResult {
+	// do we have a sequence to work with?
+	if sq == nil {
+		return []StepResult{}
+	}
+
+	// we don't want the caller changing our copy
+	retval := make([]StepResult, len(sq.stepResults))
+	copy(retval, sq.stepResults)
+	return retval
+}
+
+// runStepWithResult runs the given step in the given pipe, and returns
+// what happened
+//
+// i is the step's position in its sequence.
+func runStepWithResult(step *SequenceStep, i int, p *Pipe) StepResult {
+	start := time.Now()
+	statusCode, err := step.RunStep(p)
+	retval := StepResult{
+		Index:      i,
+		Label:      stepLabel(step, i),
+		StatusCode: statusCode,
+		Err:        err,
+		Duration:   time.Since(start),
+	}
+
+	// all done
+	return retval
+}
+
+// setLastStatusCode sets `$?` in the sequence's LocalVars
+func setLastStatusCode(sq *Sequence, statusCode int) {
+	// robustness
+	if sq.LocalVars == nil {
+		return
+	}
+
+	sq.LocalVars.Setenv("$?", strconv.Itoa(statusCode))
+}
+
+// stepLabel returns the label for the given step
+func stepLabel(step *SequenceStep, i int) string {
+	// has the step been given a label?
+	for j := len(step.Opts) - 1; j >= 0; j-- {
+		if step.Opts[j] != nil && step.Opts[j].label != "" {
+			return step.Opts[j].label
+		}
+	}
+
+	// if we get here, we name the step after the function that
+	// created its Command
+	retval := commandName(step.Command)
+	if retval == "" {
+		retval = fmt.Sprintf("step %d", i)
+	}
+	return retval
+}
+
+// scriptishPackagePrefix is what the runtime puts in front of the names
+// of our own functions
+var scriptishPackagePrefix = reflect.TypeOf(StepResult{}).PkgPath() + "."
+
+// commandName returns the name of the function that created the given
+// Command, such as `Exec` or `main.buildStep`
+func commandName(command Command) string {
+	// robustness
+	if command == nil {
+		return ""
+	}
+
+	fn := runtime.FuncForPC(reflect.ValueOf(command).Pointer())
+	if fn == nil {
+		return ""
+	}
+
+	// our own commands are closures, called something like
+	// `github.com/ganbarodigital/go_scriptish.Exec.func1`
+	retval := fn.Name()
+	for {
+		lastDot := strings.LastIndex(retval, ".")
+		if lastDot < 0 || !strings.HasPrefix(retval[lastDot+1:], "func") {
+			break
+		}
+		retval = retval[:lastDot]
+	}
+
+	// our own commands don't need the package name
+	if strings.HasPrefix(retval, scriptishPackagePrefix) {
+		return retval[len(scriptishPackagePrefix):]
+	}
+
+	// everyone else's commands don't need the full package path
+	lastSlash := strings.LastIndex(retval, "/")
+	return retval[lastSlash+1:]
+}
@@ -0,0 +1,297 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStepResultsCopesWithNilSequencePointer(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	var sq *Sequence
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult := sq.StepResults()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Empty(t, actualResult)
+}
+
+func TestStepResultsIsEmptyBeforeTheSequenceRuns(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	list := NewList(Echo("hello world"))
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult := list.StepResults()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Empty(t, actualResult)
+}
+
+func TestStepResultsReportsEveryStepInAList(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedErr := errors.New("step failed")
+	list := NewList(
+		Echo("hello world"),
+		NewSequenceStep(
+			func(p *Pipe) (int, error) {
+				return 3, expectedErr
+			},
+			WithLabel("deploy"),
+		),
+		TestFilepathExists("./testdata/missing"),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	list.Exec()
+	actualResult := list.StepResults()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Len(t, actualResult, 3)
+
+	assert.Equal(t, 0, actualResult[0].Index)
+	assert.Equal(t, "Echo", actualResult[0].Label)
+	assert.Equal(t, StatusOkay, actualResult[0].StatusCode)
+	assert.Nil(t, actualResult[0].Err)
+
+	assert.Equal(t, 1, actualResult[1].Index)
+	assert.Equal(t, "deploy", actualResult[1].Label)
+	assert.Equal(t, 3, actualResult[1].StatusCode)
+	assert.Equal(t, expectedErr, actualResult[1].Err)
+
+	assert.Equal(t, 2, actualResult[2].Index)
+	assert.Equal(t, "TestFilepathExists", actualResult[2].Label)
+	assert.Equal(t, StatusNotOkay, actualResult[2].StatusCode)
+	assert.Error(t, actualResult[2].Err)
+
+	for _, result := range actualResult {
+		assert.True(t, result.Duration >= 0)
+	}
+}
+
+func TestStepResultsOnlyReportsTheStepsThatRan(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	pipeline := NewPipeline(
+		Echo("hello world"),
+		TestFilepathExists("./testdata/missing"),
+		CountLines(),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	pipeline.Exec()
+	actualResult := pipeline.StepResults()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Len(t, actualResult, 2)
+	assert.Equal(t, StatusOkay, actualResult[0].StatusCode)
+	assert.Equal(t, StatusNotOkay, actualResult[1].StatusCode)
+	assert.Equal(t, 1, actualResult[1].Index)
+}
+
+func TestStepResultsReportsEveryStepInAStreamingPipeline(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedErr := errors.New("step failed")
+	pipeline := NewStreamingPipeline(
+		Echo("hello world"),
+		NewSequenceStep(
+			func(p *Pipe) (int, error) {
+				p.DrainStdinToStdout()
+				return 4, expectedErr
+			},
+			WithLabel("middle"),
+		),
+		CountLines(),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	pipeline.Exec()
+	actualResult := pipeline.StepResults()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Len(t, actualResult, 3)
+	assert.Equal(t, "Echo", actualResult[0].Label)
+	assert.Equal(t, StatusOkay, actualResult[0].StatusCode)
+	assert.Equal(t, "middle", actualResult[1].Label)
+	assert.Equal(t, 4, actualResult[1].StatusCode)
+	assert.Equal(t, expectedErr, actualResult[1].Err)
+	assert.Equal(t, "CountLines", actualResult[2].Label)
+	assert.Equal(t, StatusOkay, actualResult[2].StatusCode)
+}
+
+func TestStepResultsAreResetEveryTimeTheSequenceRuns(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	list := NewList(
+		TestFilepathExists("$1"),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	list.Exec("./testdata/missing")
+	firstResult := list.StepResults()
+	list.Exec("./testdata/listfiles/one.txt")
+	secondResult := list.StepResults()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Len(t, firstResult, 1)
+	assert.Equal(t, StatusNotOkay, firstResult[0].StatusCode)
+	assert.Len(t, secondResult, 1)
+	assert.Equal(t, StatusOkay, secondResult[0].StatusCode)
+}
+
+func TestStepResultsLabelsOtherCommandsWithTheirFunctionName(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	list := NewList(
+		NewSequenceStep(noopCommand),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	list.Exec()
+	actualResult := list.StepResults()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Len(t, actualResult, 1)
+	assert.Equal(t, "noopCommand", actualResult[0].Label)
+}
+
+func TestSequenceSetsTheLastStatusCodeAfterEveryStep(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := "0\n1\n0\n"
+	list := NewList(
+		Echo("$?"),
+		TestFilepathExists("./testdata/missing"),
+		Echo("$?"),
+		Echo("$?"),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec().String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestStreamingPipelinesSetTheLastStatusCodeWhenTheyFinish(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	pipeline := NewStreamingPipeline(
+		Echo("hello world"),
+		TestFilepathExists("./testdata/missing"),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	pipeline.Exec()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, "1", pipeline.LocalVars.Getenv("$?"))
+}
@@ -41,6 +41,7 @@ package scriptish
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"testing"
 
@@ -1095,3 +1096,83 @@ func TestSequenceTrimmedStringReturnsContentsOfStdoutWhenError(t *testing.T) {
 	assert.NotNil(t, err)
 	assert.Equal(t, expectedResult, actualResult)
 }
+
+func TestSequenceExecContextMakesTheContextAvailableToEachStep(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	type contextKey string
+	ctx := context.WithValue(context.Background(), contextKey("name"), "alfred")
+
+	expectedResult := "alfred\n"
+	op := NewSequenceStep(
+		func(p *Pipe) (int, error) {
+			name, _ := PipeContext(p).Value(contextKey("name")).(string)
+			p.Stdout.WriteString(name)
+			p.Stdout.WriteRune('\n')
+
+			return StatusOkay, nil
+		},
+	)
+	list := NewList(op)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.ExecContext(ctx).String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestSequenceExecContextDoesNotRunAnyStepsIfContextIsAlreadyCancelled(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	list := NewList(
+		Echo("this should not be seen"),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.ExecContext(ctx).String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Error(t, err)
+	_, ok := err.(ErrCancelled)
+	assert.True(t, ok)
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Empty(t, actualResult)
+}
+
+func TestSequenceExecContextCopesWithNilSequencePointer(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	var sequence *Sequence
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	sequence.ExecContext(context.Background())
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	// as long as it didn't crash, we're good
+}
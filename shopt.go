@@ -42,19 +42,68 @@ package scriptish
 import (
 	"fmt"
 	"io"
+	"sync"
 )
 
 // ShellOptions holds flags and settings that change Scriptish's behaviour
 type ShellOptions struct {
 	// trace is where we send our debugging output to
 	trace io.Writer
+
+	// Errexit makes a List stop at the first step that fails. It is
+	// an emulation of UNIX shell scripting's `set -e`.
+	//
+	// Like UNIX shells, it does not apply to the conditions of If(),
+	// IfElse(), While() and Until(), to any step that is followed by
+	// And() or Or(), or to an And() that did not run its sequence.
+	Errexit bool
+
+	// Nounset makes it an error to expand a variable that has not
+	// been set. It is an emulation of UNIX shell scripting's `set -u`.
+	//
+	// The step that tries to expand the variable fails with an
+	// ErrExpansion, before it does anything else. Like UNIX shells,
+	// this stops the list, even if Errexit is switched off.
+	Nounset bool
+
+	// Pipefail makes a streaming pipeline report the right-most step
+	// that failed, instead of the left-most one. It is an emulation of
+	// UNIX shell scripting's `set -o pipefail`.
+	Pipefail bool
+
+	// Noglob switches off filename globbing and brace expansion. It is
+	// an emulation of UNIX shell scripting's `set -f`.
+	//
+	// Scriptish has no quoting, so this is how you pass `*`, `?`, `[`
+	// and `{` to a command untouched. Use the NoGlob() step option to
+	// switch it off for a single step.
+	Noglob bool
+
+	// Nullglob makes a glob pattern that does not match anything expand
+	// to nothing, instead of being left as it is. It is an emulation of
+	// UNIX shell scripting's `shopt -s nullglob`.
+	Nullglob bool
+
+	// Failglob makes a glob pattern that does not match anything an
+	// error. The step fails with an ErrNoGlobMatch. It is an emulation
+	// of UNIX shell scripting's `shopt -s failglob`, and it wins over
+	// Nullglob.
+	Failglob bool
 }
 
 // shopt holds the parameters you can set to change Scriptish's behaviour
 var shopt ShellOptions
 
+// traceMutex stops trace messages from streaming pipelines getting
+// mixed up with each other
+var traceMutex sync.Mutex
+
 // GetShellOptions gives you access to the package-wide behaviour flags
 // and settings
+//
+// Any changes you make to Errexit, Nounset, Pipefail and the globbing
+// options apply to every sequence that you Exec() afterwards, unless the
+// sequence has its own ShellOptions.
 func GetShellOptions() *ShellOptions {
 	return &shopt
 }
@@ -83,6 +132,8 @@ func IsTraceEnabled() bool {
 // Tracef writes a trace message to os.Stderr if tracing is enabled
 func Tracef(format string, args ...interface{}) {
 	if IsTraceEnabled() {
+		traceMutex.Lock()
+		defer traceMutex.Unlock()
 		fmt.Fprintf(shopt.trace, "+ "+format+"\n", args...)
 	}
 }
@@ -65,6 +65,26 @@ func TestShoptTracingIsDisabledByDefault(t *testing.T) {
 	assert.Equal(t, expectedResult, actualResult2)
 }
 
+func TestShoptShellOptionsAreSwitchedOffByDefault(t *testing.T) {
+	// ----------------------------------------------------------------
+	// setup your test
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	opts := GetShellOptions()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.False(t, opts.Errexit)
+	assert.False(t, opts.Nounset)
+	assert.False(t, opts.Pipefail)
+	assert.False(t, opts.Noglob)
+	assert.False(t, opts.Nullglob)
+	assert.False(t, opts.Failglob)
+}
+
 func TestShoptTracingCanBeEnabled(t *testing.T) {
 
 	// ----------------------------------------------------------------
@@ -51,14 +51,18 @@ func AppendToFile(filename string, opts ...*StepOption) *SequenceStep {
 	return NewSequenceStep(
 		func(p *Pipe) (int, error) {
 			// expand our input
-			expFilename := p.Env.Expand(filename)
+			expFilename, err := expandPathArg(p, filename)
 
 			// debugging support
 			Tracef("AppendToFile(%#v)", filename)
 			Tracef("=> AppendToFile(%#v)", expFilename)
 
+			if err != nil {
+				return StatusNotOkay, err
+			}
+
 			// open / create the file
-			fh, err := os.OpenFile(expFilename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+			fh, err := os.OpenFile(resolvePipePath(p, expFilename), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 			if err != nil {
 				return StatusNotOkay, err
 			}
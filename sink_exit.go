@@ -39,12 +39,17 @@
 
 package scriptish
 
-import "os"
+import (
+	"io"
+	"os"
+)
 
 // Exit terminates the Golang app with the given status code.
 //
-// It does *NOT* flush the pipe's Stdout or Stderr to your Golang's
-// os.Stdout / os.Stderr first.
+// Before it does, it writes out anything that is waiting in the pipe's
+// Stdout and Stderr to your Golang's os.Stdout / os.Stderr, along with
+// anything waiting in the Stdout and Stderr of whatever is running
+// the pipe (eg, the list that an If() belongs to).
 //
 // NOTE: it does *NOT* support StepOptions
 func Exit(statusCode int) *SequenceStep {
@@ -54,6 +59,16 @@ func Exit(statusCode int) *SequenceStep {
 			// debugging support
 			Tracef("Exit(%d)", statusCode)
 
+			// whatever is running us wrote its output first
+			pipes := []*Pipe{}
+			for pipe := p; pipe != nil; pipe = getCallerPipe(pipe) {
+				pipes = append(pipes, pipe)
+			}
+			for i := len(pipes) - 1; i >= 0; i-- {
+				io.Copy(os.Stdout, pipes[i].Stdout)
+				io.Copy(os.Stderr, pipes[i].Stderr)
+			}
+
 			// all done
 			os.Exit(statusCode)
 
@@ -0,0 +1,104 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestExitHelperProcess is not a real test. The Exit() tests run it in
+// a child process, because Exit() terminates the process that runs it.
+func TestExitHelperProcess(t *testing.T) {
+	if os.Getenv("SCRIPTISH_EXIT_HELPER") != "1" {
+		return
+	}
+
+	list := NewList(
+		Echo("before the if"),
+		If(
+			NewList(TestNotEmpty("x")),
+			NewList(
+				Echo("inside the if"),
+				Echo("to stderr", RedirectStdoutToStderr()),
+				Exit(3),
+			),
+		),
+		Echo("this should not be seen"),
+	)
+	list.Exec()
+	list.Flush(os.Stdout, os.Stderr)
+	os.Exit(0)
+}
+
+func TestExitWritesOutAnyOutputBeforeExiting(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedStdout := "before the if\ninside the if\n"
+	expectedStderr := "to stderr\n"
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestExitHelperProcess$")
+	cmd.Env = append(os.Environ(), "SCRIPTISH_EXIT_HELPER=1")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualStdout, err := cmd.Output()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	exitErr, ok := err.(*exec.ExitError)
+	assert.True(t, ok)
+	if ok {
+		assert.Equal(t, 3, exitErr.ExitCode())
+	}
+	assert.Equal(t, expectedStdout, string(actualStdout))
+	assert.Equal(t, expectedStderr, stderr.String())
+}
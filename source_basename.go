@@ -53,12 +53,16 @@ func Basename(input string, opts ...*StepOption) *SequenceStep {
 	return NewSequenceStep(
 		func(p *Pipe) (int, error) {
 			// expand our input
-			expInput := p.Env.Expand(input)
+			expInput, err := expandString(p, input)
 
 			// debugging support
 			Tracef("Basename(%#v)", input)
 			Tracef("=> Basename(%#v)", expInput)
 
+			if err != nil {
+				return StatusNotOkay, err
+			}
+
 			var basename string
 
 			if len(strings.TrimSpace(expInput)) > 0 {
@@ -46,29 +46,38 @@ import (
 )
 
 // CatFile writes the contents of a file to the pipeline's stdout
+//
+// If filename is a glob pattern or a brace expression, CatFile writes
+// the contents of every file that it expands to, one after the other.
 func CatFile(filename string, opts ...*StepOption) *SequenceStep {
 	// build our Scriptish command
 	return NewSequenceStep(
 		func(p *Pipe) (int, error) {
 			// expand our input
-			expFilename := p.Env.Expand(filename)
+			expFilenames, err := expandPathArgs(p, filename)
 
 			// debugging support
 			Tracef("CatFile(%#v)", filename)
-			Tracef("=> CatFile(%#v)", expFilename)
+			Tracef("=> CatFile(%s)", tracePathArgs(expFilenames))
 
-			// can we open the file?
-			f, err := os.Open(expFilename)
 			if err != nil {
 				return StatusNotOkay, err
 			}
 
-			// copy the file into our pipeline
-			p.Stdin = ioextra.NewTextFile(f)
-			for line := range p.Stdin.ReadLines() {
-				TracePipeStdout("%s", line)
-				p.Stdout.WriteString(line)
-				p.Stdout.WriteRune('\n')
+			for _, expFilename := range expFilenames {
+				// can we open the file?
+				f, err := os.Open(resolvePipePath(p, expFilename))
+				if err != nil {
+					return StatusNotOkay, err
+				}
+
+				// copy the file into our pipeline
+				p.Stdin = ioextra.NewTextFile(f)
+				for line := range p.Stdin.ReadLines() {
+					TracePipeStdout("%s", line)
+					p.Stdout.WriteString(line)
+					p.Stdout.WriteRune('\n')
+				}
 			}
 
 			// all done
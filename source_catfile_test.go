@@ -77,6 +77,36 @@ func TestCatFileReadsFromFilename(t *testing.T) {
 	assert.Equal(t, expectedResult, actualResult)
 }
 
+func TestCatFileReadsEveryFileThatAGlobMatches(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := "This is a test file.\n" +
+		"\n" +
+		"It contains three lines.\n" +
+		"This is another test file.\n" +
+		"\n" +
+		"It is called two.txt.\n" +
+		"It contains five lines.\n" +
+		"\n"
+	pipeline := NewPipeline(
+		CatFile("./testdata/concatfiles/*.txt"),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := pipeline.Exec().String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
 func TestCatFileSetsErrorWhenFilenameDoesNotExist(t *testing.T) {
 	// ----------------------------------------------------------------
 	// setup your test
@@ -53,12 +53,16 @@ func Dirname(input string, opts ...*StepOption) *SequenceStep {
 	return NewSequenceStep(
 		func(p *Pipe) (int, error) {
 			// expand our input
-			expInput := p.Env.Expand(input)
+			expInput, err := expandString(p, input)
 
 			// debugging support
 			Tracef("Dirname(%#v)", input)
 			Tracef("=> Dirname(%#v)", expInput)
 
+			if err != nil {
+				return StatusNotOkay, err
+			}
+
 			// special case:
 			//
 			// filepath.Dir() does not handle trailing slashes correctly
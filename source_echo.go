@@ -49,12 +49,16 @@ func Echo(input string, opts ...*StepOption) *SequenceStep {
 	return NewSequenceStep(
 		func(p *Pipe) (int, error) {
 			// expand our input
-			expInput := p.Env.Expand(input)
+			expInput, err := expandString(p, input)
 
 			// debugging support
 			Tracef("Echo(%#v)", input)
 			Tracef("=> Echo(%#v)", expInput)
 
+			if err != nil {
+				return StatusNotOkay, err
+			}
+
 			TracePipeStdout("%s", expInput)
 			p.Stdout.WriteString(expInput)
 
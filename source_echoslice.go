@@ -58,7 +58,10 @@ func EchoSlice(input []string, opts ...*StepOption) *SequenceStep {
 			// send the slice to the pipe
 			for _, line := range input {
 				// expand our input
-				expLine := p.Env.Expand(line)
+				expLine, err := expandString(p, line)
+				if err != nil {
+					return StatusNotOkay, err
+				}
 
 				TracePipeStdout("%s", expLine)
 				p.Stdout.WriteString(expLine)
@@ -49,12 +49,16 @@ func EchoToStderr(input string, opts ...*StepOption) *SequenceStep {
 	return NewSequenceStep(
 		func(p *Pipe) (int, error) {
 			// expand our input
-			expInput := p.Env.Expand(input)
+			expInput, err := expandString(p, input)
 
 			// debugging support
 			Tracef("EchoToStderr(%#v)", input)
 			Tracef("=> EchoToStderr(%#v)", expInput)
 
+			if err != nil {
+				return StatusNotOkay, err
+			}
+
 			// write it
 			TracePipeStderr("%s", expInput)
 			p.Stderr.WriteString(expInput)
@@ -46,64 +46,128 @@ import (
 // Exec runs an operating system command, and posts the results to
 // the pipeline's Stdout and Stderr.
 //
+// The command's output is written to the pipeline's Stdout and Stderr
+// as soon as the command writes it. If they are the same (eg, when you
+// use RedirectStderrToStdout()), the command's output stays in the order
+// that the command wrote it.
+//
 // The command's status code is stored in the pipeline.StatusCode.
+//
+// The command is killed if the pipeline's context.Context is cancelled.
+// If the context has a deadline (eg, from Timeout() or WithTimeout()),
+// the command runs in its own process group, and any processes that it
+// starts are killed too.
+//
+// The command's environment is the program's environment, plus any
+// local variables that have been exported by Export().
+//
+// The command runs in the sequence's working directory.
+//
+// Any argument that contains a glob pattern or a brace expression is
+// expanded into one argument per match, just like UNIX shells do. Use
+// the NoGlob() step option to pass these arguments on untouched.
 func Exec(args []string, opts ...*StepOption) *SequenceStep {
 	// build our Scriptish command
 	return NewSequenceStep(
 		func(p *Pipe) (int, error) {
 			// expand our input
-			expArgs := make([]string, len(args))
-			for i := 0; i < len(args); i++ {
-				expArgs[i] = p.Env.Expand(args[i])
-			}
+			expArgs, err := expandArgs(p, args)
 
 			// debugging support
 			Tracef("Exec(%#v)", args)
 			Tracef("=> Exec(%#v)", expArgs)
 
-			// build our command
-			cmd := exec.Command(expArgs[0], expArgs[1:]...)
-
-			// attach all of our inputs and outputs
-			stdout := NewTextBuffer()
-			stderr := NewTextBuffer()
-			cmd.Stdin = p.Stdin
-			cmd.Stdout = stdout
-			cmd.Stderr = stderr
-
-			// let's do it
-			err := cmd.Start()
 			if err != nil {
 				return StatusNotOkay, err
 			}
 
-			// wait for it to finish
-			err = cmd.Wait()
-
-			// copy the output to our pipe
-			//
-			// it's not ideal, because we can't preserve the original mixed
-			// order of the command's output atm
-			//
-			// at some point, we'll need a new version of pipe that does
-			// support preserving mixed order output!
-			for line := range stdout.ReadLines() {
-				TracePipeStdout("%s", line)
-				p.Stdout.WriteString(line)
-				p.Stdout.WriteRune('\n')
-			}
-			for line := range stderr.ReadLines() {
-				TracePipeStderr("%s", line)
-				p.Stderr.WriteString(line)
-				p.Stderr.WriteRune('\n')
-			}
-
-			// we want the process's status code
-			statusCode := cmd.ProcessState.ExitCode()
-
-			// all done
-			return statusCode, err
+			// let's do it
+			return runExecCommand(p, expArgs, nil)
 		},
 		opts...,
 	)
 }
+
+// runExecCommand does the work for Exec() and ExecWithEnv()
+//
+// extraEnv is a list of 'key=value' pairs to add to the command's
+// environment
+func runExecCommand(p *Pipe, expArgs []string, extraEnv []string) (int, error) {
+	// like UNIX shells, there is nothing to run if the command
+	// expanded to nothing
+	if len(expArgs) == 0 {
+		return StatusOkay, nil
+	}
+
+	// have we been cancelled already?
+	ctx := PipeContext(p)
+	if ctx.Err() != nil {
+		return StatusNotOkay, ErrCancelled{ctx.Err()}
+	}
+
+	// build our command
+	//
+	// we cannot use exec.CommandContext() here; see killOnCancel()
+	// for why
+	cmd := exec.Command(expArgs[0], expArgs[1:]...)
+
+	// if we are up against a deadline, we need to be able to kill
+	// off anything that the command starts too
+	if _, ok := ctx.Deadline(); ok {
+		startProcessGroup(cmd)
+	}
+
+	// the command needs to see our exported variables too
+	cmd.Env = getExecEnviron(p)
+
+	// the command runs in our working directory
+	env, ok := getSequenceEnv(p)
+	if ok && env.dir != "" {
+		cmd.Dir = env.dir
+		cmd.Env = append(cmd.Env, "PWD="+env.dir)
+	}
+
+	// any one-off variables go last, so that they win
+	cmd.Env = append(cmd.Env, extraEnv...)
+
+	// attach all of our inputs and outputs
+	//
+	// the command's output goes straight into our pipe, as soon as
+	// the command writes it
+	stdout := newExecOutput(p.Stdout, TracePipeStdout)
+	stderr := stdout
+	if !sameWriter(p.Stdout, p.Stderr) {
+		stderr = newExecOutput(p.Stderr, TracePipeStderr)
+	}
+	cmd.Stdin = p.Stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	// let's do it
+	err := cmd.Start()
+	if err != nil {
+		return StatusNotOkay, err
+	}
+
+	// wait for it to finish
+	//
+	// the command is killed if the pipe's context is cancelled
+	commandFinished := killOnCancel(ctx, cmd)
+	err = cmd.Wait()
+	commandFinished()
+
+	// make sure that nothing else ends up on the command's last line
+	stdout.finish()
+	stderr.finish()
+
+	// were we killed off?
+	if err != nil && ctx.Err() != nil {
+		return StatusNotOkay, ErrCancelled{ctx.Err()}
+	}
+
+	// we want the process's status code
+	statusCode := cmd.ProcessState.ExitCode()
+
+	// all done
+	return statusCode, err
+}
@@ -40,9 +40,12 @@
 package scriptish
 
 import (
+	"context"
+	"errors"
 	"os"
 	"os/exec"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -179,6 +182,75 @@ func TestExecSetsErrorIfCommandCannotBeRun(t *testing.T) {
 	assert.Equal(t, expectedResult, pipeline.StatusCode())
 }
 
+func TestExecExpandsGlobPatternsIntoSeparateArgs(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := "testdata/listfiles/one.txt|testdata/listfiles/two.txt|x1|x2|\n"
+	pipeline := NewPipeline(
+		Exec([]string{"/usr/bin/env", "printf", "%s|", "testdata/listfiles/*.txt", "x{1..2}"}),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := pipeline.Exec().String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestExecPassesEachPositionalParameterAsASeparateArgForDollarAt(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := "a *|b|$c|a * b $c|\n"
+	pipeline := NewPipeline(
+		Exec([]string{"/usr/bin/env", "printf", "%s|", "$@", "$*"}),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := pipeline.Exec("a *", "b", "$c").String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestExecDoesNothingIfTheCommandExpandsToNothing(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	pipeline := NewPipeline(
+		Exec([]string{"testdata/*.does-not-exist"}),
+	)
+	pipeline.ShellOptions = &ShellOptions{Nullglob: true}
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := pipeline.Exec().String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Empty(t, actualResult)
+}
+
 func TestExecWritesToTheTraceOutput(t *testing.T) {
 
 	// ----------------------------------------------------------------
@@ -213,3 +285,156 @@ func TestExecWritesToTheTraceOutput(t *testing.T) {
 
 	assert.Equal(t, expectedResult, actualResult)
 }
+
+func TestExecKillsTheCommandWhenTheContextIsCancelled(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	pipeline := NewPipeline(
+		Exec([]string{"/usr/bin/env", "sleep", "10"}),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	start := time.Now()
+	pipeline.ExecContext(ctx)
+	duration := time.Since(start)
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	err := pipeline.Error()
+	_, ok := err.(ErrCancelled)
+	assert.True(t, ok)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.True(t, duration < 5*time.Second)
+}
+
+func TestExecDoesNotPassUnexportedLocalVariablesToTheCommand(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := ""
+	list := NewList(
+		Exec([]string{"/bin/sh", "-c", "printenv SCRIPTISH_TEST_LOCAL || true"}),
+	)
+	list.LocalVars.Setenv("SCRIPTISH_TEST_LOCAL", "hello world")
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec().String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestExecPassesTheProgramEnvironmentToTheCommand(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := os.Getenv("PATH") + "\n"
+	list := NewList(
+		Exec([]string{"/usr/bin/env", "printenv", "PATH"}),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec().String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestExecPreservesTheOrderOfStdoutAndStderrWhenTheyAreRedirectedTogether(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := "one\ntwo\nthree\nfour\n"
+	pipeline := NewPipeline(
+		Exec(
+			[]string{"/bin/sh", "-c", "echo one; echo two >&2; echo three; echo four >&2"},
+			RedirectStderrToStdout(),
+		),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := pipeline.Exec().String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestExecKeepsOutputWrittenBeforeTheCommandIsKilled(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := "hello world\n"
+	pipeline := NewPipeline(
+		Exec(
+			[]string{"/bin/sh", "-c", "echo hello world; sleep 10"},
+			WithTimeout(500*time.Millisecond),
+		),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	pipeline.Exec()
+	actualResult := pipeline.Pipe.Stdout.String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, expectedResult, actualResult)
+	assert.Equal(t, StatusTimeout, pipeline.StatusCode())
+}
+
+func TestExecAddsAMissingNewlineToTheEndOfTheOutput(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := "hello world\n"
+	pipeline := NewPipeline(
+		Exec([]string{"/usr/bin/env", "printf", "hello world"}),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := pipeline.Exec().String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
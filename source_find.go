@@ -0,0 +1,261 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Find walks the tree under root, and writes every path that passes all
+// of the given predicates to the pipeline's Stdout, one path per line.
+// With no predicates, it writes out every path that it finds.
+//
+// It starts with root itself, and looks inside folders in alphabetical
+// order. Paths start with root, just like UNIX `find`: if root is `.`,
+// you get `.`, `./a`, `./a/b` and so on.
+//
+// root goes through the same glob patterns and brace expansion as every
+// other filepath. If it expands to several paths, Find walks each of
+// them in turn. Relative paths are resolved against the sequence's
+// working directory.
+//
+// If Find cannot look at part of the tree, it carries on with the rest
+// of the tree, and returns the first error when it has finished.
+//
+// It is an emulation of UNIX `find root expr`.
+func Find(root string, preds []FindPredicate, opts ...*StepOption) *SequenceStep {
+	// build our Scriptish command
+	return NewSequenceStep(
+		func(p *Pipe) (int, error) {
+			// expand our input
+			expRoots, err := expandPathArgs(p, root)
+
+			// debugging support
+			Tracef("Find(%#v)", root)
+			Tracef("=> Find(%s)", tracePathArgs(expRoots))
+
+			if err != nil {
+				return StatusNotOkay, err
+			}
+
+			// prepare our predicates
+			walker := findWalker{
+				p: p,
+				settings: findSettings{
+					maxDepth: -1,
+					now:      time.Now(),
+				},
+			}
+			walker.test, err = FindAnd(preds...).prepare(p, &walker.settings)
+			if err != nil {
+				return StatusNotOkay, err
+			}
+
+			// walk the tree(s)
+			for _, expRoot := range expRoots {
+				err = walker.walk(expRoot, resolvePipePath(p, expRoot), 0, nil)
+				if err != nil {
+					return StatusNotOkay, err
+				}
+			}
+
+			// did anything go wrong along the way?
+			if walker.firstErr != nil {
+				return StatusNotOkay, walker.firstErr
+			}
+
+			// all done
+			return StatusOkay, nil
+		},
+		opts...,
+	)
+}
+
+// findSettings change how Find() walks the tree
+type findSettings struct {
+	// maxDepth is the deepest that we look; -1 means no limit
+	maxDepth int
+
+	// minDepth is the shallowest that we test paths at
+	minDepth int
+
+	// followSymlinks makes us look at what symlinks point to
+	followSymlinks bool
+
+	// now is when Find() started, for age-based predicates
+	now time.Time
+}
+
+// foundPath is a path that Find() has found
+type foundPath struct {
+	// path is the path that we write out
+	path string
+
+	// osPath is the path that we give to the operating system
+	osPath string
+
+	// info describes what is at the path
+	info os.FileInfo
+
+	// prune is set by FindPrune()
+	prune bool
+}
+
+// name returns the last element of the path
+func (f *foundPath) name() string {
+	return filepath.Base(f.path)
+}
+
+// findWalker walks the tree for Find()
+type findWalker struct {
+	p        *Pipe
+	settings findSettings
+	test     findTest
+
+	// firstErr is the first problem that we could carry on from
+	firstErr error
+}
+
+// errFindLoop is the reason we give when following a symlink would take
+// us round in circles
+var errFindLoop = errors.New("file system loop detected")
+
+// walk tests the given path, and then looks inside it if it is a folder
+//
+// parents are the folders that we are already inside, so that we can
+// spot symlink loops.
+//
+// It only returns an error if Find() needs to stop.
+func (w *findWalker) walk(path, osPath string, depth int, parents []os.FileInfo) error {
+	info, err := w.stat(osPath)
+	if err != nil {
+		// special case - the starting point must exist
+		if depth == 0 {
+			return err
+		}
+		w.remember(err)
+		return nil
+	}
+
+	found := foundPath{path: path, osPath: osPath, info: info}
+	if depth >= w.settings.minDepth {
+		ok, err := w.test(&found)
+		if err != nil {
+			w.remember(err)
+		}
+		if ok {
+			TracePipeStdout("%s", path)
+			w.p.Stdout.WriteString(path)
+			w.p.Stdout.WriteRune('\n')
+		}
+	}
+
+	// are we going inside?
+	if !info.IsDir() || found.prune || (w.settings.maxDepth >= 0 && depth >= w.settings.maxDepth) {
+		return nil
+	}
+	for _, parent := range parents {
+		if os.SameFile(parent, info) {
+			w.remember(&os.PathError{Op: "find", Path: path, Err: errFindLoop})
+			return nil
+		}
+	}
+
+	entries, err := ioutil.ReadDir(osPath)
+	if err != nil {
+		w.remember(err)
+		return nil
+	}
+
+	parents = append(parents, info)
+	for _, entry := range entries {
+		// have we been told to stop?
+		ctxErr := PipeContext(w.p).Err()
+		if ctxErr != nil {
+			return ErrCancelled{ctxErr}
+		}
+
+		err = w.walk(joinFindPath(path, entry.Name()), filepath.Join(osPath, entry.Name()), depth+1, parents)
+		if err != nil {
+			return err
+		}
+	}
+
+	// all done
+	return nil
+}
+
+// stat returns the FileInfo that our predicates look at
+func (w *findWalker) stat(osPath string) (os.FileInfo, error) {
+	if !w.settings.followSymlinks {
+		return os.Lstat(osPath)
+	}
+
+	// like UNIX `find -L`, broken symlinks are still symlinks
+	info, err := os.Stat(osPath)
+	if err != nil {
+		return os.Lstat(osPath)
+	}
+	return info, nil
+}
+
+// remember keeps hold of the first error that Find() can carry on from
+func (w *findWalker) remember(err error) {
+	Tracef("=> %s", err.Error())
+
+	if w.firstErr == nil {
+		w.firstErr = err
+	}
+}
+
+// joinFindPath adds name onto the end of path, without cleaning the
+// path up like filepath.Join() does
+func joinFindPath(path, name string) string {
+	if strings.HasSuffix(path, string(filepath.Separator)) {
+		return path + name
+	}
+
+	return path + string(filepath.Separator) + name
+}
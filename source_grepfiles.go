@@ -0,0 +1,442 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"syscall"
+)
+
+// GrepFilesOptions tells GrepFiles() which files to search, how to
+// match lines, and what to write out
+type GrepFilesOptions struct {
+	// GrepOptions says how to match lines, and what to write out for
+	// each file, just like it does for GrepWith()
+	GrepOptions
+
+	// Recursive searches inside folders, like `grep -r`
+	//
+	// Like `grep -r`, symlinks inside the folders are skipped.
+	Recursive bool
+
+	// Include only searches files whose names match one of these glob
+	// patterns, like `grep --include`
+	Include []string
+
+	// Exclude skips files whose names match any of these glob
+	// patterns, like `grep --exclude`
+	Exclude []string
+
+	// ExcludeDir skips folders whose names match any of these glob
+	// patterns, like `grep --exclude-dir`
+	ExcludeDir []string
+
+	// FilesWithMatches only writes out the names of the files that
+	// have selected lines, like `grep -l`
+	FilesWithMatches bool
+
+	// FilesWithoutMatch only writes out the names of the files that
+	// do not have any selected lines, like `grep -L`
+	FilesWithoutMatch bool
+
+	// WithFilename always puts the filename in front of each line,
+	// like `grep -H`
+	WithFilename bool
+
+	// NoFilename never puts the filename in front of each line, like
+	// `grep -h`
+	NoFilename bool
+
+	// SkipBinaryFiles does not search binary files, like `grep -I`
+	SkipBinaryFiles bool
+
+	// BinaryAsText searches binary files as if they were text, like
+	// `grep -a`
+	BinaryAsText bool
+}
+
+// grepBinaryPeekSize is how much of each file we look at, to see if it
+// is a binary file
+const grepBinaryPeekSize = 32 * 1024
+
+// GrepFiles searches the given files for lines that match any of the
+// given regexes, and writes out what it finds, like `grep` does
+//
+// By default, it writes out each selected line. The filename goes in
+// front of each line when there is more than one file to search. Use
+// gfopts to write out the names of the files, or how many lines each
+// file has, instead.
+//
+// Each path goes through the same glob patterns and brace expansion as
+// every other filepath. Relative paths are resolved against the
+// sequence's working directory. When there are no paths, a Recursive
+// search looks in the sequence's working directory.
+//
+// Folders are searched in alphabetical order. A file that has a NUL
+// byte in its first 32KB is a binary file. Like `grep`, we do not write
+// out the matching lines of a binary file; we write a message to Stderr
+// instead.
+//
+// If GrepFiles cannot search a file, it carries on with the rest of the
+// files, and returns the first error when it has finished. Like `grep`,
+// it returns StatusNotOkay if no lines were selected. Like GNU grep 3.5
+// and later, that is true for FilesWithoutMatch too: it is the lines
+// that count, not the names of the files that are written out.
+//
+// It is an emulation of `grep -E -r ... paths`.
+func GrepFiles(patterns []string, paths []string, gfopts GrepFilesOptions, opts ...*StepOption) *SequenceStep {
+	// build our Scriptish command
+	return NewSequenceStep(
+		func(p *Pipe) (int, error) {
+			// expand our input
+			expPatterns, err := expandGrepPatterns(p, patterns, gfopts.GrepOptions)
+			var expPaths []string
+			for _, path := range paths {
+				if err != nil {
+					break
+				}
+				var expPath []string
+				expPath, err = expandPathArgs(p, path)
+				expPaths = append(expPaths, expPath...)
+			}
+
+			// debugging support
+			Tracef("GrepFiles(%#v, %#v, %#v)", patterns, paths, gfopts)
+			Tracef("=> GrepFiles(%#v, []string{%s}, %#v)", expPatterns, tracePathArgs(expPaths), gfopts)
+
+			if err != nil {
+				return StatusNotOkay, err
+			}
+
+			// do we have valid patterns?
+			walker := grepFilesWalker{p: p, opts: gfopts}
+			walker.grepper, err = newGrepper(expPatterns, gfopts.GrepOptions)
+			if err != nil {
+				return StatusNotOkay, err
+			}
+			walker.include, err = compileGrepFilesGlobs(p, gfopts.Include)
+			if err != nil {
+				return StatusNotOkay, err
+			}
+			walker.exclude, err = compileGrepFilesGlobs(p, gfopts.Exclude)
+			if err != nil {
+				return StatusNotOkay, err
+			}
+			walker.excludeDir, err = compileGrepFilesGlobs(p, gfopts.ExcludeDir)
+			if err != nil {
+				return StatusNotOkay, err
+			}
+
+			// special case - a recursive search with no paths
+			if len(expPaths) == 0 && gfopts.Recursive {
+				walker.showNames = !gfopts.NoFilename
+				err = walker.walkDir("", resolvePipePath(p, "."))
+				if err != nil {
+					return StatusNotOkay, err
+				}
+			}
+
+			// search the files
+			for _, expPath := range expPaths {
+				osPath := resolvePipePath(p, expPath)
+				info, err := os.Stat(osPath)
+				if err != nil {
+					walker.remember(err)
+					continue
+				}
+
+				// like grep, we only show filenames when there is more
+				// than one file to search
+				walker.showNames = gfopts.WithFilename || (!gfopts.NoFilename && (len(expPaths) > 1 || (info.IsDir() && gfopts.Recursive)))
+
+				switch {
+				case !info.IsDir():
+					err = walker.grepFile(expPath, osPath, info)
+				case gfopts.Recursive:
+					err = walker.walkDir(expPath, osPath)
+				default:
+					walker.remember(&os.PathError{Op: "grep", Path: expPath, Err: syscall.EISDIR})
+				}
+				if err != nil {
+					return StatusNotOkay, err
+				}
+			}
+
+			// did anything go wrong along the way?
+			if walker.firstErr != nil {
+				return StatusNotOkay, walker.firstErr
+			}
+
+			// like grep, we fail if nothing was selected
+			if walker.selected == 0 {
+				return StatusNotOkay, nil
+			}
+
+			// all done
+			return StatusOkay, nil
+		},
+		opts...,
+	)
+}
+
+// grepFilesWalker searches the files for GrepFiles()
+type grepFilesWalker struct {
+	p       *Pipe
+	opts    GrepFilesOptions
+	grepper *grepper
+
+	// the (expanded) Include, Exclude and ExcludeDir glob patterns
+	include    []*regexp.Regexp
+	exclude    []*regexp.Regexp
+	excludeDir []*regexp.Regexp
+
+	// showNames is true when we put filenames in front of each line
+	showNames bool
+
+	// selected is how many lines we have selected, in all of the files
+	selected int
+
+	// wroteOutput is true once we have written out any lines (or a
+	// binary file message) for any of the files
+	wroteOutput bool
+
+	// firstErr is the first problem that we could carry on from
+	firstErr error
+}
+
+// walkDir searches every file inside the given folder
+//
+// It only returns an error if GrepFiles() needs to stop.
+func (w *grepFilesWalker) walkDir(path, osPath string) error {
+	entries, err := ioutil.ReadDir(osPath)
+	if err != nil {
+		w.remember(err)
+		return nil
+	}
+
+	for _, entry := range entries {
+		// have we been told to stop?
+		ctxErr := PipeContext(w.p).Err()
+		if ctxErr != nil {
+			return ErrCancelled{ctxErr}
+		}
+
+		entryPath := entry.Name()
+		if path != "" {
+			entryPath = joinFindPath(path, entry.Name())
+		}
+		entryOsPath := filepath.Join(osPath, entry.Name())
+
+		switch {
+		case entry.IsDir():
+			if grepFilesMatchAny(w.excludeDir, entry.Name()) {
+				continue
+			}
+			err = w.walkDir(entryPath, entryOsPath)
+		case entry.Mode().IsRegular():
+			err = w.grepFile(entryPath, entryOsPath, entry)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	// all done
+	return nil
+}
+
+// grepFile searches a single file
+//
+// It only returns an error if GrepFiles() needs to stop.
+func (w *grepFilesWalker) grepFile(path, osPath string, info os.FileInfo) error {
+	// do we want to search this file?
+	if len(w.include) > 0 && !grepFilesMatchAny(w.include, info.Name()) {
+		return nil
+	}
+	if grepFilesMatchAny(w.exclude, info.Name()) {
+		return nil
+	}
+
+	f, err := os.Open(osPath)
+	if err != nil {
+		w.remember(err)
+		return nil
+	}
+	defer f.Close()
+
+	// is it a binary file?
+	r := bufio.NewReaderSize(f, grepBinaryPeekSize)
+	peek, _ := r.Peek(grepBinaryPeekSize)
+	binary := !w.opts.BinaryAsText && bytes.IndexByte(peek, 0) >= 0
+	if binary && w.opts.SkipBinaryFiles {
+		return nil
+	}
+
+	// when we are not writing out the lines, we only need to find one
+	fileGrepper := *w.grepper
+	if w.opts.FilesWithMatches || w.opts.FilesWithoutMatch || (binary && !w.opts.Count) {
+		fileGrepper.opts.Count = true
+		fileGrepper.opts.MaxCount = 1
+	}
+
+	name := ""
+	if w.showNames {
+		name = path
+	}
+
+	// search the file
+	var readErr error
+	readLine := func() (string, bool) {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			if err != io.EOF {
+				readErr = err
+			}
+			return line, line != ""
+		}
+		return line[:len(line)-1], true
+	}
+	selected := fileGrepper.grepLines(readLine, name, w.contextWriter())
+	w.selected += selected
+	if readErr != nil {
+		w.remember(&os.PathError{Op: "read", Path: path, Err: readErr})
+	}
+
+	// what do we need to write out?
+	switch {
+	case w.opts.FilesWithMatches:
+		if selected > 0 {
+			w.write(path)
+		}
+	case w.opts.FilesWithoutMatch:
+		if selected == 0 {
+			w.write(path)
+		}
+	case w.opts.Count:
+		if name != "" {
+			w.write(name + ":" + strconv.Itoa(selected))
+		} else {
+			w.write(strconv.Itoa(selected))
+		}
+	case binary && selected > 0:
+		w.p.Stderr.WriteString(path + ": binary file matches\n")
+		w.wroteOutput = true
+	}
+
+	// all done
+	return nil
+}
+
+// write writes a line to the pipe's Stdout
+func (w *grepFilesWalker) write(line string) {
+	TracePipeStdout("%s", line)
+	w.p.Stdout.WriteString(line)
+	w.p.Stdout.WriteRune('\n')
+}
+
+// contextWriter returns the write function for searching the next file
+//
+// Like grep, when we are writing out lines of context, we put `--`
+// between the lines that we write out for each file. A binary file
+// that matches counts as a file that we have written out lines for.
+func (w *grepFilesWalker) contextWriter() func(string) {
+	if w.opts.BeforeContext == 0 && w.opts.AfterContext == 0 {
+		return w.write
+	}
+
+	firstLine := true
+	return func(line string) {
+		if firstLine && w.wroteOutput {
+			w.write("--")
+		}
+		firstLine = false
+		w.wroteOutput = true
+		w.write(line)
+	}
+}
+
+// remember keeps hold of the first error that GrepFiles() can carry on
+// from
+func (w *grepFilesWalker) remember(err error) {
+	Tracef("=> %s", err.Error())
+
+	if w.firstErr == nil {
+		w.firstErr = err
+	}
+}
+
+// compileGrepFilesGlobs expands the given glob patterns, and turns them
+// into regexes
+func compileGrepFilesGlobs(p *Pipe, globs []string) ([]*regexp.Regexp, error) {
+	retval := []*regexp.Regexp{}
+	for _, glob := range globs {
+		expGlob, err := expandString(p, glob)
+		if err != nil {
+			return nil, err
+		}
+		re, err := regexp.Compile(globToRegexp(expGlob))
+		if err != nil {
+			return nil, err
+		}
+		retval = append(retval, re)
+	}
+
+	// all done
+	return retval, nil
+}
+
+// grepFilesMatchAny returns true if the name matches any of the given
+// glob patterns
+func grepFilesMatchAny(globs []*regexp.Regexp, name string) bool {
+	for _, glob := range globs {
+		if glob.MatchString(name) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,418 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// makeGrepFilesFolder creates a folder of files for GrepFiles() to
+// search
+func makeGrepFilesFolder(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "scriptish-grepfiles-")
+	assert.Nil(t, err)
+
+	files := map[string]string{
+		"src/a.go":        "package a\n\n// TODO: one\nfunc a() {}\n",
+		"src/b.go":        "package b\n// todo: two\n// TODO three\n",
+		"src/c.txt":       "nothing to do here\n",
+		"src/nonl.go":     "// TODO: no newline",
+		"src/sub/d.go":    "x\ny\n// TODO: four\nz\n",
+		"src/.git/config": "TODO git\n",
+		"src/data.bin":    "TODO\x00binary\n",
+		"other.txt":       "another TODO\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		assert.Nil(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.Nil(t, ioutil.WriteFile(path, []byte(content), 0644))
+	}
+	assert.Nil(t, os.Symlink("a.go", filepath.Join(dir, "src", "link.go")))
+
+	return dir
+}
+
+func TestGrepFilesMatchesGrep(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	grepCmd, err := exec.LookPath("grep")
+	if err != nil {
+		t.Skip("grep not found")
+	}
+
+	dir := makeGrepFilesFolder(t)
+	defer os.RemoveAll(dir)
+
+	testData := []struct {
+		grepArgs []string
+		paths    []string
+		gfopts   GrepFilesOptions
+	}{
+		{[]string{"-r", "TODO", "src"}, []string{"src"}, GrepFilesOptions{Recursive: true}},
+		{[]string{"-r", "-n", "-i", "todo", "src"}, []string{"src"}, GrepFilesOptions{Recursive: true, GrepOptions: GrepOptions{LineNumbers: true, IgnoreCase: true}}},
+		{[]string{"-rl", "TODO", "src"}, []string{"src"}, GrepFilesOptions{Recursive: true, FilesWithMatches: true}},
+		{[]string{"-rL", "TODO", "src"}, []string{"src"}, GrepFilesOptions{Recursive: true, FilesWithoutMatch: true}},
+		{[]string{"-rc", "TODO", "src"}, []string{"src"}, GrepFilesOptions{Recursive: true, GrepOptions: GrepOptions{Count: true}}},
+		{[]string{"-rI", "TODO", "src"}, []string{"src"}, GrepFilesOptions{Recursive: true, SkipBinaryFiles: true}},
+		{[]string{"-r", "--include=*.go", "--exclude-dir=.git", "TODO", "src"}, []string{"src"}, GrepFilesOptions{Recursive: true, Include: []string{"*.go"}, ExcludeDir: []string{".git"}}},
+		{[]string{"-r", "--exclude=*.go", "--exclude=*.bin", "TODO", "src"}, []string{"src"}, GrepFilesOptions{Recursive: true, Exclude: []string{"*.go", "*.bin"}}},
+		{[]string{"-r", "-h", "TODO", "src/sub"}, []string{"src/sub"}, GrepFilesOptions{Recursive: true, NoFilename: true}},
+		{[]string{"-r", "-A1", "-n", "TODO", "src/sub"}, []string{"src/sub"}, GrepFilesOptions{Recursive: true, GrepOptions: GrepOptions{AfterContext: 1, LineNumbers: true}}},
+		{[]string{"-o", "-w", "TODO", "src/a.go", "src/b.go", "other.txt"}, []string{"src/a.go", "src/b.go", "other.txt"}, GrepFilesOptions{GrepOptions: GrepOptions{OnlyMatching: true, MatchWords: true}}},
+		{[]string{"TODO", "src/a.go"}, []string{"src/a.go"}, GrepFilesOptions{}},
+		{[]string{"-H", "TODO", "src/a.go"}, []string{"src/a.go"}, GrepFilesOptions{WithFilename: true}},
+		{[]string{"-c", "TODO", "src/a.go"}, []string{"src/a.go"}, GrepFilesOptions{GrepOptions: GrepOptions{Count: true}}},
+		{[]string{"-a", "TODO", "src/data.bin"}, []string{"src/data.bin"}, GrepFilesOptions{BinaryAsText: true}},
+		{[]string{"-c", "TODO", "src/data.bin"}, []string{"src/data.bin"}, GrepFilesOptions{GrepOptions: GrepOptions{Count: true}}},
+		{[]string{"-r", "--include=*.txt", "TODO", "src", "other.txt"}, []string{"src", "other.txt"}, GrepFilesOptions{Recursive: true, Include: []string{"*.txt"}}},
+		{[]string{"-r", "missing", "src"}, []string{"src"}, GrepFilesOptions{Recursive: true}},
+		{[]string{"-r", "TODO$", "src", "other.txt"}, []string{"src", "other.txt"}, GrepFilesOptions{Recursive: true}},
+		{[]string{"-rl", "^package [ab]$", "src"}, []string{"src"}, GrepFilesOptions{Recursive: true, FilesWithMatches: true}},
+		{[]string{"-l", "TODO", "src/link.go", "src/c.txt"}, []string{"src/link.go", "src/c.txt"}, GrepFilesOptions{FilesWithMatches: true}},
+		{[]string{"-L", "TODO", "src/a.go", "src/c.txt"}, []string{"src/a.go", "src/c.txt"}, GrepFilesOptions{FilesWithoutMatch: true}},
+		{[]string{"-L", "TODO", "src/a.go", "src/b.go"}, []string{"src/a.go", "src/b.go"}, GrepFilesOptions{FilesWithoutMatch: true}},
+		{[]string{"-L", "missing", "src/a.go"}, []string{"src/a.go"}, GrepFilesOptions{FilesWithoutMatch: true}},
+		{[]string{"-rL", "package", "src"}, []string{"src"}, GrepFilesOptions{Recursive: true, FilesWithoutMatch: true}},
+		{[]string{"-A1", "TODO", "src/a.go", "src/b.go", "src/sub/d.go"}, []string{"src/a.go", "src/b.go", "src/sub/d.go"}, GrepFilesOptions{GrepOptions: GrepOptions{AfterContext: 1}}},
+		{[]string{"-r", "-B1", "-n", "--exclude=*.bin", "TODO", "src"}, []string{"src"}, GrepFilesOptions{Recursive: true, Exclude: []string{"*.bin"}, GrepOptions: GrepOptions{BeforeContext: 1, LineNumbers: true}}},
+	}
+
+	for _, testCase := range testData {
+		cmd := exec.Command(grepCmd, append([]string{"-E"}, testCase.grepArgs...)...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "LC_ALL=C")
+		output, _ := cmd.Output()
+		expectedStatus := cmd.ProcessState.ExitCode()
+
+		// grep does not search folders in alphabetical order
+		expectedResult := strings.Split(string(output), "\n")
+		sort.Strings(expectedResult)
+
+		list := NewList(
+			Cd(dir),
+			GrepFiles([]string{testCase.grepArgs[len(testCase.grepArgs)-len(testCase.paths)-1]}, testCase.paths, testCase.gfopts),
+		)
+
+		// ----------------------------------------------------------------
+		// perform the change
+
+		list.Exec()
+		actualResult := strings.Split(list.Pipe.Stdout.String(), "\n")
+		sort.Strings(actualResult)
+
+		// ----------------------------------------------------------------
+		// test the results
+
+		assert.Equal(t, expectedResult, actualResult, testCase.grepArgs)
+		assert.Equal(t, expectedStatus, list.StatusCode(), testCase.grepArgs)
+	}
+}
+
+func TestGrepFilesSeparatesTheContextOfEachFile(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	grepCmd, err := exec.LookPath("grep")
+	if err != nil {
+		t.Skip("grep not found")
+	}
+
+	dir := makeGrepFilesFolder(t)
+	defer os.RemoveAll(dir)
+
+	testData := []struct {
+		grepArgs []string
+		gfopts   GrepFilesOptions
+	}{
+		{[]string{"-A1"}, GrepFilesOptions{GrepOptions: GrepOptions{AfterContext: 1}}},
+		{[]string{"-B1", "-n"}, GrepFilesOptions{GrepOptions: GrepOptions{BeforeContext: 1, LineNumbers: true}}},
+		{[]string{"-C1", "-h"}, GrepFilesOptions{NoFilename: true, GrepOptions: GrepOptions{BeforeContext: 1, AfterContext: 1}}},
+	}
+	paths := []string{"src/data.bin", "src/c.txt", "src/a.go", "src/data.bin", "src/b.go", "src/c.txt", "src/sub/d.go"}
+
+	for _, testCase := range testData {
+		args := append(append([]string{"-E"}, testCase.grepArgs...), "TODO")
+		cmd := exec.Command(grepCmd, append(args, paths...)...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "LC_ALL=C")
+		expectedResult, err := cmd.Output()
+		assert.Nil(t, err, testCase.grepArgs)
+
+		list := NewList(
+			Cd(dir),
+			GrepFiles([]string{"TODO"}, paths, testCase.gfopts),
+		)
+
+		// ----------------------------------------------------------------
+		// perform the change
+
+		actualResult, err := list.Exec().String()
+
+		// ----------------------------------------------------------------
+		// test the results
+
+		assert.Nil(t, err, testCase.grepArgs)
+		assert.Equal(t, string(expectedResult), actualResult, testCase.grepArgs)
+	}
+}
+
+func TestGrepFilesSearchesFoldersInOrder(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	dir := makeGrepFilesFolder(t)
+	defer os.RemoveAll(dir)
+
+	expectedResult := []string{
+		"src/a.go:3:// TODO: one",
+		"src/b.go:3:// TODO three",
+		"src/nonl.go:1:// TODO: no newline",
+		"src/sub/d.go:3:// TODO: four",
+	}
+	list := NewList(
+		Cd(dir),
+		GrepFiles(
+			[]string{"TODO"},
+			[]string{"src"},
+			GrepFilesOptions{
+				Recursive:       true,
+				SkipBinaryFiles: true,
+				ExcludeDir:      []string{".*"},
+				GrepOptions:     GrepOptions{LineNumbers: true},
+			},
+		),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec().Strings()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestGrepFilesSearchesTheWorkingDirectoryIfThereAreNoPaths(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	dir := makeGrepFilesFolder(t)
+	defer os.RemoveAll(dir)
+
+	expectedResult := []string{"d.go:// TODO: four"}
+	list := NewList(
+		Cd(filepath.Join(dir, "src", "sub")),
+		GrepFiles([]string{"TODO"}, nil, GrepFilesOptions{Recursive: true}),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec().Strings()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestGrepFilesExpandsPathsAndGlobs(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	dir := makeGrepFilesFolder(t)
+	defer os.RemoveAll(dir)
+
+	expectedResult := []string{"src/a.go", "src/b.go", "src/link.go", "src/nonl.go"}
+	list := NewList(
+		Cd(dir),
+		GrepFiles(
+			[]string{"TODO"},
+			[]string{"src/*.go", "$1"},
+			GrepFilesOptions{Exclude: []string{"$2"}, FilesWithMatches: true},
+		),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec("other.txt", "*.txt").Strings()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestGrepFilesWritesBinaryFileMatchesToStderr(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	dir := makeGrepFilesFolder(t)
+	defer os.RemoveAll(dir)
+
+	expectedStderr := "src/data.bin: binary file matches\n"
+	list := NewList(
+		Cd(dir),
+		GrepFiles([]string{"TODO"}, []string{"src/data.bin"}, GrepFilesOptions{}),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec().String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, "", actualResult)
+	assert.Equal(t, expectedStderr, list.Pipe.Stderr.String())
+}
+
+func TestGrepFilesCarriesOnAfterAnError(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	dir := makeGrepFilesFolder(t)
+	defer os.RemoveAll(dir)
+
+	expectedResult := "src/a.go:// TODO: one\nother.txt:another TODO\n"
+	list := NewList(
+		Cd(dir),
+		GrepFiles([]string{"TODO"}, []string{"src/a.go", "src", "missing.txt", "other.txt"}, GrepFilesOptions{}),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	list.Exec()
+	err := list.Error()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Error(t, err)
+	assert.True(t, os.IsNotExist(err) || strings.Contains(err.Error(), "is a directory"))
+	assert.Equal(t, expectedResult, list.Pipe.Stdout.String())
+}
+
+func TestGrepFilesReturnsErrorIfRegexInvalid(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	list := NewList(
+		GrepFiles([]string{"[* "}, []string{"."}, GrepFilesOptions{Recursive: true}),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	_, err := list.Exec().String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Error(t, err)
+}
+
+func TestGrepFilesWritesToTheTraceOutput(t *testing.T) {
+	// ----------------------------------------------------------------
+	// setup your test
+
+	dir := makeGrepFilesFolder(t)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "other.txt")
+
+	expectedResult := `+ GrepFiles([]string{"TODO"}, []string{"$1"}, scriptish.GrepFilesOptions{GrepOptions:scriptish.GrepOptions{IgnoreCase:false, FixedStrings:false, MatchWords:false, MatchLines:false, Invert:false, Count:false, OnlyMatching:false, MaxCount:0, LineNumbers:false, BeforeContext:0, AfterContext:0}, Recursive:false, Include:[]string(nil), Exclude:[]string(nil), ExcludeDir:[]string(nil), FilesWithMatches:true, FilesWithoutMatch:false, WithFilename:false, NoFilename:false, SkipBinaryFiles:false, BinaryAsText:false})
++ => GrepFiles([]string{"TODO"}, []string{"` + path + `"}, scriptish.GrepFilesOptions{GrepOptions:scriptish.GrepOptions{IgnoreCase:false, FixedStrings:false, MatchWords:false, MatchLines:false, Invert:false, Count:false, OnlyMatching:false, MaxCount:0, LineNumbers:false, BeforeContext:0, AfterContext:0}, Recursive:false, Include:[]string(nil), Exclude:[]string(nil), ExcludeDir:[]string(nil), FilesWithMatches:true, FilesWithoutMatch:false, WithFilename:false, NoFilename:false, SkipBinaryFiles:false, BinaryAsText:false})
++ p.Stdout> ` + path + `
+`
+	dest := NewTextBuffer()
+	GetShellOptions().EnableTrace(dest)
+
+	// clean up after ourselves
+	defer GetShellOptions().DisableTrace()
+
+	pipeline := NewPipeline(
+		GrepFiles([]string{"TODO"}, []string{"$1"}, GrepFilesOptions{FilesWithMatches: true}),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	pipeline.Exec(path)
+	actualResult := dest.String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, expectedResult, actualResult)
+}
@@ -43,7 +43,6 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
-	"strings"
 )
 
 // ListFiles is the equivalent of `ls -1 <path>`.
@@ -53,7 +52,12 @@ import (
 // to the pipeline's stdout. The path to the folder is included.
 //
 // If `path` contains wildcards, ListFiles writes any files that matches
-// to the pipeline's stdout.
+// to the pipeline's stdout. It supports the same glob patterns and brace
+// expressions as every other step. A pattern that does not match
+// anything writes nothing, unless Failglob is switched on.
+//
+// Relative paths are resolved against the sequence's working directory,
+// but are written to the pipeline's stdout just as they were given.
 func ListFiles(path string, opts ...*StepOption) *SequenceStep {
 	// build our Scriptish command
 	return NewSequenceStep(
@@ -61,38 +65,73 @@ func ListFiles(path string, opts ...*StepOption) *SequenceStep {
 			// debugging support
 			Tracef("ListFiles(%#v)", path)
 
-			// special case: user wants a list of files that match a wildcard
-			if strings.ContainsAny(path, "[]^*?\\{}!") {
-				return globFiles(p, path)
-			}
-
-			// general case: user has given us a path with no wildcards
-			info, err := os.Stat(path)
+			// can we expand our input?
+			opts := pipeShellOptions(p)
+			prepared, err := prepareExpansion(p, path, opts)
 			if err != nil {
 				return StatusNotOkay, err
 			}
 
-			// what are we looking at?
-			if info.IsDir() {
-				return listFolder(p, path)
+			// special case: globbing has been switched off
+			if opts.Noglob {
+				return listPath(p, expandPrepared(p, escapeBraces(prepared)))
+			}
+
+			for _, word := range expandBraces(prepared) {
+				expWord := expandPrepared(p, escapeBraces(word))
+
+				// special case: user wants a list of files that match a wildcard
+				listFunc := listPath
+				if hasGlobChars(expWord) {
+					listFunc = globFiles
+				}
+
+				statusCode, err := listFunc(p, expWord)
+				if err != nil {
+					return statusCode, err
+				}
 			}
 
-			// if we get here, then `path` maps onto a single file
-			return listFile(p, path)
+			// all done
+			return StatusOkay, nil
 		},
 		opts...,
 	)
 }
 
+// listPath writes the given path, or the contents of the given folder,
+// to the pipeline's stdout
+func listPath(p *Pipe, path string) (int, error) {
+	info, err := os.Stat(resolvePipePath(p, path))
+	if err != nil {
+		return StatusNotOkay, err
+	}
+
+	// what are we looking at?
+	if info.IsDir() {
+		return listFolder(p, path)
+	}
+
+	// if we get here, then `path` maps onto a single file
+	return listFile(p, path)
+}
+
 func globFiles(p *Pipe, path string) (int, error) {
 	// can we find any files?
-	filenames, err := filepath.Glob(path)
+	filenames, err := globPaths(p, path)
 	if err != nil {
 		return StatusNotOkay, err
 	}
+	if len(filenames) == 0 && pipeShellOptions(p).Failglob {
+		return StatusNotOkay, ErrNoGlobMatch{path}
+	}
 
 	// we have something to pass on
+	//
+	// we have always written out the cleaned-up filenames here, and we
+	// do not want to break anything that relies on that
 	for _, filename := range filenames {
+		filename = filepath.Clean(filename)
 		TracePipeStdout("%s", filename)
 		p.Stdout.WriteString(filename)
 		p.Stdout.WriteRune('\n')
@@ -112,7 +151,7 @@ func listFile(p *Pipe, path string) (int, error) {
 
 func listFolder(p *Pipe, path string) (int, error) {
 	// can we read what's in the folder?
-	files, err := ioutil.ReadDir(path)
+	files, err := ioutil.ReadDir(resolvePipePath(p, path))
 	if err != nil {
 		return StatusNotOkay, err
 	}
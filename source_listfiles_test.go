@@ -119,6 +119,34 @@ func TestListFilesReturnsMatchesIfPathContainsWildcards(t *testing.T) {
 	assert.Equal(t, expectedResult, actualResult)
 }
 
+func TestListFilesSupportsBraceExpansionAndRecursiveGlobs(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := []string{
+		"testdata/listfiles/one.txt",
+		"testdata/listfiles/two.txt",
+		"testdata/listfiles/three.yaml",
+	}
+	pipeline := NewPipeline(
+		ListFiles("./testdata/**/*.{txt,yaml}"),
+		Grep("listfiles"),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := pipeline.Exec().Strings()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
 func TestListFilesReturnsErrIfPathDoesNotExist(t *testing.T) {
 	// ----------------------------------------------------------------
 	// setup your test
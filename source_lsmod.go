@@ -48,28 +48,37 @@ import (
 //
 // Permissions are in the form '-rwxrwxrwx'.
 //
+// If filepath is a glob pattern or a brace expression, Lsmod writes
+// one line for every path that it expands to.
+//
 // It ignores the contents of the pipeline.
 func Lsmod(filepath string, opts ...*StepOption) *SequenceStep {
 	// build our Scriptish command
 	return NewSequenceStep(
 		func(p *Pipe) (int, error) {
 			// expand our input
-			expFilepath := p.Env.Expand(filepath)
+			expFilepaths, err := expandPathArgs(p, filepath)
 
 			// debugging support
 			Tracef("Lsmod(%#v)", filepath)
-			Tracef("=> Lsmod(%#v)", expFilepath)
+			Tracef("=> Lsmod(%s)", tracePathArgs(expFilepaths))
 
-			fileInfo, err := os.Stat(expFilepath)
 			if err != nil {
 				return StatusNotOkay, err
 			}
 
-			// write it to the pipe
-			mode := fileInfo.Mode().String()
-			TracePipeStdout("%s", mode)
-			p.Stdout.WriteString(mode)
-			p.Stdout.WriteRune('\n')
+			for _, expFilepath := range expFilepaths {
+				fileInfo, err := os.Stat(resolvePipePath(p, expFilepath))
+				if err != nil {
+					return StatusNotOkay, err
+				}
+
+				// write it to the pipe
+				mode := fileInfo.Mode().String()
+				TracePipeStdout("%s", mode)
+				p.Stdout.WriteString(mode)
+				p.Stdout.WriteRune('\n')
+			}
 
 			// all done
 			return StatusOkay, nil
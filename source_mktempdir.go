@@ -50,15 +50,22 @@ func MkTempDir(dir string, prefix string, opts ...*StepOption) *SequenceStep {
 	return NewSequenceStep(
 		func(p *Pipe) (int, error) {
 			// expand our inputs
-			expDir := p.Env.Expand(dir)
-			expPrefix := p.Env.Expand(prefix)
+			expDir, err := expandPathArg(p, dir)
+			expPrefix, prefixErr := expandString(p, prefix)
 
 			// debugging support
 			Tracef("MkTempDir(%#v, %#v)", dir, prefix)
 			Tracef("=> MkTempDir(%#v, %#v)", expDir, expPrefix)
 
+			if err != nil {
+				return StatusNotOkay, err
+			}
+			if prefixErr != nil {
+				return StatusNotOkay, prefixErr
+			}
+
 			// create the file
-			name, err := ioutil.TempDir(expDir, expPrefix)
+			name, err := ioutil.TempDir(resolvePipePath(p, expDir), expPrefix)
 			if err != nil {
 				return StatusNotOkay, err
 			}
@@ -50,15 +50,22 @@ func MkTempFile(dir string, pattern string, opts ...*StepOption) *SequenceStep {
 	return NewSequenceStep(
 		func(p *Pipe) (int, error) {
 			// expand our inputs
-			expDir := p.Env.Expand(dir)
-			expPattern := p.Env.Expand(pattern)
+			expDir, err := expandPathArg(p, dir)
+			expPattern, patternErr := expandString(p, pattern)
 
 			// debugging support
 			Tracef("MkTempFile(%#v, %#v)", dir, pattern)
 			Tracef("=> MkTempFile(%#v, %#v)", expDir, expPattern)
 
+			if err != nil {
+				return StatusNotOkay, err
+			}
+			if patternErr != nil {
+				return StatusNotOkay, patternErr
+			}
+
 			// create the file
-			fh, err := ioutil.TempFile(expDir, expPattern)
+			fh, err := ioutil.TempFile(resolvePipePath(p, expDir), expPattern)
 			if err != nil {
 				return StatusNotOkay, err
 			}
@@ -51,16 +51,23 @@ func MkTempFilename(dir string, pattern string, opts ...*StepOption) *SequenceSt
 	return NewSequenceStep(
 		func(p *Pipe) (int, error) {
 			// expand our inputs
-			expDir := p.Env.Expand(dir)
-			expPattern := p.Env.Expand(pattern)
+			expDir, err := expandPathArg(p, dir)
+			expPattern, patternErr := expandString(p, pattern)
 
 			// debugging support
 			Tracef("MkTempFilename(%#v, %#v)", dir, pattern)
 			Tracef("=> MkTempFilename(%#v, %#v)", expDir, expPattern)
 
+			if err != nil {
+				return StatusNotOkay, err
+			}
+			if patternErr != nil {
+				return StatusNotOkay, patternErr
+			}
+
 			// We have to generate an actual temporary file, delete it,
 			// and then use that filename
-			fh, err := ioutil.TempFile(expDir, expPattern)
+			fh, err := ioutil.TempFile(resolvePipePath(p, expDir), expPattern)
 			if err != nil {
 				return StatusNotOkay, err
 			}
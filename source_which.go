@@ -52,12 +52,16 @@ func Which(cmd string, opts ...*StepOption) *SequenceStep {
 	return NewSequenceStep(
 		func(p *Pipe) (int, error) {
 			// expand our input
-			expCmd := p.Env.Expand(cmd)
+			expCmd, err := expandString(p, cmd)
 
 			// debugging support
 			Tracef("Which(%#v)", cmd)
 			Tracef("=> Which(%#v)", expCmd)
 
+			if err != nil {
+				return StatusNotOkay, err
+			}
+
 			filepath, err := exec.LookPath(expCmd)
 			if err != nil {
 				return StatusNotOkay, err
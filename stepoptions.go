@@ -52,6 +52,9 @@ type StepOption struct {
 	// use this to clean up afterwards (eg close any files that
 	// were opened during the setup phase)
 	runTeardown Command
+
+	// label is set by WithLabel(), and ends up in the step's StepResult
+	label string
 }
 
 // noopCommand is a dummy Command, that we use to avoid having to check
@@ -0,0 +1,80 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+// NewStreamingPipeline creates a pipeline that's ready to run. Unlike
+// NewPipeline(), every step in the pipeline runs at the same time, and
+// output flows from one step to the next as it is written.
+//
+// Use it for long-running commands, for commands that never finish
+// (such as `tail -f`), and for large amounts of data.
+func NewStreamingPipeline(steps ...*SequenceStep) *Sequence {
+	// build our pipeline
+	retval := NewSequence(steps...)
+
+	// tell the underlying sequence how we want these commands to run
+	retval.Controller = StreamingPipelineController(retval)
+	retval.newController = StreamingPipelineController
+
+	// tell the commands what context they are running in
+	retval.Flags = contextIsPipeline | contextIsStreaming
+
+	// we already have a pipe, so it needs to get our flags too
+	retval.Pipe.Flags = retval.Flags
+
+	// all done
+	return retval
+}
+
+// NewStreamingPipelineFunc creates a streaming pipeline, and wraps it in
+// a function to make it easier to call.
+func NewStreamingPipelineFunc(steps ...*SequenceStep) func(...string) *Pipeline {
+	newPipe := NewStreamingPipeline(steps...)
+	return func(params ...string) *Pipeline {
+		return newPipe.Exec(params...)
+	}
+}
+
+// ExecStreamingPipeline creates and runs a streaming pipeline. Use this
+// for short, throwaway actions.
+func ExecStreamingPipeline(steps ...*SequenceStep) *Pipeline {
+	pipeline := NewStreamingPipeline(steps...).Exec()
+	return pipeline
+}
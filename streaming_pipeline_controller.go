@@ -0,0 +1,278 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// StreamingPipelineController executes a sequence of commands as if they
+// were a UNIX shell pipeline, with every command running at the same time.
+//
+// Each command runs in its own goroutine. The Stdout of each command is
+// connected to the Stdin of the next command by an io.Pipe, so that
+// lines flow down the pipeline as soon as they are written.
+//
+// Anything that a command writes to its Stderr is passed on, in the
+// order that the commands appear in the pipeline.
+//
+// When a command finishes, its Stdin is closed. Any command still trying
+// to write to it gets an io.ErrClosedPipe error (and any Exec()'d process
+// gets SIGPIPE). We treat these as the downstream command closing the
+// pipe, not as errors. A Scriptish command that gets this error has its
+// own Stdin closed too, so that it stops reading, and so does anything
+// upstream of it.
+//
+// The pipeline's StatusCode() and Error() come from the left-most
+// command that failed (or the right-most, if the pipeline is running with
+// Pipefail switched on). If nothing failed, they come from the last
+// command in the pipeline.
+//
+// If the pipeline's context.Context is cancelled, every connection
+// between the commands is closed, and Error() returns an ErrCancelled.
+func StreamingPipelineController(sq *Sequence) SequenceController {
+	return func() {
+		// do we have a pipeline to play with?
+		if sq == nil || len(sq.Steps) == 0 {
+			return
+		}
+
+		// have we been cancelled before we even start?
+		if checkPipeContext(sq.Pipe) {
+			return
+		}
+
+		// we start with no results
+		sq.stepResults = nil
+
+		// build the pipes that connect our steps together
+		stream := newStreamingPipes(sq)
+
+		// make sure that cancelling the pipeline unblocks any step
+		// that is waiting to read or write
+		finished := make(chan struct{})
+		go stream.closeOnCancel(PipeContext(sq.Pipe), finished)
+
+		// run every step at the same time
+		var wg sync.WaitGroup
+		for i, step := range sq.Steps {
+			wg.Add(1)
+			go func(i int, step *SequenceStep) {
+				defer wg.Done()
+				stream.runStep(i, step)
+			}(i, step)
+		}
+		wg.Wait()
+		close(finished)
+		sq.stepResults = stream.results
+
+		// were we cancelled while the steps were running?
+		if checkPipeContext(sq.Pipe) {
+			return
+		}
+
+		// which step do we report on?
+		reported := stream.reportedStep(pipeShellOptions(sq.Pipe).Pipefail)
+		stepPipe := stream.pipes[reported]
+
+		// every step shared the same stderr in a UNIX pipeline, so
+		// we pass on the stderr from all of them
+		for _, pipe := range stream.pipes {
+			io.Copy(sq.Pipe.Stderr, pipe.Stderr)
+		}
+
+		// copy its results into our pipe
+		sq.Pipe.RunCommand(func(p *Pipe) (int, error) {
+			return stepPipe.StatusError()
+		})
+
+		// the steps ran at the same time, so `$?` can only tell
+		// anyone how the whole pipeline went
+		setLastStatusCode(sq, sq.Pipe.StatusCode())
+
+		// debugging support
+		err := sq.Pipe.Error()
+		if err != nil {
+			Tracef("status code: %d", sq.Pipe.StatusCode())
+			Tracef("error: %s", err.Error())
+		}
+	}
+}
+
+// streamingPipes keeps track of the pipes (and the io.Pipes between them)
+// for a single run of a streaming pipeline
+type streamingPipes struct {
+	// each step gets its own pipe to run in
+	pipes []*Pipe
+
+	// readers[i] is the Stdin of step i; it is nil for the first step
+	readers []*textStreamReader
+
+	// writers[i] is the Stdout of step i; it is nil for the last step
+	writers []*textStreamWriter
+
+	// what happened to each step
+	results []StepResult
+
+	// we need to know the order that the steps finished in
+	mu         sync.Mutex
+	finished   []bool
+	brokenPipe []bool
+}
+
+func newStreamingPipes(sq *Sequence) *streamingPipes {
+	stepCount := len(sq.Steps)
+
+	retval := streamingPipes{
+		pipes:      make([]*Pipe, stepCount),
+		readers:    make([]*textStreamReader, stepCount),
+		writers:    make([]*textStreamWriter, stepCount),
+		results:    make([]StepResult, stepCount),
+		finished:   make([]bool, stepCount),
+		brokenPipe: make([]bool, stepCount),
+	}
+
+	// every step gets its own pipe, sharing our environment
+	for i := range sq.Steps {
+		retval.pipes[i] = NewPipe()
+		retval.pipes[i].Env = shareSequenceEnv(sq.Pipe)
+		retval.pipes[i].Flags = sq.Pipe.Flags
+	}
+
+	// the first step reads whatever is in our pipe's Stdin
+	retval.pipes[0].Stdin = sq.Pipe.Stdin
+
+	// every step's Stdout becomes the next step's Stdin
+	for i := 1; i < stepCount; i++ {
+		retval.readers[i], retval.writers[i-1] = newTextStream()
+		retval.pipes[i-1].Stdout = retval.writers[i-1]
+		retval.pipes[i].Stdin = retval.readers[i]
+	}
+
+	// once the next step has stopped reading, a step cannot write
+	// anything else, so we close its Stdin too; this stops any
+	// Scriptish filter, and anything upstream of it, just like
+	// SIGPIPE stops a UNIX command
+	for i := 1; i < stepCount-1; i++ {
+		retval.writers[i].onWriteError = func(reader *textStreamReader) func() {
+			return func() { reader.Close() }
+		}(retval.readers[i])
+	}
+
+	// the last step writes straight into our pipe's Stdout
+	retval.pipes[stepCount-1].Stdout = sq.Pipe.Stdout
+
+	// all done
+	return &retval
+}
+
+func (s *streamingPipes) runStep(i int, step *SequenceStep) {
+	s.results[i] = runStepWithResult(step, i, s.pipes[i])
+	err := s.results[i].Err
+
+	// the next step needs to know that there is no more input coming
+	if s.writers[i] != nil {
+		s.writers[i].w.CloseWithError(err)
+	}
+
+	// the previous step needs to know that we are not reading any more
+	if s.readers[i] != nil {
+		s.readers[i].Close()
+	}
+
+	// if the previous step is still running, anything that goes wrong
+	// with it from now on is down to us closing its Stdout
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.finished[i] = true
+	if i > 0 && !s.finished[i-1] {
+		s.brokenPipe[i-1] = true
+	}
+}
+
+// closeOnCancel closes every connection between the steps if the given
+// context is cancelled before the steps have finished
+func (s *streamingPipes) closeOnCancel(ctx context.Context, finished <-chan struct{}) {
+	select {
+	case <-ctx.Done():
+		err := ErrCancelled{ctx.Err()}
+		for _, reader := range s.readers {
+			if reader != nil {
+				reader.r.CloseWithError(err)
+			}
+		}
+		for _, writer := range s.writers {
+			if writer != nil {
+				writer.w.CloseWithError(err)
+			}
+		}
+	case <-finished:
+	}
+}
+
+// reportedStep returns the index of the step whose status code and
+// error becomes the status code and error of the whole pipeline
+//
+// If pipefail is true, we report the right-most step that failed.
+// Otherwise, we report the left-most step that failed.
+func (s *streamingPipes) reportedStep(pipefail bool) int {
+	failed := func(i int) bool {
+		return s.pipes[i].Error() != nil && !s.brokenPipe[i]
+	}
+
+	if pipefail {
+		for i := len(s.pipes) - 1; i >= 0; i-- {
+			if failed(i) {
+				return i
+			}
+		}
+	} else {
+		for i := range s.pipes {
+			if failed(i) {
+				return i
+			}
+		}
+	}
+
+	// if we get here, the last step gets to decide
+	return len(s.pipes) - 1
+}
@@ -0,0 +1,432 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamingPipelineControllerCopesWithNilSequencePointer(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	controller := StreamingPipelineController(nil)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	controller()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	// as long as it didn't crash, we're good
+}
+
+func TestStreamingPipelineControllerCopesWithEmptySequence(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	pipeline := NewStreamingPipeline()
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	pipeline.Exec()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, pipeline.Error())
+	assert.Equal(t, StatusOkay, pipeline.StatusCode())
+}
+
+func TestStreamingPipelineControllerRunsAllStepsInOrder(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := "hello world\nhave a nice day\n"
+	op1 := NewSequenceStep(
+		func(p *Pipe) (int, error) {
+			p.Stdout.WriteString("hello world")
+			p.Stdout.WriteRune('\n')
+
+			// all done
+			return 0, nil
+		},
+	)
+	op2 := NewSequenceStep(
+		func(p *Pipe) (int, error) {
+			// copy what op1 did first
+			p.DrainStdinToStdout()
+
+			// add our own content
+			p.Stdout.WriteString("have a nice day")
+			p.Stdout.WriteRune('\n')
+
+			// all done
+			return 0, nil
+		},
+	)
+
+	pipeline := NewStreamingPipeline(op1, op2)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := pipeline.Exec().String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestStreamingPipelineControllerPassesLinesOnAsTheyAreWritten(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := "first line\nsecond line\n"
+
+	// the consumer closes this when it has seen the first line
+	seenFirstLine := make(chan bool)
+
+	producer := NewSequenceStep(
+		func(p *Pipe) (int, error) {
+			p.Stdout.WriteString("first line\n")
+
+			// we do not write the second line until the first line
+			// has arrived at the next step
+			select {
+			case <-seenFirstLine:
+			case <-time.After(5 * time.Second):
+				return StatusNotOkay, errors.New("first line was not streamed")
+			}
+
+			p.Stdout.WriteString("second line\n")
+			return StatusOkay, nil
+		},
+	)
+	consumer := NewSequenceStep(
+		func(p *Pipe) (int, error) {
+			for line := range p.Stdin.ReadLines() {
+				p.Stdout.WriteString(line)
+				p.Stdout.WriteRune('\n')
+
+				if line == "first line" {
+					close(seenFirstLine)
+				}
+			}
+			return StatusOkay, nil
+		},
+	)
+	pipeline := NewStreamingPipeline(producer, consumer)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := pipeline.Exec().String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestStreamingPipelineControllerStopsUpstreamStepsWhenADownstreamStepFinishes(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := "y\ny\n"
+
+	// `yes` never finishes on its own
+	pipeline := NewStreamingPipeline(
+		Exec([]string{"/usr/bin/env", "yes"}),
+		Head(2),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := pipeline.Exec().String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+	assert.Equal(t, StatusOkay, pipeline.StatusCode())
+}
+
+func TestStreamingPipelineControllerStopsScriptishFiltersWhenADownstreamStepFinishes(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	testData := []struct {
+		filter         *SequenceStep
+		expectedResult string
+	}{
+		{Grep("y"), "y\n"},
+		{GrepWith([]string{"y"}, GrepOptions{}), "y\n"},
+		{Tr([]string{"y"}, []string{"n"}), "n\n"},
+		{Sed([]string{"s/y/n/"}), "n\n"},
+	}
+
+	for _, testCase := range testData {
+		// `yes` never finishes on its own
+		pipeline := NewStreamingPipeline(
+			Exec([]string{"/usr/bin/env", "yes"}),
+			testCase.filter,
+			Head(1),
+		)
+
+		// ----------------------------------------------------------------
+		// perform the change
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		actualResult, err := pipeline.ExecContext(ctx).String()
+		cancel()
+
+		// ----------------------------------------------------------------
+		// test the results
+
+		assert.Nil(t, err)
+		assert.Equal(t, testCase.expectedResult, actualResult)
+		assert.Equal(t, StatusOkay, pipeline.StatusCode())
+	}
+}
+
+func TestStreamingPipelineControllerReportsTheLeftMostError(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedErr := errors.New("stop at step 1")
+	op1 := NewSequenceStep(
+		func(p *Pipe) (int, error) {
+			p.Stderr.WriteString("alfred the great\n")
+			return 3, expectedErr
+		},
+	)
+	op2 := NewSequenceStep(
+		func(p *Pipe) (int, error) {
+			// wait for op1 to finish
+			p.DrainStdinToStdout()
+
+			return 4, errors.New("stop at step 2")
+		},
+	)
+
+	pipeline := NewStreamingPipeline(op1, op2)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	statusCode, err := pipeline.Exec().StatusError()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, expectedErr, err)
+	assert.Equal(t, 3, statusCode)
+}
+
+func TestStreamingPipelineControllerPassesOnStderrFromEveryStep(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedStderr := "alfred the great\nharold godwinson\n"
+	op1 := NewSequenceStep(
+		func(p *Pipe) (int, error) {
+			p.Stderr.WriteString("alfred the great\n")
+			return 3, errors.New("stop at step 1")
+		},
+	)
+	op2 := NewSequenceStep(
+		func(p *Pipe) (int, error) {
+			// wait for op1 to finish
+			p.DrainStdinToStdout()
+
+			p.Stderr.WriteString("harold godwinson\n")
+			return StatusOkay, nil
+		},
+	)
+
+	pipeline := NewStreamingPipeline(op1, op2)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	pipeline.Exec()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, expectedStderr, pipeline.Pipe.Stderr.String())
+}
+
+func TestStreamingPipelineControllerReportsTheRightMostErrorWhenPipefailIsSwitchedOn(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedErr := errors.New("stop at step 2")
+	op1 := NewSequenceStep(
+		func(p *Pipe) (int, error) {
+			return 3, errors.New("stop at step 1")
+		},
+	)
+	op2 := NewSequenceStep(
+		func(p *Pipe) (int, error) {
+			// wait for op1 to finish
+			p.DrainStdinToStdout()
+
+			return 4, expectedErr
+		},
+	)
+	op3 := NewSequenceStep(
+		func(p *Pipe) (int, error) {
+			p.DrainStdinToStdout()
+			return StatusOkay, nil
+		},
+	)
+
+	pipeline := NewStreamingPipeline(op1, op2, op3)
+	pipeline.ShellOptions = &ShellOptions{Pipefail: true}
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	statusCode, err := pipeline.Exec().StatusError()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, expectedErr, err)
+	assert.Equal(t, 4, statusCode)
+}
+
+func TestStreamingPipelineControllerWritesErrorsToTheTraceOutput(t *testing.T) {
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	dest := NewTextBuffer()
+	GetShellOptions().EnableTrace(dest)
+
+	// clean up after ourselves
+	defer GetShellOptions().DisableTrace()
+
+	op1 := NewSequenceStep(
+		func(p *Pipe) (int, error) {
+			return StatusNotOkay, errors.New("this is a test error")
+		},
+	)
+
+	expectedResult := `+ status code: 1
++ error: this is a test error
+`
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	pipeline := NewStreamingPipeline(
+		op1,
+	)
+	pipeline.Exec()
+	actualResult := dest.String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestStreamingPipelineControllerStopsWhenTheContextIsCancelled(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	// neither of these steps will finish on their own
+	pipeline := NewStreamingPipeline(
+		Exec([]string{"/usr/bin/env", "yes"}),
+		Grep("this will never match"),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	pipeline.ExecContext(ctx)
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	err := pipeline.Error()
+	_, ok := err.(ErrCancelled)
+	assert.True(t, ok)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
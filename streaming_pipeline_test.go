@@ -0,0 +1,188 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewStreamingPipelineCreatesEmptyPipeline(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	pipeline := NewStreamingPipeline()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.NotNil(t, pipeline)
+	assert.Empty(t, pipeline.Steps)
+	assert.NotNil(t, pipeline.Controller)
+}
+
+func TestExecStreamingPipelineCreatesAndRunsAPipeline(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := "hello world\n"
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := ExecStreamingPipeline(
+		Echo("hello world"),
+	).String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestNewStreamingPipelineFuncReturnsAPipelineAsAFunction(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := "hello world\n"
+	echoFunc := NewStreamingPipelineFunc(
+		Echo("$*"),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := echoFunc("hello", "world").String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestNewStreamingPipelineHasTheContextFlagsSet(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	pipeline := NewStreamingPipeline()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, contextIsPipeline, pipeline.Pipe.Flags&contextIsPipeline)
+	assert.Equal(t, contextIsStreaming, pipeline.Pipe.Flags&contextIsStreaming)
+}
+
+func TestExecutingStreamingPipelineHasTheContextFlagsSet(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	op := NewSequenceStep(
+		func(p *Pipe) (int, error) {
+			if p.Flags&contextIsPipeline == 0 {
+				return StatusNotOkay, errors.New("pipeline flag not set")
+			}
+			if p.Flags&contextIsStreaming == 0 {
+				return StatusNotOkay, errors.New("streaming flag not set")
+			}
+
+			return StatusOkay, nil
+		},
+	)
+	pipeline := NewStreamingPipeline(op)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	pipeline.Exec()
+	actualResult, err := pipeline.StatusError()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, StatusOkay, actualResult)
+}
+
+func TestStreamingPipelineCanBeReused(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	pipeline := NewStreamingPipeline(
+		Echo("$1"),
+		Tr([]string{"o"}, []string{"0"}),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	firstResult, firstErr := pipeline.Exec("foo").TrimmedString()
+	secondResult, secondErr := pipeline.Exec("boo").TrimmedString()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, firstErr)
+	assert.Equal(t, "f00", firstResult)
+	assert.Nil(t, secondErr)
+	assert.Equal(t, "b00", secondResult)
+}
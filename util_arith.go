@@ -0,0 +1,772 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// the reasons why parseArithNumber() can fail
+var (
+	errArithInvalidBase     = errors.New("invalid arithmetic base")
+	errArithInvalidNumber   = errors.New("invalid number")
+	errArithTooGreatForBase = errors.New("value too great for base")
+)
+
+// arithVars is where arithmetic expressions get and set their variables
+type arithVars interface {
+	LookupEnv(key string) (string, bool)
+	Setenv(key, value string) error
+}
+
+// arithMaxDepth is how deeply variables can refer to other variables,
+// before we decide that they are going round in circles
+const arithMaxDepth = 1024
+
+// arithOperators are the operators that arithmetic expressions support,
+// longest first
+var arithOperators = []string{
+	"<<=", ">>=",
+	"**", "++", "--", "<<", ">>", "<=", ">=", "==", "!=", "&&", "||",
+	"*=", "/=", "%=", "+=", "-=", "&=", "^=", "|=",
+	"+", "-", "*", "/", "%", "<", ">", "=", "!", "~", "&", "^", "|",
+	"?", ":", "(", ")", ",",
+}
+
+// arithAssignments maps each assignment operator onto the operator that
+// it applies before the assignment
+var arithAssignments = map[string]string{
+	"=":   "",
+	"*=":  "*",
+	"/=":  "/",
+	"%=":  "%",
+	"+=":  "+",
+	"-=":  "-",
+	"<<=": "<<",
+	">>=": ">>",
+	"&=":  "&",
+	"^=":  "^",
+	"|=":  "|",
+}
+
+// arithTokenKind tells you what kind of token an arithToken is
+type arithTokenKind int
+
+const (
+	arithEOF arithTokenKind = iota
+	arithNumber
+	arithName
+	arithOperator
+)
+
+// arithToken is a single token from an arithmetic expression
+type arithToken struct {
+	kind  arithTokenKind
+	text  string
+	start int
+}
+
+// arithParser evaluates an arithmetic expression as it parses it, just
+// like UNIX shells do
+type arithParser struct {
+	expr    string
+	pos     int
+	tok     arithToken
+	vars    arithVars
+	nounset bool
+
+	// noeval is greater than zero while we are in a part of the
+	// expression that is not being evaluated (eg, the right-hand side
+	// of `0 && ...`); assignments and division by zero are ignored
+	noeval int
+
+	// depth is how many variables we have evaluated to get here
+	depth int
+}
+
+// evalArith evaluates the given arithmetic expression, using the rules
+// of UNIX shells' `$((...))`
+//
+// Variables are read from (and assigned to) vars. If nounset is true,
+// it is an error to use a variable that has not been set.
+func evalArith(vars arithVars, expr string, nounset bool) (int64, error) {
+	return evalArithAtDepth(vars, expr, nounset, 0)
+}
+
+// evalArithAtDepth does the work for evalArith()
+func evalArithAtDepth(vars arithVars, expr string, nounset bool, depth int) (int64, error) {
+	// special case: an empty expression is zero
+	if strings.TrimSpace(expr) == "" {
+		return 0, nil
+	}
+
+	ap := &arithParser{
+		expr:    expr,
+		vars:    vars,
+		nounset: nounset,
+		depth:   depth,
+	}
+
+	err := ap.next()
+	if err != nil {
+		return 0, err
+	}
+	retval, err := ap.parseComma()
+	if err != nil {
+		return 0, err
+	}
+
+	// did we use up the whole expression?
+	if ap.tok.kind != arithEOF {
+		if _, ok := arithAssignments[ap.tok.text]; ok {
+			return 0, ap.errorf("attempted assignment to non-variable")
+		}
+		return 0, ap.errorf("syntax error in expression")
+	}
+
+	return retval, nil
+}
+
+// errorf returns an ErrArithmetic for the current token
+func (ap *arithParser) errorf(message string) error {
+	token := strings.TrimSpace(ap.expr[ap.tok.start:])
+	if token != "" {
+		message += " (error token is \"" + token + "\")"
+	}
+
+	return ErrArithmetic{strings.TrimSpace(ap.expr), message}
+}
+
+// next moves on to the next token in the expression
+func (ap *arithParser) next() error {
+	// skip any whitespace
+	for ap.pos < len(ap.expr) && strings.IndexByte(" \t\r\n", ap.expr[ap.pos]) >= 0 {
+		ap.pos++
+	}
+
+	start := ap.pos
+	ap.tok = arithToken{start: start}
+	if start >= len(ap.expr) {
+		ap.tok.kind = arithEOF
+		return nil
+	}
+
+	c := ap.expr[start]
+	switch {
+	case isVarDigit(c):
+		for ap.pos < len(ap.expr) && (isVarNameChar(ap.expr[ap.pos], false) || ap.expr[ap.pos] == '#' || ap.expr[ap.pos] == '@') {
+			ap.pos++
+		}
+		ap.tok.kind = arithNumber
+	case isVarNameChar(c, true):
+		for ap.pos < len(ap.expr) && isVarNameChar(ap.expr[ap.pos], false) {
+			ap.pos++
+		}
+		ap.tok.kind = arithName
+	default:
+		for _, op := range arithOperators {
+			if strings.HasPrefix(ap.expr[start:], op) {
+				ap.pos += len(op)
+				ap.tok.kind = arithOperator
+				break
+			}
+		}
+		if ap.tok.kind != arithOperator {
+			return ap.errorf("syntax error: invalid arithmetic operator")
+		}
+	}
+
+	ap.tok.text = ap.expr[start:ap.pos]
+	return nil
+}
+
+// isOperator returns true if the current token is the given operator
+func (ap *arithParser) isOperator(op string) bool {
+	return ap.tok.kind == arithOperator && ap.tok.text == op
+}
+
+// parseComma evaluates `expr, expr, ...`
+func (ap *arithParser) parseComma() (int64, error) {
+	retval, err := ap.parseAssignment()
+	for err == nil && ap.isOperator(",") {
+		err = ap.next()
+		if err == nil {
+			retval, err = ap.parseAssignment()
+		}
+	}
+
+	return retval, err
+}
+
+// parseAssignment evaluates `name = expr`, `name += expr` and friends
+func (ap *arithParser) parseAssignment() (int64, error) {
+	// special case: not an assignment
+	if ap.tok.kind != arithName {
+		return ap.parseConditional()
+	}
+
+	// we need to look ahead to find out
+	saved, savedPos := ap.tok, ap.pos
+	err := ap.next()
+	if err != nil {
+		return 0, err
+	}
+	op, isAssignment := arithAssignments[ap.tok.text]
+	if ap.tok.kind != arithOperator || !isAssignment {
+		ap.tok, ap.pos = saved, savedPos
+		return ap.parseConditional()
+	}
+
+	// if we get here, we have an assignment
+	name := saved.text
+	err = ap.next()
+	if err != nil {
+		return 0, err
+	}
+	value, err := ap.parseAssignment()
+	if err != nil {
+		return 0, err
+	}
+
+	if op != "" {
+		current, err := ap.varValue(name)
+		if err != nil {
+			return 0, err
+		}
+		value, err = ap.apply(op, current, value)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return value, ap.setVar(name, value)
+}
+
+// parseConditional evaluates `expr ? expr : expr`
+func (ap *arithParser) parseConditional() (int64, error) {
+	cond, err := ap.parseBinary(0)
+	if err != nil || !ap.isOperator("?") {
+		return cond, err
+	}
+	err = ap.next()
+	if err != nil {
+		return 0, err
+	}
+
+	// we only evaluate the branch that we need
+	if cond == 0 {
+		ap.noeval++
+	}
+	ifTrue, err := ap.parseComma()
+	if cond == 0 {
+		ap.noeval--
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if !ap.isOperator(":") {
+		return 0, ap.errorf("`:' expected for conditional expression")
+	}
+	err = ap.next()
+	if err != nil {
+		return 0, err
+	}
+
+	if cond != 0 {
+		ap.noeval++
+	}
+	ifFalse, err := ap.parseConditional()
+	if cond != 0 {
+		ap.noeval--
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if cond != 0 {
+		return ifTrue, nil
+	}
+	return ifFalse, nil
+}
+
+// arithBinaryLevels are the binary operators, from the lowest precedence
+// to the highest
+var arithBinaryLevels = [][]string{
+	{"||"},
+	{"&&"},
+	{"|"},
+	{"^"},
+	{"&"},
+	{"==", "!="},
+	{"<", "<=", ">", ">="},
+	{"<<", ">>"},
+	{"+", "-"},
+	{"*", "/", "%"},
+}
+
+// parseBinary evaluates the left-associative binary operators, starting
+// at the given level of precedence
+func (ap *arithParser) parseBinary(level int) (int64, error) {
+	// are we out of binary operators?
+	if level >= len(arithBinaryLevels) {
+		return ap.parsePower()
+	}
+
+	left, err := ap.parseBinary(level + 1)
+	for err == nil && ap.tok.kind == arithOperator && isArithOperatorIn(ap.tok.text, arithBinaryLevels[level]) {
+		op := ap.tok.text
+		err = ap.next()
+		if err != nil {
+			return 0, err
+		}
+
+		// `&&` and `||` only evaluate the right-hand side if they
+		// need to
+		skip := (op == "&&" && left == 0) || (op == "||" && left != 0)
+		if skip {
+			ap.noeval++
+		}
+		var right int64
+		right, err = ap.parseBinary(level + 1)
+		if skip {
+			ap.noeval--
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		left, err = ap.apply(op, left, right)
+	}
+
+	return left, err
+}
+
+// parsePower evaluates `expr ** expr`, which is right-associative
+func (ap *arithParser) parsePower() (int64, error) {
+	base, err := ap.parseUnary()
+	if err != nil || !ap.isOperator("**") {
+		return base, err
+	}
+	err = ap.next()
+	if err != nil {
+		return 0, err
+	}
+
+	exponent, err := ap.parsePower()
+	if err != nil {
+		return 0, err
+	}
+
+	return ap.apply("**", base, exponent)
+}
+
+// parseUnary evaluates `!expr`, `~expr`, `-expr`, `+expr`, `++name`
+// and `--name`
+func (ap *arithParser) parseUnary() (int64, error) {
+	// special case: not a unary operator
+	if ap.tok.kind != arithOperator {
+		return ap.parsePostfix()
+	}
+
+	op := ap.tok.text
+	switch op {
+	case "!", "~", "-", "+":
+		err := ap.next()
+		if err != nil {
+			return 0, err
+		}
+		value, err := ap.parseUnary()
+		if err != nil {
+			return 0, err
+		}
+		switch op {
+		case "!":
+			return arithBool(value == 0), nil
+		case "~":
+			return ^value, nil
+		case "-":
+			return -value, nil
+		default:
+			return value, nil
+		}
+	case "++", "--":
+		err := ap.next()
+		if err != nil {
+			return 0, err
+		}
+
+		// like UNIX shells, `++5` is `+(+5)` and `--5` is `-(-5)`
+		if ap.tok.kind != arithName {
+			return ap.parseUnary()
+		}
+
+		name := ap.tok.text
+		err = ap.next()
+		if err != nil {
+			return 0, err
+		}
+		value, err := ap.varValue(name)
+		if err != nil {
+			return 0, err
+		}
+		if op == "++" {
+			value++
+		} else {
+			value--
+		}
+		return value, ap.setVar(name, value)
+	}
+
+	return ap.parsePostfix()
+}
+
+// parsePostfix evaluates `name++` and `name--`, and anything that
+// binds more tightly
+func (ap *arithParser) parsePostfix() (int64, error) {
+	// special case: only variables can be incremented
+	if ap.tok.kind != arithName {
+		return ap.parsePrimary()
+	}
+
+	name := ap.tok.text
+	err := ap.next()
+	if err != nil {
+		return 0, err
+	}
+	value, err := ap.varValue(name)
+	if err != nil {
+		return 0, err
+	}
+
+	switch {
+	case ap.isOperator("++"):
+		err = ap.next()
+		if err == nil {
+			err = ap.setVar(name, value+1)
+		}
+	case ap.isOperator("--"):
+		err = ap.next()
+		if err == nil {
+			err = ap.setVar(name, value-1)
+		}
+	}
+
+	return value, err
+}
+
+// parsePrimary evaluates numbers and `(expr)`
+func (ap *arithParser) parsePrimary() (int64, error) {
+	switch {
+	case ap.tok.kind == arithNumber:
+		value, err := parseArithNumber(ap.tok.text)
+		if err != nil {
+			return 0, ap.errorf(err.Error())
+		}
+		return value, ap.next()
+	case ap.isOperator("("):
+		err := ap.next()
+		if err != nil {
+			return 0, err
+		}
+		value, err := ap.parseComma()
+		if err != nil {
+			return 0, err
+		}
+		if !ap.isOperator(")") {
+			return 0, ap.errorf("missing `)'")
+		}
+		return value, ap.next()
+	}
+
+	// if we get here, something is missing
+	return 0, ap.errorf("syntax error: operand expected")
+}
+
+// apply evaluates a binary operator
+func (ap *arithParser) apply(op string, left, right int64) (int64, error) {
+	switch op {
+	case "||":
+		return arithBool(left != 0 || right != 0), nil
+	case "&&":
+		return arithBool(left != 0 && right != 0), nil
+	case "|":
+		return left | right, nil
+	case "^":
+		return left ^ right, nil
+	case "&":
+		return left & right, nil
+	case "==":
+		return arithBool(left == right), nil
+	case "!=":
+		return arithBool(left != right), nil
+	case "<":
+		return arithBool(left < right), nil
+	case "<=":
+		return arithBool(left <= right), nil
+	case ">":
+		return arithBool(left > right), nil
+	case ">=":
+		return arithBool(left >= right), nil
+	case "<<":
+		return left << uint64(right&63), nil
+	case ">>":
+		return left >> uint64(right&63), nil
+	case "+":
+		return left + right, nil
+	case "-":
+		return left - right, nil
+	case "*":
+		return left * right, nil
+	case "/", "%":
+		if right == 0 {
+			if ap.noeval > 0 {
+				return 0, nil
+			}
+			return 0, ErrArithmetic{strings.TrimSpace(ap.expr), "division by 0"}
+		}
+		if op == "/" {
+			return left / right, nil
+		}
+		return left % right, nil
+	case "**":
+		if right < 0 {
+			if ap.noeval > 0 {
+				return 0, nil
+			}
+			return 0, ErrArithmetic{strings.TrimSpace(ap.expr), "exponent less than 0"}
+		}
+		retval := int64(1)
+		for ; right > 0; right-- {
+			retval *= left
+		}
+		return retval, nil
+	}
+
+	// if we get here, we have missed an operator
+	return 0, ap.errorf("syntax error in expression")
+}
+
+// varValue returns the value of the named variable
+//
+// Like UNIX shells, a variable that is empty or has not been set is
+// zero, and a variable that holds an expression is evaluated.
+func (ap *arithParser) varValue(name string) (int64, error) {
+	value, ok := ap.vars.LookupEnv(name)
+	if !ok && ap.nounset && ap.noeval == 0 {
+		return 0, ErrUnboundVariable{name}
+	}
+
+	// special case: the value is a plain number
+	retval, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err == nil {
+		return retval, nil
+	}
+
+	// make sure we do not go round in circles
+	if ap.depth >= arithMaxDepth {
+		return 0, ErrArithmetic{value, "expression recursion level exceeded"}
+	}
+
+	return evalArithAtDepth(ap.vars, value, ap.nounset, ap.depth+1)
+}
+
+// setVar assigns the given value to the named variable
+func (ap *arithParser) setVar(name string, value int64) error {
+	// special case: we are not evaluating this part of the expression
+	if ap.noeval > 0 {
+		return nil
+	}
+
+	return ap.vars.Setenv(name, strconv.FormatInt(value, 10))
+}
+
+// parseArithNumber turns a number in an arithmetic expression into
+// its value
+//
+// It supports decimal, octal (`0755`), hexadecimal (`0xff`), and any
+// base between 2 and 64 (`base#digits`).
+func parseArithNumber(text string) (int64, error) {
+	base := int64(10)
+	digits := text
+
+	switch {
+	case strings.Contains(text, "#"):
+		parts := strings.SplitN(text, "#", 2)
+		var err error
+		base, err = strconv.ParseInt(parts[0], 10, 64)
+		if err != nil || base < 2 || base > 64 {
+			return 0, errArithInvalidBase
+		}
+		digits = parts[1]
+	case len(text) > 2 && (text[:2] == "0x" || text[:2] == "0X"):
+		base = 16
+		digits = text[2:]
+	case len(text) > 1 && text[0] == '0':
+		base = 8
+		digits = text[1:]
+	}
+
+	// robustness
+	if digits == "" {
+		return 0, errArithInvalidNumber
+	}
+
+	var retval int64
+	for i := 0; i < len(digits); i++ {
+		digit := arithDigitValue(digits[i], base)
+		if digit < 0 || digit >= base {
+			return 0, errArithTooGreatForBase
+		}
+		retval = retval*base + digit
+	}
+
+	return retval, nil
+}
+
+// arithDigitValue returns the value of a single digit in the given base,
+// or -1 if it is not a digit
+//
+// Up to base 36, letters are not case-sensitive. Above that, lowercase
+// letters are 10-35, uppercase letters are 36-61, then `@` and `_`.
+func arithDigitValue(c byte, base int64) int64 {
+	switch {
+	case c >= '0' && c <= '9':
+		return int64(c - '0')
+	case c >= 'a' && c <= 'z':
+		return int64(c-'a') + 10
+	case c >= 'A' && c <= 'Z' && base <= 36:
+		return int64(c-'A') + 10
+	case c >= 'A' && c <= 'Z':
+		return int64(c-'A') + 36
+	case c == '@':
+		return 62
+	case c == '_':
+		return 63
+	}
+
+	return -1
+}
+
+// arithBool turns a boolean into 1 or 0
+func arithBool(value bool) int64 {
+	if value {
+		return 1
+	}
+
+	return 0
+}
+
+// isArithOperatorIn returns true if op is one of the given operators
+func isArithOperatorIn(op string, ops []string) bool {
+	for _, candidate := range ops {
+		if op == candidate {
+			return true
+		}
+	}
+
+	return false
+}
+
+// expandArith evaluates the given `$((...))` expression
+//
+// Like UNIX shells, we expand the expression before we evaluate it.
+// Any assignments are made to the pipe's local variables.
+func expandArith(p *Pipe, expr string, opts ShellOptions) (int64, error) {
+	// expand the expression first
+	prepared, err := runSubstitutions(p, expr, opts)
+	if err != nil {
+		return 0, err
+	}
+	err = checkExpansion(p.Env, prepared, opts.Nounset)
+	if err != nil {
+		return 0, err
+	}
+	expExpr := expandPrepared(p, prepared)
+
+	// now we can work it out
+	retval, err := evalArith(pipeArithVars{p}, expExpr, opts.Nounset)
+	if err != nil {
+		return 0, err
+	}
+
+	// debugging support
+	Tracef("$((%s)) => %d", expExpr, retval)
+
+	// all done
+	return retval, nil
+}
+
+// pipeArithVars gives arithmetic expressions access to a pipe's variables
+type pipeArithVars struct {
+	p *Pipe
+}
+
+// LookupEnv returns the value of the named variable, and whether or not
+// it has been set
+func (v pipeArithVars) LookupEnv(key string) (string, bool) {
+	return v.p.Env.LookupEnv(key)
+}
+
+// Setenv sets the named local variable
+func (v pipeArithVars) Setenv(key, value string) error {
+	return setLocalVar(v.p, key, value)
+}
+
+// arithStatus evaluates the given (already expanded) expression, and
+// turns the result into a status code, just like UNIX shells' `let`
+// and `(( ... ))` do
+func arithStatus(p *Pipe, expr string) (int, error) {
+	value, err := evalArith(pipeArithVars{p}, expr, pipeShellOptions(p).Nounset)
+	if err != nil {
+		return StatusNotOkay, err
+	}
+
+	// debugging support
+	Tracef("=> %d", value)
+
+	if value == 0 {
+		return StatusNotOkay, nil
+	}
+
+	return StatusOkay, nil
+}
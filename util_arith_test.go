@@ -0,0 +1,406 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"os/exec"
+	"strconv"
+	"strings"
+	"testing"
+
+	envish "github.com/ganbarodigital/go_envish/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+// arithTestExpressions are evaluated by both Scriptish and bash, to
+// make sure that we get the same answers
+var arithTestExpressions = []string{
+	"1 + 2",
+	"7 - 10",
+	"6 * 7",
+	"7 / 2",
+	"-7 / 2",
+	"7 % 3",
+	"-7 % 3",
+	"2 ** 10",
+	"2 ** 3 ** 2",
+	"-2 ** 2",
+	"1 + 2 * 3",
+	"(1 + 2) * 3",
+	"10 - 4 - 3",
+	"1 < 2",
+	"2 <= 1",
+	"3 > 2",
+	"3 >= 4",
+	"5 == 5",
+	"5 != 5",
+	"!0",
+	"!7",
+	"~5",
+	"-(-3)",
+	"--5",
+	"++5",
+	"6 & 3",
+	"6 | 3",
+	"6 ^ 3",
+	"1 << 4",
+	"256 >> 2",
+	"1 && 0",
+	"1 || 0",
+	"0 || 0",
+	"2 && 3",
+	"1 ? 10 : 20",
+	"0 ? 10 : 20",
+	"0 ? 1 : 0 ? 2 : 3",
+	"1, 2, 3",
+	"0x1f",
+	"0X1F",
+	"0755",
+	"2#1010",
+	"16#ff",
+	"36#Z",
+	"64#_",
+	"x + y",
+	"x * y - z",
+	"unset_var + 1",
+	"empty + 1",
+	"expr * 2",
+	"x++ + x",
+	"++x + x",
+	"x--, x",
+	"x += 3",
+	"x -= 3, x",
+	"x *= y",
+	"x /= 2",
+	"x %= 3",
+	"x <<= 2",
+	"x >>= 1",
+	"x &= 4",
+	"x |= 2",
+	"x ^= 1",
+	"a = b = 7, a + b",
+	"0 && (x = 100), x",
+	"1 || (x = 100), x",
+	"1 ? (x = 1) : (x = 2), x",
+	"0 && 1 / 0",
+	"1 || 1 / 0",
+	"9223372036854775807 + 1",
+	"   42   ",
+}
+
+func TestEvalArithMatchesBash(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not found")
+	}
+
+	vars := "x=5 y=3 z=4 empty= expr='x+y';"
+
+	for _, expr := range arithTestExpressions {
+		env := envish.NewLocalEnv()
+		env.Setenv("x", "5")
+		env.Setenv("y", "3")
+		env.Setenv("z", "4")
+		env.Setenv("empty", "")
+		env.Setenv("expr", "x+y")
+
+		cmd := exec.Command(bash, "-c", vars+" echo $(("+expr+"))")
+		cmd.Env = []string{}
+		output, err := cmd.Output()
+		assert.Nil(t, err, expr)
+		expectedResult, err := strconv.ParseInt(strings.TrimSpace(string(output)), 10, 64)
+		assert.Nil(t, err, expr)
+
+		// ----------------------------------------------------------------
+		// perform the change
+
+		actualResult, err := evalArith(env, expr, false)
+
+		// ----------------------------------------------------------------
+		// test the results
+
+		assert.Nil(t, err, expr)
+		assert.Equal(t, expectedResult, actualResult, expr)
+	}
+}
+
+func TestEvalArithFailsWhenBashFails(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not found")
+	}
+
+	testData := []string{
+		"1 / 0",
+		"1 % 0",
+		"2 ** -1",
+		"1 +",
+		"(1 + 2",
+		"1 2",
+		"1 ? 2",
+		"08",
+		"2#102",
+		"1 = 2",
+		"1 @ 2",
+		"loop",
+	}
+
+	for _, expr := range testData {
+		env := envish.NewLocalEnv()
+		env.Setenv("loop", "loop + 1")
+
+		cmd := exec.Command(bash, "-c", "loop='loop + 1'; echo $(("+expr+"))")
+		cmd.Env = []string{}
+		bashErr := cmd.Run()
+
+		// ----------------------------------------------------------------
+		// perform the change
+
+		_, err := evalArith(env, expr, false)
+
+		// ----------------------------------------------------------------
+		// test the results
+
+		assert.Error(t, bashErr, expr)
+		assert.Error(t, err, expr)
+	}
+}
+
+func TestEvalArithAssignsToVariables(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	env := envish.NewLocalEnv()
+	env.Setenv("i", "41")
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := evalArith(env, "i++, j = i * 2", false)
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(84), actualResult)
+	assert.Equal(t, "42", env.Getenv("i"))
+	assert.Equal(t, "84", env.Getenv("j"))
+}
+
+func TestEvalArithReturnsErrArithmetic(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	env := envish.NewLocalEnv()
+	testData := map[string]error{
+		"1 / 0":   ErrArithmetic{"1 / 0", "division by 0"},
+		"2 ** -1": ErrArithmetic{"2 ** -1", "exponent less than 0"},
+		"1 +":     ErrArithmetic{"1 +", "syntax error: operand expected"},
+		"1 2":     ErrArithmetic{"1 2", `syntax error in expression (error token is "2")`},
+		"(1":      ErrArithmetic{"(1", "missing `)'"},
+		"08":      ErrArithmetic{"08", `value too great for base (error token is "08")`},
+		"1 = 2":   ErrArithmetic{"1 = 2", `attempted assignment to non-variable (error token is "= 2")`},
+	}
+
+	for expr, expectedResult := range testData {
+		// ----------------------------------------------------------------
+		// perform the change
+
+		_, actualResult := evalArith(env, expr, false)
+
+		// ----------------------------------------------------------------
+		// test the results
+
+		assert.Equal(t, expectedResult, actualResult, expr)
+	}
+}
+
+func TestEvalArithSupportsNounset(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := ErrUnboundVariable{"missing"}
+	env := envish.NewLocalEnv()
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	_, actualResult := evalArith(env, "missing + 1", true)
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestEvalArithStopsVariablesThatGoRoundInCircles(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	env := envish.NewLocalEnv()
+	env.Setenv("a", "b")
+	env.Setenv("b", "a")
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	_, err := evalArith(env, "a + 1", false)
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, ErrArithmetic{"b", "expression recursion level exceeded"}, err)
+}
+
+func TestArithmeticExpansionWorksInStrings(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := "attempt 3 of 5\n"
+	list := NewList(
+		Echo("attempt $(($1 + 1)) of $((max))"),
+	)
+	list.LocalVars.Setenv("max", "5")
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec("2").String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestArithmeticExpansionAssignsToLocalVars(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := "1 2\n"
+	list := NewList(
+		Echo("$((count += 1)) $((count += 1))"),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec().String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+	assert.Equal(t, "2", list.LocalVars.Getenv("count"))
+}
+
+func TestArithmeticExpansionCanBeNested(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := "10\n"
+	list := NewList(
+		Echo("$(( $((2 + 3)) * 2 ))"),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec().String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestArithmeticExpansionErrorsStopTheList(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedErr := ErrExpansion{
+		"$((10 / $1))",
+		ErrArithmetic{"10 / 0", "division by 0"},
+	}
+	list := NewList(
+		Echo("$((10 / $1))"),
+		Echo("this should not be seen"),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec("0").String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, expectedErr, err)
+	assert.Equal(t, "", actualResult)
+}
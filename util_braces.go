@@ -0,0 +1,304 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// braceSequenceRegexp matches the inside of a `{1..10}`, `{a..e}` or
+// `{1..10..2}` sequence expression
+var braceSequenceRegexp = regexp.MustCompile(`^(-?[0-9]+|[a-zA-Z])\.\.(-?[0-9]+|[a-zA-Z])(\.\.(-?[0-9]+))?$`)
+
+// expandBraces performs UNIX shell brace expansion on the given word
+//
+// It supports lists (`{a,b,c}`), sequences (`{1..10}`, `{01..10}`,
+// `{a..e}` and `{1..10..2}`), and any mix of those nested inside each
+// other. Like UNIX shells, it leaves alone any braces that are escaped
+// with a backslash, that belong to a `${...}` variable, or that do not
+// hold a list or a sequence.
+//
+// You always get at least one word back.
+func expandBraces(word string) []string {
+	for i := 0; i < len(word); i++ {
+		switch word[i] {
+		case '\\':
+			// the next character is not special
+			i++
+		case '$':
+			// `${...}` is a variable, not a brace expression
+			if i+1 < len(word) && word[i+1] == '{' {
+				end := findClosingBrace(word, i+1)
+				if end < 0 {
+					return []string{word}
+				}
+				i = end
+			}
+		case '{':
+			end, parts := braceExpressionParts(word, i)
+			if end < 0 {
+				continue
+			}
+
+			// every alternative is combined with every expansion of
+			// whatever follows the brace expression
+			prefix := word[:i]
+			suffixes := expandBraces(word[end+1:])
+
+			var retval []string
+			for _, part := range parts {
+				for _, expPart := range expandBraces(part) {
+					for _, suffix := range suffixes {
+						retval = append(retval, prefix+expPart+suffix)
+					}
+				}
+			}
+			return retval
+		}
+	}
+
+	// if we get here, there is nothing to expand
+	return []string{word}
+}
+
+// escapeBraces puts a backslash in front of any braces in the given word
+// that do not belong to a `${...}` variable
+//
+// The envish expander performs brace expansion of its own. We use this
+// to stop it, either because expandBraces() has already done the job,
+// or because Noglob is switched on.
+func escapeBraces(word string) string {
+	var buf strings.Builder
+
+	for i := 0; i < len(word); i++ {
+		switch word[i] {
+		case '\\':
+			// the next character is not special
+			buf.WriteByte(word[i])
+			if i+1 < len(word) {
+				i++
+				buf.WriteByte(word[i])
+			}
+		case '$':
+			// `${...}` is a variable, not a brace expression
+			end := -1
+			if i+1 < len(word) && word[i+1] == '{' {
+				end = findClosingBrace(word, i+1)
+			}
+			if end < 0 {
+				buf.WriteByte(word[i])
+				continue
+			}
+			buf.WriteString(word[i : end+1])
+			i = end
+		case '{', '}':
+			buf.WriteByte('\\')
+			buf.WriteByte(word[i])
+		default:
+			buf.WriteByte(word[i])
+		}
+	}
+
+	return buf.String()
+}
+
+// braceExpressionParts finds the brace expression that starts at
+// word[start], and returns the position of its closing `}` and the
+// alternatives that it expands to
+//
+// If word[start] does not start a list or a sequence, you get back -1
+// and nil.
+func braceExpressionParts(word string, start int) (int, []string) {
+	depth := 0
+	partStart := start + 1
+	var parts []string
+
+	for i := start + 1; i < len(word); i++ {
+		switch word[i] {
+		case '\\':
+			i++
+		case '$':
+			if i+1 < len(word) && word[i+1] == '{' {
+				end := findClosingBrace(word, i+1)
+				if end < 0 {
+					return -1, nil
+				}
+				i = end
+			}
+		case '{':
+			depth++
+		case ',':
+			if depth == 0 {
+				parts = append(parts, word[partStart:i])
+				partStart = i + 1
+			}
+		case '}':
+			if depth > 0 {
+				depth--
+				continue
+			}
+
+			// is this a list?
+			if parts != nil {
+				return i, append(parts, word[partStart:i])
+			}
+
+			// is this a sequence?
+			seq, ok := braceSequence(word[start+1 : i])
+			if !ok {
+				return -1, nil
+			}
+			return i, seq
+		}
+	}
+
+	// if we get here, the brace expression was never closed
+	return -1, nil
+}
+
+// braceSequence expands the inside of a `{x..y}` or `{x..y..incr}`
+// sequence expression
+func braceSequence(body string) ([]string, bool) {
+	matches := braceSequenceRegexp.FindStringSubmatch(body)
+	if matches == nil {
+		return nil, false
+	}
+
+	incr := 1
+	if matches[4] != "" {
+		var err error
+		incr, err = strconv.Atoi(matches[4])
+		if err != nil {
+			return nil, false
+		}
+		if incr < 0 {
+			incr = -incr
+		}
+		if incr == 0 {
+			incr = 1
+		}
+	}
+
+	// are we counting letters?
+	if isBraceLetter(matches[1]) && isBraceLetter(matches[2]) {
+		first := int(matches[1][0])
+		last := int(matches[2][0])
+
+		var retval []string
+		for _, n := range braceRange(first, last, incr) {
+			retval = append(retval, string(rune(n)))
+		}
+		return retval, true
+	}
+
+	// we cannot mix letters and numbers
+	first, err := strconv.Atoi(matches[1])
+	if err != nil {
+		return nil, false
+	}
+	last, err := strconv.Atoi(matches[2])
+	if err != nil {
+		return nil, false
+	}
+
+	// like UNIX shells, `{01..10}` pads all the numbers to the same width
+	width := 0
+	if isBracePadded(matches[1]) || isBracePadded(matches[2]) {
+		width = len(matches[1])
+		if len(matches[2]) > width {
+			width = len(matches[2])
+		}
+	}
+
+	var retval []string
+	for _, n := range braceRange(first, last, incr) {
+		retval = append(retval, padBraceNumber(n, width))
+	}
+	return retval, true
+}
+
+// braceRange returns every value from first to last, in steps of incr,
+// counting down if last comes before first
+func braceRange(first, last, incr int) []int {
+	var retval []int
+	if first <= last {
+		for n := first; n <= last; n += incr {
+			retval = append(retval, n)
+		}
+	} else {
+		for n := first; n >= last; n -= incr {
+			retval = append(retval, n)
+		}
+	}
+
+	return retval
+}
+
+// isBraceLetter returns true if the given end of a sequence expression
+// is a single letter
+func isBraceLetter(input string) bool {
+	return len(input) == 1 && (input[0] < '0' || input[0] > '9')
+}
+
+// isBracePadded returns true if the given end of a sequence expression
+// has leading zeroes
+func isBracePadded(input string) bool {
+	input = strings.TrimPrefix(input, "-")
+	return len(input) > 1 && input[0] == '0'
+}
+
+// padBraceNumber formats n with leading zeroes, so that it is (at least)
+// width characters long
+func padBraceNumber(n int, width int) string {
+	sign := ""
+	if n < 0 {
+		sign = "-"
+		n = -n
+	}
+
+	digits := strconv.Itoa(n)
+	if len(sign)+len(digits) < width {
+		digits = strings.Repeat("0", width-len(sign)-len(digits)) + digits
+	}
+
+	return sign + digits
+}
@@ -0,0 +1,183 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpandBracesExpandsLists(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	testData := "file.{txt,md,go}"
+	expectedResult := []string{"file.txt", "file.md", "file.go"}
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult := expandBraces(testData)
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestExpandBracesExpandsNumericSequences(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	testData := map[string][]string{
+		"{1..5}":     {"1", "2", "3", "4", "5"},
+		"{3..1}":     {"3", "2", "1"},
+		"{-1..1}":    {"-1", "0", "1"},
+		"{1..10..3}": {"1", "4", "7", "10"},
+		"{08..11}":   {"08", "09", "10", "11"},
+		"{-02..1}":   {"-02", "-01", "000", "001"},
+	}
+
+	for input, expectedResult := range testData {
+		// ----------------------------------------------------------------
+		// perform the change
+
+		actualResult := expandBraces(input)
+
+		// ----------------------------------------------------------------
+		// test the results
+
+		assert.Equal(t, expectedResult, actualResult, input)
+	}
+}
+
+func TestExpandBracesExpandsLetterSequences(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	testData := "disk-{a..d}"
+	expectedResult := []string{"disk-a", "disk-b", "disk-c", "disk-d"}
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult := expandBraces(testData)
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestExpandBracesSupportsNestingAndMultipleExpressions(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	testData := "{a,b{1..2}}-{x,y}"
+	expectedResult := []string{"a-x", "a-y", "b1-x", "b1-y", "b2-x", "b2-y"}
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult := expandBraces(testData)
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestExpandBracesLeavesOtherBracesAlone(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	testData := []string{
+		"${HOME}/file",
+		"${PATH:-{a,b}}",
+		"{}",
+		"{single}",
+		`\{a,b}`,
+		"{a,b",
+		"{1..z}",
+	}
+
+	for _, input := range testData {
+		// ----------------------------------------------------------------
+		// perform the change
+
+		actualResult := expandBraces(input)
+
+		// ----------------------------------------------------------------
+		// test the results
+
+		assert.Equal(t, []string{input}, actualResult, input)
+	}
+}
+
+func TestExpandBracesSkipsOverBracesThatAreNotExpressions(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	testData := "{x}{a,b}"
+	expectedResult := []string{"{x}a", "{x}b"}
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult := expandBraces(testData)
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, expectedResult, actualResult)
+}
@@ -0,0 +1,91 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import "context"
+
+// PipeContext returns the context.Context that the given pipe is
+// running under.
+//
+// Use it in your own Scriptish commands to find out if the sequence
+// has been cancelled, or if its deadline has passed.
+//
+// If the pipe was not created by a Sequence, you get back
+// context.Background().
+func PipeContext(p *Pipe) context.Context {
+	env, ok := getSequenceEnv(p)
+	if !ok || env.ctx == nil {
+		return context.Background()
+	}
+
+	return env.ctx
+}
+
+// setPipeContext makes the given context.Context available to every
+// command that uses the pipe
+func setPipeContext(p *Pipe, ctx context.Context) {
+	env, ok := getSequenceEnv(p)
+	if ok {
+		env.ctx = ctx
+	}
+}
+
+// checkPipeContext sets the pipe's error to ErrCancelled if the pipe's
+// context has been cancelled. It returns true if that has happened.
+//
+// Sequence controllers call this before running each step.
+func checkPipeContext(p *Pipe) bool {
+	// are we done yet?
+	ctxErr := PipeContext(p).Err()
+	if ctxErr == nil {
+		return false
+	}
+
+	// yes we are
+	p.RunCommand(func(p *Pipe) (int, error) {
+		return StatusNotOkay, ErrCancelled{ctxErr}
+	})
+
+	// debugging support
+	Tracef("status code: %d", p.StatusCode())
+	Tracef("error: %s", p.Error().Error())
+
+	return true
+}
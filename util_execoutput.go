@@ -0,0 +1,130 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"bytes"
+	"io"
+)
+
+// execOutput passes the output of an Exec()'d command into the pipe,
+// as soon as the command writes it
+type execOutput struct {
+	// where the output goes
+	dest io.Writer
+
+	// how we trace the output
+	trace func(format string, args ...interface{})
+
+	// any output that we have not traced yet, because it isn't a
+	// complete line
+	partial []byte
+
+	// the last byte that we wrote to dest
+	lastByte byte
+}
+
+// newExecOutput creates a writer for the given pipe's Stdout or Stderr
+func newExecOutput(dest io.Writer, trace func(format string, args ...interface{})) *execOutput {
+	return &execOutput{
+		dest:  dest,
+		trace: trace,
+	}
+}
+
+// Write passes the given output straight on to our destination
+func (o *execOutput) Write(b []byte) (int, error) {
+	n, err := o.dest.Write(b)
+	if n == 0 {
+		return n, err
+	}
+
+	// keep track of what we have written
+	o.lastByte = b[n-1]
+
+	// debugging support
+	if IsTraceEnabled() {
+		o.partial = append(o.partial, b[:n]...)
+		for {
+			i := bytes.IndexByte(o.partial, '\n')
+			if i < 0 {
+				break
+			}
+			o.trace("%s", o.partial[:i])
+			o.partial = o.partial[i+1:]
+		}
+	}
+
+	// all done
+	return n, err
+}
+
+// finish makes sure that the output ends in a newline, so that it does
+// not run into whatever is written to the pipe next
+func (o *execOutput) finish() {
+	if o.lastByte == 0 || o.lastByte == '\n' {
+		return
+	}
+
+	// debugging support
+	if len(o.partial) > 0 {
+		o.trace("%s", o.partial)
+		o.partial = nil
+	}
+
+	o.dest.Write([]byte{'\n'})
+	o.lastByte = '\n'
+}
+
+// sameWriter returns true if both writers are the same writer
+//
+// If Exec()'s Stdout and Stderr are the same writer, the command writes
+// to both through the same pipe, and its output stays in order.
+func sameWriter(a io.Writer, b io.Writer) (retval bool) {
+	// comparing interfaces panics if the underlying type cannot be
+	// compared
+	defer func() {
+		if recover() != nil {
+			retval = false
+		}
+	}()
+
+	return a == b
+}
@@ -0,0 +1,506 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"strings"
+
+	envish "github.com/ganbarodigital/go_envish/v3"
+)
+
+// expandString replaces any variables and command substitutions in the
+// given input with their values
+//
+// Unlike p.Env.Expand(), it tells you when the input cannot be expanded:
+// when it contains a bad substitution, when it uses `${name:?message}`
+// on a variable that has not been set, when Nounset is switched on
+// and it uses a variable that has not been set, or when a `$(name)`
+// fails. You get back an ErrExpansion that names the input.
+func expandString(p *Pipe, input string) (string, error) {
+	prepared, err := prepareExpansion(p, input, pipeShellOptions(p))
+	if err != nil {
+		return "", err
+	}
+
+	return expandPrepared(p, prepared), nil
+}
+
+// expandPrepared expands the given input, which has already been through
+// prepareExpansion()
+func expandPrepared(p *Pipe, prepared string) string {
+	// sequenceEnv.Expand() does the rewriting for us
+	env, ok := getSequenceEnv(p)
+	if ok {
+		return env.Expand(prepared)
+	}
+
+	return p.Env.Expand(rewriteForExpander(p.Env, prepared))
+}
+
+// prepareExpansion runs any command substitutions in the given input,
+// and makes sure that what is left can be expanded
+//
+// You get back a string that is ready for p.Env.Expand(), or an
+// ErrExpansion that names the input.
+func prepareExpansion(p *Pipe, input string, opts ShellOptions) (string, error) {
+	retval, err := runSubstitutions(p, input, opts)
+	if err != nil {
+		return "", ErrExpansion{input, err}
+	}
+
+	err = checkExpansion(p.Env, retval, opts.Nounset)
+	if err != nil {
+		return "", ErrExpansion{input, err}
+	}
+
+	return retval, nil
+}
+
+// expandStrings runs expandString() over every input in the list
+func expandStrings(p *Pipe, inputs []string) ([]string, error) {
+	retval := make([]string, len(inputs))
+	for i, input := range inputs {
+		var err error
+		retval[i], err = expandString(p, input)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return retval, nil
+}
+
+// expandKeepingEscapes expands the text between any backslash escapes in
+// the given pattern
+//
+// The escapes themselves are left alone, so that they reach a regex
+// exactly as they were written.
+func expandKeepingEscapes(p *Pipe, pattern string) (string, error) {
+	var retval strings.Builder
+	start := 0
+
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] != '\\' || i+1 >= len(pattern) {
+			continue
+		}
+
+		expText, err := expandString(p, pattern[start:i])
+		if err != nil {
+			return "", err
+		}
+		retval.WriteString(expText)
+		retval.WriteString(pattern[i : i+2])
+		i++
+		start = i + 1
+	}
+
+	expText, err := expandString(p, pattern[start:])
+	if err != nil {
+		return "", err
+	}
+	retval.WriteString(expText)
+
+	// all done
+	return retval.String(), nil
+}
+
+// rewriteForExpander rewrites the given input into something that the
+// envish expander can cope with
+//
+// The expander only expands `$name` when it is followed by whitespace or
+// the end of the input, it panics on a `$` that does not start an
+// expansion, and it does not support `${name-word}`, `${name=word}`,
+// `${name+word}` or `${name?word}`. So we:
+//
+// - turn `$name`, `$1` and `$#` into `${name}`, `${1}` and `${#}`
+// - escape any `$` that does not start an expansion
+// - work out `${name-word}` and friends ourselves
+// - put the values of `$?`, `$$`, `$!` and `$-` in directly
+//
+// Anything that has been escaped with a backslash is left alone.
+func rewriteForExpander(env envish.Expander, input string) string {
+	var buf strings.Builder
+
+	for i := 0; i < len(input); i++ {
+		switch input[i] {
+		case '\\':
+			// the next character is not special
+			buf.WriteByte(input[i])
+			if i+1 < len(input) {
+				i++
+				buf.WriteByte(input[i])
+			}
+			continue
+		case '$':
+			// we need to look at this one
+		default:
+			buf.WriteByte(input[i])
+			continue
+		}
+
+		// what does the `$` refer to?
+		var c byte
+		if i+1 < len(input) {
+			c = input[i+1]
+		}
+		switch {
+		case c == '{':
+			end := findClosingBrace(input, i+1)
+			if end < 0 {
+				buf.WriteString(`\$`)
+				continue
+			}
+			buf.WriteString(rewriteBracedExpansion(env, input[i+2:end]))
+			i = end
+		case isVarDigit(c):
+			buf.WriteString("${" + string(c) + "}")
+			i++
+		case isVarNameChar(c, true):
+			end := i + 1
+			for end < len(input) && isVarNameChar(input[end], false) {
+				end++
+			}
+			buf.WriteString("${" + input[i+1:end] + "}")
+			i = end - 1
+		case c != 0 && strings.IndexByte("?$!-", c) >= 0:
+			buf.WriteString(escapeSubstitution(env.Getenv("$" + string(c))))
+			i++
+		case c != 0 && strings.IndexByte("#@*", c) >= 0:
+			buf.WriteString("${" + string(c) + "}")
+			i++
+		default:
+			// this `$` does not start an expansion
+			buf.WriteString(`\$`)
+		}
+	}
+
+	return buf.String()
+}
+
+// rewriteBracedExpansion rewrites the inside of a `${...}` into something
+// that the envish expander can cope with
+func rewriteBracedExpansion(env envish.Expander, body string) string {
+	name, op, word, ok := parseBracedParam(body)
+	if !ok {
+		// leave it alone, without it being expanded
+		return `\${` + body + "}"
+	}
+
+	// special parameters that the expander does not know about
+	if len(body) == 1 && strings.IndexByte("?$!-", body[0]) >= 0 {
+		return escapeSubstitution(env.Getenv("$" + body))
+	}
+
+	// we leave special parameters, and `${!name}` indirection,
+	// to the expander
+	prefix := body[:len(body)-len(op)-len(word)]
+	if name == "" {
+		return "${" + body + "}"
+	}
+
+	_, isSet := env.LookupEnv(name)
+	switch op {
+	case "":
+		return "${" + prefix + "}"
+	case "-", "?":
+		if isSet {
+			return "${" + prefix + "}"
+		}
+		return rewriteForExpander(env, word)
+	case "=":
+		if isSet {
+			return "${" + prefix + "}"
+		}
+		value := env.Expand(rewriteForExpander(env, word))
+		// like UNIX shells, we cannot assign to positional parameters
+		if !strings.HasPrefix(name, "$") {
+			env.Setenv(name, value)
+		}
+		return escapeSubstitution(value)
+	case "+":
+		if isSet {
+			return rewriteForExpander(env, word)
+		}
+		return ""
+	default:
+		value, ok, err := expandBracedOperator(env, name, op, word, false)
+		switch {
+		case err != nil:
+			// leave it alone, without it being expanded
+			return `\${` + body + "}"
+		case ok:
+			return escapeSubstitution(value)
+		}
+		return "${" + prefix + op + rewriteForExpander(env, word) + "}"
+	}
+}
+
+// checkExpansion returns an error if the given input cannot be expanded
+// in the given environment
+//
+// If nounset is true, it is an error to use a variable that has not been
+// set. We skip expansions that deal with unset variables themselves (such
+// as `${name:-default}`), and special parameters such as `$@` and `$#`.
+func checkExpansion(env envish.Expander, input string, nounset bool) error {
+	for i := 0; i < len(input); i++ {
+		switch input[i] {
+		case '\\':
+			// the next character is not special
+			i++
+			continue
+		case '$':
+			// we need to look at this one
+		default:
+			continue
+		}
+
+		// robustness
+		if i+1 >= len(input) {
+			return nil
+		}
+
+		// what does the `$` refer to?
+		c := input[i+1]
+		switch {
+		case c == '{':
+			end := findClosingBrace(input, i+1)
+			if end < 0 {
+				return ErrBadSubstitution{input[i:]}
+			}
+			err := checkBracedExpansion(env, input[i:end+1], nounset)
+			if err != nil {
+				return err
+			}
+			i = end
+		case isVarDigit(c):
+			err := checkVarIsSet(env, "$"+string(c), nounset)
+			if err != nil {
+				return err
+			}
+			i++
+		case isVarNameChar(c, true):
+			end := i + 1
+			for end < len(input) && isVarNameChar(input[end], false) {
+				end++
+			}
+			err := checkVarIsSet(env, input[i+1:end], nounset)
+			if err != nil {
+				return err
+			}
+			i = end - 1
+		}
+	}
+
+	// if we get here, we can expand the whole input
+	return nil
+}
+
+// checkVarIsSet returns an ErrUnboundVariable if nounset is true and the
+// named variable has not been set
+func checkVarIsSet(env envish.Reader, name string, nounset bool) error {
+	if !nounset {
+		return nil
+	}
+
+	_, ok := env.LookupEnv(name)
+	if !ok {
+		return ErrUnboundVariable{name}
+	}
+
+	return nil
+}
+
+// bracedOperators are the operators that can follow the name inside
+// `${...}`, longest first
+var bracedOperators = []string{
+	":-", ":=", ":+", ":?",
+	"##", "%%", "//", "^^", ",,",
+	"-", "=", "+", "?", "#", "%", "/", "^", ",", ":", "@", "[",
+}
+
+// checkBracedExpansion returns an error if the given `${...}` cannot be
+// expanded in the given environment
+func checkBracedExpansion(env envish.Expander, expr string, nounset bool) error {
+	body := expr[2 : len(expr)-1]
+	name, op, word, ok := parseBracedParam(body)
+	if !ok {
+		return ErrBadSubstitution{expr}
+	}
+
+	// we do not support arrays, `${name@op}` transformations, or
+	// slicing up `$@` and `$*`
+	switch {
+	case op == "[", op == "@":
+		return ErrBadSubstitution{expr}
+	case name == "" && (body[0] == '@' || body[0] == '*'):
+		switch op {
+		case "", ":-", ":=", ":+", ":?", "-", "=", "+", "?":
+		default:
+			return ErrBadSubstitution{expr}
+		}
+	}
+
+	// special parameters, and `${!name}` indirection
+	if name == "" {
+		return nil
+	}
+
+	switch op {
+	case ":-", ":=", ":+", "-", "=", "+":
+		// these deal with unset variables themselves
+		return nil
+	case ":?", "?":
+		value, isSet := env.LookupEnv(name)
+		if isSet && (op == "?" || value != "") {
+			return nil
+		}
+
+		message := env.Expand(rewriteForExpander(env, word))
+		switch {
+		case message != "":
+			// use the message that we have been given
+		case op == ":?":
+			message = "parameter null or not set"
+		default:
+			message = "parameter not set"
+		}
+		return ErrParameterNotSet{strings.TrimPrefix(name, "$"), message}
+	default:
+		err := checkVarIsSet(env, name, nounset)
+		if err != nil {
+			return err
+		}
+
+		// a substring's offset and length may not make sense
+		_, _, err = expandBracedOperator(env, name, op, word, nounset)
+		return err
+	}
+}
+
+// parseBracedParam splits up the inside of a `${...}` into the variable's
+// name, the operator, and the operator's word
+//
+// The name is empty for special parameters and indirection, which can
+// never be unset. Positional parameters are named `$1`, `$2` and so on,
+// because that is how they are stored. ok is false if the input is not
+// something that UNIX shells can expand.
+func parseBracedParam(body string) (name string, op string, word string, ok bool) {
+	switch {
+	case body == "":
+		return "", "", "", false
+	case len(body) > 1 && body[0] == '#':
+		// `${#name}` is the length of a variable, but `${#}` is `$#`
+		name, op, _, ok = parseBracedParam(body[1:])
+		return name, "", "", ok && op == ""
+	case len(body) > 1 && body[0] == '!':
+		// indirection
+		return "", "", "", true
+	}
+
+	// how long is the name?
+	end := 0
+	switch {
+	case isVarDigit(body[0]):
+		for end < len(body) && isVarDigit(body[end]) {
+			end++
+		}
+		name = "$" + body[:end]
+	case isVarNameChar(body[0], true):
+		for end < len(body) && isVarNameChar(body[end], false) {
+			end++
+		}
+		name = body[:end]
+	case strings.IndexByte("@*#?-$!", body[0]) >= 0:
+		end = 1
+	default:
+		return "", "", "", false
+	}
+
+	// what comes next?
+	rest := body[end:]
+	if rest == "" {
+		return name, "", "", true
+	}
+	for _, op := range bracedOperators {
+		if strings.HasPrefix(rest, op) {
+			return name, op, rest[len(op):], true
+		}
+	}
+
+	// if we get here, we do not know what this is
+	return "", "", "", false
+}
+
+// findClosingBrace returns the position of the `}` that closes the `{`
+// at input[start], or -1 if there isn't one
+func findClosingBrace(input string, start int) int {
+	depth := 0
+	for i := start; i < len(input); i++ {
+		switch input[i] {
+		case '\\':
+			i++
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+// isVarDigit returns true if c can start a positional parameter
+func isVarDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// isVarNameChar returns true if c can appear in a variable name
+func isVarNameChar(c byte, first bool) bool {
+	switch {
+	case c == '_', c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		return true
+	case c >= '0' && c <= '9':
+		return !first
+	}
+	return false
+}
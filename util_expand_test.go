@@ -0,0 +1,593 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"os/exec"
+	"testing"
+
+	envish "github.com/ganbarodigital/go_envish/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckExpansionFindsVariablesThatAreNotSet(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	env := envish.NewLocalEnv()
+	env.Setenv("SET", "yes")
+	env.Setenv("$1", "first")
+
+	testData := map[string]string{
+		"$MISSING":           "MISSING",
+		"${MISSING}/":        "MISSING",
+		"$SET/${MISSING%.*}": "MISSING",
+		"${#MISSING}":        "MISSING",
+		"$2":                 "$2",
+		"${10}":              "$10",
+		"${SET:-x}$MISSING_": "MISSING_",
+	}
+
+	for input, name := range testData {
+		expectedResult := ErrUnboundVariable{name}
+
+		// ----------------------------------------------------------------
+		// perform the change
+
+		actualResult := checkExpansion(env, input, true)
+
+		// ----------------------------------------------------------------
+		// test the results
+
+		assert.Equal(t, expectedResult, actualResult, input)
+	}
+}
+
+func TestCheckExpansionIgnoresSafeExpansions(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	env := envish.NewLocalEnv()
+	env.Setenv("SET", "yes")
+	env.Setenv("$1", "first")
+
+	testData := []string{
+		"plain text",
+		"$SET ${SET} ${#SET} $1 ${1}",
+		"${MISSING:-default} ${MISSING-default}",
+		"${MISSING:=default} ${MISSING=default}",
+		"${MISSING:+alt} ${MISSING+alt}",
+		"${SET:?error} ${SET?error}",
+		"$@ $* $# ${#} $? $$ $! $-",
+		"${!SET}",
+		`\$MISSING`,
+		"costs $",
+	}
+
+	for _, input := range testData {
+		// ----------------------------------------------------------------
+		// perform the change
+
+		err := checkExpansion(env, input, true)
+
+		// ----------------------------------------------------------------
+		// test the results
+
+		assert.Nil(t, err, input)
+	}
+}
+
+func TestCheckExpansionOnlyChecksVariablesWhenNounsetIsSwitchedOn(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	env := envish.NewLocalEnv()
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	err := checkExpansion(env, "$MISSING ${MISSING} $2", false)
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+}
+
+func TestCheckExpansionFindsBadSubstitutions(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	env := envish.NewLocalEnv()
+
+	testData := map[string]string{
+		"${}":           "${}",
+		"${ SET}":       "${ SET}",
+		"${SET&}":       "${SET&}",
+		"${#SET:-x}":    "${#SET:-x}",
+		"a ${SET b":     "${SET b",
+		"${SET:-x} ${}": "${}",
+		"${SET@Q}":      "${SET@Q}",
+		"${SET[0]}":     "${SET[0]}",
+		"${@:2}":        "${@:2}",
+		"${*#x}":        "${*#x}",
+	}
+
+	for input, expr := range testData {
+		expectedResult := ErrBadSubstitution{expr}
+
+		// ----------------------------------------------------------------
+		// perform the change
+
+		actualResult := checkExpansion(env, input, false)
+
+		// ----------------------------------------------------------------
+		// test the results
+
+		assert.Equal(t, expectedResult, actualResult, input)
+	}
+}
+
+func TestCheckExpansionFindsBadSubstrings(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	env := envish.NewLocalEnv()
+	env.Setenv("SET", "value")
+
+	testData := map[string]error{
+		"${SET:1:-10}": ErrArithmetic{"-10", "substring expression < 0"},
+		"${SET:1+}":    ErrArithmetic{"1+", "syntax error: operand expected"},
+		"${SET: -2:1}": nil,
+	}
+
+	for input, expectedResult := range testData {
+		// ----------------------------------------------------------------
+		// perform the change
+
+		actualResult := checkExpansion(env, input, false)
+
+		// ----------------------------------------------------------------
+		// test the results
+
+		assert.Equal(t, expectedResult, actualResult, input)
+	}
+}
+
+func TestCheckExpansionSupportsParameterNotSetMessages(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	env := envish.NewLocalEnv()
+	env.Setenv("EMPTY", "")
+	env.Setenv("WHAT", "build dir")
+
+	testData := map[string]error{
+		"${MISSING:?}":              ErrParameterNotSet{"MISSING", "parameter null or not set"},
+		"${MISSING?}":               ErrParameterNotSet{"MISSING", "parameter not set"},
+		"${EMPTY:?}":                ErrParameterNotSet{"EMPTY", "parameter null or not set"},
+		"${EMPTY?}":                 nil,
+		"${MISSING:?$WHAT not set}": ErrParameterNotSet{"MISSING", "build dir not set"},
+		"${2:?need two args}":       ErrParameterNotSet{"2", "need two args"},
+	}
+
+	for input, expectedResult := range testData {
+		// ----------------------------------------------------------------
+		// perform the change
+
+		actualResult := checkExpansion(env, input, false)
+
+		// ----------------------------------------------------------------
+		// test the results
+
+		assert.Equal(t, expectedResult, actualResult, input)
+	}
+}
+
+func TestExpandStringReturnsTheExpandedString(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := "hello world"
+	pipe := NewPipe()
+	pipe.Env.Setenv("NAME", "world")
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := expandString(pipe, "hello ${NAME:?}")
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestExpandStringReturnsErrExpansionWhenItCannotExpandTheString(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedErr := ErrExpansion{
+		"rm -rf ${BUILD_DIR:?}/",
+		ErrParameterNotSet{"BUILD_DIR", "parameter null or not set"},
+	}
+	pipe := NewPipe()
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := expandString(pipe, "rm -rf ${BUILD_DIR:?}/")
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, expectedErr, err)
+	assert.Equal(t, "", actualResult)
+}
+
+func TestExpansionErrorsAlwaysStopTheList(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedErr := ErrExpansion{
+		"rm -rf ${BUILD_DIR_NOT_SET:?}/",
+		ErrParameterNotSet{"BUILD_DIR_NOT_SET", "parameter null or not set"},
+	}
+	list := NewList(
+		Echo("rm -rf ${BUILD_DIR_NOT_SET:?}/"),
+		Echo("this should not be seen"),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec().String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, expectedErr, err)
+	assert.Equal(t, StatusNotOkay, list.StatusCode())
+	assert.Equal(t, "", actualResult)
+}
+
+func TestExpansionErrorsStopPathArguments(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedErr := ErrExpansion{
+		"${BUILD_DIR_NOT_SET:?}/",
+		ErrParameterNotSet{"BUILD_DIR_NOT_SET", "parameter null or not set"},
+	}
+	pipeline := NewPipeline(
+		RmDir("${BUILD_DIR_NOT_SET:?}/"),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	err := pipeline.Exec().Error()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, expectedErr, err)
+	assert.Equal(t, StatusNotOkay, pipeline.StatusCode())
+}
+
+func TestExpandStringMatchesBash(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not found")
+	}
+
+	testData := []string{
+		"plain text",
+		"$SET ${SET} [$MISSING] [${MISSING}]",
+		"${SET:-default} ${MISSING:-default} ${EMPTY:-default}",
+		"${SET-default} ${MISSING-default} ${EMPTY-default}",
+		"${MISSING:=default} ${EMPTY:=default}",
+		"${SET:+alt} [${MISSING:+alt}] [${EMPTY:+alt}]",
+		"${#SET} ${#MISSING}",
+		"$1 ${1} [$2] ${2:-two}",
+		"${SET:?} ${SET?} ${EMPTY?}",
+		"${MISSING=default} $MISSING",
+		"${SET+alt} [${MISSING+alt}] [${EMPTY+alt}]",
+		"${MISSING-$SET.} $SET.txt $SET/path [$1]",
+		"costs 5$ or $ 6, ^$SET$",
+		`${SET#v} ${SET#*a} ${SET##*[ae]} [${SET#x}] ${SET#\v} ${1#f*r} [${MISSING#x}]`,
+		`${SET%e} ${SET%l*} ${SET%%[lu]*} [${SET%x}] ${SET%\e} ${1%st}`,
+		`${SET/a/A} ${SET//[aeu]/_} ${SET/#v/V} ${SET/%e/E} ${SET/l} ${SET//}`,
+		`${SET/#x/y} ${SET/%x/y} [${SET/*/}] ${SET/u*/} ${SET//?/<&>} ${SET/a/\&} ${1/i/$SET}`,
+		`${SET:1} ${SET:1:2} ${SET: -2} ${SET: -3:2} ${SET:(-2)} ${SET:1:-1}`,
+		`[${SET:10}] [${SET: -10}] ${SET:$#:1+1} ${SET:0} ${1:1:3}`,
+		`${SET^} ${SET^^} ${SET,,} ${SET^^[lu]}`,
+	}
+
+	for _, input := range testData {
+		pipe := NewPipe()
+		pipe.Env.Setenv("SET", "value")
+		pipe.Env.Setenv("EMPTY", "")
+		pipe.Env.Setenv("$1", "first")
+		pipe.Env.Setenv("$#", "1")
+
+		cmd := exec.Command(bash, "-c", `SET=value; EMPTY=; printf %s "`+input+`"`, "bash", "first")
+		cmd.Env = []string{}
+		expectedResult, err := cmd.Output()
+		assert.Nil(t, err, input)
+
+		// ----------------------------------------------------------------
+		// perform the change
+
+		actualResult, err := expandString(pipe, input)
+
+		// ----------------------------------------------------------------
+		// test the results
+
+		assert.Nil(t, err, input)
+		assert.Equal(t, string(expectedResult), actualResult, input)
+	}
+}
+
+func TestPipeEnvExpandNeverPanics(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := "costs 5$, MISSING: must be set, ${MISSING_TOO\n"
+	list := NewList(
+		NewSequenceStep(func(p *Pipe) (int, error) {
+			p.Stdout.WriteString(p.Env.Expand("costs 5$, ${MISSING:?must be set}, ${MISSING_TOO"))
+			p.Stdout.WriteRune('\n')
+			return StatusOkay, nil
+		}),
+	)
+	list.ShellOptions = &ShellOptions{Nounset: true}
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec().String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestExpandStringFailsWhenBashFails(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not found")
+	}
+
+	testData := []string{
+		"${MISSING:?}",
+		"${MISSING?}",
+		"${EMPTY:?}",
+		"${}",
+		"${SET&}",
+	}
+
+	for _, input := range testData {
+		pipe := NewPipe()
+		pipe.Env.Setenv("SET", "value")
+		pipe.Env.Setenv("EMPTY", "")
+
+		cmd := exec.Command(bash, "-c", `SET=value; EMPTY=; printf %s "`+input+`"`)
+		cmd.Env = []string{}
+		bashErr := cmd.Run()
+
+		// ----------------------------------------------------------------
+		// perform the change
+
+		_, err := expandString(pipe, input)
+
+		// ----------------------------------------------------------------
+		// test the results
+
+		assert.Error(t, bashErr, input)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestNounsetMakesUnboundVariablesAnError(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	list := NewList(
+		Echo("rm -rf $BUILD_DIR_NOT_SET/"),
+		Echo("this should not be seen"),
+	)
+	list.ShellOptions = &ShellOptions{Nounset: true}
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec().String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	expectedErr := ErrExpansion{
+		"rm -rf $BUILD_DIR_NOT_SET/",
+		ErrUnboundVariable{"BUILD_DIR_NOT_SET"},
+	}
+	assert.Equal(t, expectedErr, err)
+	assert.Equal(t, StatusNotOkay, list.StatusCode())
+	assert.Equal(t, "", actualResult)
+}
+
+func TestNounsetAcceptsVariablesThatAreSet(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := "hello world\n"
+	list := NewList(
+		Echo("$1 ${2:-world}"),
+	)
+	list.ShellOptions = &ShellOptions{Nounset: true}
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec("hello").String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestNounsetStopsStepOptionsThatUseUnboundVariables(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	list := NewList(
+		Echo("hello world", OverwriteFilenameWithStdout("$OUTPUT_FILE_NOT_SET")),
+	)
+	list.ShellOptions = &ShellOptions{Nounset: true}
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec().String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	expectedErr := ErrExpansion{
+		"$OUTPUT_FILE_NOT_SET",
+		ErrUnboundVariable{"OUTPUT_FILE_NOT_SET"},
+	}
+	assert.Equal(t, expectedErr, err)
+	assert.Equal(t, "", actualResult)
+}
+
+func TestNounsetIsSwitchedOffByDefault(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := "[] .txt /\n"
+	list := NewList(
+		Echo("[$VARIABLE_NOT_SET] $VARIABLE_NOT_SET.txt $VARIABLE_NOT_SET/"),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec().String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestNounsetFindsUnboundVariablesNextToPunctuation(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	list := NewList(
+		Echo("[$VARIABLE_NOT_SET]"),
+		Echo("this should not be seen"),
+	)
+	list.ShellOptions = &ShellOptions{Nounset: true}
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec().String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	expectedErr := ErrExpansion{
+		"[$VARIABLE_NOT_SET]",
+		ErrUnboundVariable{"VARIABLE_NOT_SET"},
+	}
+	assert.Equal(t, expectedErr, err)
+	assert.Equal(t, "", actualResult)
+}
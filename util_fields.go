@@ -0,0 +1,145 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"regexp"
+	"strings"
+)
+
+// FieldOptions tells SelectFields() and MapFields() how to split each
+// line up into fields, and how to join the fields back together again
+//
+// All of the delimiters are expanded before they are used.
+type FieldOptions struct {
+	// Delimiter is the string that separates the fields
+	//
+	// When Delimiter and DelimiterRegex are both empty, the fields are
+	// separated by runs of whitespace, like awk does by default.
+	Delimiter string
+
+	// DelimiterRegex is a regex that separates the fields
+	//
+	// When it is set, it is used instead of Delimiter.
+	DelimiterRegex string
+
+	// OutputDelimiter goes between the fields that we write out
+	//
+	// When it is empty, we use Delimiter; if that is empty too, we use
+	// a single space.
+	OutputDelimiter string
+}
+
+// fieldSplitter splits lines up into fields, and joins them back
+// together again
+type fieldSplitter struct {
+	// split turns a line into its fields
+	split func(line string) []string
+
+	// outputDelimiter goes between the fields that we write out
+	outputDelimiter string
+}
+
+// newFieldSplitter expands the given options, and turns them into a
+// fieldSplitter
+func newFieldSplitter(p *Pipe, fopts FieldOptions) (*fieldSplitter, error) {
+	// expand our input
+	delimiter, err := expandString(p, fopts.Delimiter)
+	if err != nil {
+		return nil, err
+	}
+	delimiterRegex, err := expandKeepingEscapes(p, fopts.DelimiterRegex)
+	if err != nil {
+		return nil, err
+	}
+	outputDelimiter, err := expandString(p, fopts.OutputDelimiter)
+	if err != nil {
+		return nil, err
+	}
+
+	retval := fieldSplitter{outputDelimiter: outputDelimiter}
+
+	// how are we splitting?
+	switch {
+	case delimiterRegex != "":
+		re, err := regexp.Compile(delimiterRegex)
+		if err != nil {
+			return nil, err
+		}
+		retval.split = func(line string) []string {
+			return re.Split(line, -1)
+		}
+	case delimiter != "":
+		retval.split = func(line string) []string {
+			return strings.Split(line, delimiter)
+		}
+	default:
+		retval.split = strings.Fields
+	}
+
+	// how are we joining?
+	if retval.outputDelimiter == "" {
+		retval.outputDelimiter = delimiter
+	}
+	if retval.outputDelimiter == "" {
+		retval.outputDelimiter = " "
+	}
+
+	// all done
+	return &retval, nil
+}
+
+// rangeIndexes returns the (zero-based) index of each item that the
+// given ranges select, in the order that the ranges select them, from
+// a list of n items
+func rangeIndexes(ranges []Range, n int) []int {
+	retval := []int{}
+	for _, singleRange := range ranges {
+		lo, hi, ok := singleRange.Resolve(n)
+		if !ok {
+			continue
+		}
+		for i := lo; i <= hi; i++ {
+			retval = append(retval, i-1)
+		}
+	}
+
+	return retval
+}
@@ -0,0 +1,181 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"regexp"
+	"strings"
+)
+
+// matchGlob returns true if the whole of the input matches the given
+// UNIX shell glob pattern.
+//
+// It supports `*`, `?`, `[...]` (including `[!...]`, `[^...]` and
+// character classes such as `[[:digit:]]`), and backslash escapes.
+// Like UNIX shells, `*` also matches `/`, and a `[` that is never
+// closed is treated as an ordinary character.
+func matchGlob(pattern string, input string) (bool, error) {
+	re, err := regexp.Compile(globToRegexp(pattern))
+	if err != nil {
+		return false, err
+	}
+
+	return re.MatchString(input), nil
+}
+
+// globToRegexp converts a UNIX shell glob pattern into a Golang regexp
+// that only matches the whole of a string
+func globToRegexp(pattern string) string {
+	var buf strings.Builder
+	buf.WriteString(`(?s)^`)
+
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+
+		switch c {
+		case '*':
+			buf.WriteString(`.*`)
+		case '?':
+			buf.WriteString(`.`)
+		case '\\':
+			// the next character is not special
+			if i+1 < len(pattern) {
+				i++
+				c = pattern[i]
+			}
+			buf.WriteString(regexp.QuoteMeta(string(c)))
+		case '[':
+			end := globBracketEnd(pattern, i)
+			if end < 0 {
+				buf.WriteString(`\[`)
+				continue
+			}
+			buf.WriteString(globBracketToRegexp(pattern[i+1 : end]))
+			i = end
+		default:
+			buf.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	buf.WriteString(`$`)
+	return buf.String()
+}
+
+// globBracketEnd returns the position of the `]` that closes the
+// bracket expression that starts at pattern[start], or -1 if the
+// bracket expression is never closed
+func globBracketEnd(pattern string, start int) int {
+	i := start + 1
+
+	// a leading `!` or `^` negates the bracket expression
+	if i < len(pattern) && (pattern[i] == '!' || pattern[i] == '^') {
+		i++
+	}
+
+	// a leading `]` is an ordinary character
+	if i < len(pattern) && pattern[i] == ']' {
+		i++
+	}
+
+	for ; i < len(pattern); i++ {
+		switch {
+		case pattern[i] == '\\':
+			i++
+		case strings.HasPrefix(pattern[i:], "[:"):
+			// character classes contain their own `]`
+			end := strings.Index(pattern[i+2:], ":]")
+			if end >= 0 {
+				i += end + 3
+			}
+		case pattern[i] == ']':
+			return i
+		}
+	}
+
+	return -1
+}
+
+// globBracketToRegexp converts the contents of a bracket expression
+// into a Golang regexp character class
+func globBracketToRegexp(body string) string {
+	var buf strings.Builder
+	buf.WriteString("[")
+
+	i := 0
+	if i < len(body) && (body[i] == '!' || body[i] == '^') {
+		buf.WriteString("^")
+		i++
+	}
+
+	for start := i; i < len(body); i++ {
+		c := body[i]
+
+		switch {
+		case c == '\\' && i+1 < len(body):
+			// the next character is not special
+			//
+			// we cannot escape letters and digits in a Golang regexp,
+			// because things like `\d` mean something there
+			i++
+			c = body[i]
+			if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+				buf.WriteString(`\`)
+			}
+			buf.WriteByte(c)
+		case strings.HasPrefix(body[i:], "[:"):
+			// character classes are the same in both
+			end := strings.Index(body[i+2:], ":]")
+			if end < 0 {
+				buf.WriteString(`\[`)
+				continue
+			}
+			buf.WriteString(body[i : i+end+4])
+			i += end + 3
+		case c == ']' || c == '[' || c == '\\' || (c == '^' && i == start):
+			buf.WriteString(`\`)
+			buf.WriteByte(c)
+		default:
+			buf.WriteByte(c)
+		}
+	}
+
+	buf.WriteString("]")
+	return buf.String()
+}
@@ -0,0 +1,186 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchGlobMatchesWildcards(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	pattern := "*.tar.?z"
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	firstResult, firstErr := matchGlob(pattern, "backup.tar.gz")
+	secondResult, secondErr := matchGlob(pattern, "dir/backup.tar.xz")
+	thirdResult, thirdErr := matchGlob(pattern, "backup.tar.bz2")
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, firstErr)
+	assert.True(t, firstResult)
+	assert.Nil(t, secondErr)
+	assert.True(t, secondResult)
+	assert.Nil(t, thirdErr)
+	assert.False(t, thirdResult)
+}
+
+func TestMatchGlobMatchesTheWholeInput(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	pattern := "start"
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	firstResult, _ := matchGlob(pattern, "start")
+	secondResult, _ := matchGlob(pattern, "restart")
+	thirdResult, _ := matchGlob(pattern, "starting")
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.True(t, firstResult)
+	assert.False(t, secondResult)
+	assert.False(t, thirdResult)
+}
+
+func TestMatchGlobSupportsBracketExpressions(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	pattern := "[a-c][!0-9][[:digit:]]"
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	firstResult, _ := matchGlob(pattern, "bx7")
+	secondResult, _ := matchGlob(pattern, "dx7")
+	thirdResult, _ := matchGlob(pattern, "b17")
+	fourthResult, _ := matchGlob(pattern, "bxy")
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.True(t, firstResult)
+	assert.False(t, secondResult)
+	assert.False(t, thirdResult)
+	assert.False(t, fourthResult)
+}
+
+func TestMatchGlobTreatsEscapedCharactersAsLiterals(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	pattern := `\*.[\]\d]`
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	firstResult, _ := matchGlob(pattern, "*.]")
+	secondResult, _ := matchGlob(pattern, "*.d")
+	thirdResult, _ := matchGlob(pattern, "a.d")
+	fourthResult, _ := matchGlob(pattern, "*.7")
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.True(t, firstResult)
+	assert.True(t, secondResult)
+	assert.False(t, thirdResult)
+	assert.False(t, fourthResult)
+}
+
+func TestMatchGlobTreatsUnclosedBracketsAsLiterals(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	pattern := "[abc"
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	firstResult, firstErr := matchGlob(pattern, "[abc")
+	secondResult, _ := matchGlob(pattern, "a")
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, firstErr)
+	assert.True(t, firstResult)
+	assert.False(t, secondResult)
+}
+
+func TestMatchGlobReturnsErrorForInvalidRanges(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	pattern := "[z-a]"
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	_, err := matchGlob(pattern, "m")
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Error(t, err)
+}
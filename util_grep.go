@@ -0,0 +1,324 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// grepNonWord matches a character that `grep -w` does not treat as part
+// of a word
+const grepNonWord = `[^\pL\pN_]`
+
+// expandGrepPatterns expands the given patterns
+//
+// Regexes keep their backslash escapes. Like `grep`, a pattern that
+// contains newlines is treated as one pattern per line.
+func expandGrepPatterns(p *Pipe, patterns []string, gopts GrepOptions) ([]string, error) {
+	retval := []string{}
+	for _, pattern := range patterns {
+		var expPattern string
+		var err error
+		if gopts.FixedStrings {
+			expPattern, err = expandString(p, pattern)
+		} else {
+			expPattern, err = expandKeepingEscapes(p, pattern)
+		}
+		if err != nil {
+			return nil, err
+		}
+		retval = append(retval, strings.Split(expPattern, "\n")...)
+	}
+
+	// all done
+	return retval, nil
+}
+
+// grepMatcher finds the parts of a line that match any of our patterns
+//
+// The match is always the first subexpression of our regexes.
+type grepMatcher struct {
+	// first finds the first match in a line
+	first *regexp.Regexp
+
+	// next finds the next whole-word match, starting from the last
+	// character of the previous match
+	//
+	// It is only set for MatchWords.
+	next *regexp.Regexp
+}
+
+// newGrepMatcher turns the given patterns into a grepMatcher
+func newGrepMatcher(patterns []string, gopts GrepOptions) (*grepMatcher, error) {
+	// we match any of the patterns
+	alternatives := make([]string, len(patterns))
+	for i, pattern := range patterns {
+		if gopts.FixedStrings {
+			pattern = regexp.QuoteMeta(pattern)
+		}
+
+		// make sure that each pattern is a valid regex on its own
+		_, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+		alternatives[i] = "(?:" + pattern + ")"
+	}
+	expr := "(" + strings.Join(alternatives, "|") + ")"
+
+	flags := ""
+	if gopts.IgnoreCase {
+		flags = "(?i)"
+	}
+
+	// how much of the line do we have to match?
+	first, next := expr, ""
+	switch {
+	case gopts.MatchLines:
+		first = "^" + expr + "$"
+	case gopts.MatchWords:
+		first = "(?:^|" + grepNonWord + ")" + expr + "(?:" + grepNonWord + "|$)"
+		next = grepNonWord + expr + "(?:" + grepNonWord + "|$)"
+	}
+
+	retval := grepMatcher{}
+	var err error
+	retval.first, err = regexp.Compile(flags + first)
+	if err != nil {
+		return nil, err
+	}
+	if next != "" {
+		retval.next, err = regexp.Compile(flags + next)
+		if err != nil {
+			return nil, err
+		}
+		retval.next.Longest()
+	}
+
+	// like grep, we want the longest match
+	retval.first.Longest()
+
+	// all done
+	return &retval, nil
+}
+
+// matches returns true if the line contains a match
+func (m *grepMatcher) matches(line string) bool {
+	return m.first.MatchString(line)
+}
+
+// findAll returns the start and end of every match in the line
+func (m *grepMatcher) findAll(line string) [][2]int {
+	retval := [][2]int{}
+
+	// the simple case
+	if m.next == nil {
+		for _, loc := range m.first.FindAllStringSubmatchIndex(line, -1) {
+			retval = append(retval, [2]int{loc[2], loc[3]})
+		}
+		return retval
+	}
+
+	// whole-word matches share the non-word characters between them,
+	// so each search has to start on the last character of the
+	// previous match
+	offset := 0
+	loc := m.first.FindStringSubmatchIndex(line)
+	for loc != nil {
+		start, end := offset+loc[2], offset+loc[3]
+		retval = append(retval, [2]int{start, end})
+
+		if start == end {
+			if end >= len(line) {
+				break
+			}
+			offset = end
+		} else {
+			_, size := utf8.DecodeLastRuneInString(line[:end])
+			offset = end - size
+		}
+		loc = m.next.FindStringSubmatchIndex(line[offset:])
+	}
+
+	// all done
+	return retval
+}
+
+// grepper selects lines, and writes them out, like `grep` does
+type grepper struct {
+	opts    GrepOptions
+	matcher *grepMatcher
+}
+
+// newGrepper returns a grepper that matches the given (already
+// expanded) patterns
+func newGrepper(patterns []string, gopts GrepOptions) (*grepper, error) {
+	matcher, err := newGrepMatcher(patterns, gopts)
+	if err != nil {
+		return nil, err
+	}
+
+	// all done
+	return &grepper{opts: gopts, matcher: matcher}, nil
+}
+
+// grepContextLine is a line that we may need to write out as context
+type grepContextLine struct {
+	n    int
+	line string
+}
+
+// grepLines calls readLine until it runs out of lines, and passes the
+// lines that we need to write out to the given write function
+//
+// If filename is not empty, it goes in front of every line that we
+// write out. Nothing is written out when opts.Count is set.
+//
+// It stops reading once it has selected opts.MaxCount lines, and has
+// written out the context that comes after them. It returns how many
+// lines were selected.
+func (g *grepper) grepLines(readLine func() (string, bool), filename string, write func(string)) int {
+	// how many lines have we selected?
+	selected := 0
+
+	// the lines that we may need to write out before the next
+	// selected line
+	before := []grepContextLine{}
+
+	// how many lines do we still need to write out after the last
+	// selected line?
+	afterRemaining := 0
+
+	// the number of the last line that we wrote out
+	lastWritten := 0
+
+	// writeLine writes out a selected line (sep == ':') or a line of
+	// context (sep == '-')
+	writeLine := func(n int, line string, sep string) {
+		// are we starting a new group of lines?
+		if lastWritten > 0 && n > lastWritten+1 && (g.opts.BeforeContext > 0 || g.opts.AfterContext > 0) {
+			write("--")
+		}
+		lastWritten = n
+
+		prefix := ""
+		if filename != "" {
+			prefix = filename + sep
+		}
+		if g.opts.LineNumbers {
+			prefix += strconv.Itoa(n) + sep
+		}
+
+		switch {
+		case sep != ":":
+			// like grep, we do not write out context lines when we
+			// are only writing out the matches
+			if !g.opts.OnlyMatching {
+				write(prefix + line)
+			}
+		case g.opts.OnlyMatching:
+			for _, loc := range g.matcher.findAll(line) {
+				if loc[0] < loc[1] {
+					write(prefix + line[loc[0]:loc[1]])
+				}
+			}
+		default:
+			write(prefix + line)
+		}
+	}
+
+	n := 0
+	for {
+		// once we have selected enough lines, we only write out
+		// the context that comes after them
+		done := g.opts.MaxCount > 0 && selected >= g.opts.MaxCount
+		if done && (afterRemaining == 0 || g.opts.Count) {
+			break
+		}
+
+		line, ok := readLine()
+		if !ok {
+			break
+		}
+		n++
+
+		if done {
+			writeLine(n, line, "-")
+			afterRemaining--
+			continue
+		}
+
+		// do we want this line?
+		if g.matcher.matches(line) == g.opts.Invert {
+			if afterRemaining > 0 {
+				if !g.opts.Count {
+					writeLine(n, line, "-")
+				}
+				afterRemaining--
+				continue
+			}
+			if g.opts.BeforeContext > 0 {
+				before = append(before, grepContextLine{n, line})
+				if len(before) > g.opts.BeforeContext {
+					before = before[1:]
+				}
+			}
+			continue
+		}
+
+		selected++
+		afterRemaining = g.opts.AfterContext
+		if g.opts.Count {
+			continue
+		}
+
+		for _, context := range before {
+			writeLine(context.n, context.line, "-")
+		}
+		before = before[:0]
+		writeLine(n, line, ":")
+	}
+
+	// all done
+	return selected
+}
@@ -0,0 +1,156 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// grepTestInput is the input for our `grep` conformance tests
+var grepTestInput = []string{
+	"root:x:0:0:root:/root:/bin/bash",
+	"daemon:x:1:1:daemon:/usr/sbin:/usr/sbin/nologin",
+	"Foo foo-bar foo_bar",
+	"nothing to see here",
+	"",
+	"foofoo FOO",
+	"a.b.c a+b",
+	"bin/bash",
+	"another line",
+	"yet another line",
+	"the end of foo",
+	"x-ray -x ray",
+	"costs $5, or 5$ each",
+}
+
+func TestGrepWithMatchesGrep(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	grepCmd, err := exec.LookPath("grep")
+	if err != nil {
+		t.Skip("grep not found")
+	}
+
+	testData := []struct {
+		grepArgs []string
+		patterns []string
+		gopts    GrepOptions
+	}{
+		{[]string{"foo"}, []string{"foo"}, GrepOptions{}},
+		{[]string{"-i", "foo"}, []string{"foo"}, GrepOptions{IgnoreCase: true}},
+		{[]string{"-v", "foo"}, []string{"foo"}, GrepOptions{Invert: true}},
+		{[]string{"-w", "foo"}, []string{"foo"}, GrepOptions{MatchWords: true}},
+		{[]string{"-w", "-o", "foo"}, []string{"foo"}, GrepOptions{MatchWords: true, OnlyMatching: true}},
+		{[]string{"-w", "-i", "-o", "-n", "foo"}, []string{"foo"}, GrepOptions{MatchWords: true, IgnoreCase: true, OnlyMatching: true, LineNumbers: true}},
+		{[]string{"-w", "-e", "-x"}, []string{"-x"}, GrepOptions{MatchWords: true}},
+		{[]string{"-w", "-o", "[a-z]+"}, []string{"[a-z]+"}, GrepOptions{MatchWords: true, OnlyMatching: true}},
+		{[]string{"-x", "bin/bash"}, []string{"bin/bash"}, GrepOptions{MatchLines: true}},
+		{[]string{"-x", "-w", "a.*line"}, []string{"a.*line"}, GrepOptions{MatchLines: true, MatchWords: true}},
+		{[]string{"-F", "a.b"}, []string{"a.b"}, GrepOptions{FixedStrings: true}},
+		{[]string{"-F", "-o", "a+b"}, []string{"a+b"}, GrepOptions{FixedStrings: true, OnlyMatching: true}},
+		{[]string{"a.b"}, []string{"a.b"}, GrepOptions{}},
+		{[]string{"-c", "foo"}, []string{"foo"}, GrepOptions{Count: true}},
+		{[]string{"-c", "-v", "foo"}, []string{"foo"}, GrepOptions{Count: true, Invert: true}},
+		{[]string{"-c", "-m", "2", "foo"}, []string{"foo"}, GrepOptions{Count: true, MaxCount: 2}},
+		{[]string{"-o", "fo+"}, []string{"fo+"}, GrepOptions{OnlyMatching: true}},
+		{[]string{"-o", "-i", "-n", "fo+"}, []string{"fo+"}, GrepOptions{OnlyMatching: true, IgnoreCase: true, LineNumbers: true}},
+		{[]string{"-o", "^[a-z]"}, []string{"^[a-z]"}, GrepOptions{OnlyMatching: true}},
+		{[]string{"-o", "-e", "ro", "-e", "roo"}, []string{"ro", "roo"}, GrepOptions{OnlyMatching: true}},
+		{[]string{"-e", "daemon", "-e", "end"}, []string{"daemon", "end"}, GrepOptions{}},
+		{[]string{"-m", "1", "foo"}, []string{"foo"}, GrepOptions{MaxCount: 1}},
+		{[]string{"-n", "line"}, []string{"line"}, GrepOptions{LineNumbers: true}},
+		{[]string{"-A", "1", "foo"}, []string{"foo"}, GrepOptions{AfterContext: 1}},
+		{[]string{"-B", "2", "-n", "foo"}, []string{"foo"}, GrepOptions{BeforeContext: 2, LineNumbers: true}},
+		{[]string{"-C", "1", "-n", "bash"}, []string{"bash"}, GrepOptions{BeforeContext: 1, AfterContext: 1, LineNumbers: true}},
+		{[]string{"-C", "1", "-n", "-v", "o"}, []string{"o"}, GrepOptions{BeforeContext: 1, AfterContext: 1, LineNumbers: true, Invert: true}},
+		{[]string{"-m", "2", "-A", "3", "-n", "foo"}, []string{"foo"}, GrepOptions{MaxCount: 2, AfterContext: 3, LineNumbers: true}},
+		{[]string{"-o", "-A", "1", "-n", "foo"}, []string{"foo"}, GrepOptions{OnlyMatching: true, AfterContext: 1, LineNumbers: true}},
+		{[]string{"-c", "-A", "1", "foo"}, []string{"foo"}, GrepOptions{Count: true, AfterContext: 1}},
+		{[]string{"-v", "-o", "foo"}, []string{"foo"}, GrepOptions{Invert: true, OnlyMatching: true}},
+		{[]string{"^$"}, []string{"^$"}, GrepOptions{}},
+		{[]string{"bash$"}, []string{"bash$"}, GrepOptions{}},
+		{[]string{"-o", "[a-z]+$"}, []string{"[a-z]+$"}, GrepOptions{OnlyMatching: true}},
+		{[]string{"-o", `5\$|\$5`}, []string{`5\$|\$5`}, GrepOptions{OnlyMatching: true}},
+		{[]string{"-F", "-o", "$"}, []string{"$"}, GrepOptions{FixedStrings: true, OnlyMatching: true}},
+		{[]string{"-F", "-o", "5$ "}, []string{"5$ "}, GrepOptions{FixedStrings: true, OnlyMatching: true}},
+		{[]string{"-n", ""}, []string{""}, GrepOptions{LineNumbers: true}},
+		{[]string{"-w", ""}, []string{""}, GrepOptions{MatchWords: true}},
+		{[]string{"no such thing"}, []string{"no such thing"}, GrepOptions{}},
+		{[]string{"-c", "no such thing"}, []string{"no such thing"}, GrepOptions{Count: true}},
+	}
+
+	for _, testCase := range testData {
+		// grep does not allow -E and -F together
+		grepArgs := testCase.grepArgs
+		if !testCase.gopts.FixedStrings {
+			grepArgs = append([]string{"-E"}, grepArgs...)
+		}
+		cmd := exec.Command(grepCmd, grepArgs...)
+		cmd.Env = append(os.Environ(), "LC_ALL=C")
+		cmd.Stdin = strings.NewReader(strings.Join(grepTestInput, "\n") + "\n")
+		expectedResult, _ := cmd.Output()
+		expectedStatus := cmd.ProcessState.ExitCode()
+
+		pipeline := NewPipeline(
+			EchoRawSlice(grepTestInput),
+			GrepWith(testCase.patterns, testCase.gopts),
+		)
+
+		// ----------------------------------------------------------------
+		// perform the change
+
+		pipeline.Exec()
+		actualResult := pipeline.Pipe.Stdout.String()
+
+		// ----------------------------------------------------------------
+		// test the results
+
+		assert.Equal(t, string(expectedResult), actualResult, testCase.grepArgs)
+		assert.Equal(t, expectedStatus, pipeline.StatusCode(), testCase.grepArgs)
+	}
+}
@@ -0,0 +1,146 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"context"
+	"io"
+)
+
+// runLoop does the work for While() and Until()
+//
+// It keeps running the body for as long as the expr's success matches
+// runWhile.
+func runLoop(p *Pipe, expr, body *Sequence, runWhile bool) (int, error) {
+	// get our parameters
+	params := getParamsFromEnv(p.Env)
+	ctx := PipeContext(p)
+	maxIterations := pipeMaxIterations(p)
+
+	// like UNIX shells, we succeed if the body never runs
+	statusCode, err := StatusOkay, error(nil)
+
+	for i := 0; ; i++ {
+		// has the sequence been cancelled?
+		ctxErr := ctx.Err()
+		if ctxErr != nil {
+			return StatusNotOkay, ErrCancelled{ctxErr}
+		}
+
+		// run the test expression first
+		expr.execConditionFromPipe(ctx, p, params...)
+
+		// copy the output over to our pipe
+		io.Copy(p.Stdout, expr.Pipe.Stdout)
+		io.Copy(p.Stderr, expr.Pipe.Stderr)
+
+		// do we go around again?
+		if expr.Okay() != runWhile {
+			Tracef("loop finished after %d iteration(s)", i)
+			return statusCode, err
+		}
+
+		// safety net
+		if maxIterations > 0 && i >= maxIterations {
+			Tracef("loop stopped after %d iteration(s)", i)
+			return StatusNotOkay, ErrMaxIterations{maxIterations}
+		}
+
+		// yes we do
+		body.execFromPipe(ctx, p, params...)
+
+		// copy the output over to our pipe
+		io.Copy(p.Stdout, body.Pipe.Stdout)
+		io.Copy(p.Stderr, body.Pipe.Stderr)
+
+		statusCode, err = body.StatusError()
+	}
+}
+
+// runLoopBody does the work for each iteration of ForEach() and
+// ForWords()
+//
+// It sets the loop variable in the body's LocalVars, and then runs
+// the body.
+func runLoopBody(ctx context.Context, p *Pipe, body *Sequence, params []string, varName string, value string) (int, error) {
+	// make the value available to the body
+	err := body.LocalVars.Setenv(varName, value)
+	if err != nil {
+		return StatusNotOkay, err
+	}
+
+	// run it
+	body.execFromPipe(ctx, p, params...)
+
+	// copy the output over to our pipe
+	io.Copy(p.Stdout, body.Pipe.Stdout)
+	io.Copy(p.Stderr, body.Pipe.Stderr)
+
+	// all done
+	return body.StatusError()
+}
+
+// pipeMaxIterations returns the most times that a loop running in the
+// given pipe is allowed to run its body
+//
+// It returns zero if there is no limit.
+func pipeMaxIterations(p *Pipe) int {
+	env, ok := getSequenceEnv(p)
+	if !ok {
+		return 0
+	}
+
+	return env.maxIterations
+}
+
+// setPipeMaxIterations sets the most times that a loop running in the
+// given pipe is allowed to run its body
+//
+// It returns the previous limit.
+func setPipeMaxIterations(p *Pipe, maxIterations int) int {
+	env, ok := getSequenceEnv(p)
+	if !ok {
+		return 0
+	}
+
+	retval := env.maxIterations
+	env.maxIterations = maxIterations
+	return retval
+}
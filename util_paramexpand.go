@@ -0,0 +1,349 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"regexp"
+	"strings"
+
+	envish "github.com/ganbarodigital/go_envish/v3"
+)
+
+// expandBracedOperator works out the value of `${name<op>word}` for the
+// operators that we have to do ourselves: pattern removal (`#`, `##`,
+// `%` and `%%`), pattern substitution (`/`, `//`, `/#` and `/%`) and
+// substrings (`:offset` and `:offset:length`)
+//
+// ok is false if op is not one of these operators. If nounset is true,
+// it is an error for the substring's offset or length to use a variable
+// that has not been set.
+func expandBracedOperator(env envish.Expander, name, op, word string, nounset bool) (retval string, ok bool, err error) {
+	value := env.Getenv(name)
+
+	switch op {
+	case "#", "##", "%", "%%":
+		return removeMatchingAffix(value, op, expandPatternWord(env, word)), true, nil
+	case "/", "//":
+		return substitutePattern(env, value, op, word), true, nil
+	case ":":
+		retval, err = substring(env, value, word, nounset)
+		return retval, true, err
+	}
+
+	return "", false, nil
+}
+
+// expandPatternWord expands the text between any backslash escapes in
+// the given glob pattern, and keeps the escapes, just like
+// expandKeepingEscapes() does
+func expandPatternWord(env envish.Expander, word string) string {
+	var buf strings.Builder
+	start := 0
+
+	for i := 0; i < len(word); i++ {
+		if word[i] != '\\' || i+1 >= len(word) {
+			continue
+		}
+
+		buf.WriteString(env.Expand(rewriteForExpander(env, word[start:i])))
+		buf.WriteString(word[i : i+2])
+		i++
+		start = i + 1
+	}
+	buf.WriteString(env.Expand(rewriteForExpander(env, word[start:])))
+
+	return buf.String()
+}
+
+// compileGlob returns a regexp that matches the whole of a string against
+// the given glob pattern, or nil if the pattern is not valid
+//
+// Like UNIX shells, we treat a pattern that is not valid as one that
+// never matches.
+func compileGlob(pattern string) *regexp.Regexp {
+	re, err := regexp.Compile(globToRegexp(pattern))
+	if err != nil {
+		return nil
+	}
+
+	return re
+}
+
+// runeBoundaries returns the position of every character in the given
+// string, plus the end of the string
+func runeBoundaries(s string) []int {
+	retval := make([]int, 0, len(s)+1)
+	for i := range s {
+		retval = append(retval, i)
+	}
+
+	return append(retval, len(s))
+}
+
+// removeMatchingAffix is `${name#pattern}`, `${name##pattern}`,
+// `${name%pattern}` and `${name%%pattern}`
+func removeMatchingAffix(value, op, pattern string) string {
+	re := compileGlob(pattern)
+	if re == nil {
+		return value
+	}
+	bounds := runeBoundaries(value)
+
+	switch op {
+	case "#":
+		for _, end := range bounds {
+			if re.MatchString(value[:end]) {
+				return value[end:]
+			}
+		}
+	case "##":
+		for i := len(bounds) - 1; i >= 0; i-- {
+			if re.MatchString(value[:bounds[i]]) {
+				return value[bounds[i]:]
+			}
+		}
+	case "%":
+		for i := len(bounds) - 1; i >= 0; i-- {
+			if re.MatchString(value[bounds[i]:]) {
+				return value[:bounds[i]]
+			}
+		}
+	case "%%":
+		for _, start := range bounds {
+			if re.MatchString(value[start:]) {
+				return value[:start]
+			}
+		}
+	}
+
+	// if we get here, nothing matched
+	return value
+}
+
+// substitutePattern is `${name/pattern/string}`, `${name//pattern/string}`,
+// `${name/#pattern/string}` and `${name/%pattern/string}`
+//
+// Like UNIX shells, it replaces the longest match, and an `&` in the
+// string is replaced by the text that matched.
+func substitutePattern(env envish.Expander, value, op, word string) string {
+	// `/#` and `/%` only match at the start and the end
+	var anchor byte
+	if op == "/" && word != "" && (word[0] == '#' || word[0] == '%') {
+		anchor = word[0]
+		word = word[1:]
+	}
+
+	patternWord, replacementWord := splitPatternWord(word)
+	pattern := expandPatternWord(env, patternWord)
+	if pattern == "" && anchor == 0 {
+		return value
+	}
+	re := compileGlob(pattern)
+	if re == nil {
+		return value
+	}
+	replacement := expandPatternWord(env, replacementWord)
+	bounds := runeBoundaries(value)
+
+	switch anchor {
+	case '#':
+		for i := len(bounds) - 1; i >= 0; i-- {
+			end := bounds[i]
+			if re.MatchString(value[:end]) {
+				return replaceMatch(replacement, value[:end]) + value[end:]
+			}
+		}
+		return value
+	case '%':
+		for _, start := range bounds {
+			if re.MatchString(value[start:]) {
+				return value[:start] + replaceMatch(replacement, value[start:])
+			}
+		}
+		return value
+	}
+
+	var buf strings.Builder
+	i := 0
+	for i < len(bounds)-1 {
+		start := bounds[i]
+
+		// look for the longest match that starts here
+		matched := false
+		for j := len(bounds) - 1; j > i; j-- {
+			end := bounds[j]
+			if !re.MatchString(value[start:end]) {
+				continue
+			}
+
+			buf.WriteString(replaceMatch(replacement, value[start:end]))
+			if op == "/" {
+				buf.WriteString(value[end:])
+				return buf.String()
+			}
+			i = j
+			matched = true
+			break
+		}
+
+		if !matched {
+			buf.WriteString(value[start:bounds[i+1]])
+			i++
+		}
+	}
+
+	return buf.String()
+}
+
+// splitPatternWord splits the word of `${name/pattern/string}` into the
+// pattern and the string
+func splitPatternWord(word string) (string, string) {
+	for i := 0; i < len(word); i++ {
+		switch word[i] {
+		case '\\':
+			i++
+		case '$':
+			// skip over any `${...}`, as it may contain a `/` of its own
+			if i+1 < len(word) && word[i+1] == '{' {
+				end := findClosingBrace(word, i+1)
+				if end >= 0 {
+					i = end
+				}
+			}
+		case '/':
+			return word[:i], word[i+1:]
+		}
+	}
+
+	// if we get here, there is no string
+	return word, ""
+}
+
+// replaceMatch returns the replacement string for the given match
+//
+// An `&` is the match, and a backslash stops the next character from
+// being special.
+func replaceMatch(replacement, match string) string {
+	var buf strings.Builder
+	for i := 0; i < len(replacement); i++ {
+		switch {
+		case replacement[i] == '\\' && i+1 < len(replacement):
+			i++
+			buf.WriteByte(replacement[i])
+		case replacement[i] == '&':
+			buf.WriteString(match)
+		default:
+			buf.WriteByte(replacement[i])
+		}
+	}
+
+	return buf.String()
+}
+
+// substring is `${name:offset}` and `${name:offset:length}`
+//
+// The offset and length are arithmetic expressions. A negative offset
+// counts back from the end of the value, and a negative length is where
+// the substring ends, counting back from the end of the value.
+func substring(env envish.Expander, value, word string, nounset bool) (string, error) {
+	offsetExpr, lengthExpr, hasLength := splitSubstringWord(word)
+	offset, err := evalArith(env, env.Expand(rewriteForExpander(env, offsetExpr)), nounset)
+	if err != nil {
+		return "", err
+	}
+
+	chars := []rune(value)
+	size := int64(len(chars))
+	if offset < 0 {
+		offset += size
+	}
+	if offset < 0 || offset > size {
+		return "", nil
+	}
+
+	end := size
+	if hasLength {
+		length, err := evalArith(env, env.Expand(rewriteForExpander(env, lengthExpr)), nounset)
+		if err != nil {
+			return "", err
+		}
+
+		switch {
+		case length < 0:
+			end = size + length
+			if end < offset {
+				return "", ErrArithmetic{strings.TrimSpace(lengthExpr), "substring expression < 0"}
+			}
+		case offset+length < size:
+			end = offset + length
+		}
+	}
+
+	return string(chars[offset:end]), nil
+}
+
+// splitSubstringWord splits the word of `${name:offset:length}` into
+// the offset and the length
+func splitSubstringWord(word string) (string, string, bool) {
+	depth := 0
+	for i := 0; i < len(word); i++ {
+		switch word[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case '$':
+			// skip over any `${...}`, as it may contain a `:` of its own
+			if i+1 < len(word) && word[i+1] == '{' {
+				end := findClosingBrace(word, i+1)
+				if end >= 0 {
+					i = end
+				}
+			}
+		case ':':
+			if depth == 0 {
+				return word[:i], word[i+1:], true
+			}
+		}
+	}
+
+	// if we get here, there is no length
+	return word, "", false
+}
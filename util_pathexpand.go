@@ -0,0 +1,356 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+)
+
+// expandPathArgs turns the given argument into a list of filepaths
+//
+// It performs brace expansion, expands any variables, and then expands
+// any glob patterns against the filesystem, in the same order that UNIX
+// shells do. Relative paths are matched against the sequence's working
+// directory, but are returned in the same form that they were given.
+//
+// A glob pattern that does not match anything is returned as it is,
+// unless Nullglob or Failglob are switched on. If Noglob is switched on,
+// the argument is only variable-expanded.
+func expandPathArgs(p *Pipe, arg string) ([]string, error) {
+	return expandPathArgsWithOptions(p, arg, pipeShellOptions(p))
+}
+
+// expandPathArgsWithOptions does the work for expandPathArgs()
+func expandPathArgsWithOptions(p *Pipe, arg string, opts ShellOptions) ([]string, error) {
+	// can we expand the argument at all?
+	prepared, err := prepareExpansion(p, arg, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// special case: globbing has been switched off
+	if opts.Noglob {
+		return []string{expandPrepared(p, escapeBraces(prepared))}, nil
+	}
+
+	var retval []string
+	for _, word := range expandBraces(prepared) {
+		expWord := expandPrepared(p, escapeBraces(word))
+		if !hasGlobChars(expWord) {
+			retval = append(retval, expWord)
+			continue
+		}
+
+		matches, err := globPaths(p, expWord)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case len(matches) > 0:
+			retval = append(retval, matches...)
+		case opts.Failglob:
+			return nil, ErrNoGlobMatch{expWord}
+		case opts.Nullglob:
+			// the pattern disappears
+		default:
+			retval = append(retval, expWord)
+		}
+	}
+
+	// all done
+	return retval, nil
+}
+
+// expandPathArg turns the given argument into a single filepath
+//
+// It works just like expandPathArgs(), but returns an ErrAmbiguousPath
+// if the argument does not expand to exactly one filepath.
+func expandPathArg(p *Pipe, arg string) (string, error) {
+	paths, err := expandPathArgs(p, arg)
+	if err != nil {
+		return "", err
+	}
+	if len(paths) != 1 {
+		return "", ErrAmbiguousPath{arg, paths}
+	}
+
+	return paths[0], nil
+}
+
+// expandArgs runs expandPathArgs() over every argument in the list, and
+// returns all of the results as a single list
+//
+// Like `"$@"` in UNIX shells, an argument that is just `$@` (or `${@}`)
+// becomes one argument per positional parameter, without any further
+// expansion.
+func expandArgs(p *Pipe, args []string) ([]string, error) {
+	retval := make([]string, 0, len(args))
+	for _, arg := range args {
+		if arg == "$@" || arg == "${@}" {
+			retval = append(retval, getParamsFromEnv(p.Env)...)
+			continue
+		}
+
+		expArg, err := expandPathArgs(p, arg)
+		if err != nil {
+			return nil, err
+		}
+		retval = append(retval, expArg...)
+	}
+
+	return retval, nil
+}
+
+// tracePathArgs formats a list of expanded filepaths for our trace
+// messages
+func tracePathArgs(paths []string) string {
+	retval := make([]string, len(paths))
+	for i, path := range paths {
+		retval[i] = fmt.Sprintf("%#v", path)
+	}
+
+	return strings.Join(retval, ", ")
+}
+
+// hasGlobChars returns true if the input contains any unescaped glob
+// pattern characters
+func hasGlobChars(input string) bool {
+	for i := 0; i < len(input); i++ {
+		switch input[i] {
+		case '\\':
+			i++
+		case '*', '?':
+			return true
+		case '[':
+			if globBracketEnd(input, i) >= 0 {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// unescapeGlob removes the backslashes from a part of a glob pattern
+// that has no glob characters in it
+func unescapeGlob(input string) string {
+	if !strings.Contains(input, `\`) {
+		return input
+	}
+
+	var buf strings.Builder
+	for i := 0; i < len(input); i++ {
+		if input[i] == '\\' && i+1 < len(input) {
+			i++
+		}
+		buf.WriteByte(input[i])
+	}
+
+	return buf.String()
+}
+
+// globPaths returns the sorted list of filepaths that match the given
+// glob pattern
+//
+// `**` on its own matches any number of folders (including none). When
+// it is the last part of the pattern, it matches every file and folder
+// underneath. Like UNIX shells, wildcards do not match names that start
+// with a `.`, unless the pattern does too.
+func globPaths(p *Pipe, pattern string) ([]string, error) {
+	// we build up the paths one part of the pattern at a time
+	candidates := []string{""}
+	if strings.HasPrefix(pattern, "/") {
+		candidates = []string{"/"}
+	}
+	parts := strings.Split(strings.TrimLeft(pattern, "/"), "/")
+
+	for i, part := range parts {
+		isLast := i == len(parts)-1
+
+		var next []string
+		for _, candidate := range candidates {
+			var matches []string
+			var err error
+
+			switch {
+			case part == "" && isLast:
+				// a trailing slash only matches folders
+				if isGlobDir(p, candidate) {
+					matches = []string{candidate + "/"}
+				}
+			case part == "":
+				// double slashes are the same as one
+				matches = []string{candidate}
+			case part == "**":
+				matches = globStarStar(p, candidate, isLast)
+			case !hasGlobChars(part):
+				path := joinGlobPath(candidate, unescapeGlob(part))
+				if _, err = os.Lstat(resolvePipePath(p, path)); err == nil {
+					matches = []string{path}
+				}
+			default:
+				matches, err = globFolder(p, candidate, part, !isLast)
+				if err != nil {
+					return nil, err
+				}
+			}
+			next = append(next, matches...)
+		}
+
+		candidates = next
+	}
+
+	// `**` can find the same path more than once
+	sort.Strings(candidates)
+	retval := candidates[:0]
+	for i, candidate := range candidates {
+		if i > 0 && candidate == candidates[i-1] {
+			continue
+		}
+		retval = append(retval, candidate)
+	}
+
+	return retval, nil
+}
+
+// globFolder returns the entries in the given folder that match the
+// given part of a glob pattern
+func globFolder(p *Pipe, dir string, part string, dirsOnly bool) ([]string, error) {
+	entries := readGlobDir(p, dir)
+
+	var retval []string
+	for _, entry := range entries {
+		// hidden files must be asked for
+		if strings.HasPrefix(entry.Name(), ".") && !strings.HasPrefix(unescapeGlob(part), ".") {
+			continue
+		}
+
+		ok, err := matchGlob(part, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+
+		path := joinGlobPath(dir, entry.Name())
+		if dirsOnly && !isGlobDir(p, path) {
+			continue
+		}
+		retval = append(retval, path)
+	}
+
+	return retval, nil
+}
+
+// globStarStar returns the folders (and, if it is the last part of the
+// pattern, the files too) that `**` matches underneath the given folder
+//
+// It does not follow symlinks, and it skips hidden files and folders.
+func globStarStar(p *Pipe, dir string, isLast bool) []string {
+	var retval []string
+	if !isLast {
+		retval = append(retval, dir)
+	}
+
+	for _, entry := range readGlobDir(p, dir) {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		path := joinGlobPath(dir, entry.Name())
+		if entry.IsDir() {
+			if isLast {
+				retval = append(retval, path)
+			}
+			retval = append(retval, globStarStar(p, path, isLast)...)
+		} else if isLast {
+			retval = append(retval, path)
+		}
+	}
+
+	return retval
+}
+
+// readGlobDir returns the contents of the given folder, or nothing if
+// the folder cannot be read
+func readGlobDir(p *Pipe, dir string) []os.FileInfo {
+	if dir == "" {
+		dir = "."
+	}
+
+	entries, err := ioutil.ReadDir(resolvePipePath(p, dir))
+	if err != nil {
+		return nil
+	}
+
+	return entries
+}
+
+// isGlobDir returns true if the given path is a folder, or a symlink
+// to a folder
+func isGlobDir(p *Pipe, path string) bool {
+	if path == "" {
+		path = "."
+	}
+
+	info, err := os.Stat(resolvePipePath(p, path))
+	return err == nil && info.IsDir()
+}
+
+// joinGlobPath adds a name to a path that we are building up
+//
+// Unlike filepath.Join(), it keeps the path in the form that the user
+// gave it to us.
+func joinGlobPath(dir string, name string) string {
+	switch {
+	case dir == "":
+		return name
+	case strings.HasSuffix(dir, "/"):
+		return dir + name
+	default:
+		return dir + "/" + name
+	}
+}
@@ -0,0 +1,410 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// makeGlobTestTree creates a folder full of files for our globbing
+// tests to match against
+func makeGlobTestTree(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "scriptish-glob-")
+	assert.Nil(t, err)
+
+	for _, filename := range []string{
+		"a.txt",
+		"b.txt",
+		"c.log",
+		".hidden.txt",
+		"sub/d.txt",
+		"sub/deep/e.txt",
+		"sub/.secret/f.txt",
+	} {
+		fullPath := filepath.Join(dir, filename)
+		err = os.MkdirAll(filepath.Dir(fullPath), 0755)
+		assert.Nil(t, err)
+		err = ioutil.WriteFile(fullPath, []byte(filename+"\n"), 0644)
+		assert.Nil(t, err)
+	}
+
+	return dir
+}
+
+// expandPathArgsInDir runs expandPathArgs() inside a sequence that
+// is working in the given folder
+func expandPathArgsInDir(dir string, arg string, opts ShellOptions) ([]string, error) {
+	var retval []string
+
+	sq := NewList(
+		NewSequenceStep(func(p *Pipe) (int, error) {
+			var err error
+			retval, err = expandPathArgs(p, arg)
+			return StatusOkay, err
+		}),
+	)
+	sq.Dir = dir
+	sq.ShellOptions = &opts
+
+	err := sq.Exec().Error()
+	return retval, err
+}
+
+func TestExpandPathArgsMatchesGlobPatterns(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	dir := makeGlobTestTree(t)
+	defer os.RemoveAll(dir)
+
+	expectedResult := []string{"a.txt", "b.txt"}
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := expandPathArgsInDir(dir, "*.txt", ShellOptions{})
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestExpandPathArgsKeepsThePathInTheFormItWasGiven(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	dir := makeGlobTestTree(t)
+	defer os.RemoveAll(dir)
+
+	expectedResult := []string{
+		"./sub/../a.txt",
+		"./sub/../b.txt",
+		dir + "/sub/d.txt",
+	}
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	relResult, err1 := expandPathArgsInDir(dir, "./sub/../?.txt", ShellOptions{})
+	absResult, err2 := expandPathArgsInDir("", dir+"/sub/[a-d].txt", ShellOptions{})
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err1)
+	assert.Nil(t, err2)
+	assert.Equal(t, expectedResult, append(relResult, absResult...))
+}
+
+func TestExpandPathArgsSupportsRecursiveGlobs(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	dir := makeGlobTestTree(t)
+	defer os.RemoveAll(dir)
+
+	expectedResult := []string{"a.txt", "b.txt", "sub/d.txt", "sub/deep/e.txt"}
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := expandPathArgsInDir(dir, "**/*.txt", ShellOptions{})
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestExpandPathArgsMatchesEverythingUnderneathATrailingDoubleStar(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	dir := makeGlobTestTree(t)
+	defer os.RemoveAll(dir)
+
+	expectedResult := []string{"sub/d.txt", "sub/deep", "sub/deep/e.txt"}
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := expandPathArgsInDir(dir, "sub/**", ShellOptions{})
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestExpandPathArgsOnlyMatchesHiddenFilesWhenAskedTo(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	dir := makeGlobTestTree(t)
+	defer os.RemoveAll(dir)
+
+	expectedResult := []string{".hidden.txt", "sub/.secret/f.txt"}
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	firstResult, err1 := expandPathArgsInDir(dir, ".*.txt", ShellOptions{})
+	secondResult, err2 := expandPathArgsInDir(dir, "sub/.*/*", ShellOptions{})
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err1)
+	assert.Nil(t, err2)
+	assert.Equal(t, expectedResult, append(firstResult, secondResult...))
+}
+
+func TestExpandPathArgsPerformsBraceExpansionFirst(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	dir := makeGlobTestTree(t)
+	defer os.RemoveAll(dir)
+
+	// brace expansion does not care if the files exist
+	expectedResult := []string{"c.log", "a.txt", "b.txt", "missing.md"}
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := expandPathArgsInDir(dir, "{*.log,*.txt,missing.md}", ShellOptions{})
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestExpandPathArgsOnlyPerformsBraceExpansionOnce(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	dir := makeGlobTestTree(t)
+	defer os.RemoveAll(dir)
+
+	// this is what bash does with `echo {solo}{1..2} \{a,b\}`
+	expectedResult := []string{"{solo}1", "{solo}2", "{a,b}"}
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := expandPathArgsInDir(dir, "{solo}{1..2}", ShellOptions{})
+	escapedResult, escapedErr := expandPathArgsInDir(dir, "\\{a,b\\}", ShellOptions{})
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Nil(t, escapedErr)
+	assert.Equal(t, expectedResult, append(actualResult, escapedResult...))
+}
+
+func TestExpandPathArgsLeavesUnmatchedPatternsAlone(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	dir := makeGlobTestTree(t)
+	defer os.RemoveAll(dir)
+
+	expectedResult := []string{"*.yaml"}
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := expandPathArgsInDir(dir, "*.yaml", ShellOptions{})
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestExpandPathArgsSupportsNullglob(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	dir := makeGlobTestTree(t)
+	defer os.RemoveAll(dir)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := expandPathArgsInDir(dir, "*.yaml", ShellOptions{Nullglob: true})
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Empty(t, actualResult)
+}
+
+func TestExpandPathArgsSupportsFailglob(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	dir := makeGlobTestTree(t)
+	defer os.RemoveAll(dir)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	_, err := expandPathArgsInDir(dir, "*.yaml", ShellOptions{Nullglob: true, Failglob: true})
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, ErrNoGlobMatch{"*.yaml"}, err)
+}
+
+func TestExpandPathArgsSupportsNoglob(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	dir := makeGlobTestTree(t)
+	defer os.RemoveAll(dir)
+
+	expectedResult := []string{"*.{txt,log}"}
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := expandPathArgsInDir(dir, "*.{txt,log}", ShellOptions{Noglob: true})
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestExpandPathArgSetsErrorIfThePathIsAmbiguous(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	dir := makeGlobTestTree(t)
+	defer os.RemoveAll(dir)
+
+	pipeline := NewPipeline(
+		Echo("hello"),
+		WriteToFile("*.txt"),
+	)
+	pipeline.Dir = dir
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	err := pipeline.Exec().Error()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, ErrAmbiguousPath{"*.txt", []string{"a.txt", "b.txt"}}, err)
+}
+
+func TestExpandPathArgUsesASingleMatch(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	dir := makeGlobTestTree(t)
+	defer os.RemoveAll(dir)
+
+	expectedResult := "hello\n"
+
+	pipeline := NewPipeline(
+		Echo("hello"),
+		WriteToFile("*.log"),
+	)
+	pipeline.Dir = dir
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	err := pipeline.Exec().Error()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	content, err := ioutil.ReadFile(filepath.Join(dir, "c.log"))
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, string(content))
+}
@@ -0,0 +1,79 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"context"
+	"os/exec"
+)
+
+// killOnCancel kills the given (already started) command if the given
+// context is cancelled.
+//
+// Call the returned function once the command has finished.
+//
+// We do not use exec.CommandContext() for this. We support Go 1.13, and
+// until Go 1.20 added Cmd.Cancel, CommandContext() can only kill the
+// command itself. Anything that the command has started (eg, the
+// `sleep` in `bash -c "sleep 60; echo done"`) keeps running, and keeps
+// the command's stdout and stderr open. Until Go 1.20 added
+// Cmd.WaitDelay, cmd.Wait() does not return until they are closed, so
+// the timeout would never happen. When the command runs under a
+// deadline, we put it in its own process group (see
+// startProcessGroup()), and kill the whole group instead.
+func killOnCancel(ctx context.Context, cmd *exec.Cmd) func() {
+	// can this context ever be cancelled?
+	if ctx.Done() == nil {
+		return func() {}
+	}
+
+	finished := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			killProcess(cmd)
+		case <-finished:
+		}
+	}()
+
+	return func() {
+		close(finished)
+	}
+}
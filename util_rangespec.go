@@ -48,17 +48,54 @@ import (
 )
 
 // Range tracks the start and end of a given range of numbers
+//
+// Negative numbers count back from the end of the list: -1 is the last
+// item (awk's `$NF`), -2 is the item before that (`$(NF-1)`), and so on.
+// Use Resolve() to turn them into positions in a list.
 type Range struct {
 	Lo int
 	Hi int
 }
 
+// Resolve returns the (one-based) start and end of the range, for a
+// list that has n items in it
+//
+// Ranges that count back from the end of the list are turned into
+// positions, and the range is trimmed to fit the list. ok is false if
+// the range does not select any items at all.
+func (r Range) Resolve(n int) (lo int, hi int, ok bool) {
+	lo = resolveRangeIndex(r.Lo, n)
+	hi = resolveRangeIndex(r.Hi, n)
+
+	// trim the range to fit
+	if lo < 1 {
+		lo = 1
+	}
+	if hi > n {
+		hi = n
+	}
+
+	return lo, hi, lo <= hi
+}
+
+// resolveRangeIndex turns an index that counts back from the end of a
+// list of n items into a position in the list
+func resolveRangeIndex(index int, n int) int {
+	if index < 0 {
+		return n + 1 + index
+	}
+
+	return index
+}
+
 var rangeRegex = regexp.MustCompile("([1-9][0-9]*){0,1}-([1-9][0-9]*){0,1}")
 
 // ParseRangeSpec takes a string of the form `X1-Y1[,X2-Y2 ...]` and turns
 // it into a list of start and end ranges
 //
-// It emulates the `cut -f <range>` range support.
+// It emulates the `cut -f <range>` range support. Like awk, you can use
+// `NF` for the last item, and `NF-N` for the item N before that: for
+// example, `NF`, `2-NF-1` or `NF-2-`.
 func ParseRangeSpec(spec string) ([]Range, error) {
 	// this will hold all the columns that have been requested
 	var retval []Range
@@ -77,6 +114,11 @@ func ParseRangeSpec(spec string) ([]Range, error) {
 }
 
 func parseSingleRange(spec string) (*Range, error) {
+	// special case - are we counting back from the end?
+	if strings.Contains(spec, "NF") {
+		return parseFromEndRange(strings.TrimSpace(spec))
+	}
+
 	// special case - have we received a single number?
 	if !strings.HasPrefix(spec, "-") {
 		element, err := strconv.Atoi(spec)
@@ -121,3 +163,71 @@ func parseSingleRange(spec string) (*Range, error) {
 
 	return &Range{lo, hi}, nil
 }
+
+// parseFromEndRange parses a single range that uses `NF` for either its
+// start or its end
+func parseFromEndRange(spec string) (*Range, error) {
+	lo, rest := parseRangeBound(spec)
+
+	// special case - have we received a single index?
+	if rest == "" && lo != 0 {
+		return &Range{lo, lo}, nil
+	}
+
+	if !strings.HasPrefix(rest, "-") {
+		return nil, fmt.Errorf("invalid range: %s", spec)
+	}
+	hi, rest := parseRangeBound(rest[1:])
+	if rest != "" {
+		return nil, fmt.Errorf("invalid range: %s", spec)
+	}
+
+	// like `cut`, a missing start or end means the start or end of
+	// the list
+	if lo == 0 {
+		lo = 1
+	}
+	if hi == 0 {
+		hi = math.MaxInt64
+	}
+
+	return &Range{lo, hi}, nil
+}
+
+// parseRangeBound parses the number, `NF` or `NF-N` at the start of
+// the given spec
+//
+// It returns 0 if there is no bound there, and anything in the spec
+// that comes after the bound.
+func parseRangeBound(spec string) (int, string) {
+	// special case - counting back from the end
+	if strings.HasPrefix(spec, "NF") {
+		spec = spec[2:]
+		offset, rest := parseRangeNumber(strings.TrimPrefix(spec, "-"))
+		if offset == 0 || !strings.HasPrefix(spec, "-") {
+			return -1, spec
+		}
+		return -1 - offset, rest
+	}
+
+	return parseRangeNumber(spec)
+}
+
+// parseRangeNumber parses the positive number at the start of the
+// given spec
+//
+// It returns 0 if there is no number there, and anything in the spec
+// that comes after the number.
+func parseRangeNumber(spec string) (int, string) {
+	end := 0
+	for end < len(spec) && spec[end] >= '0' && spec[end] <= '9' {
+		end++
+	}
+
+	retval, err := strconv.Atoi(spec[:end])
+	if err != nil {
+		return 0, spec
+	}
+
+	return retval, spec[end:]
+}
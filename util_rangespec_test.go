@@ -174,3 +174,82 @@ func TestParseRangeReturnsErrorOnEmptyRange(t *testing.T) {
 	assert.NotNil(t, err)
 	assert.Equal(t, expectedResult, actualResult)
 }
+
+func TestParseRangeSupportsCountingBackFromTheEnd(t *testing.T) {
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := []Range{
+		{-1, -1},
+		{-3, -3},
+		{2, -2},
+		{-3, math.MaxInt64},
+		{1, -1},
+		{-2, -1},
+	}
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := ParseRangeSpec("NF,NF-2,2-NF-1,NF-2-,-NF,NF-1-NF")
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestParseRangeReturnsErrorOnInvalidFromEndRange(t *testing.T) {
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := []Range{}
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := ParseRangeSpec("NFX")
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.NotNil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestRangeResolveTurnsRangesIntoPositions(t *testing.T) {
+	// ----------------------------------------------------------------
+	// setup your test
+
+	testData := []struct {
+		input    Range
+		n        int
+		expected []int
+	}{
+		{Range{2, 4}, 5, []int{2, 4, 1}},
+		{Range{2, math.MaxInt64}, 5, []int{2, 5, 1}},
+		{Range{-1, -1}, 5, []int{5, 5, 1}},
+		{Range{-2, -1}, 5, []int{4, 5, 1}},
+		{Range{2, -2}, 5, []int{2, 4, 1}},
+		{Range{-10, 2}, 5, []int{1, 2, 1}},
+		{Range{7, 9}, 5, []int{7, 5, 0}},
+		{Range{-7, -7}, 5, []int{1, -1, 0}},
+	}
+
+	for _, testCase := range testData {
+		// ----------------------------------------------------------------
+		// perform the change
+
+		lo, hi, ok := testCase.input.Resolve(testCase.n)
+
+		// ----------------------------------------------------------------
+		// test the results
+
+		actualResult := []int{lo, hi, 0}
+		if ok {
+			actualResult[2] = 1
+		}
+		assert.Equal(t, testCase.expected, actualResult, testCase.input)
+	}
+}
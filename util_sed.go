@@ -0,0 +1,708 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// sedCommand is a single command from a Sed() script, along with the
+// lines that it applies to
+type sedCommand struct {
+	// addr1 and addr2 are the command's addresses
+	//
+	// addr1 is nil when the command applies to every line. addr2 is
+	// nil unless the command applies to a range of lines.
+	addr1 *sedAddress
+	addr2 *sedAddress
+
+	// negate is true when the command applies to the lines that its
+	// addresses do NOT select (ie, `addr!cmd`)
+	negate bool
+
+	// name is the command itself: 'd', 'p' or 's'
+	name byte
+
+	// subst is the `s///` command's pattern, replacement and flags
+	subst *sedSubst
+
+	// inRange is true when we have seen addr1, and are waiting for
+	// addr2 to end the range
+	inRange bool
+}
+
+// sedAddress selects the lines that a command applies to
+type sedAddress struct {
+	// line is the line number to match, or 0 when this is not a line
+	// number address
+	line int
+
+	// last is true when this address is `$`
+	last bool
+
+	// re is the regex to match, or nil when this is not a `/regex/`
+	// address
+	re *regexp.Regexp
+}
+
+// sedSubst is everything that an `s///` command needs
+type sedSubst struct {
+	// re is the regex that we are replacing
+	re *regexp.Regexp
+
+	// replacement is what we replace each match with
+	replacement []sedReplacementPart
+
+	// occurrence is the first match that we replace (the `N` flag)
+	occurrence int
+
+	// global is true when we replace every match from occurrence
+	// onwards (the `g` flag)
+	global bool
+
+	// print is true when we print the line after making a replacement
+	// (the `p` flag)
+	print bool
+}
+
+// sedReplacementPart is either some text, or a reference to part of
+// the match
+type sedReplacementPart struct {
+	// text is written out as-is
+	text string
+
+	// group is the subexpression to write out (0 for the whole match),
+	// or -1 when this part is text
+	group int
+}
+
+// sedParser turns the expressions given to Sed() into a list of commands
+type sedParser struct {
+	// p is the pipe that we are parsing the expressions for
+	p *Pipe
+
+	// expr is the expression that we are parsing
+	expr string
+
+	// pos is the next character to look at
+	pos int
+
+	// usesLast is true when any of the commands uses the `$` address
+	usesLast bool
+}
+
+// parseSedScript turns the given expressions into a list of commands
+//
+// The regexes and replacements are expanded as we parse them. The bool
+// is true if any of the commands needs to know which line is the last
+// line.
+func parseSedScript(p *Pipe, exprs []string) ([]*sedCommand, bool, error) {
+	retval := []*sedCommand{}
+	usesLast := false
+
+	for _, expr := range exprs {
+		parser := sedParser{p: p, expr: expr}
+		commands, err := parser.parse()
+		if err != nil {
+			return nil, false, err
+		}
+		retval = append(retval, commands...)
+		usesLast = usesLast || parser.usesLast
+	}
+
+	// all done
+	return retval, usesLast, nil
+}
+
+func (s *sedParser) syntaxError(message string) error {
+	return ErrSedSyntax{
+		Expr:    s.expr,
+		Message: message,
+	}
+}
+
+// peek returns the next character, or 0 if there isn't one
+func (s *sedParser) peek() byte {
+	if s.pos >= len(s.expr) {
+		return 0
+	}
+
+	return s.expr[s.pos]
+}
+
+// skipSpaces moves past any spaces and tabs
+func (s *sedParser) skipSpaces() {
+	for s.peek() == ' ' || s.peek() == '\t' {
+		s.pos++
+	}
+}
+
+// parse reads every command in the expression
+func (s *sedParser) parse() ([]*sedCommand, error) {
+	retval := []*sedCommand{}
+
+	for {
+		// skip over anything between commands
+		for s.pos < len(s.expr) && strings.IndexByte(" \t\n;", s.peek()) >= 0 {
+			s.pos++
+		}
+		if s.pos >= len(s.expr) {
+			return retval, nil
+		}
+
+		// comments run to the end of the line
+		if s.peek() == '#' {
+			end := strings.IndexByte(s.expr[s.pos:], '\n')
+			if end < 0 {
+				return retval, nil
+			}
+			s.pos += end
+			continue
+		}
+
+		command, err := s.parseCommand()
+		if err != nil {
+			return nil, err
+		}
+		retval = append(retval, command)
+	}
+}
+
+// parseCommand reads a single command, along with its addresses
+func (s *sedParser) parseCommand() (*sedCommand, error) {
+	retval := sedCommand{}
+
+	// what lines does it apply to?
+	var err error
+	retval.addr1, err = s.parseAddress()
+	if err != nil {
+		return nil, err
+	}
+	s.skipSpaces()
+	if retval.addr1 != nil && s.peek() == ',' {
+		s.pos++
+		s.skipSpaces()
+		retval.addr2, err = s.parseAddress()
+		if err != nil {
+			return nil, err
+		}
+		if retval.addr2 == nil {
+			return nil, s.syntaxError("unexpected `,'")
+		}
+		s.skipSpaces()
+	}
+	if s.peek() == '!' {
+		retval.negate = true
+		s.pos++
+		s.skipSpaces()
+	}
+
+	// what does it do?
+	switch s.peek() {
+	case 0:
+		return nil, s.syntaxError("missing command")
+	case 'd', 'p':
+		retval.name = s.peek()
+		s.pos++
+	case 's':
+		retval.name = 's'
+		s.pos++
+		retval.subst, err = s.parseSubst()
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, s.syntaxError("unknown command: `" + string(s.peek()) + "'")
+	}
+
+	// there must not be anything else before the next command
+	s.skipSpaces()
+	if s.pos < len(s.expr) && strings.IndexByte("\n;#", s.peek()) < 0 {
+		return nil, s.syntaxError("extra characters after command")
+	}
+
+	// all done
+	return &retval, nil
+}
+
+// parseAddress reads a line number, `$` or `/regex/` address
+//
+// It returns nil if there is no address to read.
+func (s *sedParser) parseAddress() (*sedAddress, error) {
+	c := s.peek()
+	switch {
+	case c >= '0' && c <= '9':
+		start := s.pos
+		for s.peek() >= '0' && s.peek() <= '9' {
+			s.pos++
+		}
+		line, err := strconv.Atoi(s.expr[start:s.pos])
+		if err != nil || line == 0 {
+			return nil, s.syntaxError("invalid usage of line address " + s.expr[start:s.pos])
+		}
+		return &sedAddress{line: line}, nil
+
+	case c == '$':
+		s.pos++
+		s.usesLast = true
+		return &sedAddress{last: true}, nil
+
+	case c == '/' || c == '\\':
+		// `\cREGEXc` uses `c` as the delimiter
+		if c == '\\' {
+			s.pos++
+			if s.pos >= len(s.expr) || s.peek() == '\n' {
+				return nil, s.syntaxError("unexpected end of address")
+			}
+		}
+		delim := s.peek()
+		s.pos++
+		pattern, ok := s.readDelimited(delim)
+		if !ok {
+			return nil, s.syntaxError("unterminated address regex")
+		}
+
+		// GNU sed's `I` flag makes the match case-insensitive
+		icase := false
+		if s.peek() == 'I' {
+			icase = true
+			s.pos++
+		}
+
+		re, err := s.compileRegex(pattern, icase)
+		if err != nil {
+			return nil, err
+		}
+		return &sedAddress{re: re}, nil
+	}
+
+	// if we get here, there is no address
+	return nil, nil
+}
+
+// parseSubst reads the rest of an `s///` command
+//
+// s.pos must point at the delimiter that follows the `s`.
+func (s *sedParser) parseSubst() (*sedSubst, error) {
+	delim := s.peek()
+	if delim == 0 || delim == '\n' || delim == '\\' {
+		return nil, s.syntaxError("unterminated `s' command")
+	}
+	s.pos++
+
+	pattern, ok := s.readDelimited(delim)
+	if !ok {
+		return nil, s.syntaxError("unterminated `s' command")
+	}
+	replacement, ok := s.readReplacement(delim)
+	if !ok {
+		return nil, s.syntaxError("unterminated `s' command")
+	}
+
+	retval := sedSubst{occurrence: 1}
+	icase := false
+	seenOccurrence := false
+
+	// what flags do we have?
+	for done := false; !done; {
+		c := s.peek()
+		switch {
+		case c == 'g':
+			if retval.global {
+				return nil, s.syntaxError("multiple `g' options to `s' command")
+			}
+			retval.global = true
+			s.pos++
+		case c == 'p':
+			if retval.print {
+				return nil, s.syntaxError("multiple `p' options to `s' command")
+			}
+			retval.print = true
+			s.pos++
+		case c == 'i' || c == 'I':
+			icase = true
+			s.pos++
+		case c >= '0' && c <= '9':
+			if seenOccurrence {
+				return nil, s.syntaxError("multiple number options to `s' command")
+			}
+			start := s.pos
+			for s.peek() >= '0' && s.peek() <= '9' {
+				s.pos++
+			}
+			n, err := strconv.Atoi(s.expr[start:s.pos])
+			if err != nil || n == 0 {
+				return nil, s.syntaxError("number option to `s' command may not be zero")
+			}
+			retval.occurrence = n
+			seenOccurrence = true
+		default:
+			done = true
+		}
+	}
+
+	// now that we know the flags, we can build the regex
+	var err error
+	retval.re, err = s.compileRegex(pattern, icase)
+	if err != nil {
+		return nil, err
+	}
+	retval.replacement, err = s.parseReplacement(replacement)
+	if err != nil {
+		return nil, err
+	}
+	for _, part := range retval.replacement {
+		if part.group > retval.re.NumSubexp() {
+			return nil, s.syntaxError(fmt.Sprintf("invalid reference \\%d on `s' command's RHS", part.group))
+		}
+	}
+
+	// all done
+	return &retval, nil
+}
+
+// readDelimited reads a regex up to the next (unescaped) delimiter, and
+// moves past it
+//
+// `\` followed by the delimiter becomes the delimiter, and `\n` becomes
+// a newline. All other escapes are left for the regex to deal with. ok
+// is false if we run out of expression first.
+func (s *sedParser) readDelimited(delim byte) (string, bool) {
+	var retval strings.Builder
+	for s.pos < len(s.expr) {
+		c := s.expr[s.pos]
+		switch {
+		case c == delim:
+			s.pos++
+			return retval.String(), true
+		case c == '\n':
+			return "", false
+		case c == '\\' && s.pos+1 < len(s.expr):
+			next := s.expr[s.pos+1]
+			switch next {
+			case delim:
+				retval.WriteByte(delim)
+			case 'n':
+				retval.WriteByte('\n')
+			default:
+				retval.WriteByte(c)
+				retval.WriteByte(next)
+			}
+			s.pos += 2
+		default:
+			retval.WriteByte(c)
+			s.pos++
+		}
+	}
+
+	// if we get here, the delimiter is missing
+	return "", false
+}
+
+// readReplacement reads the replacement half of an `s///` command up to
+// the next (unescaped) delimiter, and moves past it
+//
+// The escapes are left for parseReplacement() to deal with. ok is false
+// if we run out of expression first.
+func (s *sedParser) readReplacement(delim byte) (string, bool) {
+	start := s.pos
+	for s.pos < len(s.expr) && s.expr[s.pos] != delim {
+		if s.expr[s.pos] == '\\' {
+			s.pos++
+		}
+		s.pos++
+	}
+	if s.pos >= len(s.expr) {
+		return "", false
+	}
+
+	retval := s.expr[start:s.pos]
+	s.pos++
+	return retval, true
+}
+
+// compileRegex expands the given pattern, and turns it into a regex
+func (s *sedParser) compileRegex(pattern string, icase bool) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, s.syntaxError("no previous regular expression")
+	}
+
+	expPattern, err := expandKeepingEscapes(s.p, pattern)
+	if err != nil {
+		return nil, err
+	}
+	if icase {
+		expPattern = "(?i)" + expPattern
+	}
+
+	retval, err := regexp.Compile(expPattern)
+	if err != nil {
+		return nil, s.syntaxError("invalid regular expression " + strconv.Quote(expPattern))
+	}
+
+	return retval, nil
+}
+
+// parseReplacement turns the replacement half of an `s///` command into
+// a list of text and references to the match
+//
+// `&` and `\0` are the whole match, `\1` to `\9` are subexpressions,
+// `\n` and `\t` are a newline and a tab, and any other escaped character
+// is itself. The text between the escapes is expanded.
+func (s *sedParser) parseReplacement(replacement string) ([]sedReplacementPart, error) {
+	retval := []sedReplacementPart{}
+	start := 0
+
+	// flush adds any text that we have not added yet
+	flush := func(end int) error {
+		if start < end {
+			text, err := expandString(s.p, replacement[start:end])
+			if err != nil {
+				return err
+			}
+			retval = append(retval, sedReplacementPart{text: text, group: -1})
+		}
+		return nil
+	}
+
+	for i := 0; i < len(replacement); i++ {
+		c := replacement[i]
+		if c != '&' && (c != '\\' || i+1 >= len(replacement)) {
+			continue
+		}
+
+		err := flush(i)
+		if err != nil {
+			return nil, err
+		}
+
+		part := sedReplacementPart{group: 0}
+		if c == '\\' {
+			i++
+			next := replacement[i]
+			switch {
+			case next >= '0' && next <= '9':
+				part.group = int(next - '0')
+			case next == 'n':
+				part = sedReplacementPart{text: "\n", group: -1}
+			case next == 't':
+				part = sedReplacementPart{text: "\t", group: -1}
+			default:
+				// this includes `\&`, `\\` and the delimiter
+				part = sedReplacementPart{text: string(next), group: -1}
+			}
+		}
+		retval = append(retval, part)
+		start = i + 1
+	}
+
+	err := flush(len(replacement))
+	if err != nil {
+		return nil, err
+	}
+
+	// all done
+	return retval, nil
+}
+
+// selects returns true if the command applies to the given line
+func (c *sedCommand) selects(lineNo int, line string, isLast bool) bool {
+	return c.matchAddresses(lineNo, line, isLast) != c.negate
+}
+
+func (c *sedCommand) matchAddresses(lineNo int, line string, isLast bool) bool {
+	// special case - no address at all
+	if c.addr1 == nil {
+		return true
+	}
+
+	// special case - a single address
+	if c.addr2 == nil {
+		return c.addr1.matches(lineNo, line, isLast)
+	}
+
+	// are we inside the range?
+	if c.inRange {
+		// a line number that we have already passed ends the range
+		// straight away
+		switch {
+		case c.addr2.line > 0:
+			c.inRange = lineNo < c.addr2.line
+		default:
+			c.inRange = !c.addr2.matches(lineNo, line, isLast)
+		}
+		return true
+	}
+
+	// does the range start here?
+	if !c.addr1.matches(lineNo, line, isLast) {
+		return false
+	}
+
+	// a regex that ends the range is only checked from the next line
+	// onwards
+	switch {
+	case c.addr2.line > 0:
+		c.inRange = lineNo < c.addr2.line
+	case c.addr2.last:
+		c.inRange = !isLast
+	default:
+		c.inRange = true
+	}
+
+	return true
+}
+
+// matches returns true if the address selects the given line
+func (a *sedAddress) matches(lineNo int, line string, isLast bool) bool {
+	switch {
+	case a.re != nil:
+		return a.re.MatchString(line)
+	case a.last:
+		return isLast
+	default:
+		return a.line == lineNo
+	}
+}
+
+// apply makes the substitution on the given line
+//
+// It returns the new line, and whether or not anything was replaced.
+func (s *sedSubst) apply(line string) (string, bool) {
+	matches := s.re.FindAllStringSubmatchIndex(line, -1)
+	if len(matches) < s.occurrence {
+		return line, false
+	}
+
+	var retval strings.Builder
+	last := 0
+	for i := s.occurrence - 1; i < len(matches); i++ {
+		// special case - without the `g` flag, we only replace the one
+		// match
+		if i >= s.occurrence && !s.global {
+			break
+		}
+
+		match := matches[i]
+		retval.WriteString(line[last:match[0]])
+		for _, part := range s.replacement {
+			switch {
+			case part.group < 0:
+				retval.WriteString(part.text)
+			case match[part.group*2] >= 0:
+				retval.WriteString(line[match[part.group*2]:match[part.group*2+1]])
+			}
+		}
+		last = match[1]
+	}
+	retval.WriteString(line[last:])
+
+	// all done
+	return retval.String(), true
+}
+
+// runSedScript runs the given commands over every line of the pipe's
+// input
+//
+// When quiet is true, lines are only written to the pipe's output by
+// the `p` command and the `s///p` flag.
+func runSedScript(p *Pipe, commands []*sedCommand, usesLast bool, quiet bool) {
+	writeLine := func(line string) {
+		TracePipeStdout("%s", line)
+		p.Stdout.WriteString(line)
+		p.Stdout.WriteRune('\n')
+	}
+
+	lines := p.Stdin.ReadLines()
+	line, ok := <-lines
+	lineNo := 0
+	for ok {
+		lineNo++
+
+		// we only read ahead when we need to know if this is the
+		// last line, so that we don't hold up a streaming pipeline
+		isLast := false
+		var next string
+		var more bool
+		if usesLast {
+			next, more = <-lines
+			isLast = !more
+		}
+
+		deleted := false
+		for _, command := range commands {
+			if !command.selects(lineNo, line, isLast) {
+				continue
+			}
+
+			switch command.name {
+			case 'd':
+				deleted = true
+			case 'p':
+				writeLine(line)
+			case 's':
+				var replaced bool
+				line, replaced = command.subst.apply(line)
+				if replaced && command.subst.print {
+					writeLine(line)
+				}
+			}
+
+			// `d` starts the next cycle straight away
+			if deleted {
+				break
+			}
+		}
+
+		if !deleted && !quiet {
+			writeLine(line)
+		}
+
+		// move on to the next line
+		if usesLast {
+			line, ok = next, more
+		} else {
+			line, ok = <-lines
+		}
+	}
+}
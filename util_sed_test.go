@@ -0,0 +1,201 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSedScriptsMatchSed(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	sedCmd, err := exec.LookPath("sed")
+	if err != nil {
+		t.Skip("sed not found")
+	}
+
+	input := strings.Join([]string{
+		"foo1 foo22 foo333",
+		"Hello World",
+		"",
+		"START",
+		"middle line",
+		"END",
+		"a/b/c",
+		"last foo4",
+	}, "\n") + "\n"
+
+	testData := []struct {
+		quiet bool
+		exprs []string
+	}{
+		{false, []string{`s/foo([0-9]+)/bar\1/g`}},
+		{false, []string{`s/foo([0-9]+)/bar\1/`}},
+		{false, []string{`s/foo/X/2`}},
+		{false, []string{`s/foo/X/2g`}},
+		{false, []string{`s/hello/Bye/i`}},
+		{false, []string{`s/hello/Bye/I`}},
+		{false, []string{`s/o/[&]/g`}},
+		{false, []string{`s/o/\&/g`}},
+		{false, []string{`s/(l+)(o)/\2\1/g`}},
+		{false, []string{`s/ /\n/g`}},
+		{false, []string{`s/ /\t/`}},
+		{false, []string{`s|/|_|g`}},
+		{false, []string{`s/\//_/g`}},
+		{false, []string{`s,a/b,x\,y,`}},
+		{false, []string{`s/x*/-/g`}},
+		{false, []string{`s/^/> /`}},
+		{false, []string{`s/$/;/`}},
+		{false, []string{`s/$/ $/`}},
+		{false, []string{`s/[0-9]+$/($)/`}},
+		{false, []string{`/^$|foo4$/d`}},
+		{false, []string{`2d`}},
+		{false, []string{`$d`}},
+		{false, []string{`/^$/d`}},
+		{false, []string{`/START/,/END/d`}},
+		{false, []string{`/START/,/END/!d`}},
+		{false, []string{`2,4d`}},
+		{false, []string{`4,2d`}},
+		{false, []string{`3,$d`}},
+		{false, []string{`/foo/,3d`}},
+		{false, []string{`\,a/b,d`}},
+		{false, []string{`/hello/Id`}},
+		{false, []string{`1!d`}},
+		{false, []string{`p`}},
+		{false, []string{`2p;4p`}},
+		{false, []string{"2p\n4p"}},
+		{false, []string{`s/foo/bar/`, `s/bar/baz/`}},
+		{false, []string{`s/o/0/g; /World/d`}},
+		{false, []string{`/START/,/END/s/^/# /`}},
+		{false, []string{`# a comment`, `s/a/A/`}},
+		{true, []string{`p`}},
+		{true, []string{`$p`}},
+		{true, []string{`/foo/p`}},
+		{true, []string{`s/foo/bar/gp`}},
+		{true, []string{`/START/,/END/p`}},
+		{true, []string{`2,3p`, `5p`}},
+	}
+
+	for _, testCase := range testData {
+		args := []string{"-E"}
+		if testCase.quiet {
+			args = append(args, "-n")
+		}
+		for _, expr := range testCase.exprs {
+			args = append(args, "-e", expr)
+		}
+		cmd := exec.Command(sedCmd, args...)
+		cmd.Stdin = strings.NewReader(input)
+		expectedResult, err := cmd.Output()
+		assert.Nil(t, err, testCase.exprs)
+
+		step := Sed(testCase.exprs)
+		if testCase.quiet {
+			step = SedN(testCase.exprs)
+		}
+		pipeline := NewPipeline(
+			Echo(input),
+			step,
+		)
+
+		// ----------------------------------------------------------------
+		// perform the change
+
+		actualResult, err := pipeline.Exec().String()
+
+		// ----------------------------------------------------------------
+		// test the results
+
+		assert.Nil(t, err, testCase.exprs)
+		assert.Equal(t, string(expectedResult), actualResult, testCase.exprs)
+	}
+}
+
+func TestSedScriptsReturnErrSedSyntaxWhenTheyCannotBeParsed(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	testData := []struct {
+		expr     string
+		expected string
+	}{
+		{"s/foo/bar", "unterminated `s' command"},
+		{"s/foo", "unterminated `s' command"},
+		{"s", "unterminated `s' command"},
+		{"s/foo/bar/gg", "multiple `g' options to `s' command"},
+		{"s/foo/bar/0", "number option to `s' command may not be zero"},
+		{"s/foo/bar/x", "extra characters after command"},
+		{`s/foo/\1/`, "invalid reference \\1 on `s' command's RHS"},
+		{"s/[a/b/", `invalid regular expression "[a"`},
+		{"s//bar/", "no previous regular expression"},
+		{"/foo", "unterminated address regex"},
+		{"0d", "invalid usage of line address 0"},
+		{"1,", "unexpected `,'"},
+		{"1", "missing command"},
+		{"y/abc/xyz/", "unknown command: `y'"},
+	}
+
+	for _, testCase := range testData {
+		pipeline := NewPipeline(
+			Echo("foo"),
+			Sed([]string{testCase.expr}),
+		)
+
+		// ----------------------------------------------------------------
+		// perform the change
+
+		_, err := pipeline.Exec().String()
+
+		// ----------------------------------------------------------------
+		// test the results
+
+		assert.Equal(t, ErrSedSyntax{testCase.expr, testCase.expected}, err, testCase.expr)
+		assert.Equal(t, StatusNotOkay, pipeline.StatusCode(), testCase.expr)
+	}
+}
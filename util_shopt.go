@@ -0,0 +1,126 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+// pipeShellOptions returns the shell options that the given pipe is
+// running with
+//
+// If the pipe was not created by a Sequence, you get back the
+// package-wide settings.
+func pipeShellOptions(p *Pipe) ShellOptions {
+	env, ok := getSequenceEnv(p)
+	if !ok {
+		return shopt
+	}
+
+	return env.shopt
+}
+
+// setPipeShellOptions sets the shell options that the given pipe runs
+// with
+func setPipeShellOptions(p *Pipe, opts ShellOptions) {
+	env, ok := getSequenceEnv(p)
+	if ok {
+		env.shopt = opts
+	}
+}
+
+// shellOptionsFor returns the settings that a sequence runs with
+//
+// The sequence's own ShellOptions win. If it does not have any, it runs
+// with the given settings (which come from whatever is running it).
+func shellOptionsFor(sq *Sequence, inherited ShellOptions) ShellOptions {
+	if sq.ShellOptions != nil {
+		return *sq.ShellOptions
+	}
+
+	return inherited
+}
+
+// ignoreErrexit tells the list that is running the current step not to
+// stop if the step fails
+//
+// Use it in steps whose failure is not really a failure, such as an If()
+// whose condition was false.
+func ignoreErrexit(p *Pipe) {
+	env, ok := getSequenceEnv(p)
+	if ok {
+		env.ignoreErrexit = true
+	}
+}
+
+// resetIgnoreErrexit clears any earlier call to ignoreErrexit(), and
+// tells you if there was one
+func resetIgnoreErrexit(p *Pipe) bool {
+	env, ok := getSequenceEnv(p)
+	if !ok {
+		return false
+	}
+
+	retval := env.ignoreErrexit
+	env.ignoreErrexit = false
+	return retval
+}
+
+// listMustStop returns true if the list must stop after sq.Steps[i],
+// because of Errexit or an expansion error
+func listMustStop(sq *Sequence, i int) bool {
+	// was the failure expected?
+	ignored := resetIgnoreErrexit(sq.Pipe)
+
+	// like UNIX shells, an expansion error always stops the list
+	_, ok := sq.Pipe.Error().(ErrExpansion)
+	if ok {
+		return true
+	}
+
+	// is there anything to stop for?
+	if !pipeShellOptions(sq.Pipe).Errexit || sq.Pipe.Error() == nil || ignored {
+		return false
+	}
+
+	// like UNIX shells, `&&` and `||` get to deal with the failure
+	if i+1 < len(sq.Steps) && sq.Steps[i+1].isAndOr {
+		return false
+	}
+
+	// if we get here, we have to stop
+	return true
+}
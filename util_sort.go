@@ -0,0 +1,764 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"bufio"
+	"container/heap"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"sort"
+	"strings"
+)
+
+// sortLineOverhead is roughly how much memory each line costs us, on
+// top of the line itself
+const sortLineOverhead = 64
+
+// sortMergeWidth is the most runs that we merge at once, which is the
+// same as GNU sort's default for --batch-size
+const sortMergeWidth = 16
+
+// sortLine is a line that we are sorting, along with its keys
+type sortLine struct {
+	line string
+	keys []string
+}
+
+// lineSorter compares lines in the way that SortOptions asks for
+type lineSorter struct {
+	// opts are the options that we were given, with Separator and
+	// TempDir expanded
+	opts SortOptions
+
+	// keys are the fields that we compare, in order; when it is empty,
+	// we compare the whole line
+	keys []Range
+}
+
+// newSortLine splits the line up into the keys that we compare
+func (s *lineSorter) newSortLine(line string) *sortLine {
+	retval := sortLine{line: line}
+
+	// special case - no keys, so we compare the whole line
+	if len(s.keys) == 0 {
+		retval.keys = []string{line}
+		return &retval
+	}
+
+	spans := s.fieldSpans(line)
+	retval.keys = make([]string, len(s.keys))
+	for i, key := range s.keys {
+		lo, hi, ok := key.Resolve(len(spans))
+		if !ok {
+			continue
+		}
+
+		// a key that runs to the end of the last field runs to the end
+		// of the line, like `sort -k2` does
+		end := spans[hi-1][1]
+		if key.Hi == math.MaxInt64 {
+			end = len(line)
+		}
+		retval.keys[i] = line[spans[lo-1][0]:end]
+	}
+
+	return &retval
+}
+
+// fieldSpans returns the start and end of each field in the line
+//
+// Without a separator, each field is a run of non-blank characters,
+// along with any blanks in front of it. This is what `sort` does.
+func (s *lineSorter) fieldSpans(line string) [][2]int {
+	retval := [][2]int{}
+
+	// special case - we have been given a separator
+	if s.opts.Separator != "" {
+		start := 0
+		for {
+			i := strings.Index(line[start:], s.opts.Separator)
+			if i < 0 {
+				return append(retval, [2]int{start, len(line)})
+			}
+			retval = append(retval, [2]int{start, start + i})
+			start += i + len(s.opts.Separator)
+		}
+	}
+
+	for i := 0; i < len(line); {
+		start := i
+		for i < len(line) && isSortBlank(line[i]) {
+			i++
+		}
+
+		// trailing blanks are not a field
+		if i >= len(line) {
+			break
+		}
+		for i < len(line) && !isSortBlank(line[i]) {
+			i++
+		}
+		retval = append(retval, [2]int{start, i})
+	}
+
+	return retval
+}
+
+// compare returns a negative number, zero or a positive number,
+// depending on whether line a comes before, alongside, or after line b
+//
+// Like `sort`, when the keys are the same, we compare the whole lines,
+// unless we have been asked for a stable or unique sort.
+func (s *lineSorter) compare(a, b *sortLine) int {
+	retval := 0
+	for i := range a.keys {
+		retval = s.compareKeys(a.keys[i], b.keys[i])
+		if retval != 0 {
+			break
+		}
+	}
+
+	if retval == 0 && !s.opts.Stable && !s.opts.Unique {
+		retval = strings.Compare(a.line, b.line)
+	}
+	if s.opts.Reverse {
+		retval = -retval
+	}
+
+	return retval
+}
+
+// compareKeys compares two keys, in the way that the options ask for
+func (s *lineSorter) compareKeys(a, b string) int {
+	switch s.opts.Compare {
+	case SortNumeric:
+		aNum, _ := parseSortNumber(a)
+		bNum, _ := parseSortNumber(b)
+		return compareSortNumbers(aNum, bNum)
+	case SortHumanNumeric:
+		return compareHumanNumbers(a, b)
+	case SortMonth:
+		return parseSortMonth(a) - parseSortMonth(b)
+	case SortVersion:
+		return compareVersions(a, b)
+	}
+
+	// if we get here, we are comparing text
+	if s.opts.FoldCase {
+		return strings.Compare(foldSortText(a), foldSortText(b))
+	}
+	return strings.Compare(a, b)
+}
+
+// sortLines sorts the given lines, keeping lines that are the same in
+// the order that we read them in
+func (s *lineSorter) sortLines(lines []*sortLine) {
+	sort.SliceStable(lines, func(i, j int) bool {
+		return s.compare(lines[i], lines[j]) < 0
+	})
+}
+
+// spill sorts the given lines, and writes them to a temp file
+//
+// It returns the temp file's name.
+func (s *lineSorter) spill(lines []*sortLine) (string, error) {
+	s.sortLines(lines)
+
+	fh, err := ioutil.TempFile(s.opts.TempDir, "scriptish-sort.*")
+	if err != nil {
+		return "", err
+	}
+
+	w := bufio.NewWriter(fh)
+	for _, line := range lines {
+		w.WriteString(line.line)
+		w.WriteByte('\n')
+	}
+	err = w.Flush()
+	closeErr := fh.Close()
+	if err == nil {
+		err = closeErr
+	}
+
+	return fh.Name(), err
+}
+
+// merge reads back the sorted runs that we have spilled to temp files,
+// along with the lines that are still in memory, and passes every line
+// to the emit function in sorted order
+//
+// The runs must be in the order that we read their lines in, and the
+// lines in memory must be the last lines that we read.
+//
+// Like GNU sort, we never open more than sortMergeWidth runs at once.
+// If there are too many runs, we merge them into bigger runs first.
+func (s *lineSorter) merge(runs []string, lines []*sortLine, emit func(*sortLine)) error {
+	s.sortLines(lines)
+
+	// any bigger runs that we create are ours to clean up
+	created := map[string]bool{}
+	defer func() {
+		for run := range created {
+			os.Remove(run)
+		}
+	}()
+
+	// the lines in memory count as one more run when we do
+	// the final merge
+	for len(runs) >= sortMergeWidth {
+		merged := []string{}
+		for start := 0; start < len(runs); start += sortMergeWidth {
+			end := start + sortMergeWidth
+			if end > len(runs) {
+				end = len(runs)
+			}
+
+			run, err := s.mergeToTempFile(runs[start:end])
+			if run != "" {
+				created[run] = true
+				merged = append(merged, run)
+			}
+			if err != nil {
+				return err
+			}
+
+			// we do not need these runs any more
+			for _, oldRun := range runs[start:end] {
+				os.Remove(oldRun)
+				delete(created, oldRun)
+			}
+		}
+		runs = merged
+	}
+
+	// all done
+	return s.mergeRuns(runs, lines, emit)
+}
+
+// mergeToTempFile merges the given runs into one bigger run
+//
+// It returns the temp file's name.
+func (s *lineSorter) mergeToTempFile(runs []string) (string, error) {
+	fh, err := ioutil.TempFile(s.opts.TempDir, "scriptish-sort.*")
+	if err != nil {
+		return "", err
+	}
+
+	w := bufio.NewWriter(fh)
+	err = s.mergeRuns(runs, nil, func(line *sortLine) {
+		w.WriteString(line.line)
+		w.WriteByte('\n')
+	})
+	if err == nil {
+		err = w.Flush()
+	}
+	closeErr := fh.Close()
+	if err == nil {
+		err = closeErr
+	}
+
+	return fh.Name(), err
+}
+
+// mergeRuns passes every line from the given runs, and then the given
+// (sorted) lines, to the emit function in sorted order
+func (s *lineSorter) mergeRuns(runs []string, lines []*sortLine, emit func(*sortLine)) error {
+	// open up each of the runs
+	sources := []sortSource{}
+	for _, run := range runs {
+		fh, err := os.Open(run)
+		if err != nil {
+			return err
+		}
+		defer fh.Close()
+		sources = append(sources, &sortFileSource{sorter: s, r: bufio.NewReader(fh)})
+	}
+	sources = append(sources, &sortSliceSource{lines: lines})
+
+	// prime the heap with the first line from each source
+	h := sortHeap{sorter: s}
+	for i, source := range sources {
+		line, err := source.next()
+		if err != nil {
+			return err
+		}
+		if line != nil {
+			h.items = append(h.items, sortHeapItem{line: line, source: i})
+		}
+	}
+	heap.Init(&h)
+
+	// keep taking the smallest line, until there are none left
+	for len(h.items) > 0 {
+		item := h.items[0]
+		emit(item.line)
+
+		line, err := sources[item.source].next()
+		if err != nil {
+			return err
+		}
+		if line == nil {
+			heap.Pop(&h)
+			continue
+		}
+		h.items[0].line = line
+		heap.Fix(&h, 0)
+	}
+
+	// all done
+	return nil
+}
+
+// sortSource is a sorted list of lines that merge() reads from
+type sortSource interface {
+	// next returns the next line, or nil when there are none left
+	next() (*sortLine, error)
+}
+
+// sortFileSource reads a sorted run back in from a temp file
+type sortFileSource struct {
+	sorter *lineSorter
+	r      *bufio.Reader
+}
+
+func (f *sortFileSource) next() (*sortLine, error) {
+	line, err := f.r.ReadString('\n')
+	if line == "" && err != nil {
+		if err == io.EOF {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return f.sorter.newSortLine(strings.TrimSuffix(line, "\n")), nil
+}
+
+// sortSliceSource is a sorted run that is still in memory
+type sortSliceSource struct {
+	lines []*sortLine
+}
+
+func (s *sortSliceSource) next() (*sortLine, error) {
+	if len(s.lines) == 0 {
+		return nil, nil
+	}
+
+	retval := s.lines[0]
+	s.lines = s.lines[1:]
+	return retval, nil
+}
+
+// sortHeapItem is the next line from one of the sources that we are
+// merging
+type sortHeapItem struct {
+	line   *sortLine
+	source int
+}
+
+// sortHeap is a container/heap of the next line from each source
+//
+// Lines that are the same come out in source order, which keeps the
+// merge stable.
+type sortHeap struct {
+	sorter *lineSorter
+	items  []sortHeapItem
+}
+
+func (h *sortHeap) Len() int {
+	return len(h.items)
+}
+
+func (h *sortHeap) Less(i, j int) bool {
+	retval := h.sorter.compare(h.items[i].line, h.items[j].line)
+	if retval == 0 {
+		return h.items[i].source < h.items[j].source
+	}
+
+	return retval < 0
+}
+
+func (h *sortHeap) Swap(i, j int) {
+	h.items[i], h.items[j] = h.items[j], h.items[i]
+}
+
+func (h *sortHeap) Push(x interface{}) {
+	h.items = append(h.items, x.(sortHeapItem))
+}
+
+func (h *sortHeap) Pop() interface{} {
+	last := len(h.items) - 1
+	retval := h.items[last]
+	h.items = h.items[:last]
+	return retval
+}
+
+// isSortBlank returns true if `sort` treats the character as a blank
+func isSortBlank(c byte) bool {
+	return c == ' ' || c == '\t'
+}
+
+// foldSortText turns any lowercase ASCII letters into uppercase, like
+// `sort -f` does
+func foldSortText(input string) string {
+	return strings.Map(
+		func(r rune) rune {
+			if r >= 'a' && r <= 'z' {
+				return r - 'a' + 'A'
+			}
+			return r
+		},
+		input,
+	)
+}
+
+// sortNumber is a number, as `sort -n` understands it
+//
+// We keep the digits as strings, so that we can compare numbers of any
+// size without losing any precision.
+type sortNumber struct {
+	negative bool
+
+	// whole is the part before the decimal point, without any leading
+	// zeroes
+	whole string
+
+	// frac is the part after the decimal point, without any trailing
+	// zeroes
+	frac string
+}
+
+// parseSortNumber parses the number at the start of the input, after
+// any leading blanks
+//
+// Anything that is not a number is zero. It also returns whatever
+// comes after the number.
+func parseSortNumber(input string) (sortNumber, string) {
+	retval := sortNumber{}
+
+	i := 0
+	for i < len(input) && isSortBlank(input[i]) {
+		i++
+	}
+	if i < len(input) && input[i] == '-' {
+		retval.negative = true
+		i++
+	}
+
+	start := i
+	for i < len(input) && isVarDigit(input[i]) {
+		i++
+	}
+	retval.whole = strings.TrimLeft(input[start:i], "0")
+
+	if i < len(input) && input[i] == '.' {
+		i++
+		start = i
+		for i < len(input) && isVarDigit(input[i]) {
+			i++
+		}
+		retval.frac = strings.TrimRight(input[start:i], "0")
+	}
+
+	// there is no such thing as negative zero
+	if retval.whole == "" && retval.frac == "" {
+		retval.negative = false
+	}
+
+	return retval, input[i:]
+}
+
+// compareSortNumbers returns a negative number, zero or a positive
+// number, depending on whether a is less than, equal to, or greater
+// than b
+func compareSortNumbers(a, b sortNumber) int {
+	if a.negative != b.negative {
+		if a.negative {
+			return -1
+		}
+		return 1
+	}
+
+	// compare the sizes
+	retval := len(a.whole) - len(b.whole)
+	if retval == 0 {
+		retval = strings.Compare(a.whole, b.whole)
+	}
+	if retval == 0 {
+		retval = strings.Compare(a.frac, b.frac)
+	}
+
+	if a.negative {
+		return -retval
+	}
+	return retval
+}
+
+// sortNumberSign returns -1, 0 or 1, depending on whether the number is
+// negative, zero or positive
+func sortNumberSign(n sortNumber) int {
+	switch {
+	case n.negative:
+		return -1
+	case n.whole == "" && n.frac == "":
+		return 0
+	default:
+		return 1
+	}
+}
+
+// humanSuffixes are the SI suffixes that `sort -h` understands, smallest
+// first
+const humanSuffixes = "KMGTPEZY"
+
+// compareHumanNumbers compares two numbers that can have SI suffixes
+// (eg 2K or 1.5G), like `sort -h` does
+//
+// Like `sort -h`, we compare the suffixes before we compare the numbers,
+// so 1500K comes before 1M.
+func compareHumanNumbers(a, b string) int {
+	aNum, aRest := parseSortNumber(a)
+	bNum, bRest := parseSortNumber(b)
+
+	// compare the signs first
+	aSign := sortNumberSign(aNum)
+	bSign := sortNumberSign(bNum)
+	if aSign != bSign {
+		return aSign - bSign
+	}
+
+	// then the suffixes
+	retval := humanSuffixRank(aRest) - humanSuffixRank(bRest)
+	if retval != 0 {
+		return retval * aSign
+	}
+
+	// then the numbers themselves
+	return compareSortNumbers(aNum, bNum)
+}
+
+// humanSuffixRank returns the position of the SI suffix at the start of
+// the input, or 0 if there is no suffix
+func humanSuffixRank(input string) int {
+	if input == "" {
+		return 0
+	}
+
+	// `sort -h` treats `k` the same as `K`
+	if input[0] == 'k' {
+		return 1
+	}
+	return strings.IndexByte(humanSuffixes, input[0]) + 1
+}
+
+// sortMonths are the month names that `sort -M` understands
+var sortMonths = []string{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}
+
+// parseSortMonth returns the number (1-12) of the month at the start
+// of the input, after any leading blanks
+//
+// Anything that is not a month is 0, and comes before January.
+func parseSortMonth(input string) int {
+	input = strings.TrimLeft(input, " \t")
+	if len(input) < 3 {
+		return 0
+	}
+
+	month := foldSortText(input[:3])
+	for i, name := range sortMonths {
+		if month == name {
+			return i + 1
+		}
+	}
+
+	return 0
+}
+
+// compareVersions compares two version numbers (or filenames that
+// contain version numbers), like `sort -V` does
+func compareVersions(a, b string) int {
+	// special cases - empty strings, `.` and `..` come first
+	for _, special := range []string{"", ".", ".."} {
+		switch {
+		case a == b:
+			return 0
+		case a == special:
+			return -1
+		case b == special:
+			return 1
+		}
+	}
+
+	// hidden files come before everything else
+	aHidden := a[0] == '.'
+	bHidden := b[0] == '.'
+	if aHidden != bHidden {
+		if aHidden {
+			return -1
+		}
+		return 1
+	}
+	if aHidden {
+		a = a[1:]
+		b = b[1:]
+	}
+
+	// compare without any file extensions first
+	aPrefix := a[:versionPrefixLen(a)]
+	bPrefix := b[:versionPrefixLen(b)]
+	retval := 0
+	if aPrefix != bPrefix {
+		retval = compareVersionParts(aPrefix, bPrefix)
+	}
+	if retval == 0 {
+		retval = compareVersionParts(a, b)
+	}
+
+	return retval
+}
+
+// versionPrefixLen returns the length of the input, without any file
+// extensions (eg `.tar.gz`) on the end
+func versionPrefixLen(input string) int {
+	retval := len(input)
+
+	for i := 0; i < len(input); i++ {
+		if input[i] != '.' || i+1 >= len(input) || !isVersionSuffixStart(input[i+1]) {
+			continue
+		}
+
+		// is everything from here to the end a file extension?
+		j := i
+		for j < len(input) && input[j] == '.' && j+1 < len(input) && isVersionSuffixStart(input[j+1]) {
+			j += 2
+			for j < len(input) && isVersionSuffixChar(input[j]) {
+				j++
+			}
+		}
+		if j == len(input) {
+			return i
+		}
+	}
+
+	return retval
+}
+
+func isVersionSuffixStart(c byte) bool {
+	return isVersionLetter(c) || c == '~'
+}
+
+func isVersionSuffixChar(c byte) bool {
+	return isVersionSuffixStart(c) || isVarDigit(c)
+}
+
+func isVersionLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// versionOrder is where a non-digit character sorts in a version number
+//
+// Letters come before everything else, apart from `~`, which comes
+// before everything (even the end of the string).
+func versionOrder(c byte) int {
+	switch {
+	case isVarDigit(c):
+		return 0
+	case isVersionLetter(c):
+		return int(c)
+	case c == '~':
+		return -1
+	default:
+		return int(c) + 256
+	}
+}
+
+// compareVersionParts compares two strings, treating any runs of digits
+// as numbers
+//
+// This is the algorithm that Debian uses for its package versions.
+func compareVersionParts(a, b string) int {
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		// compare the non-digits
+		for (i < len(a) && !isVarDigit(a[i])) || (j < len(b) && !isVarDigit(b[j])) {
+			aOrder, bOrder := 0, 0
+			if i < len(a) {
+				aOrder = versionOrder(a[i])
+			}
+			if j < len(b) {
+				bOrder = versionOrder(b[j])
+			}
+			if aOrder != bOrder {
+				return aOrder - bOrder
+			}
+			i++
+			j++
+		}
+
+		// compare the digits
+		for i < len(a) && a[i] == '0' {
+			i++
+		}
+		for j < len(b) && b[j] == '0' {
+			j++
+		}
+		firstDiff := 0
+		for i < len(a) && isVarDigit(a[i]) && j < len(b) && isVarDigit(b[j]) {
+			if firstDiff == 0 {
+				firstDiff = int(a[i]) - int(b[j])
+			}
+			i++
+			j++
+		}
+		if i < len(a) && isVarDigit(a[i]) {
+			return 1
+		}
+		if j < len(b) && isVarDigit(b[j]) {
+			return -1
+		}
+		if firstDiff != 0 {
+			return firstDiff
+		}
+	}
+
+	return 0
+}
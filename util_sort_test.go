@@ -0,0 +1,171 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// sortTestInput is the input for our `sort` conformance tests
+var sortTestInput = []string{
+	"apple:10:1.5K:v1.10.0:Mar",
+	"Banana:9:900:v1.9.0:jan",
+	"cherry:-3:2M:v1.10.0-rc1:Dec",
+	"apple:2:1G:v1.2:feb",
+	"date:10:1.5K:v1.2.0:xyz",
+	"  elder:0010:10:v2:MAR",
+	"fig:-0:0:1.0~beta:Apr",
+	"Apple:2.50:3k:v1.2.tar.gz:apr",
+	"grape:abc:1500K:.hidden:May",
+	"apple:10:1.5K:v1.10.0:Mar",
+	"kiwi::-2K:v10:",
+	"lemon:1e3:512:a1b2:Jun",
+}
+
+func TestSortByMatchesSort(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	sortCmd, err := exec.LookPath("sort")
+	if err != nil {
+		t.Skip("sort not found")
+	}
+
+	testData := []struct {
+		sortArgs []string
+		sopts    SortOptions
+	}{
+		{[]string{}, SortOptions{}},
+		{[]string{"-r"}, SortOptions{Reverse: true}},
+		{[]string{"-f"}, SortOptions{FoldCase: true}},
+		{[]string{"-u"}, SortOptions{Unique: true}},
+		{[]string{"-f", "-u"}, SortOptions{FoldCase: true, Unique: true}},
+		{[]string{"-t:", "-k2,2n"}, SortOptions{Compare: SortNumeric, Separator: ":", Keys: "2"}},
+		{[]string{"-t:", "-n", "-r", "-k2,2"}, SortOptions{Compare: SortNumeric, Separator: ":", Keys: "2", Reverse: true}},
+		{[]string{"-t:", "-k2,2n", "-s"}, SortOptions{Compare: SortNumeric, Separator: ":", Keys: "2", Stable: true}},
+		{[]string{"-t:", "-n", "-r", "-s", "-k2,2"}, SortOptions{Compare: SortNumeric, Separator: ":", Keys: "2", Stable: true, Reverse: true}},
+		{[]string{"-t:", "-k2,2n", "-u"}, SortOptions{Compare: SortNumeric, Separator: ":", Keys: "2", Unique: true}},
+		{[]string{"-t:", "-k3,3h"}, SortOptions{Compare: SortHumanNumeric, Separator: ":", Keys: "3"}},
+		{[]string{"-t:", "-k4,4V"}, SortOptions{Compare: SortVersion, Separator: ":", Keys: "4"}},
+		{[]string{"-t:", "-k5,5M"}, SortOptions{Compare: SortMonth, Separator: ":", Keys: "5"}},
+		{[]string{"-t:", "-k5,5M", "-k1,1"}, SortOptions{Compare: SortMonth, Separator: ":", Keys: "5,1"}},
+		{[]string{"-t:", "-k1,1", "-s"}, SortOptions{Separator: ":", Keys: "1", Stable: true}},
+		{[]string{"-t:", "-k1,1f", "-s"}, SortOptions{Separator: ":", Keys: "1", Stable: true, FoldCase: true}},
+		{[]string{"-t:", "-k2"}, SortOptions{Separator: ":", Keys: "2-"}},
+		{[]string{"-t:", "-k2,3"}, SortOptions{Separator: ":", Keys: "2-3"}},
+		{[]string{"-n"}, SortOptions{Compare: SortNumeric}},
+		{[]string{"-V"}, SortOptions{Compare: SortVersion}},
+		{[]string{"-k1,1"}, SortOptions{Keys: "1"}},
+		{[]string{"-k2"}, SortOptions{Keys: "2-"}},
+	}
+
+	for _, testCase := range testData {
+		cmd := exec.Command(sortCmd, testCase.sortArgs...)
+		cmd.Env = append(os.Environ(), "LC_ALL=C")
+		cmd.Stdin = strings.NewReader(strings.Join(sortTestInput, "\n") + "\n")
+		expectedResult, err := cmd.Output()
+		assert.Nil(t, err, testCase.sortArgs)
+
+		pipeline := NewPipeline(
+			EchoRawSlice(sortTestInput),
+			SortBy(testCase.sopts),
+		)
+
+		// ----------------------------------------------------------------
+		// perform the change
+
+		actualResult, err := pipeline.Exec().String()
+
+		// ----------------------------------------------------------------
+		// test the results
+
+		assert.Nil(t, err, testCase.sortArgs)
+		assert.Equal(t, string(expectedResult), actualResult, testCase.sortArgs)
+	}
+}
+
+func TestSortByComparesVersionsLikeSort(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	sortCmd, err := exec.LookPath("sort")
+	if err != nil {
+		t.Skip("sort not found")
+	}
+
+	testData := []string{
+		"v1.10", "v1.9", "v1.9.1", "v1.9a", "v1.9~rc1", "v1.9-rc1", "1.0",
+		"1.0.tar.gz", "1.0.1.tar.gz", "file10.txt", "file9.txt", "file",
+		".", "..", ".hidden2", ".hidden10", "", "a~", "a", "a0", "a00",
+		"2.0.0", "2.0", "2", "10", "01", "1", "abc-1.2.3~beta.tgz",
+		"abc-1.2.3.tgz", "abc-1.2.3-1.tgz",
+	}
+
+	cmd := exec.Command(sortCmd, "-V")
+	cmd.Env = append(os.Environ(), "LC_ALL=C")
+	cmd.Stdin = strings.NewReader(strings.Join(testData, "\n") + "\n")
+	expectedResult, err := cmd.Output()
+	assert.Nil(t, err)
+
+	pipeline := NewPipeline(
+		EchoRawSlice(testData),
+		SortBy(SortOptions{Compare: SortVersion}),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := pipeline.Exec().String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, string(expectedResult), actualResult)
+}
@@ -0,0 +1,224 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"io"
+	"strconv"
+	"strings"
+)
+
+// MaxSubstitutionDepth is how many `$(name)` substitutions can run
+// inside each other, before we decide that they are never going to end
+const MaxSubstitutionDepth = 100
+
+// runSubstitutions replaces every `$(name)` in the given input with
+// the output of the named sub-sequence, and every `$((expr))` with
+// the value of the arithmetic expression
+//
+// The sub-sequence runs with the caller's positional parameters. Its
+// output is trimmed, and escaped so that it is not expanded a second
+// time. Anything it writes to stderr goes to the pipe's stderr.
+//
+// Each time it runs, it gets its own copy of the sub-sequence's local
+// variables, just like a UNIX subshell does.
+func runSubstitutions(p *Pipe, input string, opts ShellOptions) (string, error) {
+	// special case: nothing to substitute
+	if !strings.Contains(input, "$(") {
+		return input, nil
+	}
+
+	var buf strings.Builder
+	for i := 0; i < len(input); i++ {
+		// is this the start of a substitution?
+		if !isSubstitution(input, i) {
+			// keep escaped characters escaped
+			if input[i] == '\\' && i+1 < len(input) {
+				buf.WriteByte(input[i])
+				i++
+			}
+			buf.WriteByte(input[i])
+			continue
+		}
+
+		end := findClosingParen(input, i+1)
+		if end < 0 {
+			return "", ErrBadSubstitution{input[i:]}
+		}
+
+		// what kind of substitution is it?
+		if isArithSubstitution(input, i, end) {
+			value, err := expandArith(p, input[i+3:end-1], opts)
+			if err != nil {
+				return "", err
+			}
+			buf.WriteString(strconv.FormatInt(value, 10))
+		} else {
+			output, err := runSubstitution(p, strings.TrimSpace(input[i+2:end]))
+			if err != nil {
+				return "", err
+			}
+			buf.WriteString(escapeSubstitution(output))
+		}
+		i = end
+	}
+
+	// all done
+	return buf.String(), nil
+}
+
+// isSubstitution returns true if input[i] is the start of a `$(...)`
+// or a `$((...))`
+func isSubstitution(input string, i int) bool {
+	return input[i] == '$' && i+1 < len(input) && input[i+1] == '('
+}
+
+// isArithSubstitution returns true if the `$(...)` that runs from
+// input[start] to input[end] is a `$((...))`
+func isArithSubstitution(input string, start int, end int) bool {
+	return start+2 < end &&
+		input[start+2] == '(' &&
+		findClosingParen(input, start+2) == end-1
+}
+
+// findClosingParen returns the position of the `)` that closes the `(`
+// at input[start], or -1 if there isn't one
+func findClosingParen(input string, start int) int {
+	depth := 0
+	for i := start; i < len(input); i++ {
+		switch input[i] {
+		case '\\':
+			i++
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+
+	// if we get here, the `(` is never closed
+	return -1
+}
+
+// runSubstitution runs the named sub-sequence, and returns its trimmed
+// output
+func runSubstitution(p *Pipe, name string) (string, error) {
+	// what are we running?
+	sub, ok := lookupSubstitution(p, name)
+	if !ok {
+		return "", ErrUnknownSubstitution{name}
+	}
+
+	// are we stuck in a loop?
+	depth := 1
+	if env, ok := getSequenceEnv(p); ok {
+		depth += env.substitutionDepth
+	}
+	if depth > MaxSubstitutionDepth {
+		return "", ErrSubstitutionTooDeep{name, MaxSubstitutionDepth}
+	}
+
+	// debugging support
+	Tracef("$(%s)", name)
+
+	// the same sub-sequence can be running somewhere else at the
+	// same time (eg, in a streaming pipeline), so we run a copy
+	sub = sub.clone()
+	sub.substitutionDepth = depth
+
+	// run it
+	params := getParamsFromEnv(p.Env)
+	sub.execFromPipe(PipeContext(p), p, params...)
+
+	// like UNIX shells, stderr is not captured
+	io.Copy(p.Stderr, sub.Pipe.Stderr)
+
+	// did it work?
+	output, err := sub.TrimmedString()
+	statusCode := sub.StatusCode()
+	if err != nil || statusCode != StatusOkay {
+		return "", ErrSubstitutionFailed{name, statusCode, err}
+	}
+
+	// debugging support
+	Tracef("=> $(%s): %#v", name, output)
+
+	// all done
+	return output, nil
+}
+
+// lookupSubstitution finds the named sub-sequence
+//
+// We look in the Substitutions of the sequence that the pipe belongs to
+// first, and then in the Substitutions of whatever is running it.
+func lookupSubstitution(p *Pipe, name string) (*Sequence, bool) {
+	env, ok := getSequenceEnv(p)
+	if !ok {
+		return nil, false
+	}
+
+	for ; env != nil; env = env.caller {
+		sub, found := env.substitutions[name]
+		if found && sub != nil {
+			return sub, true
+		}
+	}
+
+	// if we get here, nobody knows about it
+	return nil, false
+}
+
+// escapeSubstitution escapes the output of a sub-sequence, so that it
+// is not expanded again
+func escapeSubstitution(output string) string {
+	var buf strings.Builder
+	for i := 0; i < len(output); i++ {
+		switch output[i] {
+		case '\\', '$', '{', '}':
+			buf.WriteByte('\\')
+		}
+		buf.WriteByte(output[i])
+	}
+
+	return buf.String()
+}
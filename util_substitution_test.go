@@ -0,0 +1,415 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubstitutionsSubstituteTheOutputOfTheNamedSequence(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := "built at 1577836800 on buildhost\n"
+	list := NewList(
+		Echo("built at $(now) on $(hostname)"),
+	)
+	list.Substitutions["now"] = NewPipeline(Echo("1577836800"))
+	list.Substitutions["hostname"] = NewPipeline(Echo("  buildhost  "))
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec().String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestSubstitutionsRunWithTheCallersPositionalParameters(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := "version: v1.2.3\n"
+	list := NewList(
+		Echo("version: $(version)"),
+	)
+	list.Substitutions["version"] = NewPipeline(
+		Echo("$1"),
+		Tr([]string{"release-"}, []string{"v"}),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec("release-1.2.3").String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestSubstitutionsAreNotExpandedAgain(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := "[$HOME {a,b}]\n"
+	list := NewList(
+		Echo("[$(literal)]"),
+	)
+	list.Substitutions["literal"] = NewPipeline(
+		Echo(`\$HOME \{a,b\}`),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec().String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestSubstitutionsIgnoreEscapedDollarSigns(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := "$(now)\n"
+	list := NewList(
+		Echo(`\$(now)`),
+	)
+	list.Substitutions["now"] = NewPipeline(Echo("1577836800"))
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec().String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestSubstitutionsCanBeUsedFromLogicCalls(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := "hello world\n"
+	list := NewList(
+		If(
+			NewList(TestNotEmpty("$(greeting)")),
+			NewList(Echo("$(greeting) world")),
+		),
+	)
+	list.Substitutions["greeting"] = NewPipeline(Echo("hello"))
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec().String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestSubstitutionsWorkInFilepaths(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	tmpDir, err := ioutil.TempDir("", "scriptish-substitution-")
+	assert.Nil(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	expectedResult := filepath.Join(tmpDir, "build-42")
+	list := NewList(
+		Mkdir("$1/build-$(buildnumber)", 0755),
+	)
+	list.Substitutions["buildnumber"] = NewPipeline(Echo("42"))
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	err = list.Exec(tmpDir).Error()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	stat, err := os.Stat(expectedResult)
+	assert.Nil(t, err)
+	assert.True(t, stat.IsDir())
+}
+
+func TestSubstitutionsPassStderrThrough(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedStdout := "hello\n"
+	expectedStderr := "warning\n"
+	list := NewList(
+		Echo("$(greeting)"),
+	)
+	list.Substitutions["greeting"] = NewList(
+		EchoToStderr("warning"),
+		Echo("hello"),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	list.Exec()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	actualStdout, err := list.String()
+	assert.Nil(t, err)
+	assert.Equal(t, expectedStdout, actualStdout)
+	actualStderr, _ := ioutil.ReadAll(list.Pipe.Stderr)
+	assert.Equal(t, expectedStderr, string(actualStderr))
+}
+
+func TestSubstitutionsReturnErrExpansionForUnknownNames(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedErr := ErrExpansion{
+		"built at $(now)",
+		ErrUnknownSubstitution{"now"},
+	}
+	list := NewList(
+		Echo("built at $(now)"),
+		Echo("this should not be seen"),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec().String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, expectedErr, err)
+	assert.Equal(t, StatusNotOkay, list.StatusCode())
+	assert.Equal(t, "", actualResult)
+}
+
+func TestSubstitutionsReportFailuresThroughTheCallingStep(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	list := NewList(
+		Echo("built at $(now)"),
+		Echo("this should not be seen"),
+	)
+	list.Substitutions["now"] = NewList(
+		Echo("partial output"),
+		Return(3),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec().String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.IsType(t, ErrExpansion{}, err)
+	var failed ErrSubstitutionFailed
+	assert.True(t, errors.As(err, &failed))
+	assert.Equal(t, "now", failed.Name)
+	assert.Equal(t, 3, failed.StatusCode)
+	assert.Equal(t, StatusNotOkay, list.StatusCode())
+	assert.Equal(t, "", actualResult)
+}
+
+func TestSubstitutionsReturnErrBadSubstitutionWhenTheyAreNotClosed(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedErr := ErrExpansion{
+		"built at $(now",
+		ErrBadSubstitution{"$(now"},
+	}
+	list := NewList(
+		Echo("built at $(now"),
+	)
+	list.Substitutions["now"] = NewPipeline(Echo("1577836800"))
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	err := list.Exec().Error()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, expectedErr, err)
+}
+
+func TestRunSubstitutionsEvaluatesArithmeticExpansions(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := `3 \$((1 + 2))`
+	pipe := NewPipe()
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := runSubstitutions(pipe, `$((1 + 2)) \$((1 + 2))`, ShellOptions{})
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestSubstitutionsCanRunAtTheSameTime(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	var input, expectedResult []string
+	for i := 0; i < 50; i++ {
+		line := string(rune('a'+i%26)) + string(rune('a'+i/26))
+		input = append(input, line)
+		expectedResult = append(expectedResult, line+" is v"+line)
+	}
+
+	pipeline := NewPipeline(
+		EchoRawSlice(input),
+		XargsParallel(
+			4,
+			func() *Sequence {
+				return NewList(Echo("${1} is $(version)"))
+			},
+			XargsOptions{KeepOrder: true},
+		),
+	)
+	pipeline.Substitutions["version"] = NewList(
+		Assign("PREFIX", "v"),
+		Echo("${PREFIX}${1}"),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := pipeline.Exec().Strings()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestSubstitutionsReturnErrSubstitutionTooDeepWhenTheyNeverEnd(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	list := NewList(
+		Echo("$(self)"),
+		Echo("this should not be seen"),
+	)
+	list.Substitutions["self"] = NewList(Echo("$(self)"))
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult, err := list.Exec().String()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.IsType(t, ErrExpansion{}, err)
+	var tooDeep ErrSubstitutionTooDeep
+	assert.True(t, errors.As(err, &tooDeep))
+	assert.Equal(t, ErrSubstitutionTooDeep{"self", MaxSubstitutionDepth}, tooDeep)
+	assert.Equal(t, StatusNotOkay, list.StatusCode())
+	assert.Equal(t, "", actualResult)
+}
@@ -0,0 +1,627 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// StatusSyntaxError is the status code that Test() returns when it
+// cannot make sense of its arguments.
+//
+// It is the same status code that UNIX shells' `[[ ... ]]` uses.
+const StatusSyntaxError = 2
+
+// testBinaryOperators are the operators that go between two operands
+var testBinaryOperators = map[string]bool{
+	"=":   true,
+	"==":  true,
+	"!=":  true,
+	"<":   true,
+	">":   true,
+	"=~":  true,
+	"-eq": true,
+	"-ne": true,
+	"-lt": true,
+	"-le": true,
+	"-gt": true,
+	"-ge": true,
+	"-nt": true,
+	"-ot": true,
+	"-ef": true,
+}
+
+// testUnaryOperators are the operators that go in front of a single
+// operand
+var testUnaryOperators = map[string]bool{
+	"-a": true,
+	"-b": true,
+	"-c": true,
+	"-d": true,
+	"-e": true,
+	"-f": true,
+	"-g": true,
+	"-h": true,
+	"-k": true,
+	"-n": true,
+	"-o": true,
+	"-p": true,
+	"-r": true,
+	"-s": true,
+	"-t": true,
+	"-u": true,
+	"-v": true,
+	"-w": true,
+	"-x": true,
+	"-z": true,
+	"-G": true,
+	"-L": true,
+	"-N": true,
+	"-O": true,
+	"-S": true,
+}
+
+// testParser evaluates a `[[ ... ]]` expression, one argument at a time
+//
+// We only look for operators in the arguments that we were given. The
+// operands are expanded just before we use them, so that an operand can
+// never be mistaken for an operator, and so that we don't expand the
+// operands that short-circuiting skips over.
+type testParser struct {
+	// p is the pipe that we are evaluating the expression for
+	p *Pipe
+
+	// args is the expression that we are evaluating
+	args []string
+
+	// expArgs is args, with each operand replaced by its expansion
+	// once we have expanded it
+	expArgs []string
+
+	// pos is the next argument to look at
+	pos int
+
+	// noeval is true when short-circuiting means that we must parse
+	// the arguments without evaluating them
+	noeval bool
+}
+
+// evalTest evaluates the given `[[ ... ]]` expression
+//
+// It returns the evaluated expression (with all of the operands that it
+// used expanded), for debugging purposes.
+func evalTest(p *Pipe, args []string) (bool, []string, error) {
+	parser := testParser{
+		p:       p,
+		args:    args,
+		expArgs: append([]string{}, args...),
+	}
+
+	// special case - like `test`, no expression is false
+	if len(args) == 0 {
+		return false, parser.expArgs, nil
+	}
+
+	retval, err := parser.parseOr()
+	if err == nil && parser.pos < len(args) {
+		err = parser.syntaxError("unexpected argument " + strconv.Quote(args[parser.pos]))
+	}
+	if err != nil {
+		return false, parser.expArgs, err
+	}
+
+	// all done
+	return retval, parser.expArgs, nil
+}
+
+// testStatus turns the result of evalTest() into a status code
+func testStatus(result bool, err error) int {
+	if err != nil {
+		if _, ok := err.(ErrTestSyntax); ok {
+			return StatusSyntaxError
+		}
+		return StatusNotOkay
+	}
+
+	if !result {
+		return StatusNotOkay
+	}
+
+	return StatusOkay
+}
+
+func (t *testParser) syntaxError(message string) error {
+	return ErrTestSyntax{
+		Expr:    strings.Join(t.args, " "),
+		Message: message,
+	}
+}
+
+func (t *testParser) peek() (string, bool) {
+	if t.pos >= len(t.args) {
+		return "", false
+	}
+
+	return t.args[t.pos], true
+}
+
+// parseOr handles `expr || expr` and `expr -o expr`
+func (t *testParser) parseOr() (bool, error) {
+	retval, err := t.parseAnd()
+	if err != nil {
+		return false, err
+	}
+
+	for {
+		op, ok := t.peek()
+		if !ok || (op != "||" && op != "-o") {
+			return retval, nil
+		}
+		t.pos++
+
+		// short-circuiting
+		noeval := t.noeval
+		t.noeval = noeval || retval
+		rhs, err := t.parseAnd()
+		t.noeval = noeval
+		if err != nil {
+			return false, err
+		}
+
+		retval = retval || rhs
+	}
+}
+
+// parseAnd handles `expr && expr` and `expr -a expr`
+func (t *testParser) parseAnd() (bool, error) {
+	retval, err := t.parseNot()
+	if err != nil {
+		return false, err
+	}
+
+	for {
+		op, ok := t.peek()
+		if !ok || (op != "&&" && op != "-a") {
+			return retval, nil
+		}
+		t.pos++
+
+		// short-circuiting
+		noeval := t.noeval
+		t.noeval = noeval || !retval
+		rhs, err := t.parseNot()
+		t.noeval = noeval
+		if err != nil {
+			return false, err
+		}
+
+		retval = retval && rhs
+	}
+}
+
+// parseNot handles `! expr`
+func (t *testParser) parseNot() (bool, error) {
+	op, ok := t.peek()
+	if ok && op == "!" && t.pos+1 < len(t.args) && !t.isBinaryExpr() {
+		t.pos++
+		retval, err := t.parseNot()
+		return !retval, err
+	}
+
+	return t.parsePrimary()
+}
+
+// isBinaryExpr returns true if the next argument is the left-hand side
+// of a binary operator
+func (t *testParser) isBinaryExpr() bool {
+	return t.pos+2 < len(t.args) && testBinaryOperators[t.args[t.pos+1]]
+}
+
+// parsePrimary handles `( expr )`, binary operators, unary operators,
+// and a word on its own
+func (t *testParser) parsePrimary() (bool, error) {
+	arg, ok := t.peek()
+	if !ok {
+		return false, t.syntaxError("expression expected")
+	}
+
+	// `operand op operand`
+	if t.isBinaryExpr() {
+		t.pos += 3
+		return t.evalBinary(t.pos-3, t.args[t.pos-2], t.pos-1)
+	}
+
+	// `( expr )`
+	if arg == "(" {
+		t.pos++
+		retval, err := t.parseOr()
+		if err != nil {
+			return false, err
+		}
+		closing, ok := t.peek()
+		if !ok || closing != ")" {
+			return false, t.syntaxError("expected `)'")
+		}
+		t.pos++
+		return retval, nil
+	}
+
+	// `op operand`
+	if t.pos+1 < len(t.args) && testUnaryOperators[arg] {
+		t.pos += 2
+		return t.evalUnary(arg, t.pos-1)
+	}
+
+	// `word`
+	t.pos++
+	if t.noeval {
+		return false, nil
+	}
+	word, err := t.expandArg(t.pos - 1)
+	return word != "", err
+}
+
+// expandArg expands the argument at the given position
+func (t *testParser) expandArg(pos int) (string, error) {
+	retval, err := expandString(t.p, t.args[pos])
+	if err != nil {
+		return "", err
+	}
+
+	t.expArgs[pos] = retval
+	return retval, nil
+}
+
+// expandArgs expands the arguments at the given positions
+func (t *testParser) expandArgs(lhsPos, rhsPos int) (string, string, error) {
+	lhs, err := t.expandArg(lhsPos)
+	if err != nil {
+		return "", "", err
+	}
+	rhs, err := t.expandArg(rhsPos)
+	if err != nil {
+		return "", "", err
+	}
+
+	return lhs, rhs, nil
+}
+
+func (t *testParser) evalBinary(lhsPos int, op string, rhsPos int) (bool, error) {
+	// special case - short-circuiting
+	if t.noeval {
+		return false, nil
+	}
+
+	// special case - like UNIX shells, we keep any escaped characters
+	// in a glob pattern or a regular expression escaped
+	var lhs, rhs string
+	var err error
+	switch op {
+	case "=", "==", "!=", "=~":
+		lhs, rhs, err = t.expandPatternArgs(lhsPos, rhsPos)
+	default:
+		lhs, rhs, err = t.expandArgs(lhsPos, rhsPos)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	switch op {
+	case "=~":
+		return t.evalRegexp(lhs, rhs)
+	case "=", "==":
+		return matchGlob(rhs, lhs)
+	case "!=":
+		matched, err := matchGlob(rhs, lhs)
+		return !matched, err
+	case "<":
+		return lhs < rhs, nil
+	case ">":
+		return lhs > rhs, nil
+	case "-nt", "-ot", "-ef":
+		return t.evalFileComparison(lhs, op, rhs)
+	}
+
+	return t.evalArithComparison(lhs, op, rhs)
+}
+
+// expandPatternArgs expands the arguments at the given positions. The
+// second one is a glob pattern or a regular expression, so it keeps any
+// escaped characters escaped.
+func (t *testParser) expandPatternArgs(lhsPos, rhsPos int) (string, string, error) {
+	lhs, err := t.expandArg(lhsPos)
+	if err != nil {
+		return "", "", err
+	}
+	rhs, err := expandKeepingEscapes(t.p, t.args[rhsPos])
+	if err != nil {
+		return "", "", err
+	}
+	t.expArgs[rhsPos] = rhs
+
+	return lhs, rhs, nil
+}
+
+// evalRegexp matches lhs against the regular expression rhs
+//
+// Like UNIX shells, we put what matched into BASH_REMATCH. We don't have
+// arrays, so the first subexpression goes into BASH_REMATCH_1, the second
+// subexpression goes into BASH_REMATCH_2, and so on.
+func (t *testParser) evalRegexp(lhs, rhs string) (bool, error) {
+	re, err := regexp.Compile(rhs)
+	if err != nil {
+		return false, t.syntaxError("invalid regular expression " + strconv.Quote(rhs))
+	}
+
+	// get rid of the previous match
+	clearRematch(t.p)
+
+	matches := re.FindStringSubmatch(lhs)
+	if matches == nil {
+		return false, nil
+	}
+
+	for i, match := range matches {
+		name := "BASH_REMATCH"
+		if i > 0 {
+			name = name + "_" + strconv.Itoa(i)
+		}
+		err = setLocalVar(t.p, name, match)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	// all done
+	return true, nil
+}
+
+// clearRematch removes BASH_REMATCH and BASH_REMATCH_1 onwards
+func clearRematch(p *Pipe) {
+	for _, name := range p.Env.MatchVarNames("BASH_REMATCH") {
+		suffix := strings.TrimPrefix(name, "BASH_REMATCH")
+		if suffix == "" || isRematchSuffix(suffix) {
+			unsetLocalVar(p, name)
+		}
+	}
+}
+
+func isRematchSuffix(suffix string) bool {
+	if len(suffix) < 2 || suffix[0] != '_' {
+		return false
+	}
+	for i := 1; i < len(suffix); i++ {
+		if !isVarDigit(suffix[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (t *testParser) evalArithComparison(lhs, op, rhs string) (bool, error) {
+	nounset := pipeShellOptions(t.p).Nounset
+	vars := pipeArithVars{t.p}
+
+	lhsValue, err := evalArith(vars, lhs, nounset)
+	if err != nil {
+		return false, err
+	}
+	rhsValue, err := evalArith(vars, rhs, nounset)
+	if err != nil {
+		return false, err
+	}
+
+	switch op {
+	case "-eq":
+		return lhsValue == rhsValue, nil
+	case "-ne":
+		return lhsValue != rhsValue, nil
+	case "-lt":
+		return lhsValue < rhsValue, nil
+	case "-le":
+		return lhsValue <= rhsValue, nil
+	case "-gt":
+		return lhsValue > rhsValue, nil
+	}
+
+	// if we get here, it must be -ge
+	return lhsValue >= rhsValue, nil
+}
+
+func (t *testParser) evalFileComparison(lhs, op, rhs string) (bool, error) {
+	lhsInfo, lhsErr := os.Stat(resolvePipePath(t.p, lhs))
+	rhsInfo, rhsErr := os.Stat(resolvePipePath(t.p, rhs))
+
+	switch op {
+	case "-nt":
+		if lhsErr != nil {
+			return false, nil
+		}
+		if rhsErr != nil {
+			return true, nil
+		}
+		return lhsInfo.ModTime().After(rhsInfo.ModTime()), nil
+	case "-ot":
+		if rhsErr != nil {
+			return false, nil
+		}
+		if lhsErr != nil {
+			return true, nil
+		}
+		return lhsInfo.ModTime().Before(rhsInfo.ModTime()), nil
+	}
+
+	// if we get here, it must be -ef
+	if lhsErr != nil || rhsErr != nil {
+		return false, nil
+	}
+	return os.SameFile(lhsInfo, rhsInfo), nil
+}
+
+func (t *testParser) evalUnary(op string, pos int) (bool, error) {
+	// special case - we cannot tell when a file was last read
+	if op == "-N" {
+		return false, t.syntaxError("-N: unsupported operator")
+	}
+
+	// special case - short-circuiting
+	if t.noeval {
+		return false, nil
+	}
+
+	operand, err := t.expandArg(pos)
+	if err != nil {
+		return false, err
+	}
+
+	switch op {
+	case "-z":
+		return operand == "", nil
+	case "-n":
+		return operand != "", nil
+	case "-v":
+		_, ok := t.p.Env.LookupEnv(operand)
+		return ok, nil
+	case "-o":
+		return testShellOption(t.p, operand), nil
+	case "-t":
+		return testTerminal(operand), nil
+	case "-h", "-L":
+		info, err := os.Lstat(resolvePipePath(t.p, operand))
+		return err == nil && info.Mode()&os.ModeSymlink != 0, nil
+	case "-r":
+		return canAccessFile(resolvePipePath(t.p, operand), accessRead), nil
+	case "-w":
+		return canAccessFile(resolvePipePath(t.p, operand), accessWrite), nil
+	case "-x":
+		return canAccessFile(resolvePipePath(t.p, operand), accessExecute), nil
+	}
+
+	// everything else looks at what the operand points to
+	info, err := os.Stat(resolvePipePath(t.p, operand))
+	if err != nil {
+		return false, nil
+	}
+
+	return testFileInfo(op, info), nil
+}
+
+// testFileInfo applies the given unary file operator to a file that
+// exists
+func testFileInfo(op string, info os.FileInfo) bool {
+	mode := info.Mode()
+
+	switch op {
+	case "-b":
+		return mode&os.ModeDevice != 0 && mode&os.ModeCharDevice == 0
+	case "-c":
+		return mode&os.ModeCharDevice != 0
+	case "-d":
+		return mode.IsDir()
+	case "-f":
+		return mode.IsRegular()
+	case "-g":
+		return mode&os.ModeSetgid != 0
+	case "-k":
+		return mode&os.ModeSticky != 0
+	case "-p":
+		return mode&os.ModeNamedPipe != 0
+	case "-s":
+		return info.Size() > 0
+	case "-u":
+		return mode&os.ModeSetuid != 0
+	case "-S":
+		return mode&os.ModeSocket != 0
+	case "-O":
+		ownedByUs, _ := fileOwnership(info)
+		return ownedByUs
+	case "-G":
+		_, groupIsOurs := fileOwnership(info)
+		return groupIsOurs
+	}
+
+	// if we get here, it must be -a or -e
+	return true
+}
+
+// testShellOption returns true if the named `set -o` option is switched
+// on
+func testShellOption(p *Pipe, name string) bool {
+	opts := pipeShellOptions(p)
+
+	switch name {
+	case "errexit":
+		return opts.Errexit
+	case "nounset":
+		return opts.Nounset
+	case "pipefail":
+		return opts.Pipefail
+	case "noglob":
+		return opts.Noglob
+	}
+
+	return false
+}
+
+// testTerminal returns true if the given file descriptor is open, and
+// is a terminal
+//
+// We can only check our program's stdin, stdout and stderr.
+func testTerminal(operand string) bool {
+	var file *os.File
+
+	switch operand {
+	case "0":
+		file = os.Stdin
+	case "1":
+		file = os.Stdout
+	case "2":
+		file = os.Stderr
+	default:
+		return false
+	}
+
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,384 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testExpressions are evaluated by both Scriptish and bash, to make sure
+// that we get the same answers
+//
+// $d is a folder that contains `file` (not empty), `empty`, `script`
+// (executable), `older`, `subdir` and `link` (a symlink to `file`).
+var testExpressions = []string{
+	"",
+	"x",
+	"$empty",
+	"-z $empty",
+	"-z $x",
+	"-n $x",
+	"-n $empty",
+	"abc = abc",
+	"abc == a*",
+	"abc = b*",
+	"abc != abc",
+	"abc != x?z",
+	"a == \\*",
+	"* == \\*",
+	"a != \\*",
+	"* != \\*",
+	"a*c == a\\*c",
+	"abc == a\\*c",
+	"? == \\?",
+	"a == \\?",
+	"[ab] == \\[ab]",
+	"a == \\[ab]",
+	"$x = 5",
+	"abc < abd",
+	"abc > abd",
+	"b > abc",
+	"5 -eq $x",
+	"5 -ne 5",
+	"4 -lt $x",
+	"5 -le 5",
+	"x -gt 4",
+	"x+1 -ge 7",
+	"010 -eq 8",
+	"abc =~ ^a.c$",
+	"abc =~ ^b",
+	"v1.23 =~ ^v([0-9]+)\\.([0-9]+)$",
+	"v1x23 =~ ^v([0-9]+)\\.([0-9]+)$",
+	"$x =~ ^$x$",
+	"-v x",
+	"-v unset_var",
+	"! -v unset_var",
+	"! ! x",
+	"-e $d/file",
+	"-e $d/missing",
+	"-a $d/file",
+	"-f $d/file",
+	"-f $d/subdir",
+	"-d $d/subdir",
+	"-d $d/file",
+	"-s $d/file",
+	"-s $d/empty",
+	"-s $d/missing",
+	"-h $d/link",
+	"-L $d/file",
+	"-f $d/link",
+	"-r $d/file",
+	"-w $d/file",
+	"-x $d/script",
+	"-x $d/missing",
+	"-p $d/file",
+	"-S $d/file",
+	"-b $d/file",
+	"-c /dev/null",
+	"-u $d/file",
+	"-g $d/file",
+	"-k $d/file",
+	"-O $d/file",
+	"-G $d/file",
+	"$d/file -nt $d/older",
+	"$d/older -nt $d/file",
+	"$d/file -nt $d/missing",
+	"$d/older -ot $d/file",
+	"$d/missing -ot $d/file",
+	"$d/file -ef $d/link",
+	"$d/file -ef $d/empty",
+	"-o errexit",
+	"-o nosuchoption",
+	"-t 99",
+	"x && $empty",
+	"x && y",
+	"$empty || x",
+	"$empty || $empty",
+	"x || $empty && $empty",
+	"( x || $empty ) && $empty",
+	"! ( x && y )",
+	"-n $x && $x -eq 5 && -f $d/file",
+	"-e $d/missing || -d $d/subdir",
+	"= = =",
+	"-n",
+	"-f",
+	"!",
+}
+
+// makeTestExprFolder creates the folder that testExpressions use
+func makeTestExprFolder(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "scriptish-test-")
+	assert.Nil(t, err)
+
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "file"), []byte("hello\n"), 0644))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "empty"), []byte{}, 0644))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "script"), []byte("#!/bin/sh\n"), 0755))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "older"), []byte("old\n"), 0644))
+	assert.Nil(t, os.Mkdir(filepath.Join(dir, "subdir"), 0755))
+	assert.Nil(t, os.Symlink(filepath.Join(dir, "file"), filepath.Join(dir, "link")))
+
+	past := time.Now().Add(-time.Hour)
+	assert.Nil(t, os.Chtimes(filepath.Join(dir, "older"), past, past))
+
+	return dir
+}
+
+func TestTestExpressionsMatchBash(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not found")
+	}
+
+	dir := makeTestExprFolder(t)
+	defer os.RemoveAll(dir)
+
+	vars := "d=" + dir + " x=5 empty=;"
+
+	for _, expr := range testExpressions {
+		cmd := exec.Command(bash, "-c", vars+" [[ "+expr+" ]]")
+		cmd.Env = []string{}
+		cmd.Stderr = ioutil.Discard
+		bashErr := cmd.Run()
+		expectedResult := StatusOkay
+		if exitErr, ok := bashErr.(*exec.ExitError); ok {
+			expectedResult = exitErr.ExitCode()
+		}
+
+		// bash can only tell us that an empty expression is a syntax
+		// error
+		if expr == "" {
+			expectedResult = StatusNotOkay
+		}
+
+		list := NewList(Test(strings.Fields(expr)))
+		list.LocalVars.Setenv("d", dir)
+		list.LocalVars.Setenv("x", "5")
+		list.LocalVars.Setenv("empty", "")
+
+		// ----------------------------------------------------------------
+		// perform the change
+
+		actualResult := list.Exec().StatusCode()
+
+		// ----------------------------------------------------------------
+		// test the results
+
+		assert.Equal(t, expectedResult, actualResult, expr)
+	}
+}
+
+func TestTestExpressionSetsBashRematch(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	list := NewList(
+		Test([]string{"v1.23", "=~", `^v([0-9]+)\.([0-9]+)$`}),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult := list.Exec().StatusCode()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, StatusOkay, actualResult)
+	assert.Equal(t, "v1.23", list.LocalVars.Getenv("BASH_REMATCH"))
+	assert.Equal(t, "1", list.LocalVars.Getenv("BASH_REMATCH_1"))
+	assert.Equal(t, "23", list.LocalVars.Getenv("BASH_REMATCH_2"))
+}
+
+func TestTestExpressionClearsBashRematchWhenRegexpDoesNotMatch(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	list := NewList(
+		Test([]string{"v1.23", "=~", `^v([0-9]+)\.([0-9]+)$`}),
+		Test([]string{"abc", "=~", `^v([0-9]+)$`}),
+	)
+	list.LocalVars.Setenv("BASH_REMATCHES", "keep me")
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult := list.Exec().StatusCode()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, StatusNotOkay, actualResult)
+	assert.Equal(t, []string{"BASH_REMATCHES"}, list.LocalVars.MatchVarNames("BASH_REMATCH"))
+}
+
+func TestTestExpressionDoesNotExpandOperandsItSkips(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	list := NewList(
+		Test([]string{"x", "||", "$((count += 1))", "&&", "$empty"}),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult := list.Exec().StatusCode()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, StatusOkay, actualResult)
+	_, ok := list.LocalVars.LookupEnv("count")
+	assert.False(t, ok)
+}
+
+func TestTestExpressionDoesNotTreatExpandedOperandsAsOperators(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	list := NewList(
+		Test([]string{"$op", "=", "-n"}),
+	)
+	list.LocalVars.Setenv("op", "-n")
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult := list.Exec().StatusCode()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, StatusOkay, actualResult)
+}
+
+func TestTestExpressionReturnsErrTestSyntaxForBadExpressions(t *testing.T) {
+	t.Parallel()
+
+	testData := []struct {
+		args        []string
+		expectedErr error
+	}{
+		{
+			[]string{"(", "x"},
+			ErrTestSyntax{"( x", "expected `)'"},
+		},
+		{
+			[]string{"x", "y"},
+			ErrTestSyntax{"x y", `unexpected argument "y"`},
+		},
+		{
+			[]string{"x", "&&"},
+			ErrTestSyntax{"x &&", "expression expected"},
+		},
+		{
+			[]string{"abc", "=~", "("},
+			ErrTestSyntax{"abc =~ (", `invalid regular expression "("`},
+		},
+		{
+			[]string{"-N", "file"},
+			ErrTestSyntax{"-N file", "-N: unsupported operator"},
+		},
+	}
+
+	for _, testCase := range testData {
+		// ----------------------------------------------------------------
+		// setup your test
+
+		pipeline := NewPipeline(Test(testCase.args))
+
+		// ----------------------------------------------------------------
+		// perform the change
+
+		actualResult, err := pipeline.Exec().StatusError()
+
+		// ----------------------------------------------------------------
+		// test the results
+
+		assert.Equal(t, testCase.expectedErr, err, testCase.args)
+		assert.Equal(t, StatusSyntaxError, actualResult, testCase.args)
+	}
+}
+
+func TestTestExpressionResolvesPathsFromTheWorkingDirectory(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	dir := makeTestExprFolder(t)
+	defer os.RemoveAll(dir)
+
+	list := NewList(
+		Cd(dir),
+		Test([]string{"-f", "file", "&&", "-d", "subdir"}),
+	)
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	actualResult := list.Exec().StatusCode()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, StatusOkay, actualResult)
+}
@@ -0,0 +1,269 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"bufio"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// textStreamReader is a TextReader that reads from one end of an io.Pipe.
+//
+// We use it to connect the steps of a streaming pipeline together.
+type textStreamReader struct {
+	r *io.PipeReader
+
+	// done is closed when the stream is closed, so that any goroutine
+	// feeding ReadLines() or ReadWords() can stop
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// textStreamWriter is a TextReaderWriter that writes to one end of an
+// io.Pipe.
+//
+// Nothing can be read back from it; anything written to it goes to the
+// next step in the streaming pipeline.
+type textStreamWriter struct {
+	w *io.PipeWriter
+
+	// onWriteError is called the first time that a write fails, which
+	// happens when the other end of the stream has been closed
+	//
+	// The streaming pipeline uses it to stop the step that is writing
+	// to us, just like SIGPIPE stops a UNIX command. It can be nil.
+	onWriteError func()
+	errorOnce    sync.Once
+}
+
+// newTextStream creates an io.Pipe, and wraps both ends so that they
+// can be used as a Pipe's Stdin and Stdout
+func newTextStream() (*textStreamReader, *textStreamWriter) {
+	r, w := io.Pipe()
+	return &textStreamReader{r: r, done: make(chan struct{})}, &textStreamWriter{w: w}
+}
+
+// Close stops the stream. Any writes to the other end of the stream
+// will fail with io.ErrClosedPipe.
+func (s *textStreamReader) Close() error {
+	s.closeOnce.Do(func() { close(s.done) })
+	return s.r.Close()
+}
+
+// ParseInt returns the contents of the stream as an integer
+func (s *textStreamReader) ParseInt() (int, error) {
+	return strconv.Atoi(s.TrimmedString())
+}
+
+// Read is the standard io.Reader interface
+func (s *textStreamReader) Read(b []byte) (int, error) {
+	return s.r.Read(b)
+}
+
+// ReadLine returns the next line from the stream, including its
+// trailing newline. It returns io.EOF if the stream ends before the
+// next newline.
+//
+// It reads one byte at a time, so that nothing after the line is
+// taken out of the stream.
+func (s *textStreamReader) ReadLine() (string, error) {
+	var line []byte
+	buf := make([]byte, 1)
+	for {
+		n, err := s.r.Read(buf)
+		if n > 0 {
+			line = append(line, buf[0])
+			if buf[0] == '\n' {
+				return string(line), nil
+			}
+		}
+		if err != nil {
+			return string(line), err
+		}
+	}
+}
+
+// ReadLines returns a channel that you can `range` over to read the
+// stream one line at a time
+func (s *textStreamReader) ReadLines() <-chan string {
+	return scanStream(s.r, bufio.ScanLines, s.done)
+}
+
+// ReadWords returns a channel that you can `range` over to read the
+// stream one word at a time
+func (s *textStreamReader) ReadWords() <-chan string {
+	return scanStream(s.r, bufio.ScanWords, s.done)
+}
+
+// String returns everything left in the stream, as a single string.
+//
+// It blocks until the other end of the stream has been closed.
+func (s *textStreamReader) String() string {
+	retval, _ := ioutil.ReadAll(s.r)
+	return string(retval)
+}
+
+// Strings returns everything left in the stream, one string per line.
+//
+// It blocks until the other end of the stream has been closed.
+func (s *textStreamReader) Strings() []string {
+	retval := []string{}
+	for line := range s.ReadLines() {
+		retval = append(retval, line)
+	}
+
+	return retval
+}
+
+// TrimmedString returns everything left in the stream, as a single
+// string. Any leading or trailing whitespace is removed.
+func (s *textStreamReader) TrimmedString() string {
+	return strings.TrimSpace(s.String())
+}
+
+// Close tells the other end of the stream that there is no more input
+// to come
+func (s *textStreamWriter) Close() error {
+	return s.w.Close()
+}
+
+// ParseInt always fails, because there is nothing to read back
+func (s *textStreamWriter) ParseInt() (int, error) {
+	return strconv.Atoi("")
+}
+
+// Read always returns io.EOF, because there is nothing to read back
+func (s *textStreamWriter) Read(b []byte) (int, error) {
+	return 0, io.EOF
+}
+
+// ReadLine always returns io.EOF, because there is nothing to read back
+func (s *textStreamWriter) ReadLine() (string, error) {
+	return "", io.EOF
+}
+
+// ReadLines returns a closed channel, because there is nothing to read
+// back
+func (s *textStreamWriter) ReadLines() <-chan string {
+	return scanStream(s, bufio.ScanLines, nil)
+}
+
+// ReadWords returns a closed channel, because there is nothing to read
+// back
+func (s *textStreamWriter) ReadWords() <-chan string {
+	return scanStream(s, bufio.ScanWords, nil)
+}
+
+// String always returns an empty string, because there is nothing to
+// read back
+func (s *textStreamWriter) String() string {
+	return ""
+}
+
+// Strings always returns an empty slice, because there is nothing to
+// read back
+func (s *textStreamWriter) Strings() []string {
+	return []string{}
+}
+
+// TrimmedString always returns an empty string, because there is nothing
+// to read back
+func (s *textStreamWriter) TrimmedString() string {
+	return ""
+}
+
+// Write is the standard io.Writer interface.
+//
+// It blocks until the other end of the stream has read everything
+// that we have written.
+func (s *textStreamWriter) Write(b []byte) (int, error) {
+	n, err := s.w.Write(b)
+	if err != nil && s.onWriteError != nil {
+		s.errorOnce.Do(s.onWriteError)
+	}
+
+	return n, err
+}
+
+// WriteByte writes a single byte to the stream
+func (s *textStreamWriter) WriteByte(c byte) error {
+	_, err := s.Write([]byte{c})
+	return err
+}
+
+// WriteRune writes a single UTF-8 character to the stream
+func (s *textStreamWriter) WriteRune(r rune) (int, error) {
+	return s.Write([]byte(string(r)))
+}
+
+// WriteString writes a string to the stream
+func (s *textStreamWriter) WriteString(str string) (int, error) {
+	return s.Write([]byte(str))
+}
+
+// scanStream returns a channel that is fed from the given io.Reader,
+// split up by the given bufio.SplitFunc
+//
+// The goroutine feeding the channel stops when the done channel is
+// closed, so that it does not leak when nobody is reading from the
+// channel any more. done can be nil.
+func scanStream(r io.Reader, splitFunc bufio.SplitFunc, done <-chan struct{}) <-chan string {
+	retval := make(chan string)
+
+	go func() {
+		defer close(retval)
+
+		scanner := bufio.NewScanner(r)
+		scanner.Split(splitFunc)
+		for scanner.Scan() {
+			select {
+			case retval <- scanner.Text():
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return retval
+}
@@ -0,0 +1,156 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTextStreamReaderReadLineReturnsOneLineAtATime(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	reader, writer := newTextStream()
+	go func() {
+		writer.WriteString("hello world\nhave a nice day")
+		writer.Close()
+	}()
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	line1, err1 := reader.ReadLine()
+	line2, err2 := reader.ReadLine()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Nil(t, err1)
+	assert.Equal(t, "hello world\n", line1)
+	assert.Equal(t, io.EOF, err2)
+	assert.Equal(t, "have a nice day", line2)
+}
+
+func TestTextStreamReaderReadLineLeavesTheRestOfTheStreamAlone(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	expectedResult := []string{"have a nice day", "goodbye"}
+
+	reader, writer := newTextStream()
+	go func() {
+		writer.WriteString("hello world\nhave a nice day\ngoodbye\n")
+		writer.Close()
+	}()
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	reader.ReadLine()
+	actualResult := reader.Strings()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestTextStreamReaderReadLinesStopsWhenTheStreamIsClosed(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	reader, writer := newTextStream()
+	go func() {
+		for {
+			_, err := writer.WriteString("y\n")
+			if err != nil {
+				return
+			}
+		}
+	}()
+	lines := reader.ReadLines()
+	<-lines
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	reader.Close()
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	// the channel must be closed, or this loop never ends
+	for range lines {
+	}
+}
+
+func TestTextStreamWriterCallsOnWriteErrorOnceWhenTheStreamIsClosed(t *testing.T) {
+	t.Parallel()
+
+	// ----------------------------------------------------------------
+	// setup your test
+
+	reader, writer := newTextStream()
+	calls := 0
+	writer.onWriteError = func() { calls++ }
+	reader.Close()
+
+	// ----------------------------------------------------------------
+	// perform the change
+
+	_, err1 := writer.WriteString("hello world\n")
+	err2 := writer.WriteByte('x')
+
+	// ----------------------------------------------------------------
+	// test the results
+
+	assert.Equal(t, io.ErrClosedPipe, err1)
+	assert.Equal(t, io.ErrClosedPipe, err2)
+	assert.Equal(t, 1, calls)
+}
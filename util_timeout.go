@@ -0,0 +1,79 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"context"
+	"time"
+)
+
+// StatusTimeout is the status code set by Timeout() and WithTimeout()
+// when they stop something that has run for too long.
+//
+// It is the same status code that the UNIX `timeout` command uses.
+const StatusTimeout = 124
+
+// timeoutStatusError works out what the status code and error should be,
+// after something has been run under a context created by
+// context.WithTimeout().
+//
+// If the context's deadline caused the failure, we return StatusTimeout
+// and an ErrTimeout. Otherwise, we return the statusCode and err that we
+// were given.
+func timeoutStatusError(ctx, parent context.Context, d time.Duration, statusCode int, err error) (int, error) {
+	// did it succeed?
+	if err == nil {
+		return statusCode, err
+	}
+
+	// did we run out of time?
+	if ctx.Err() != context.DeadlineExceeded {
+		return statusCode, err
+	}
+
+	// if our parent has been cancelled too, that's what the caller
+	// needs to know about
+	if parent.Err() != nil {
+		return statusCode, err
+	}
+
+	// if we get here, it is definitely our fault
+	return StatusTimeout, ErrTimeout{d}
+}
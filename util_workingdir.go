@@ -0,0 +1,188 @@
+// scriptish is a library to help you port bash scripts to Golang
+//
+// inspired by:
+//
+// - http://labix.org/pipe
+// - https://github.com/bitfield/script
+//
+// Copyright 2019-present Ganbaro Digital Ltd
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions
+// are met:
+//
+//   * Redistributions of source code must retain the above copyright
+//     notice, this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//     notice, this list of conditions and the following disclaimer in
+//     the documentation and/or other materials provided with the
+//     distribution.
+//
+//   * Neither the names of the copyright holders nor the names of his
+//     contributors may be used to endorse or promote products derived
+//     from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS
+// FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE
+// COPYRIGHT OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT,
+// INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING,
+// BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+// LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+// CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT
+// LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN
+// ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package scriptish
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// PipeDir returns the working directory of the given pipe.
+//
+// Use it in your own Scriptish commands to work out where any relative
+// paths should point to.
+//
+// If the pipe was not created by a Sequence, or the sequence has not
+// changed directory, you get back the program's current working
+// directory.
+func PipeDir(p *Pipe) string {
+	env, ok := getSequenceEnv(p)
+	if ok && env.dir != "" {
+		return env.dir
+	}
+
+	// if we get here, we fall back to the program's working directory
+	retval, err := os.Getwd()
+	if err != nil {
+		return "."
+	}
+	return retval
+}
+
+// setPipeDir makes the given directory the working directory for every
+// command that uses the pipe
+func setPipeDir(p *Pipe, dir string) {
+	env, ok := getSequenceEnv(p)
+	if ok {
+		env.dir = dir
+	}
+}
+
+// inheritPipeDir sets the working directory of a sequence's pipe, when
+// that sequence is called from a step that is running in the parent pipe
+//
+// dir is the sequence's own Dir, which may be empty
+func inheritPipeDir(p *Pipe, parent *Pipe, dir string) {
+	// does the sequence start somewhere special?
+	if dir != "" {
+		setPipeDir(p, resolvePipePath(parent, dir))
+		return
+	}
+
+	// no, it starts wherever the caller is
+	env, ok := getSequenceEnv(parent)
+	if ok {
+		setPipeDir(p, env.dir)
+	}
+}
+
+// resolvePipePath returns the path that we need to give to the operating
+// system, to find the given path from the pipe's working directory
+func resolvePipePath(p *Pipe, path string) string {
+	// special case - leave empty paths for the caller to deal with
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+
+	// special case - we are still in the program's working directory
+	env, ok := getSequenceEnv(p)
+	if !ok || env.dir == "" {
+		return path
+	}
+
+	// general case
+	return filepath.Join(env.dir, path)
+}
+
+// changePipeDir makes the given directory the pipe's working directory.
+//
+// The directory is resolved against the pipe's current working directory.
+// It must exist.
+func changePipeDir(p *Pipe, dir string) error {
+	newDir, err := filepath.Abs(resolvePipePath(p, dir))
+	if err != nil {
+		return err
+	}
+
+	// make sure it is a directory
+	info, err := os.Stat(newDir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return &os.PathError{Op: "chdir", Path: dir, Err: syscall.ENOTDIR}
+	}
+
+	// debugging support
+	Tracef("working directory is now %#v", newDir)
+
+	// all done
+	setPipeDir(p, newDir)
+	return nil
+}
+
+// cdPipeDir makes the given directory the pipe's working directory,
+// and updates $PWD and $OLDPWD, just like UNIX shells do
+func cdPipeDir(p *Pipe, dir string) error {
+	oldDir := PipeDir(p)
+
+	err := changePipeDir(p, dir)
+	if err != nil {
+		return err
+	}
+
+	env, ok := getSequenceEnv(p)
+	if ok {
+		env.localVars.Setenv("OLDPWD", oldDir)
+		env.localVars.Setenv("PWD", env.dir)
+	}
+
+	// all done
+	return nil
+}
+
+// pushPipeDir adds the pipe's working directory to the top of
+// the pipe's directory stack
+func pushPipeDir(p *Pipe) {
+	env, ok := getSequenceEnv(p)
+	if !ok {
+		return
+	}
+
+	// we don't want to share the stack's backing array with any other
+	// copies of this environment
+	stackLen := len(env.dirStack)
+	env.dirStack = append(env.dirStack[:stackLen:stackLen], PipeDir(p))
+}
+
+// popPipeDir removes the directory at the top of the pipe's directory
+// stack, and returns it
+func popPipeDir(p *Pipe) (string, bool) {
+	env, ok := getSequenceEnv(p)
+	if !ok || len(env.dirStack) == 0 {
+		return "", false
+	}
+
+	stackLen := len(env.dirStack)
+	retval := env.dirStack[stackLen-1]
+	env.dirStack = env.dirStack[:stackLen-1]
+	return retval, true
+}